@@ -192,4 +192,8 @@ export interface FeatureToggles {
   alertingCentralAlertHistory?: boolean;
   pluginProxyPreserveTrailingSlash?: boolean;
   azureMonitorPrometheusExemplars?: boolean;
+  liveAccessControl?: boolean;
+  alertingLiveStreamingRules?: boolean;
+  lokiBackendQuerySplitting?: boolean;
+  influxdbSchemaResources?: boolean;
 }
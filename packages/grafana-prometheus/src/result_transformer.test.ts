@@ -2,6 +2,7 @@
 import {
   cacheFieldDisplayNames,
   createDataFrame,
+  DataFrameType,
   FieldType,
   type DataQueryRequest,
   type DataQueryResponse,
@@ -412,6 +413,50 @@ describe('Prometheus Result Transformer', () => {
       expect(series.data[0].fields[3].name).toEqual('+Inf');
     });
 
+    it('results with heatmap format should keep series marked as graph out of the heatmap', () => {
+      const options = {
+        targets: [
+          {
+            format: 'heatmap',
+            refId: 'A',
+          },
+        ],
+      } as unknown as DataQueryRequest<PromQuery>;
+      const response = {
+        state: 'Done',
+        data: [
+          createDataFrame({
+            refId: 'A',
+            fields: [
+              { name: 'Time', type: FieldType.time, values: [6, 5, 4] },
+              { name: 'Value', type: FieldType.number, values: [10, 10, 0], labels: { le: '1' } },
+            ],
+          }),
+          createDataFrame({
+            refId: 'A',
+            fields: [
+              { name: 'Time', type: FieldType.time, values: [6, 5, 4] },
+              { name: 'Value', type: FieldType.number, values: [30, 10, 40], labels: { le: '+Inf' } },
+            ],
+          }),
+          createDataFrame({
+            refId: 'A',
+            meta: { preferredVisualisationType: 'graph' },
+            fields: [
+              { name: 'Time', type: FieldType.time, values: [6, 5, 4] },
+              { name: 'Value', type: FieldType.number, values: [0.9, 0.8, 0.95] },
+            ],
+          }),
+        ],
+      } as unknown as DataQueryResponse;
+
+      const series = transformV2(response, options, {});
+      expect(series.data.length).toEqual(2);
+      expect(series.data[0].fields[1].values).toEqual([0.9, 0.8, 0.95]);
+      expect(series.data[1].meta?.type).toEqual(DataFrameType.HeatmapRows);
+      expect(series.data[1].fields[2].values).toEqual([20, 0, 40]);
+    });
+
     it('results with heatmap format (with metric name) should be correctly transformed', () => {
       const options = {
         targets: [
@@ -43,6 +43,11 @@ const isCumulativeHeatmapResult = (dataFrame: DataFrame, options: DataQueryReque
     return false;
   }
 
+  // series returned next to histogram buckets that are not buckets themselves, like histogram_quantile results
+  if (dataFrame.meta?.preferredVisualisationType === 'graph') {
+    return false;
+  }
+
   const target = options.targets.find((target) => target.refId === dataFrame.refId);
   return target?.format === 'heatmap';
 };
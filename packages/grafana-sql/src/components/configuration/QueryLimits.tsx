@@ -0,0 +1,95 @@
+import React from 'react';
+
+import { DataSourceSettings } from '@grafana/data';
+import { ConfigSubSection, Stack } from '@grafana/experimental';
+import { Field, Icon, Label, Tooltip } from '@grafana/ui';
+
+import { SQLOptions, SQLQueryLimits } from '../../types';
+
+import { NumberInput } from './NumberInput';
+
+interface Props {
+  onOptionsChange: Function;
+  options: DataSourceSettings<SQLOptions>;
+}
+
+export const QueryLimits = (props: Props) => {
+  const { onOptionsChange, options } = props;
+  const jsonData = options.jsonData;
+
+  const onJSONDataNumberChanged = (property: keyof SQLQueryLimits) => {
+    return (number?: number) => {
+      onOptionsChange({
+        ...options,
+        jsonData: {
+          ...jsonData,
+          [property]: number,
+        },
+      });
+    };
+  };
+
+  const labelWidth = 40;
+
+  return (
+    <ConfigSubSection title="Query limits">
+      <Field
+        label={
+          <Label>
+            <Stack gap={0.5}>
+              <span>Statement timeout</span>
+              <Tooltip
+                content={
+                  <span>
+                    The maximum amount of time in seconds a query may run. Queries running longer are cancelled on the
+                    database server. If set to 0, there is no timeout.
+                  </span>
+                }
+              >
+                <Icon name="info-circle" size="sm" />
+              </Tooltip>
+            </Stack>
+          </Label>
+        }
+      >
+        <NumberInput
+          value={jsonData.statementTimeout ?? 0}
+          defaultValue={0}
+          onChange={(value) => {
+            onJSONDataNumberChanged('statementTimeout')(value);
+          }}
+          width={labelWidth}
+        />
+      </Field>
+
+      <Field
+        label={
+          <Label>
+            <Stack gap={0.5}>
+              <span>Max result size</span>
+              <Tooltip
+                content={
+                  <span>
+                    The maximum estimated size in bytes of the result of a query. Results are truncated once the limit
+                    is reached. If set to 0, there is no limit on the size of the results.
+                  </span>
+                }
+              >
+                <Icon name="info-circle" size="sm" />
+              </Tooltip>
+            </Stack>
+          </Label>
+        }
+      >
+        <NumberInput
+          value={jsonData.resultBytesLimit ?? 0}
+          defaultValue={0}
+          onChange={(value) => {
+            onJSONDataNumberChanged('resultBytesLimit')(value);
+          }}
+          width={labelWidth}
+        />
+      </Field>
+    </ConfigSubSection>
+  );
+};
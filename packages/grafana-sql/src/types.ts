@@ -34,7 +34,12 @@ export interface SQLConnectionLimits {
   connMaxLifetime: number;
 }
 
-export interface SQLOptions extends SQLConnectionLimits, DataSourceJsonData {
+export interface SQLQueryLimits {
+  statementTimeout?: number;
+  resultBytesLimit?: number;
+}
+
+export interface SQLOptions extends SQLConnectionLimits, SQLQueryLimits, DataSourceJsonData {
   tlsAuth: boolean;
   tlsAuthWithCACert: boolean;
   timezone: string;
@@ -10,6 +10,7 @@ import (
 	"github.com/grafana/grafana/pkg/services/datasources"
 	"github.com/grafana/grafana/pkg/services/featuremgmt"
 	"github.com/grafana/grafana/pkg/services/libraryelements"
+	"github.com/grafana/grafana/pkg/services/live"
 	"github.com/grafana/grafana/pkg/services/org"
 	"github.com/grafana/grafana/pkg/services/pluginsintegration/pluginaccesscontrol"
 	"github.com/grafana/grafana/pkg/tsdb/grafanads"
@@ -648,6 +649,10 @@ func (hs *HTTPServer) declareFixedRoles() error {
 		roles = append(roles, allAnnotationsReaderRole, allAnnotationsWriterRole)
 	}
 
+	if hs.Features.IsEnabled(context.Background(), featuremgmt.FlagLiveAccessControl) {
+		roles = append(roles, live.FixedRoles()...)
+	}
+
 	return hs.accesscontrolService.DeclareFixedRoles(roles...)
 }
 
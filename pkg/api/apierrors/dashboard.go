@@ -14,6 +14,11 @@ import (
 
 // ToDashboardErrorResponse returns a different response status according to the dashboard error type
 func ToDashboardErrorResponse(ctx context.Context, pluginStore pluginstore.Store, err error) response.Response {
+	var conflictErr dashboards.DashboardMergeConflictError
+	if ok := errors.As(err, &conflictErr); ok {
+		return response.JSON(http.StatusPreconditionFailed, conflictErr.Body())
+	}
+
 	var dashboardErr dashboards.DashboardErr
 	if ok := errors.As(err, &dashboardErr); ok {
 		if body := dashboardErr.Body(); body != nil {
@@ -615,6 +615,7 @@ func (hs *HTTPServer) postDashboard(c *contextmodel.ReqContext, cmd dashboards.S
 		"uid":       dashboard.UID,
 		"url":       dashboard.GetURL(),
 		"folderUid": dashboard.FolderUID,
+		"merged":    dashItem.Merged,
 	})
 }
 
@@ -919,6 +920,119 @@ func (hs *HTTPServer) GetDashboardVersion(c *contextmodel.ReqContext) response.R
 	return response.JSON(http.StatusOK, dashVersionMeta)
 }
 
+// swagger:route GET /dashboards/uid/{uid}/versions/{DashboardVersionID}/diff dashboard_versions getDashboardVersionDiffByUID
+//
+// Get the structured diff between a dashboard version and its parent, or the version given as base.
+//
+// Responses:
+// 200: dashboardVersionDiffResponse
+// 400: badRequestError
+// 401: unauthorisedError
+// 403: forbiddenError
+// 404: notFoundError
+// 500: internalServerError
+func (hs *HTTPServer) GetDashboardVersionDiff(c *contextmodel.ReqContext) response.Response {
+	dash, rsp := hs.getDashboardHelper(c.Req.Context(), c.SignedInUser.GetOrgID(), 0, web.Params(c.Req)[":uid"])
+	if rsp != nil {
+		return rsp
+	}
+
+	guardian, err := guardian.NewByDashboard(c.Req.Context(), dash, c.SignedInUser.GetOrgID(), c.SignedInUser)
+	if err != nil {
+		return response.Err(err)
+	}
+	if canSave, err := guardian.CanSave(); err != nil || !canSave {
+		return dashboardGuardianResponse(err)
+	}
+
+	version, err := strconv.ParseInt(web.Params(c.Req)[":id"], 10, 32)
+	if err != nil {
+		return response.Error(http.StatusBadRequest, "version is invalid", err)
+	}
+
+	query := dashver.DiffDashboardVersionsQuery{
+		OrgID:        c.SignedInUser.GetOrgID(),
+		DashboardID:  dash.ID,
+		DashboardUID: dash.UID,
+		BaseVersion:  c.QueryInt("base"),
+		NewVersion:   int(version),
+	}
+	res, err := hs.dashboardVersionService.Diff(c.Req.Context(), &query)
+	if err != nil {
+		if errors.Is(err, dashver.ErrDashboardVersionNotFound) {
+			return response.Error(http.StatusNotFound, "Dashboard version not found", err)
+		}
+		return response.Error(http.StatusInternalServerError, "Unable to compute diff", err)
+	}
+
+	return response.JSON(http.StatusOK, res)
+}
+
+// swagger:route GET /dashboards/uid/{uid}/blame dashboard_versions getDashboardBlameByUID
+//
+// Get the last version and user changing each panel of a dashboard.
+//
+// Responses:
+// 200: dashboardBlameResponse
+// 401: unauthorisedError
+// 403: forbiddenError
+// 404: notFoundError
+// 500: internalServerError
+func (hs *HTTPServer) GetDashboardBlame(c *contextmodel.ReqContext) response.Response {
+	dash, rsp := hs.getDashboardHelper(c.Req.Context(), c.SignedInUser.GetOrgID(), 0, web.Params(c.Req)[":uid"])
+	if rsp != nil {
+		return rsp
+	}
+
+	guardian, err := guardian.NewByDashboard(c.Req.Context(), dash, c.SignedInUser.GetOrgID(), c.SignedInUser)
+	if err != nil {
+		return response.Err(err)
+	}
+	if canSave, err := guardian.CanSave(); err != nil || !canSave {
+		return dashboardGuardianResponse(err)
+	}
+
+	query := dashver.BlameDashboardVersionsQuery{
+		OrgID:        c.SignedInUser.GetOrgID(),
+		DashboardID:  dash.ID,
+		DashboardUID: dash.UID,
+	}
+	blame, err := hs.dashboardVersionService.Blame(c.Req.Context(), &query)
+	if err != nil {
+		if errors.Is(err, dashver.ErrNoVersionsForDashboardID) {
+			return response.Error(http.StatusNotFound, fmt.Sprintf("No versions found for dashboard %s", dash.UID), err)
+		}
+		return response.Error(http.StatusInternalServerError, "Failed to get dashboard blame", err)
+	}
+
+	loginMem := make(map[int64]string)
+	res := make([]dashver.PanelBlameMeta, 0, len(blame))
+	for _, b := range blame {
+		creator := anonString
+		if b.CreatedBy > 0 {
+			login, found := loginMem[b.CreatedBy]
+			if found {
+				creator = login
+			} else {
+				creator = hs.getUserLogin(c.Req.Context(), b.CreatedBy)
+				if creator != anonString {
+					loginMem[b.CreatedBy] = creator
+				}
+			}
+		}
+
+		res = append(res, dashver.PanelBlameMeta{
+			PanelID:   b.PanelID,
+			Title:     b.Title,
+			Version:   b.Version,
+			Created:   b.Created,
+			CreatedBy: creator,
+		})
+	}
+
+	return response.JSON(http.StatusOK, res)
+}
+
 // swagger:route POST /dashboards/calculate-diff dashboards calculateDashboardDiff
 //
 // Perform diff on two dashboards.
@@ -1038,11 +1152,16 @@ func (hs *HTTPServer) CalculateDashboardDiff(c *contextmodel.ReqContext) respons
 //
 // Restore a dashboard to a given dashboard version using UID.
 //
+// When merge is set, the changes made by the version to the panels are
+// reapplied onto the current dashboard instead, and the conflicting paths are
+// returned when they can't be merged.
+//
 // Responses:
 // 200: postDashboardResponse
 // 401: unauthorisedError
 // 403: forbiddenError
 // 404: notFoundError
+// 412: mergeDashboardVersionConflictResponse
 // 500: internalServerError
 func (hs *HTTPServer) RestoreDashboardVersion(c *contextmodel.ReqContext) response.Response {
 	var dashID int64
@@ -1097,9 +1216,35 @@ func (hs *HTTPServer) RestoreDashboardVersion(c *contextmodel.ReqContext) respon
 	saveCmd.OrgID = c.SignedInUser.GetOrgID()
 	saveCmd.UserID = userID
 	saveCmd.Dashboard = version.Data
+	saveCmd.Message = fmt.Sprintf("Restored from version %d", version.Version)
+	if apiCmd.Merge {
+		mergeCmd := dashver.MergeDashboardVersionCommand{
+			DashboardID:  dash.ID,
+			DashboardUID: dash.UID,
+			OrgID:        c.SignedInUser.GetOrgID(),
+			Version:      version.Version,
+			BaseVersion:  apiCmd.BaseVersion,
+			Current:      dash.Data,
+		}
+		merged, err := hs.dashboardVersionService.Merge(c.Req.Context(), &mergeCmd)
+		if err != nil {
+			if errors.Is(err, dashver.ErrDashboardVersionNotFound) {
+				return response.Error(http.StatusNotFound, "Dashboard version not found", err)
+			}
+			return response.Error(http.StatusInternalServerError, "Failed to merge dashboard version", err)
+		}
+		if len(merged.Conflicts) > 0 {
+			return response.JSON(http.StatusPreconditionFailed, util.DynMap{
+				"status":    "conflict",
+				"message":   fmt.Sprintf("The changes of version %d conflict with the dashboard", version.Version),
+				"conflicts": merged.Conflicts,
+			})
+		}
+		saveCmd.Dashboard = merged.Data
+		saveCmd.Message = fmt.Sprintf("Merged changes from version %d", version.Version)
+	}
 	saveCmd.Dashboard.Set("version", dash.Version)
 	saveCmd.Dashboard.Set("uid", dash.UID)
-	saveCmd.Message = fmt.Sprintf("Restored from version %d", version.Version)
 	// nolint:staticcheck
 	saveCmd.FolderID = dash.FolderID
 	metrics.MFolderIDsAPICount.WithLabelValues(metrics.RestoreDashboardVersion).Inc()
@@ -1180,6 +1325,26 @@ type RestoreDashboardVersionByUIDParams struct {
 	UID string `json:"uid"`
 }
 
+// swagger:parameters getDashboardVersionDiffByUID
+type GetDashboardVersionDiffByUIDParams struct {
+	// in:path
+	DashboardVersionID int64
+	// in:path
+	// required:true
+	UID string `json:"uid"`
+	// Version to compare with, defaults to the parent version
+	// in:query
+	// required:false
+	Base int `json:"base"`
+}
+
+// swagger:parameters getDashboardBlameByUID
+type GetDashboardBlameByUIDParams struct {
+	// in:path
+	// required:true
+	UID string `json:"uid"`
+}
+
 // swagger:parameters getDashboardVersionByID
 type GetDashboardVersionByIDParams struct {
 	// in:path
@@ -1322,6 +1487,11 @@ type PostDashboardResponse struct {
 		// FolderUID The unique identifier (uid) of the folder the dashboard belongs to.
 		// required: false
 		FolderUID string `json:"folderUid"`
+
+		// Merged Whether the dashboard was saved from an outdated version and merged with the changes saved since
+		// that version, in which case the saved dashboard should be loaded again.
+		// required: false
+		Merged bool `json:"merged"`
 	} `json:"body"`
 }
 
@@ -1367,6 +1537,40 @@ type DashboardVersionResponse struct {
 	Body *dashver.DashboardVersionMeta `json:"body"`
 }
 
+// swagger:response dashboardVersionDiffResponse
+type DashboardVersionDiffResponse struct {
+	// in: body
+	Body *dashver.DashboardVersionDiff `json:"body"`
+}
+
+// swagger:response dashboardBlameResponse
+type DashboardBlameResponse struct {
+	// in: body
+	Body []dashver.PanelBlameMeta `json:"body"`
+}
+
+// swagger:response mergeDashboardVersionConflictResponse
+type MergeDashboardVersionConflictResponse struct {
+	// in: body
+	Body struct {
+		// Status of the response.
+		//
+		// required: true
+		// example: conflict
+		Status string `json:"status"`
+
+		// Message of the error.
+		//
+		// required: true
+		Message string `json:"message"`
+
+		// Conflicts are the paths changed both by the version and since the version.
+		//
+		// required: true
+		Conflicts []dashdiffs.Conflict `json:"conflicts"`
+	} `json:"body"`
+}
+
 // swagger:parameters restoreDeletedDashboardByUID
 type RestoreDeletedDashboardByUID struct {
 	// in:path
@@ -17,6 +17,7 @@ import (
 	"github.com/grafana/grafana/pkg/api/response"
 	"github.com/grafana/grafana/pkg/api/routing"
 	"github.com/grafana/grafana/pkg/bus"
+	"github.com/grafana/grafana/pkg/components/dashdiffs"
 	"github.com/grafana/grafana/pkg/components/simplejson"
 	"github.com/grafana/grafana/pkg/infra/db"
 	"github.com/grafana/grafana/pkg/infra/db/dbtest"
@@ -327,6 +328,8 @@ func TestHTTPServer_GetDashboardVersions_AccessControl(t *testing.T) {
 			hs.dashboardVersionService = &dashvertest.FakeDashboardVersionService{
 				ExpectedListDashboarVersions: []*dashver.DashboardVersionDTO{},
 				ExpectedDashboardVersion:     &dashver.DashboardVersionDTO{},
+				ExpectedDiff:                 &dashver.DashboardVersionDiff{},
+				ExpectedBlame:                []*dashver.PanelBlame{{PanelID: 1, Version: 1}},
 			}
 
 			guardian.InitAccessControlGuardian(hs.Cfg, hs.AccessControl, hs.DashboardService)
@@ -341,6 +344,14 @@ func TestHTTPServer_GetDashboardVersions_AccessControl(t *testing.T) {
 		return server.Send(webtest.RequestWithSignedInUser(server.NewGetRequest("/api/dashboards/uid/1/versions"), userWithPermissions(1, permissions)))
 	}
 
+	getDiff := func(server *webtest.Server, permissions []accesscontrol.Permission) (*http.Response, error) {
+		return server.Send(webtest.RequestWithSignedInUser(server.NewGetRequest("/api/dashboards/uid/1/versions/2/diff?base=1"), userWithPermissions(1, permissions)))
+	}
+
+	getBlame := func(server *webtest.Server, permissions []accesscontrol.Permission) (*http.Response, error) {
+		return server.Send(webtest.RequestWithSignedInUser(server.NewGetRequest("/api/dashboards/uid/1/blame"), userWithPermissions(1, permissions)))
+	}
+
 	t.Run("Should not be able to list dashboard versions without correct permission", func(t *testing.T) {
 		server := setup()
 
@@ -352,6 +363,16 @@ func TestHTTPServer_GetDashboardVersions_AccessControl(t *testing.T) {
 		res, err = getVersion(server, []accesscontrol.Permission{})
 		require.NoError(t, err)
 		assert.Equal(t, http.StatusForbidden, res.StatusCode)
+		require.NoError(t, res.Body.Close())
+
+		res, err = getDiff(server, []accesscontrol.Permission{})
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusForbidden, res.StatusCode)
+		require.NoError(t, res.Body.Close())
+
+		res, err = getBlame(server, []accesscontrol.Permission{})
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusForbidden, res.StatusCode)
 
 		require.NoError(t, res.Body.Close())
 	})
@@ -372,6 +393,20 @@ func TestHTTPServer_GetDashboardVersions_AccessControl(t *testing.T) {
 		res, err = getVersion(server, permissions)
 		require.NoError(t, err)
 		assert.Equal(t, http.StatusOK, res.StatusCode)
+		require.NoError(t, res.Body.Close())
+
+		res, err = getDiff(server, permissions)
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusOK, res.StatusCode)
+		require.NoError(t, res.Body.Close())
+
+		res, err = getBlame(server, permissions)
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusOK, res.StatusCode)
+		var blame []dashver.PanelBlameMeta
+		require.NoError(t, json.NewDecoder(res.Body).Decode(&blame))
+		require.Len(t, blame, 1)
+		assert.Equal(t, "Anonymous", blame[0].CreatedBy)
 
 		require.NoError(t, res.Body.Close())
 	})
@@ -476,6 +511,7 @@ func TestDashboardAPIEndpoint(t *testing.T) {
 				{SaveError: dashboards.ErrDashboardWithSameUIDExists, ExpectedStatusCode: http.StatusBadRequest},
 				{SaveError: dashboards.ErrDashboardWithSameNameInFolderExists, ExpectedStatusCode: http.StatusPreconditionFailed},
 				{SaveError: dashboards.ErrDashboardVersionMismatch, ExpectedStatusCode: http.StatusPreconditionFailed},
+				{SaveError: dashboards.DashboardMergeConflictError{Conflicts: []dashdiffs.Conflict{{Path: "title"}}}, ExpectedStatusCode: http.StatusPreconditionFailed},
 				{SaveError: dashboards.ErrDashboardTitleEmpty, ExpectedStatusCode: http.StatusBadRequest},
 				{SaveError: dashboards.ErrDashboardFolderCannotHaveParent, ExpectedStatusCode: http.StatusBadRequest},
 				{SaveError: dashboards.ErrDashboardTypeMismatch, ExpectedStatusCode: http.StatusBadRequest},
@@ -635,6 +671,46 @@ func TestDashboardAPIEndpoint(t *testing.T) {
 			}, mockSQLStore)
 	})
 
+	t.Run("Given conflicting changes when restoring with merge should not save", func(t *testing.T) {
+		fakeDash := dashboards.NewDashboard("Child dash")
+		fakeDash.ID = 2
+		fakeDash.HasACL = false
+
+		dashboardService := dashboards.NewFakeDashboardService(t)
+		dashboardService.On("GetDashboard", mock.Anything, mock.AnythingOfType("*dashboards.GetDashboardQuery")).Return(fakeDash, nil)
+
+		fakeDashboardVersionService := dashvertest.NewDashboardVersionServiceFake()
+		fakeDashboardVersionService.ExpectedDashboardVersion = &dashver.DashboardVersionDTO{
+			DashboardID: 2,
+			Version:     1,
+			Data:        fakeDash.Data,
+		}
+		fakeDashboardVersionService.ExpectedMergeResult = &dashver.MergeDashboardVersionResult{
+			Conflicts: []dashdiffs.Conflict{{Path: "title", Base: "a", Ours: "b", Theirs: "c"}},
+		}
+
+		cmd := dtos.RestoreDashboardVersionCommand{
+			Version: 1,
+			Merge:   true,
+		}
+		mockSQLStore := dbtest.NewFakeDB()
+		origNewGuardian := guardian.New
+		guardian.MockDashboardGuardian(&guardian.FakeDashboardGuardian{CanSaveValue: true})
+		t.Cleanup(func() {
+			guardian.New = origNewGuardian
+		})
+
+		restoreDashboardVersionScenario(t, "When calling POST on", "/api/dashboards/id/1/restore",
+			"/api/dashboards/id/:dashboardId/restore", dashboardService, fakeDashboardVersionService, cmd, func(sc *scenarioContext) {
+				callRestoreDashboardVersion(sc)
+				assert.Equal(t, http.StatusPreconditionFailed, sc.resp.Code)
+
+				result := sc.ToJSON()
+				assert.Equal(t, "conflict", result.Get("status").MustString())
+				assert.Equal(t, "title", result.Get("conflicts").GetIndex(0).Get("path").MustString())
+			}, mockSQLStore)
+	})
+
 	t.Run("Given provisioned dashboard", func(t *testing.T) {
 		mockSQLStore := dbtest.NewFakeDB()
 		dashboardStore := dashboards.NewFakeDashboardStore(t)
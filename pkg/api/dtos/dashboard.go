@@ -60,4 +60,10 @@ type CalculateDiffTarget struct {
 
 type RestoreDashboardVersionCommand struct {
 	Version int `json:"version" binding:"Required"`
+	// Merge reapplies the changes made by the version to the panels onto the
+	// current dashboard, instead of replacing it with the version.
+	Merge bool `json:"merge"`
+	// BaseVersion is the version the changes are computed from when merging,
+	// it defaults to the parent of the version.
+	BaseVersion int `json:"baseVersion"`
 }
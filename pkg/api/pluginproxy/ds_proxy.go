@@ -24,6 +24,7 @@ import (
 	"github.com/grafana/grafana/pkg/services/datasources"
 	"github.com/grafana/grafana/pkg/services/featuremgmt"
 	"github.com/grafana/grafana/pkg/services/oauthtoken"
+	"github.com/grafana/grafana/pkg/services/querylimit"
 	"github.com/grafana/grafana/pkg/setting"
 	"github.com/grafana/grafana/pkg/util"
 	"github.com/grafana/grafana/pkg/util/proxyutil"
@@ -47,6 +48,7 @@ type DataSourceProxy struct {
 	dataSourcesService datasources.DataSourceService
 	tracer             tracing.Tracer
 	features           featuremgmt.FeatureToggles
+	limiter            *querylimit.Limiter
 }
 
 type httpClient interface {
@@ -57,7 +59,7 @@ type httpClient interface {
 func NewDataSourceProxy(ds *datasources.DataSource, pluginRoutes []*plugins.Route, ctx *contextmodel.ReqContext,
 	proxyPath string, cfg *setting.Cfg, clientProvider httpclient.Provider,
 	oAuthTokenService oauthtoken.OAuthTokenService, dsService datasources.DataSourceService,
-	tracer tracing.Tracer, features featuremgmt.FeatureToggles) (*DataSourceProxy, error) {
+	tracer tracing.Tracer, features featuremgmt.FeatureToggles, limiter *querylimit.Limiter) (*DataSourceProxy, error) {
 	targetURL, err := datasource.ValidateURL(ds.Type, ds.URL)
 	if err != nil {
 		return nil, err
@@ -75,6 +77,7 @@ func NewDataSourceProxy(ds *datasources.DataSource, pluginRoutes []*plugins.Rout
 		dataSourcesService: dsService,
 		tracer:             tracer,
 		features:           features,
+		limiter:            limiter,
 	}, nil
 }
 
@@ -91,6 +94,14 @@ func (proxy *DataSourceProxy) HandleRequest() {
 		return
 	}
 
+	release, err := proxy.limiter.Acquire(proxy.ctx.Req.Context(), querylimit.SourceProxy, proxy.ctx.SignedInUser, proxy.ds.UID)
+	if err != nil {
+		querylimit.SetRetryAfter(proxy.ctx.Resp.Header(), err)
+		proxy.ctx.WriteErrOrFallback(http.StatusInternalServerError, "Failed to proxy request", err)
+		return
+	}
+	defer release()
+
 	proxyErrorLogger := logger.New(
 		"userId", proxy.ctx.UserID,
 		"orgId", proxy.ctx.OrgID,
@@ -0,0 +1,125 @@
+package dashdiffs
+
+// Conflict is a path changed differently on both sides of a merge.
+type Conflict struct {
+	Path   string `json:"path"`
+	Base   any    `json:"base,omitempty"`
+	Ours   any    `json:"ours,omitempty"`
+	Theirs any    `json:"theirs,omitempty"`
+}
+
+// missing marks a value absent from one side of a merge.
+type missingValue struct{}
+
+var missing any = missingValue{}
+
+// Merge applies the changes from base to theirs on top of ours, the changes made on both sides to different
+// paths are kept. The paths changed differently on both sides are returned as conflicts and keep the value of
+// ours. Elements of keyed arrays, like panels, are merged by key and keep the order of ours, the elements only
+// added by theirs are appended.
+//
+// The top level id, uid and version of the result are the ones of ours.
+func Merge(base, ours, theirs map[string]any) (map[string]any, []Conflict) {
+	conflicts := []Conflict{}
+	merged := mergeMaps("", withoutIgnoredKeys(base), withoutIgnoredKeys(ours), withoutIgnoredKeys(theirs), &conflicts)
+	for k := range ignoredKeys {
+		if v, ok := ours[k]; ok {
+			merged[k] = v
+		}
+	}
+	return merged, conflicts
+}
+
+func mergeValues(path string, base, ours, theirs any, conflicts *[]Conflict) any {
+	switch {
+	case sameValue(ours, theirs), sameValue(base, theirs):
+		return ours
+	case sameValue(base, ours):
+		return theirs
+	}
+
+	if om, ok := ours.(map[string]any); ok {
+		if tm, ok := theirs.(map[string]any); ok {
+			bm, ok := base.(map[string]any)
+			if ok || base == missing {
+				return mergeMaps(path, bm, om, tm, conflicts)
+			}
+		}
+	}
+
+	if oa, ok := ours.([]any); ok {
+		if ta, ok := theirs.([]any); ok {
+			ba, ok := base.([]any)
+			if ok || base == missing {
+				if field := commonKeyField(ba, oa, ta); field != "" {
+					return mergeKeyed(path, field, ba, oa, ta, conflicts)
+				}
+			}
+		}
+	}
+
+	*conflicts = append(*conflicts, Conflict{
+		Path:   path,
+		Base:   presentOrNil(base),
+		Ours:   presentOrNil(ours),
+		Theirs: presentOrNil(theirs),
+	})
+	return ours
+}
+
+func mergeMaps(path string, base, ours, theirs map[string]any, conflicts *[]Conflict) map[string]any {
+	merged := make(map[string]any, len(ours))
+	for _, k := range sortedKeys(base, ours, theirs) {
+		v := mergeValues(keyPath(path, k), lookup(base, k), lookup(ours, k), lookup(theirs, k), conflicts)
+		if v != missing {
+			merged[k] = v
+		}
+	}
+	return merged
+}
+
+func mergeKeyed(path, field string, base, ours, theirs []any, conflicts *[]Conflict) []any {
+	baseByKey := indexByKey(base, field)
+	oursByKey := indexByKey(ours, field)
+	theirsByKey := indexByKey(theirs, field)
+
+	merged := make([]any, 0, len(ours))
+	add := func(key string) {
+		v := mergeValues(elementPath(path, field, key), lookup(baseByKey, key), lookup(oursByKey, key),
+			lookup(theirsByKey, key), conflicts)
+		if v != missing {
+			merged = append(merged, v)
+		}
+	}
+
+	for _, el := range ours {
+		add(elementKey(el, field))
+	}
+	for _, el := range theirs {
+		if key := elementKey(el, field); lookup(oursByKey, key) == missing {
+			add(key)
+		}
+	}
+	return merged
+}
+
+func sameValue(a, b any) bool {
+	if a == missing || b == missing {
+		return a == b
+	}
+	return Equal(a, b)
+}
+
+func lookup(m map[string]any, key string) any {
+	if v, ok := m[key]; ok {
+		return v
+	}
+	return missing
+}
+
+func presentOrNil(v any) any {
+	if v == missing {
+		return nil
+	}
+	return v
+}
@@ -0,0 +1,92 @@
+package dashdiffs
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestMerge(t *testing.T) {
+	base := dashboardJSON(t, `{
+		"uid": "abc",
+		"version": 1,
+		"title": "Dashboard",
+		"panels": [
+			{"id": 1, "title": "CPU"},
+			{"id": 2, "title": "Memory"},
+			{"id": 3, "title": "Disk"}
+		]
+	}`)
+
+	t.Run("keeps changes to different paths", func(t *testing.T) {
+		ours := dashboardJSON(t, `{
+			"uid": "abc",
+			"version": 3,
+			"title": "My dashboard",
+			"panels": [
+				{"id": 2, "title": "Memory"},
+				{"id": 1, "title": "CPU"},
+				{"id": 3, "title": "Disk", "description": "free space"}
+			]
+		}`)
+		theirs := dashboardJSON(t, `{
+			"uid": "abc",
+			"version": 2,
+			"title": "Dashboard",
+			"panels": [
+				{"id": 1, "title": "CPU usage"},
+				{"id": 3, "title": "Disk"},
+				{"id": 4, "title": "Network"}
+			]
+		}`)
+
+		merged, conflicts := Merge(base, ours, theirs)
+		require.Empty(t, conflicts)
+		require.Equal(t, dashboardJSON(t, `{
+			"uid": "abc",
+			"version": 3,
+			"title": "My dashboard",
+			"panels": [
+				{"id": 1, "title": "CPU usage"},
+				{"id": 3, "title": "Disk", "description": "free space"},
+				{"id": 4, "title": "Network"}
+			]
+		}`), merged)
+	})
+
+	t.Run("reports conflicting paths", func(t *testing.T) {
+		ours := dashboardJSON(t, `{
+			"title": "Ours",
+			"panels": [
+				{"id": 1, "title": "CPU"},
+				{"id": 3, "title": "Disk space"}
+			]
+		}`)
+		theirs := dashboardJSON(t, `{
+			"title": "Theirs",
+			"panels": [
+				{"id": 1, "title": "CPU"},
+				{"id": 2, "title": "Memory usage"},
+				{"id": 3, "title": "Disk usage"}
+			]
+		}`)
+
+		merged, conflicts := Merge(base, ours, theirs)
+		require.Equal(t, []Conflict{
+			{Path: "panels[id=3].title", Base: "Disk", Ours: "Disk space", Theirs: "Disk usage"},
+			{Path: "panels[id=2]", Base: base["panels"].([]any)[1], Theirs: theirs["panels"].([]any)[1]},
+			{Path: "title", Base: "Dashboard", Ours: "Ours", Theirs: "Theirs"},
+		}, conflicts)
+		require.Equal(t, ours["title"], merged["title"])
+		require.Equal(t, ours["panels"], merged["panels"])
+	})
+
+	t.Run("merges additions on both sides", func(t *testing.T) {
+		ours := dashboardJSON(t, `{"title": "Dashboard", "panels": [], "links": [{"title": "a"}]}`)
+		theirs := dashboardJSON(t, `{"title": "Dashboard", "panels": [], "refresh": "1m"}`)
+
+		merged, conflicts := Merge(dashboardJSON(t, `{"title": "Dashboard"}`), ours, theirs)
+		require.Empty(t, conflicts)
+		require.Equal(t, dashboardJSON(t, `{"title": "Dashboard", "panels": [], "links": [{"title": "a"}], "refresh": "1m"}`), merged)
+	})
+}
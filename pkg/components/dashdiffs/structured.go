@@ -0,0 +1,254 @@
+package dashdiffs
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"regexp"
+	"sort"
+	"strconv"
+)
+
+// ChangeOp is the kind of a change in a structured diff.
+type ChangeOp string
+
+const (
+	ChangeAdd     ChangeOp = "add"
+	ChangeRemove  ChangeOp = "remove"
+	ChangeReplace ChangeOp = "replace"
+)
+
+// Change is a single change between two dashboards.
+//
+// Paths look like `panels[id=2].targets`: objects are walked by key, and the elements of arrays of objects
+// having a unique `id` or `name`, like panels and template variables, are addressed by that key. Other arrays are
+// compared as a whole.
+type Change struct {
+	Path string   `json:"path"`
+	Op   ChangeOp `json:"op"`
+	Old  any      `json:"old,omitempty"`
+	New  any      `json:"new,omitempty"`
+}
+
+// ignoredKeys are the top level dashboard fields that change on every save.
+var ignoredKeys = map[string]bool{"id": true, "uid": true, "version": true}
+
+var identifierPattern = regexp.MustCompile(`^[a-zA-Z_$][a-zA-Z0-9_$]*$`)
+
+// StructuredDiff returns the changes from base to target. Object keys are visited in alphabetical order, array
+// elements in their order.
+func StructuredDiff(base, target map[string]any) []Change {
+	changes := []Change{}
+	diffMaps("", withoutIgnoredKeys(base), withoutIgnoredKeys(target), &changes)
+	return changes
+}
+
+func diffValues(path string, a, b any, changes *[]Change) {
+	if Equal(a, b) {
+		return
+	}
+
+	if am, ok := a.(map[string]any); ok {
+		if bm, ok := b.(map[string]any); ok {
+			diffMaps(path, am, bm, changes)
+			return
+		}
+	}
+
+	if aa, ok := a.([]any); ok {
+		if ba, ok := b.([]any); ok {
+			if field := commonKeyField(aa, ba); field != "" {
+				diffKeyed(path, field, aa, ba, changes)
+				return
+			}
+		}
+	}
+
+	*changes = append(*changes, Change{Path: path, Op: ChangeReplace, Old: a, New: b})
+}
+
+func diffMaps(path string, a, b map[string]any, changes *[]Change) {
+	for _, k := range sortedKeys(a, b) {
+		av, inA := a[k]
+		bv, inB := b[k]
+		p := keyPath(path, k)
+		switch {
+		case !inA:
+			*changes = append(*changes, Change{Path: p, Op: ChangeAdd, New: bv})
+		case !inB:
+			*changes = append(*changes, Change{Path: p, Op: ChangeRemove, Old: av})
+		default:
+			diffValues(p, av, bv, changes)
+		}
+	}
+}
+
+// diffKeyed compares arrays by the key of their elements, regardless of their position.
+func diffKeyed(path, field string, a, b []any, changes *[]Change) {
+	aByKey := indexByKey(a, field)
+	bByKey := indexByKey(b, field)
+
+	for _, el := range a {
+		key := elementKey(el, field)
+		p := elementPath(path, field, key)
+		if bv, ok := bByKey[key]; ok {
+			diffValues(p, el, bv, changes)
+		} else {
+			*changes = append(*changes, Change{Path: p, Op: ChangeRemove, Old: el})
+		}
+	}
+	for _, el := range b {
+		key := elementKey(el, field)
+		if _, ok := aByKey[key]; !ok {
+			*changes = append(*changes, Change{Path: elementPath(path, field, key), Op: ChangeAdd, New: el})
+		}
+	}
+}
+
+// keyField returns the field identifying the elements of the array, or an empty string when the elements are not
+// objects with a unique id or name.
+func keyField(arr []any) string {
+	for _, field := range []string{"id", "name"} {
+		seen := make(map[string]bool, len(arr))
+		unique := true
+		for _, el := range arr {
+			m, ok := el.(map[string]any)
+			if !ok || m[field] == nil {
+				unique = false
+				break
+			}
+			key := elementKey(m, field)
+			if seen[key] {
+				unique = false
+				break
+			}
+			seen[key] = true
+		}
+		if unique {
+			return field
+		}
+	}
+	return ""
+}
+
+// commonKeyField returns the key field shared by the arrays, empty arrays fit any key.
+func commonKeyField(arrays ...[]any) string {
+	field := ""
+	for _, arr := range arrays {
+		if len(arr) == 0 {
+			continue
+		}
+		f := keyField(arr)
+		if f == "" || (field != "" && f != field) {
+			return ""
+		}
+		field = f
+	}
+	return field
+}
+
+func indexByKey(arr []any, field string) map[string]any {
+	idx := make(map[string]any, len(arr))
+	for _, el := range arr {
+		idx[elementKey(el, field)] = el
+	}
+	return idx
+}
+
+func elementKey(el any, field string) string {
+	m, _ := el.(map[string]any)
+	return fmt.Sprint(m[field])
+}
+
+func keyPath(path, key string) string {
+	if !identifierPattern.MatchString(key) {
+		return fmt.Sprintf("%s[%s]", path, strconv.Quote(key))
+	}
+	if path == "" {
+		return key
+	}
+	return path + "." + key
+}
+
+func elementPath(path, field, key string) string {
+	if !identifierPattern.MatchString(key) {
+		key = strconv.Quote(key)
+	}
+	return fmt.Sprintf("%s[%s=%s]", path, field, key)
+}
+
+func sortedKeys(maps ...map[string]any) []string {
+	keys := []string{}
+	seen := map[string]bool{}
+	for _, m := range maps {
+		for k := range m {
+			if !seen[k] {
+				seen[k] = true
+				keys = append(keys, k)
+			}
+		}
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func withoutIgnoredKeys(dash map[string]any) map[string]any {
+	out := make(map[string]any, len(dash))
+	for k, v := range dash {
+		if !ignoredKeys[k] {
+			out[k] = v
+		}
+	}
+	return out
+}
+
+// Equal compares decoded JSON values. Numbers are equal when they have the same value, whether they were decoded
+// as json.Number or float64.
+func Equal(a, b any) bool {
+	switch av := a.(type) {
+	case map[string]any:
+		bv, ok := b.(map[string]any)
+		if !ok || len(av) != len(bv) {
+			return false
+		}
+		for k, v := range av {
+			w, ok := bv[k]
+			if !ok || !Equal(v, w) {
+				return false
+			}
+		}
+		return true
+	case []any:
+		bv, ok := b.([]any)
+		if !ok || len(av) != len(bv) {
+			return false
+		}
+		for i := range av {
+			if !Equal(av[i], bv[i]) {
+				return false
+			}
+		}
+		return true
+	}
+
+	if af, ok := toFloat(a); ok {
+		bf, ok := toFloat(b)
+		return ok && af == bf
+	}
+	return reflect.DeepEqual(a, b)
+}
+
+func toFloat(v any) (float64, bool) {
+	switch n := v.(type) {
+	case json.Number:
+		f, err := n.Float64()
+		return f, err == nil
+	case float64:
+		return n, true
+	case int:
+		return float64(n), true
+	case int64:
+		return float64(n), true
+	}
+	return 0, false
+}
@@ -0,0 +1,78 @@
+package dashdiffs
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/components/simplejson"
+)
+
+func dashboardJSON(t *testing.T, text string) map[string]any {
+	t.Helper()
+	js, err := simplejson.NewJson([]byte(text))
+	require.NoError(t, err)
+	return js.MustMap()
+}
+
+func TestStructuredDiff(t *testing.T) {
+	base := dashboardJSON(t, `{
+		"id": 1,
+		"version": 3,
+		"title": "Dashboard",
+		"tags": ["a", "b"],
+		"panels": [
+			{"id": 1, "title": "CPU", "gridPos": {"x": 0, "y": 0}},
+			{"id": 2, "title": "Memory", "targets": [{"refId": "A"}]},
+			{"id": 3, "title": "Disk"}
+		],
+		"templating": {"list": [{"name": "env", "query": "prod"}]},
+		"time": {"from": "now-6h", "to": "now"}
+	}`)
+	target := dashboardJSON(t, `{
+		"id": 1,
+		"version": 4,
+		"title": "Dashboard",
+		"tags": ["a", "c"],
+		"panels": [
+			{"id": 2, "title": "Memory", "targets": [{"refId": "A"}, {"refId": "B"}]},
+			{"id": 1, "title": "CPU usage", "gridPos": {"x": 0, "y": 0}},
+			{"id": 4, "title": "Network"}
+		],
+		"templating": {"list": [{"name": "env", "query": "dev"}]},
+		"time": {"from": "now-6h", "to": "now"},
+		"refresh": "5s"
+	}`)
+
+	changes := StructuredDiff(base, target)
+	require.Equal(t, []Change{
+		{Path: "panels[id=1].title", Op: ChangeReplace, Old: "CPU", New: "CPU usage"},
+		{Path: "panels[id=2].targets", Op: ChangeReplace, Old: base["panels"].([]any)[1].(map[string]any)["targets"],
+			New: target["panels"].([]any)[0].(map[string]any)["targets"]},
+		{Path: "panels[id=3]", Op: ChangeRemove, Old: base["panels"].([]any)[2]},
+		{Path: "panels[id=4]", Op: ChangeAdd, New: target["panels"].([]any)[2]},
+		{Path: "refresh", Op: ChangeAdd, New: "5s"},
+		{Path: "tags", Op: ChangeReplace, Old: []any{"a", "b"}, New: []any{"a", "c"}},
+		{Path: "templating.list[name=env].query", Op: ChangeReplace, Old: "prod", New: "dev"},
+	}, changes)
+
+	t.Run("no changes", func(t *testing.T) {
+		require.Empty(t, StructuredDiff(base, base))
+	})
+
+	t.Run("numbers are compared by value", func(t *testing.T) {
+		var decoded map[string]any
+		require.NoError(t, json.Unmarshal([]byte(`{"panels": [{"id": 1, "gridPos": {"x": 0, "y": 0}, "title": "CPU"}]}`), &decoded))
+		require.Empty(t, StructuredDiff(dashboardJSON(t, `{"panels": [{"id": 1, "gridPos": {"x": 0, "y": 0}, "title": "CPU"}]}`), decoded))
+	})
+
+	t.Run("quotes keys which are not identifiers", func(t *testing.T) {
+		changes := StructuredDiff(
+			dashboardJSON(t, `{"panels": [{"id": 1, "options": {"a.b": 1}}]}`),
+			dashboardJSON(t, `{"panels": [{"id": 1, "options": {"a.b": 2}}]}`),
+		)
+		require.Len(t, changes, 1)
+		require.Equal(t, `panels[id=1].options["a.b"]`, changes[0].Path)
+	})
+}
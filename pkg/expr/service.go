@@ -91,6 +91,15 @@ func ProvideService(cfg *setting.Cfg, pluginClient plugins.Client, pCtxProvider
 	}
 }
 
+// WithDataService returns a copy of the service that sends data source queries to the provided handler
+// instead of the plugin client. It is used to evaluate expressions against data that was not queried
+// from a data source, for example frames streamed via Grafana Live.
+func (s *Service) WithDataService(dataService backend.QueryDataHandler) *Service {
+	c := *s
+	c.dataService = dataService
+	return &c
+}
+
 func (s *Service) isDisabled() bool {
 	if s.cfg == nil {
 		return true
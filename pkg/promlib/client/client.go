@@ -105,6 +105,30 @@ func (c *Client) QueryResource(ctx context.Context, req *backend.CallResourceReq
 	return c.doer.Do(httpRequest)
 }
 
+// QueryMetadata calls a metadata endpoint of the HTTP API, like api/v1/labels. Parameters can repeat, like
+// match[]. Endpoints that accept form bodies are called with the configured method, the others with GET.
+func (c *Client) QueryMetadata(ctx context.Context, endpoint string, params url.Values, allowPost bool) (*http.Response, error) {
+	u, err := c.createUrl(endpoint, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	if allowPost && strings.ToUpper(c.method) == http.MethodPost {
+		req, err := createRequest(ctx, http.MethodPost, u, strings.NewReader(params.Encode()))
+		if err != nil {
+			return nil, err
+		}
+		return c.doer.Do(req)
+	}
+
+	u.RawQuery = params.Encode()
+	req, err := createRequest(ctx, http.MethodGet, u, http.NoBody)
+	if err != nil {
+		return nil, err
+	}
+	return c.doer.Do(req)
+}
+
 func (c *Client) createQueryRequest(ctx context.Context, endpoint string, qv map[string]string) (*http.Request, error) {
 	if strings.ToUpper(c.method) == http.MethodPost {
 		u, err := c.createUrl(endpoint, nil)
@@ -660,7 +660,11 @@ func readMatrixOrVectorMulti(iter *sdkjsoniter.Iterator, resultType string, opt
 				frame.Name = "" // only set the name if useful
 			}
 			rsp.Frames = append(rsp.Frames, frame)
-		} else {
+		}
+
+		// a series can have float samples next to its histogram samples, e.g. while it is migrated to a
+		// native histogram, so they are kept in a frame of their own
+		if histogram == nil || timeField.Len() > 0 {
 			frame := data.NewFrame("", timeField, valueField)
 			frame.Meta = &data.FrameMeta{
 				Type:   data.FrameTypeTimeSeriesMulti,
@@ -84,3 +84,26 @@ func TestTimeConversions(t *testing.T) {
 		time.Date(2033, time.May, 18, 3, 33, 20, 0, time.UTC),
 		ti)
 }
+
+func TestReadMixedFloatAndHistogramSeries(t *testing.T) {
+	body := `{"status":"success","data":{"resultType":"matrix","result":[{
+		"metric":{"__name__":"latency","job":"api"},
+		"values":[[1649963200,"1"],[1649963215,"2"]],
+		"histograms":[[1649963300,{"count":"3","sum":"1.5","buckets":[[0,"0.5","1","1"],[0,"1","2","2"]]}]]
+	}]}}`
+
+	iter := jsoniter.Parse(sdkjsoniter.ConfigDefault, strings.NewReader(body), 1024)
+	rsp := ReadPrometheusStyleResult(iter, Options{})
+	require.NoError(t, rsp.Error)
+	require.Len(t, rsp.Frames, 2)
+
+	cells := rsp.Frames[0]
+	require.Equal(t, "heatmap-cells", string(cells.Meta.Type))
+	require.Equal(t, 2, cells.Rows())
+	require.Equal(t, "api", cells.Fields[1].Labels["job"])
+
+	series := rsp.Frames[1]
+	require.Equal(t, 2, series.Rows())
+	require.Equal(t, 2.0, series.Fields[1].At(1))
+	require.Equal(t, "api", series.Fields[1].Labels["job"])
+}
@@ -123,6 +123,14 @@ func (s *Service) CallResource(ctx context.Context, req *backend.CallResourceReq
 		return sender.Send(vResp)
 	}
 
+	if resource.IsTypedResource(req.Path) {
+		resp, err := i.resource.ExecuteTyped(ctx, req)
+		if err != nil {
+			return err
+		}
+		return sender.Send(resp)
+	}
+
 	resp, err := i.resource.Execute(ctx, req)
 	if err != nil {
 		return err
@@ -170,6 +170,7 @@ type Query struct {
 	RangeQuery    bool
 	ExemplarQuery bool
 	UtcOffsetSec  int64
+	Format        PromQueryFormat
 
 	Scopes []ScopeSpec
 }
@@ -271,6 +272,7 @@ func Parse(span trace.Span, query backend.DataQuery, dsScrapeInterval string, in
 		RangeQuery:    model.Range,
 		ExemplarQuery: model.Exemplar,
 		UtcOffsetSec:  model.UtcOffsetSec,
+		Format:        model.Format,
 	}, nil
 }
 
@@ -0,0 +1,222 @@
+package querydata
+
+import (
+	"math"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+)
+
+const (
+	bucketLabel        = "le"
+	bucketMinFieldName = "bucketMin"
+	bucketMaxFieldName = "bucketMax"
+)
+
+// frameHeatmap prepares the frames of a heatmap query. Native histograms are already framed as heatmap
+// cells by the converter, classic histograms are one series per `le` bucket which are turned into a
+// heatmap by the frontend. Series that are neither, like the results of histogram_quantile, are marked
+// to be graphed alongside the heatmap, and exemplars get the bounds of the bucket they fall into.
+func frameHeatmap(frames data.Frames) data.Frames {
+	var cells []*data.Frame
+	var exemplars []*data.Frame
+	buckets := map[string]*bucketGroup{}
+	for _, frame := range frames {
+		switch {
+		case isHeatmapCellsFrame(frame):
+			cells = append(cells, frame)
+		case frame.Meta != nil && isExemplarFrame(frame):
+			exemplars = append(exemplars, frame)
+		case len(frame.Fields) > 1:
+			labels := frame.Fields[1].Labels
+			if le, ok := labels[bucketLabel]; ok {
+				if bound, err := parseBucketBound(le); err == nil {
+					key := seriesKey(labels)
+					if buckets[key] == nil {
+						buckets[key] = &bucketGroup{labels: labels}
+					}
+					buckets[key].bounds = append(buckets[key].bounds, bound)
+				}
+			}
+		}
+	}
+
+	if len(cells) == 0 && len(buckets) == 0 {
+		// nothing to tell apart from buckets, the frontend turns all series into a heatmap as before
+		return frames
+	}
+
+	for _, frame := range frames {
+		if isHeatmapCellsFrame(frame) || (frame.Meta != nil && isExemplarFrame(frame)) || len(frame.Fields) < 2 {
+			continue
+		}
+		if _, ok := frame.Fields[1].Labels[bucketLabel]; !ok {
+			if frame.Meta == nil {
+				frame.Meta = &data.FrameMeta{}
+			}
+			frame.Meta.PreferredVisualization = data.VisTypeGraph
+		}
+	}
+
+	for _, group := range buckets {
+		sort.Float64s(group.bounds)
+	}
+	for _, frame := range exemplars {
+		linkExemplarBuckets(frame, cells, buckets)
+	}
+
+	return frames
+}
+
+// linkExemplarBuckets adds the bounds of the bucket of each exemplar, taken from its `le` label for
+// classic histograms or from the heatmap cells of its series for native histograms.
+func linkExemplarBuckets(frame *data.Frame, cells []*data.Frame, buckets map[string]*bucketGroup) {
+	if len(frame.Fields) < 2 || frame.Fields[0].Type() != data.FieldTypeTime || frame.Fields[1].Type() != data.FieldTypeFloat64 {
+		return
+	}
+
+	n := frame.Rows()
+	bucketMin := data.NewField(bucketMinFieldName, nil, make([]*float64, n))
+	bucketMax := data.NewField(bucketMaxFieldName, nil, make([]*float64, n))
+	linked := false
+	indexes := map[*data.Frame]*cellIndex{}
+
+	for row := 0; row < n; row++ {
+		labels := exemplarLabels(frame, row)
+		ts := frame.Fields[0].At(row).(time.Time)
+		value := frame.Fields[1].At(row).(float64)
+
+		var lower, upper float64
+		found := false
+		if le := labels[bucketLabel]; le != "" {
+			if bound, err := parseBucketBound(le); err == nil {
+				for _, group := range buckets {
+					if labelsMatch(group.labels, labels) {
+						lower, upper, found = group.lowerBound(bound), bound, true
+						break
+					}
+				}
+			}
+		} else {
+			for _, cellsFrame := range cells {
+				if !labelsMatch(cellsFrame.Fields[1].Labels, labels) {
+					continue
+				}
+				idx, ok := indexes[cellsFrame]
+				if !ok {
+					idx = newCellIndex(cellsFrame)
+					indexes[cellsFrame] = idx
+				}
+				if lower, upper, found = idx.bucket(ts, value); found {
+					break
+				}
+			}
+		}
+
+		if found {
+			bucketMin.Set(row, &lower)
+			bucketMax.Set(row, &upper)
+			linked = true
+		}
+	}
+
+	if linked {
+		frame.Fields = append(frame.Fields, bucketMin, bucketMax)
+	}
+}
+
+// bucketGroup is the upper bounds of the buckets of a classic histogram.
+type bucketGroup struct {
+	labels data.Labels
+	bounds []float64
+}
+
+// lowerBound returns the lower bound of the bucket with the given upper bound.
+func (g *bucketGroup) lowerBound(upper float64) float64 {
+	i := sort.SearchFloat64s(g.bounds, upper)
+	if i == 0 {
+		return math.Inf(-1)
+	}
+	return g.bounds[i-1]
+}
+
+// cellIndex finds the cells of a native histogram frame by time.
+type cellIndex struct {
+	frame *data.Frame
+	times []time.Time
+	rows  map[time.Time][]int
+}
+
+func newCellIndex(frame *data.Frame) *cellIndex {
+	idx := &cellIndex{frame: frame, rows: map[time.Time][]int{}}
+	for row := 0; row < frame.Rows(); row++ {
+		t := frame.Fields[0].At(row).(time.Time)
+		if _, ok := idx.rows[t]; !ok {
+			idx.times = append(idx.times, t)
+		}
+		idx.rows[t] = append(idx.rows[t], row)
+	}
+	sort.Slice(idx.times, func(i, j int) bool { return idx.times[i].Before(idx.times[j]) })
+	return idx
+}
+
+// bucket returns the bounds of the cell containing value in the first sample at or after ts, which is the
+// sample the exemplar was recorded for.
+func (idx *cellIndex) bucket(ts time.Time, value float64) (float64, float64, bool) {
+	i := sort.Search(len(idx.times), func(i int) bool { return !idx.times[i].Before(ts) })
+	if i == len(idx.times) {
+		return 0, 0, false
+	}
+
+	for _, row := range idx.rows[idx.times[i]] {
+		lower := idx.frame.Fields[1].At(row).(float64)
+		upper := idx.frame.Fields[2].At(row).(float64)
+		if value >= lower && value <= upper {
+			return lower, upper, true
+		}
+	}
+	return 0, 0, false
+}
+
+func isHeatmapCellsFrame(frame *data.Frame) bool {
+	return frame.Meta != nil && frame.Meta.Type == data.FrameTypeHeatmapCells && len(frame.Fields) >= 3
+}
+
+// exemplarLabels returns the labels of an exemplar, exemplar frames have a string field per label.
+func exemplarLabels(frame *data.Frame, row int) data.Labels {
+	labels := data.Labels{}
+	for _, field := range frame.Fields[2:] {
+		if field.Type() == data.FieldTypeString {
+			labels[field.Name] = field.At(row).(string)
+		}
+	}
+	return labels
+}
+
+// labelsMatch reports whether the exemplar labels contain all series labels. The metric name is ignored, as
+// functions like rate drop it from the series, and so is the bucket.
+func labelsMatch(series data.Labels, exemplar data.Labels) bool {
+	for name, value := range series {
+		if name == "__name__" || name == bucketLabel {
+			continue
+		}
+		if v, ok := exemplar[name]; ok && v != value {
+			return false
+		}
+	}
+	return true
+}
+
+// seriesKey identifies the histogram a bucket series belongs to.
+func seriesKey(labels data.Labels) string {
+	copied := labels.Copy()
+	delete(copied, bucketLabel)
+	delete(copied, "__name__")
+	return copied.String()
+}
+
+func parseBucketBound(le string) (float64, error) {
+	return strconv.ParseFloat(le, 64)
+}
@@ -0,0 +1,107 @@
+package querydata
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFrameHeatmap(t *testing.T) {
+	t0 := time.Unix(1000, 0).UTC()
+	t1 := t0.Add(time.Minute)
+
+	seriesFrame := func(labels data.Labels, values ...float64) *data.Frame {
+		times := make([]time.Time, len(values))
+		for i := range values {
+			times[i] = t0.Add(time.Duration(i) * time.Minute)
+		}
+		return data.NewFrame("",
+			data.NewField(data.TimeSeriesTimeFieldName, nil, times),
+			data.NewField(data.TimeSeriesValueFieldName, labels, values),
+		).SetMeta(&data.FrameMeta{Type: data.FrameTypeTimeSeriesMulti})
+	}
+
+	exemplarFrame := func(labels map[string][]string, times []time.Time, values []float64) *data.Frame {
+		frame := data.NewFrame("exemplar",
+			data.NewField(data.TimeSeriesTimeFieldName, nil, times),
+			data.NewField(data.TimeSeriesValueFieldName, nil, values),
+		).SetMeta(&data.FrameMeta{Custom: map[string]string{"resultType": "exemplar"}})
+		for _, name := range []string{"job", "le", "traceID"} {
+			if v, ok := labels[name]; ok {
+				frame.Fields = append(frame.Fields, data.NewField(name, nil, v))
+			}
+		}
+		return frame
+	}
+
+	t.Run("leaves responses without histograms alone", func(t *testing.T) {
+		frame := seriesFrame(data.Labels{"bucket": "small"}, 1, 2)
+		frames := frameHeatmap(data.Frames{frame})
+		require.Equal(t, data.VisType(""), frames[0].Meta.PreferredVisualization)
+	})
+
+	t.Run("graphs histogram_quantile next to classic buckets", func(t *testing.T) {
+		bucket := seriesFrame(data.Labels{"job": "api", "le": "0.5"}, 1, 2)
+		quantile := seriesFrame(data.Labels{"job": "api"}, 0.4, 0.45)
+
+		frames := frameHeatmap(data.Frames{bucket, quantile})
+		require.Equal(t, data.VisType(""), frames[0].Meta.PreferredVisualization)
+		require.Equal(t, data.VisTypeGraph, frames[1].Meta.PreferredVisualization)
+	})
+
+	t.Run("links exemplars to classic buckets", func(t *testing.T) {
+		frames := data.Frames{
+			seriesFrame(data.Labels{"job": "api", "le": "0.1"}, 1),
+			seriesFrame(data.Labels{"job": "api", "le": "0.5"}, 2),
+			seriesFrame(data.Labels{"job": "api", "le": "+Inf"}, 3),
+			exemplarFrame(map[string][]string{
+				"job":     {"api", "api"},
+				"le":      {"0.1", "0.5"},
+				"traceID": {"a", "b"},
+			}, []time.Time{t0, t1}, []float64{0.05, 0.3}),
+		}
+
+		exemplars := frameHeatmap(frames)[3]
+		bucketMin, _ := exemplars.FieldByName(bucketMinFieldName)
+		bucketMax, _ := exemplars.FieldByName(bucketMaxFieldName)
+		require.NotNil(t, bucketMin)
+		require.NotNil(t, bucketMax)
+		require.Equal(t, math.Inf(-1), *bucketMin.At(0).(*float64))
+		require.Equal(t, 0.1, *bucketMax.At(0).(*float64))
+		require.Equal(t, 0.1, *bucketMin.At(1).(*float64))
+		require.Equal(t, 0.5, *bucketMax.At(1).(*float64))
+	})
+
+	t.Run("links exemplars to native histogram cells", func(t *testing.T) {
+		yMin := data.NewField("yMin", data.Labels{"job": "api"}, []float64{0, 1, 0, 1})
+		cells := data.NewFrame("",
+			data.NewField("xMax", nil, []time.Time{t0, t0, t1, t1}),
+			yMin,
+			data.NewField("yMax", nil, []float64{1, 2, 1, 2}),
+			data.NewField("count", nil, []float64{3, 4, 5, 6}),
+			data.NewField("yLayout", nil, []int8{0, 0, 0, 0}),
+		).SetMeta(&data.FrameMeta{Type: data.FrameTypeHeatmapCells})
+		quantile := seriesFrame(data.Labels{"job": "api"}, 1.5)
+		exemplars := exemplarFrame(map[string][]string{
+			"job":     {"api", "api", "other"},
+			"traceID": {"a", "b", "c"},
+		}, []time.Time{t0.Add(-10 * time.Second), t0.Add(30 * time.Second), t0}, []float64{1.5, 0.5, 0.5})
+
+		frames := frameHeatmap(data.Frames{cells, quantile, exemplars})
+		require.Equal(t, data.VisTypeGraph, frames[1].Meta.PreferredVisualization)
+
+		bucketMin, _ := frames[2].FieldByName(bucketMinFieldName)
+		bucketMax, _ := frames[2].FieldByName(bucketMaxFieldName)
+		require.NotNil(t, bucketMin)
+		require.Equal(t, 1.0, *bucketMin.At(0).(*float64))
+		require.Equal(t, 2.0, *bucketMax.At(0).(*float64))
+		// recorded before t1, so it belongs to the sample at t1
+		require.Equal(t, 0.0, *bucketMin.At(1).(*float64))
+		require.Equal(t, 1.0, *bucketMax.At(1).(*float64))
+		// different series
+		require.Nil(t, bucketMin.At(2))
+	})
+}
@@ -161,6 +161,10 @@ func (s *QueryData) fetch(traceCtx context.Context, client *client.Client, q *mo
 		dr.Frames = append(dr.Frames, res.Frames...)
 	}
 
+	if q.Format == models.PromQueryFormatHeatmap {
+		dr.Frames = frameHeatmap(dr.Frames)
+	}
+
 	return dr
 }
 
@@ -115,6 +115,19 @@ func addMetadataToMultiFrame(q *models.Query, frame *data.Frame, enableDataplane
 	}
 	frame.Fields[0].Config = &data.FieldConfig{Interval: float64(q.Step.Milliseconds())}
 
+	if isHeatmapCellsFrame(frame) {
+		// the fields of heatmap cells are read by name, so the series name goes on the count
+		// and the frame, the labels are on the yMin field
+		customName := getName(q, frame.Fields[1])
+		if customName != "" && len(frame.Fields) > 3 {
+			frame.Fields[3].Config = &data.FieldConfig{DisplayNameFromDS: customName}
+		}
+		if !enableDataplane {
+			frame.Name = customName
+		}
+		return
+	}
+
 	customName := getName(q, frame.Fields[1])
 	if customName != "" {
 		frame.Fields[1].Config = &data.FieldConfig{DisplayNameFromDS: customName}
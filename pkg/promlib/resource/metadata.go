@@ -0,0 +1,318 @@
+package resource
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+)
+
+// Typed resource paths. Unlike the raw API paths they are answered from a cache and trimmed to `limit`:
+//
+//	GET labels?match[]=&start=&end=&limit=
+//	GET label-values?label=&match[]=&start=&end=&limit=
+//	GET metadata?metric=&limit=
+//	GET cardinality?match[]=&start=&end=&limit=
+//
+// The `refresh` parameter bypasses the cache. When the data source forwards the OAuth identity of the user, the
+// cache is per user.
+const (
+	PathLabels      = "labels"
+	PathLabelValues = "label-values"
+	PathMetadata    = "metadata"
+	PathCardinality = "cardinality"
+)
+
+// errBadRequest marks errors caused by invalid parameters.
+var errBadRequest = errors.New("bad request")
+
+// IsTypedResource returns whether path is one of the typed resource paths.
+func IsTypedResource(path string) bool {
+	switch strings.Trim(path, "/") {
+	case PathLabels, PathLabelValues, PathMetadata, PathCardinality:
+		return true
+	}
+	return false
+}
+
+// cacheTTL returns how long typed resources are cached for a cache level, the same durations the
+// frontend caches metadata for.
+func cacheTTL(cacheLevel string) time.Duration {
+	switch cacheLevel {
+	case "None":
+		return 0
+	case "Medium":
+		return 10 * time.Minute
+	case "High":
+		return time.Hour
+	default:
+		return time.Minute
+	}
+}
+
+// MetricMetadata is the metadata of a metric, as returned by api/v1/metadata.
+type MetricMetadata struct {
+	Type string `json:"type"`
+	Help string `json:"help"`
+	Unit string `json:"unit"`
+}
+
+// MetricCardinality is the number of series of a metric.
+type MetricCardinality struct {
+	Name   string `json:"name"`
+	Series int    `json:"series"`
+}
+
+// SeriesCardinality is the number of series matching the selectors, and their distribution over metrics.
+type SeriesCardinality struct {
+	Series  int                 `json:"series"`
+	Metrics []MetricCardinality `json:"metrics"`
+	// Limited is set when only the metrics with most series are listed.
+	Limited bool `json:"limited"`
+}
+
+// ExecuteTyped answers a typed resource request.
+func (r *Resource) ExecuteTyped(ctx context.Context, req *backend.CallResourceRequest) (*backend.CallResourceResponse, error) {
+	reqURL, err := url.Parse(req.URL)
+	if err != nil {
+		return nil, err
+	}
+	params := reqURL.Query()
+	path := strings.Trim(req.Path, "/")
+
+	refresh, _ := strconv.ParseBool(params.Get("refresh"))
+	params.Del("refresh")
+	cacheKey := path + "?" + params.Encode()
+	if r.forwardsIdentity {
+		// the Prometheus server may return different series depending on who is asking
+		var login string
+		if user := req.PluginContext.User; user != nil {
+			login = user.Login
+		}
+		cacheKey = login + "@" + cacheKey
+	}
+	if !refresh && r.cacheTTL > 0 {
+		if body, ok := r.cache.Get(cacheKey); ok {
+			return jsonResponse(body.([]byte), "HIT"), nil
+		}
+	}
+
+	result, err := r.typedResult(ctx, path, params)
+	if err != nil {
+		var apiErr *apiError
+		switch {
+		case errors.Is(err, errBadRequest):
+			return errorResponse(http.StatusBadRequest, err), nil
+		case errors.As(err, &apiErr):
+			return errorResponse(apiErr.status, err), nil
+		default:
+			return nil, err
+		}
+	}
+
+	body, err := json.Marshal(result)
+	if err != nil {
+		return nil, err
+	}
+	if r.cacheTTL > 0 {
+		r.cache.Set(cacheKey, body, r.cacheTTL)
+	}
+	return jsonResponse(body, "MISS"), nil
+}
+
+func (r *Resource) typedResult(ctx context.Context, path string, params url.Values) (any, error) {
+	limit, err := limitParam(params)
+	if err != nil {
+		return nil, err
+	}
+
+	switch path {
+	case PathLabels:
+		var labels []string
+		if err := r.queryAPI(ctx, "api/v1/labels", seriesParams(params, limit), true, &labels); err != nil {
+			return nil, err
+		}
+		return truncate(labels, limit), nil
+
+	case PathLabelValues:
+		label := params.Get("label")
+		if label == "" {
+			return nil, fmt.Errorf("%w: missing label parameter", errBadRequest)
+		}
+		var values []string
+		endpoint := "api/v1/label/" + url.PathEscape(label) + "/values"
+		if err := r.queryAPI(ctx, endpoint, seriesParams(params, limit), false, &values); err != nil {
+			return nil, err
+		}
+		return truncate(values, limit), nil
+
+	case PathMetadata:
+		apiParams := url.Values{}
+		if metric := params.Get("metric"); metric != "" {
+			apiParams.Set("metric", metric)
+		}
+		if limit > 0 {
+			apiParams.Set("limit", strconv.Itoa(limit))
+		}
+		metadata := map[string][]MetricMetadata{}
+		if err := r.queryAPI(ctx, "api/v1/metadata", apiParams, false, &metadata); err != nil {
+			return nil, err
+		}
+		return metadata, nil
+
+	case PathCardinality:
+		if len(params["match[]"]) == 0 {
+			return nil, fmt.Errorf("%w: missing match[] parameter", errBadRequest)
+		}
+		var series []map[string]string
+		if err := r.queryAPI(ctx, "api/v1/series", seriesParams(params, 0), true, &series); err != nil {
+			return nil, err
+		}
+		return seriesCardinality(series, limit), nil
+	}
+
+	return nil, fmt.Errorf("%w: unknown resource %s", errBadRequest, path)
+}
+
+// seriesParams returns the selector and time range parameters of a request for the HTTP API.
+func seriesParams(params url.Values, limit int) url.Values {
+	apiParams := url.Values{}
+	for _, key := range []string{"match[]", "start", "end"} {
+		for _, v := range params[key] {
+			apiParams.Add(key, v)
+		}
+	}
+	if limit > 0 {
+		// only honored by newer versions, results are trimmed in any case
+		apiParams.Set("limit", strconv.Itoa(limit))
+	}
+	return apiParams
+}
+
+// seriesCardinality counts series by metric name, the metrics with most series first.
+func seriesCardinality(series []map[string]string, limit int) SeriesCardinality {
+	counts := map[string]int{}
+	for _, s := range series {
+		counts[s["__name__"]]++
+	}
+
+	metrics := make([]MetricCardinality, 0, len(counts))
+	for name, n := range counts {
+		metrics = append(metrics, MetricCardinality{Name: name, Series: n})
+	}
+	sort.Slice(metrics, func(i, j int) bool {
+		if metrics[i].Series != metrics[j].Series {
+			return metrics[i].Series > metrics[j].Series
+		}
+		return metrics[i].Name < metrics[j].Name
+	})
+
+	return SeriesCardinality{
+		Series:  len(series),
+		Metrics: truncate(metrics, limit),
+		Limited: limit > 0 && len(metrics) > limit,
+	}
+}
+
+// apiError is an error returned by the HTTP API.
+type apiError struct {
+	status  int
+	message string
+}
+
+func (e *apiError) Error() string {
+	return e.message
+}
+
+// queryAPI calls an endpoint of the HTTP API and decodes the data of the response into v.
+func (r *Resource) queryAPI(ctx context.Context, endpoint string, params url.Values, allowPost bool, v any) error {
+	resp, err := r.promClient.QueryMetadata(ctx, endpoint, params, allowPost)
+	if err != nil {
+		return fmt.Errorf("error querying resource: %v", err)
+	}
+	defer func() {
+		if err := resp.Body.Close(); err != nil {
+			r.log.FromContext(ctx).Warn("Failed to close response body", "err", err)
+		}
+	}()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+
+	var apiResp struct {
+		Status string          `json:"status"`
+		Data   json.RawMessage `json:"data"`
+		Error  string          `json:"error"`
+	}
+	if err := json.Unmarshal(body, &apiResp); err != nil {
+		if resp.StatusCode/100 != 2 {
+			return &apiError{status: resp.StatusCode, message: strings.TrimSpace(string(body))}
+		}
+		return fmt.Errorf("failed to decode response of %s: %w", endpoint, err)
+	}
+	if apiResp.Status != "success" {
+		status := resp.StatusCode
+		if status/100 == 2 {
+			status = http.StatusBadGateway
+		}
+		return &apiError{status: status, message: apiResp.Error}
+	}
+
+	if len(apiResp.Data) == 0 || string(apiResp.Data) == "null" {
+		return nil
+	}
+	return json.Unmarshal(apiResp.Data, v)
+}
+
+func limitParam(params url.Values) (int, error) {
+	value := params.Get("limit")
+	if value == "" {
+		return 0, nil
+	}
+	limit, err := strconv.Atoi(value)
+	if err != nil || limit < 0 {
+		return 0, fmt.Errorf("%w: limit must be a positive integer", errBadRequest)
+	}
+	return limit, nil
+}
+
+func truncate[T any](values []T, limit int) []T {
+	if values == nil {
+		return []T{}
+	}
+	if limit > 0 && len(values) > limit {
+		return values[:limit]
+	}
+	return values
+}
+
+func jsonResponse(body []byte, cache string) *backend.CallResourceResponse {
+	return &backend.CallResourceResponse{
+		Status: http.StatusOK,
+		Headers: map[string][]string{
+			"Content-Type": {"application/json"},
+			"X-Cache":      {cache},
+		},
+		Body: body,
+	}
+}
+
+func errorResponse(status int, err error) *backend.CallResourceResponse {
+	body, _ := json.Marshal(map[string]string{"message": err.Error()})
+	return &backend.CallResourceResponse{
+		Status:  status,
+		Headers: map[string][]string{"Content-Type": {"application/json"}},
+		Body:    body,
+	}
+}
@@ -0,0 +1,205 @@
+package resource
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/grafana/grafana-plugin-sdk-go/backend/log"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/promlib/client"
+)
+
+type fakeDoer struct {
+	status   int
+	body     string
+	requests []*http.Request
+}
+
+func (d *fakeDoer) Do(req *http.Request) (*http.Response, error) {
+	d.requests = append(d.requests, req)
+	status := d.status
+	if status == 0 {
+		status = http.StatusOK
+	}
+	return &http.Response{
+		StatusCode: status,
+		Body:       io.NopCloser(strings.NewReader(d.body)),
+	}, nil
+}
+
+func newTestResource(t *testing.T, doer *fakeDoer, cacheLevel string) *Resource {
+	t.Helper()
+
+	r, err := New(&http.Client{}, backend.DataSourceInstanceSettings{
+		URL:      "http://localhost:9090",
+		JSONData: []byte(`{"cacheLevel":"` + cacheLevel + `"}`),
+	}, log.New())
+	require.NoError(t, err)
+	r.promClient = client.NewClient(doer, http.MethodPost, "http://localhost:9090")
+	return r
+}
+
+func executeTyped(t *testing.T, r *Resource, path string, query string) *backend.CallResourceResponse {
+	t.Helper()
+
+	resp, err := r.ExecuteTyped(context.Background(), &backend.CallResourceRequest{
+		Path:   path,
+		Method: http.MethodGet,
+		URL:    path + "?" + query,
+	})
+	require.NoError(t, err)
+	return resp
+}
+
+func TestExecuteTyped(t *testing.T) {
+	t.Run("labels are limited and cached", func(t *testing.T) {
+		doer := &fakeDoer{body: `{"status":"success","data":["__name__","instance","job"]}`}
+		r := newTestResource(t, doer, "")
+
+		resp := executeTyped(t, r, PathLabels, "match[]=up&limit=2")
+		require.Equal(t, http.StatusOK, resp.Status)
+		require.JSONEq(t, `["__name__","instance"]`, string(resp.Body))
+		require.Equal(t, []string{"MISS"}, resp.Headers["X-Cache"])
+
+		require.Len(t, doer.requests, 1)
+		req := doer.requests[0]
+		require.Equal(t, http.MethodPost, req.Method)
+		require.Equal(t, "/api/v1/labels", req.URL.Path)
+		body, err := io.ReadAll(req.Body)
+		require.NoError(t, err)
+		require.Equal(t, "limit=2&match%5B%5D=up", string(body))
+
+		resp = executeTyped(t, r, PathLabels, "match[]=up&limit=2")
+		require.Equal(t, []string{"HIT"}, resp.Headers["X-Cache"])
+		require.Len(t, doer.requests, 1)
+
+		executeTyped(t, r, PathLabels, "match[]=up&limit=2&refresh=true")
+		require.Len(t, doer.requests, 2)
+	})
+
+	t.Run("cache level None disables the cache", func(t *testing.T) {
+		doer := &fakeDoer{body: `{"status":"success","data":[]}`}
+		r := newTestResource(t, doer, "None")
+
+		executeTyped(t, r, PathLabels, "")
+		resp := executeTyped(t, r, PathLabels, "")
+		require.JSONEq(t, `[]`, string(resp.Body))
+		require.Len(t, doer.requests, 2)
+	})
+
+	t.Run("label values are scoped by matchers", func(t *testing.T) {
+		doer := &fakeDoer{body: `{"status":"success","data":["api","db"]}`}
+		r := newTestResource(t, doer, "")
+
+		resp := executeTyped(t, r, PathLabelValues, `label=job&match[]=up{env="prod"}`)
+		require.Equal(t, http.StatusOK, resp.Status)
+		require.JSONEq(t, `["api","db"]`, string(resp.Body))
+
+		req := doer.requests[0]
+		require.Equal(t, http.MethodGet, req.Method)
+		require.Equal(t, "/api/v1/label/job/values", req.URL.Path)
+		require.Equal(t, []string{`up{env="prod"}`}, req.URL.Query()["match[]"])
+	})
+
+	t.Run("label values require a label", func(t *testing.T) {
+		r := newTestResource(t, &fakeDoer{}, "")
+
+		resp := executeTyped(t, r, PathLabelValues, "")
+		require.Equal(t, http.StatusBadRequest, resp.Status)
+	})
+
+	t.Run("invalid limit", func(t *testing.T) {
+		r := newTestResource(t, &fakeDoer{}, "")
+
+		resp := executeTyped(t, r, PathLabels, "limit=many")
+		require.Equal(t, http.StatusBadRequest, resp.Status)
+	})
+
+	t.Run("metadata", func(t *testing.T) {
+		doer := &fakeDoer{body: `{"status":"success","data":{"up":[{"type":"gauge","help":"Target is up","unit":""}]}}`}
+		r := newTestResource(t, doer, "")
+
+		resp := executeTyped(t, r, PathMetadata, "metric=up&limit=1")
+		require.Equal(t, http.StatusOK, resp.Status)
+		require.JSONEq(t, `{"up":[{"type":"gauge","help":"Target is up","unit":""}]}`, string(resp.Body))
+		require.Equal(t, "limit=1&metric=up", doer.requests[0].URL.RawQuery)
+	})
+
+	t.Run("cardinality counts series by metric", func(t *testing.T) {
+		doer := &fakeDoer{body: `{"status":"success","data":[
+			{"__name__":"up","job":"api"},
+			{"__name__":"http_requests_total","job":"api","code":"200"},
+			{"__name__":"http_requests_total","job":"api","code":"500"},
+			{"__name__":"process_cpu_seconds_total","job":"api"}
+		]}`}
+		r := newTestResource(t, doer, "")
+
+		resp := executeTyped(t, r, PathCardinality, `match[]={job="api"}&limit=2`)
+		require.Equal(t, http.StatusOK, resp.Status)
+		require.JSONEq(t, `{
+			"series": 4,
+			"metrics": [{"name":"http_requests_total","series":2},{"name":"process_cpu_seconds_total","series":1}],
+			"limited": true
+		}`, string(resp.Body))
+	})
+
+	t.Run("cardinality requires a matcher", func(t *testing.T) {
+		r := newTestResource(t, &fakeDoer{}, "")
+
+		resp := executeTyped(t, r, PathCardinality, "")
+		require.Equal(t, http.StatusBadRequest, resp.Status)
+	})
+
+	t.Run("responses are cached per user when the OAuth identity is forwarded", func(t *testing.T) {
+		doer := &fakeDoer{body: `{"status":"success","data":["job"]}`}
+		r, err := New(&http.Client{}, backend.DataSourceInstanceSettings{
+			URL:      "http://localhost:9090",
+			JSONData: []byte(`{"oauthPassThru":true}`),
+		}, log.New())
+		require.NoError(t, err)
+		r.promClient = client.NewClient(doer, http.MethodPost, "http://localhost:9090")
+
+		executeAs := func(login string) *backend.CallResourceResponse {
+			resp, err := r.ExecuteTyped(context.Background(), &backend.CallResourceRequest{
+				PluginContext: backend.PluginContext{User: &backend.User{Login: login}},
+				Path:          PathLabels,
+				Method:        http.MethodGet,
+				URL:           PathLabels,
+			})
+			require.NoError(t, err)
+			return resp
+		}
+
+		require.Equal(t, []string{"MISS"}, executeAs("alice").Headers["X-Cache"])
+		require.Equal(t, []string{"HIT"}, executeAs("alice").Headers["X-Cache"])
+		require.Equal(t, []string{"MISS"}, executeAs("bob").Headers["X-Cache"])
+		require.Len(t, doer.requests, 2)
+	})
+
+	t.Run("errors of the API are passed on and not cached", func(t *testing.T) {
+		doer := &fakeDoer{
+			status: http.StatusUnprocessableEntity,
+			body:   `{"status":"error","errorType":"execution","error":"too many series"}`,
+		}
+		r := newTestResource(t, doer, "")
+
+		resp := executeTyped(t, r, PathLabels, "")
+		require.Equal(t, http.StatusUnprocessableEntity, resp.Status)
+		require.JSONEq(t, `{"message":"too many series"}`, string(resp.Body))
+
+		executeTyped(t, r, PathLabels, "")
+		require.Len(t, doer.requests, 2)
+	})
+}
+
+func TestIsTypedResource(t *testing.T) {
+	require.True(t, IsTypedResource("labels"))
+	require.True(t, IsTypedResource("/label-values"))
+	require.False(t, IsTypedResource("api/v1/labels"))
+	require.False(t, IsTypedResource("version-detect"))
+}
@@ -5,6 +5,9 @@ import (
 	"context"
 	"fmt"
 	"net/http"
+	"time"
+
+	"github.com/patrickmn/go-cache"
 
 	"github.com/grafana/grafana-plugin-sdk-go/backend"
 	"github.com/grafana/grafana-plugin-sdk-go/backend/log"
@@ -17,6 +20,12 @@ import (
 type Resource struct {
 	promClient *client.Client
 	log        log.Logger
+	// cache holds the responses of the typed resources for cacheTTL, set from the cache level of the data source
+	cache    *cache.Cache
+	cacheTTL time.Duration
+	// forwardsIdentity is set when requests are sent with the OAuth token of the user, responses are then
+	// only cached for the user they were fetched for.
+	forwardsIdentity bool
 }
 
 func New(
@@ -33,10 +42,16 @@ func New(
 	if httpMethod == "" {
 		httpMethod = http.MethodPost
 	}
+	cacheLevel, _ := maputil.GetStringOptional(jsonData, "cacheLevel")
+	ttl := cacheTTL(cacheLevel)
+	oauthPassThru, _ := maputil.GetBoolOptional(jsonData, "oauthPassThru")
 
 	return &Resource{
-		log:        plog,
-		promClient: client.NewClient(httpClient, httpMethod, settings.URL),
+		log:              plog,
+		promClient:       client.NewClient(httpClient, httpMethod, settings.URL),
+		cache:            cache.New(ttl, 2*ttl),
+		cacheTTL:         ttl,
+		forwardsIdentity: oauthPassThru,
 	}, nil
 }
 
@@ -0,0 +1,193 @@
+// Package queryrecording stores the responses of data source queries on disk as fixtures and replays them, for
+// reproducible tests and offline demos. Queries are matched by their normalized model and their time range
+// relative to the time they were recorded at, and replayed responses are shifted in time to the range of the
+// replayed query. It is shared by Grafana and the testdata data source, so it must not import Grafana packages.
+package queryrecording
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+)
+
+// PathConfigKey is the key of the Grafana config holding the directory of the recordings.
+const PathConfigKey = "GF_QUERY_RECORDING_PATH"
+
+var ErrNotFound = errors.New("no recording found for query")
+
+// ignoredFields are the fields of query models that don't change the response of a query, or are set by Grafana
+// for each request.
+var ignoredFields = []string{"refId", "datasource", "datasourceId", "intervalMs", "maxDataPoints", "requestId", "key", "hide", "queryCachingTTL"}
+
+// Fixture is a recorded query of a data source and its response.
+type Fixture struct {
+	Datasource DataSourceRef   `json:"datasource"`
+	Query      json.RawMessage `json:"query"`
+	Range      RelativeRange   `json:"range"`
+	// RecordedAt is the time of the recording, in epoch milliseconds.
+	RecordedAt int64          `json:"recordedAt"`
+	Frames     data.Frames    `json:"frames,omitempty"`
+	Error      string         `json:"error,omitempty"`
+	Status     backend.Status `json:"status,omitempty"`
+}
+
+type DataSourceRef struct {
+	OrgID int64  `json:"orgId"`
+	UID   string `json:"uid"`
+	Type  string `json:"type,omitempty"`
+}
+
+// RelativeRange is a time range relative to the time of a query, like "now-6h" to "now".
+type RelativeRange struct {
+	// DurationMs is the length of the range, rounded to the second.
+	DurationMs int64 `json:"durationMs"`
+	// OffsetMs is how long before the query the range ends, rounded to the minute.
+	OffsetMs int64 `json:"offsetMs"`
+}
+
+// RangeOf returns the time range relative to now.
+func RangeOf(tr backend.TimeRange, now time.Time) RelativeRange {
+	return RelativeRange{
+		DurationMs: tr.To.Sub(tr.From).Round(time.Second).Milliseconds(),
+		OffsetMs:   now.Sub(tr.To).Round(time.Minute).Milliseconds(),
+	}
+}
+
+// NormalizeQuery returns the model of a query without the fields that don't change its response, with sorted keys.
+func NormalizeQuery(model json.RawMessage) (json.RawMessage, error) {
+	var fields map[string]any
+	if err := json.Unmarshal(model, &fields); err != nil {
+		return nil, err
+	}
+	for _, f := range ignoredFields {
+		delete(fields, f)
+	}
+	return json.Marshal(fields)
+}
+
+// Key identifies the recording of a normalized query.
+func Key(query json.RawMessage, r RelativeRange) string {
+	sum := sha256.Sum256([]byte(fmt.Sprintf("%s\x00%d\x00%d", query, r.DurationMs, r.OffsetMs)))
+	return hex.EncodeToString(sum[:12])
+}
+
+// New records the response of a query.
+func New(ds DataSourceRef, q backend.DataQuery, resp backend.DataResponse, now time.Time) (*Fixture, error) {
+	query, err := NormalizeQuery(q.JSON)
+	if err != nil {
+		return nil, err
+	}
+	f := &Fixture{
+		Datasource: ds,
+		Query:      query,
+		Range:      RangeOf(q.TimeRange, now),
+		RecordedAt: now.UnixMilli(),
+		Frames:     resp.Frames,
+		Status:     resp.Status,
+	}
+	if resp.Error != nil {
+		f.Error = resp.Error.Error()
+	}
+	return f, nil
+}
+
+// Replay returns the recorded response, with its times shifted so the recorded range ends at the end of tr.
+func (f *Fixture) Replay(tr backend.TimeRange) backend.DataResponse {
+	recordedTo := time.UnixMilli(f.RecordedAt - f.Range.OffsetMs)
+	shift := tr.To.Sub(recordedTo)
+	for _, frame := range f.Frames {
+		shiftTimes(frame, shift)
+	}
+
+	resp := backend.DataResponse{Frames: f.Frames, Status: f.Status}
+	if f.Error != "" {
+		resp.Error = errors.New(f.Error)
+	}
+	return resp
+}
+
+func shiftTimes(frame *data.Frame, shift time.Duration) {
+	for _, field := range frame.Fields {
+		switch field.Type() {
+		case data.FieldTypeTime:
+			for i := 0; i < field.Len(); i++ {
+				field.Set(i, field.At(i).(time.Time).Add(shift))
+			}
+		case data.FieldTypeNullableTime:
+			for i := 0; i < field.Len(); i++ {
+				if t := field.At(i).(*time.Time); t != nil {
+					shifted := t.Add(shift)
+					field.Set(i, &shifted)
+				}
+			}
+		}
+	}
+}
+
+// Store keeps fixtures in a directory, in one file per query named after its key, in a directory per data source
+// of each organization.
+type Store struct {
+	dir string
+}
+
+func NewStore(dir string) *Store {
+	return &Store{dir: dir}
+}
+
+var unsafePathChars = regexp.MustCompile(`[^a-zA-Z0-9_-]`)
+
+func (s *Store) path(orgID int64, dsUID string, key string) string {
+	return filepath.Join(s.dir, strconv.FormatInt(orgID, 10), unsafePathChars.ReplaceAllString(dsUID, "_"), key+".json")
+}
+
+// Save writes a fixture, replacing the previous recording of the query.
+func (s *Store) Save(f *Fixture) error {
+	path := s.path(f.Datasource.OrgID, f.Datasource.UID, Key(f.Query, f.Range))
+	if err := os.MkdirAll(filepath.Dir(path), 0o750); err != nil {
+		return err
+	}
+	content, err := json.MarshalIndent(f, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	// write to a temporary file first, so replays never read a partial recording
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, content, 0o640); err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
+
+// Find returns the recording of a query of a data source of an organization, or ErrNotFound.
+func (s *Store) Find(orgID int64, dsUID string, q backend.DataQuery, now time.Time) (*Fixture, error) {
+	query, err := NormalizeQuery(q.JSON)
+	if err != nil {
+		return nil, err
+	}
+
+	// #nosec G304 -- the path is built from a hash and a sanitized uid
+	content, err := os.ReadFile(s.path(orgID, dsUID, Key(query, RangeOf(q.TimeRange, now))))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	f := &Fixture{}
+	if err := json.Unmarshal(content, f); err != nil {
+		return nil, fmt.Errorf("invalid recording: %w", err)
+	}
+	return f, nil
+}
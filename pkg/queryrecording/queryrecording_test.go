@@ -0,0 +1,102 @@
+package queryrecording
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNormalizeQuery(t *testing.T) {
+	a, err := NormalizeQuery(json.RawMessage(`{"refId":"A","expr":"up","datasource":{"uid":"p1"},"intervalMs":1000,"maxDataPoints":100,"format":"time_series"}`))
+	require.NoError(t, err)
+	b, err := NormalizeQuery(json.RawMessage(`{"format":"time_series","expr":"up","refId":"B","intervalMs":2000}`))
+	require.NoError(t, err)
+
+	require.JSONEq(t, `{"expr":"up","format":"time_series"}`, string(a))
+	require.Equal(t, string(a), string(b))
+
+	_, err = NormalizeQuery(json.RawMessage(`not json`))
+	require.Error(t, err)
+}
+
+func TestRangeOf(t *testing.T) {
+	now := time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+
+	r := RangeOf(backend.TimeRange{From: now.Add(-6*time.Hour - 200*time.Millisecond), To: now.Add(-10 * time.Second)}, now)
+	require.Equal(t, RelativeRange{DurationMs: (6*time.Hour - 10*time.Second).Milliseconds(), OffsetMs: 0}, r)
+
+	r = RangeOf(backend.TimeRange{From: now.Add(-25 * time.Hour), To: now.Add(-24 * time.Hour)}, now)
+	require.Equal(t, RelativeRange{DurationMs: time.Hour.Milliseconds(), OffsetMs: (24 * time.Hour).Milliseconds()}, r)
+}
+
+func TestStore(t *testing.T) {
+	store := NewStore(t.TempDir())
+	recordedAt := time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+	query := backend.DataQuery{
+		RefID:     "A",
+		JSON:      json.RawMessage(`{"refId":"A","expr":"up"}`),
+		TimeRange: backend.TimeRange{From: recordedAt.Add(-time.Hour), To: recordedAt},
+	}
+	resp := backend.DataResponse{Frames: data.Frames{
+		data.NewFrame("up",
+			data.NewField("time", nil, []time.Time{recordedAt.Add(-time.Minute), recordedAt}),
+			data.NewField("value", data.Labels{"job": "grafana"}, []float64{1, 0}),
+		),
+	}}
+
+	f, err := New(DataSourceRef{OrgID: 1, UID: "../prom", Type: "prometheus"}, query, resp, recordedAt)
+	require.NoError(t, err)
+	require.NoError(t, store.Save(f))
+
+	t.Run("finds the recording of the same query at a later time", func(t *testing.T) {
+		now := recordedAt.Add(48 * time.Hour)
+		q := backend.DataQuery{
+			RefID:     "B",
+			JSON:      json.RawMessage(`{"expr":"up","refId":"B","intervalMs":15000}`),
+			TimeRange: backend.TimeRange{From: now.Add(-time.Hour), To: now},
+		}
+
+		found, err := store.Find(1, "../prom", q, now)
+		require.NoError(t, err)
+
+		replayed := found.Replay(q.TimeRange)
+		require.NoError(t, replayed.Error)
+		require.Len(t, replayed.Frames, 1)
+		require.Equal(t, now, replayed.Frames[0].Fields[0].At(1).(time.Time).UTC())
+		require.Equal(t, now.Add(-time.Minute), replayed.Frames[0].Fields[0].At(0).(time.Time).UTC())
+		require.Equal(t, 1.0, replayed.Frames[0].Fields[1].At(0))
+		require.Equal(t, data.Labels{"job": "grafana"}, replayed.Frames[0].Fields[1].Labels)
+	})
+
+	t.Run("doesn't find other queries or ranges", func(t *testing.T) {
+		_, err := store.Find(1, "../prom", backend.DataQuery{JSON: json.RawMessage(`{"expr":"down"}`), TimeRange: query.TimeRange}, recordedAt)
+		require.True(t, errors.Is(err, ErrNotFound))
+
+		_, err = store.Find(1, "../prom", backend.DataQuery{JSON: query.JSON, TimeRange: backend.TimeRange{From: recordedAt.Add(-2 * time.Hour), To: recordedAt}}, recordedAt)
+		require.True(t, errors.Is(err, ErrNotFound))
+
+		_, err = store.Find(1, "other", query, recordedAt)
+		require.True(t, errors.Is(err, ErrNotFound))
+
+		_, err = store.Find(2, "../prom", query, recordedAt)
+		require.True(t, errors.Is(err, ErrNotFound))
+	})
+
+	t.Run("replays recorded errors", func(t *testing.T) {
+		q := backend.DataQuery{JSON: json.RawMessage(`{"expr":"bad("}`), TimeRange: query.TimeRange}
+		f, err := New(DataSourceRef{OrgID: 1, UID: "prom"}, q, backend.ErrDataResponse(backend.StatusBadRequest, "parse error"), recordedAt)
+		require.NoError(t, err)
+		require.NoError(t, store.Save(f))
+
+		found, err := store.Find(1, "prom", q, recordedAt)
+		require.NoError(t, err)
+		replayed := found.Replay(q.TimeRange)
+		require.EqualError(t, replayed.Error, "parse error")
+		require.Equal(t, backend.StatusBadRequest, replayed.Status)
+	})
+}
@@ -0,0 +1,229 @@
+package caching
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"strconv"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/infra/remotecache"
+	"github.com/grafana/grafana/pkg/services/contexthandler"
+)
+
+const (
+	queryCacheKeyPrefix = "query-cache:"
+	// skipCacheHeader can be set on a request to run the queries without looking up the cache.
+	skipCacheHeader = "X-Cache-Skip"
+)
+
+// queryKeyIgnoredFields are fields of a query model that don't change its results.
+var queryKeyIgnoredFields = []string{"datasource", "datasourceId", "requestId", "hide", "key"}
+
+type keyQuery struct {
+	RefID         string         `json:"refId"`
+	QueryType     string         `json:"queryType"`
+	Interval      time.Duration  `json:"interval"`
+	MaxDataPoints int64          `json:"maxDataPoints"`
+	From          int64          `json:"from"`
+	To            int64          `json:"to"`
+	Model         map[string]any `json:"model"`
+}
+
+type keyRequest struct {
+	OrgID      int64      `json:"orgId"`
+	Datasource string     `json:"datasource"`
+	User       string     `json:"user,omitempty"`
+	Queries    []keyQuery `json:"queries"`
+}
+
+// cacheKey returns the cache key of a request. It includes the exact time range of every query, as responses
+// are only valid for the time range they were queried for. Data sources splitting queries into chunks aligned
+// to the query interval share the cached chunks between refreshes, see pkg/services/query/split.go.
+func cacheKey(req *backend.QueryDataRequest) (string, error) {
+	settings := req.PluginContext.DataSourceInstanceSettings
+	kr := keyRequest{
+		OrgID:      req.PluginContext.OrgID,
+		Datasource: settings.UID,
+		Queries:    make([]keyQuery, 0, len(req.Queries)),
+	}
+	if forwardsIdentity(settings) && req.PluginContext.User != nil {
+		// results depend on who is asking
+		kr.User = req.PluginContext.User.Login
+	}
+
+	for _, q := range req.Queries {
+		model := map[string]any{}
+		if len(q.JSON) > 0 {
+			if err := json.Unmarshal(q.JSON, &model); err != nil {
+				return "", err
+			}
+		}
+		for _, field := range queryKeyIgnoredFields {
+			delete(model, field)
+		}
+
+		kr.Queries = append(kr.Queries, keyQuery{
+			RefID:         q.RefID,
+			QueryType:     q.QueryType,
+			Interval:      q.Interval,
+			MaxDataPoints: q.MaxDataPoints,
+			From:          q.TimeRange.From.UnixMilli(),
+			To:            q.TimeRange.To.UnixMilli(),
+			Model:         model,
+		})
+	}
+
+	// maps are encoded with sorted keys, so equal queries give equal keys whatever the field order
+	b, err := json.Marshal(kr)
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256(b)
+	return queryCacheKeyPrefix + settings.UID + ":" + hex.EncodeToString(sum[:]), nil
+}
+
+// forwardsIdentity returns whether a data source queries with the identity of the user.
+func forwardsIdentity(settings *backend.DataSourceInstanceSettings) bool {
+	var jsonData struct {
+		OAuthPassThru bool `json:"oauthPassThru"`
+	}
+	_ = json.Unmarshal(settings.JSONData, &jsonData)
+	return jsonData.OAuthPassThru
+}
+
+// datasourceTTL returns the TTL a data source sets with the queryCachingTTL setting, in milliseconds.
+func datasourceTTL(settings *backend.DataSourceInstanceSettings) time.Duration {
+	var jsonData struct {
+		TTL any `json:"queryCachingTTL"`
+	}
+	if err := json.Unmarshal(settings.JSONData, &jsonData); err != nil {
+		return 0
+	}
+
+	var ms float64
+	switch v := jsonData.TTL.(type) {
+	case float64:
+		ms = v
+	case string:
+		ms, _ = strconv.ParseFloat(v, 64)
+	}
+	if ms <= 0 {
+		return 0
+	}
+	return time.Duration(ms) * time.Millisecond
+}
+
+// cacheable returns whether a response can be cached. Responses with errors are not cached.
+func cacheable(resp *backend.QueryDataResponse) bool {
+	if resp == nil {
+		return false
+	}
+	for _, dr := range resp.Responses {
+		if dr.Error != nil || dr.Status >= backend.StatusBadRequest {
+			return false
+		}
+	}
+	return true
+}
+
+// queryCache caches query responses in the remote cache.
+type queryCache struct {
+	cache        remotecache.CacheStorage
+	ttl          time.Duration
+	maxValueSize int
+	log          log.Logger
+}
+
+func (c *queryCache) handleQueryRequest(ctx context.Context, req *backend.QueryDataRequest) (bool, CachedQueryDataResponse) {
+	settings := req.PluginContext.DataSourceInstanceSettings
+	if settings == nil || settings.UID == "" || len(req.Queries) == 0 {
+		return false, CachedQueryDataResponse{}
+	}
+
+	reqCtx := contexthandler.FromContext(ctx)
+	setStatus := func(status string) {
+		if reqCtx != nil && reqCtx.Resp != nil {
+			reqCtx.Resp.Header().Set(XCacheHeader, status)
+		}
+	}
+
+	key, err := cacheKey(req)
+	if err != nil {
+		c.log.FromContext(ctx).Debug("Failed to create query cache key", "error", err)
+		setStatus(StatusError)
+		return false, CachedQueryDataResponse{}
+	}
+
+	updateFn := c.updateFn(key, c.ttlFor(settings))
+	if reqCtx != nil && reqCtx.Req != nil && reqCtx.Req.Header.Get(skipCacheHeader) == "true" {
+		setStatus(StatusBypass)
+		return false, CachedQueryDataResponse{UpdateCacheFn: updateFn}
+	}
+
+	resp, err := c.get(ctx, key)
+	if err != nil {
+		c.log.FromContext(ctx).Warn("Failed to read the query cache", "error", err)
+		setStatus(StatusError)
+		return false, CachedQueryDataResponse{}
+	}
+	if resp != nil {
+		setStatus(StatusHit)
+		return true, CachedQueryDataResponse{Response: resp}
+	}
+
+	setStatus(StatusMiss)
+	return false, CachedQueryDataResponse{UpdateCacheFn: updateFn}
+}
+
+func (c *queryCache) get(ctx context.Context, key string) (*backend.QueryDataResponse, error) {
+	b, err := c.cache.Get(ctx, key)
+	if err != nil {
+		if errors.Is(err, remotecache.ErrCacheItemNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	resp := &backend.QueryDataResponse{}
+	if err := json.Unmarshal(b, resp); err != nil {
+		// an entry written by another version, it is replaced on the next miss
+		c.log.FromContext(ctx).Debug("Failed to decode cached query response", "error", err)
+		return nil, nil
+	}
+	return resp, nil
+}
+
+// updateFn caches the responses of the request under its key.
+func (c *queryCache) updateFn(key string, ttl time.Duration) CacheQueryResponseFn {
+	return func(ctx context.Context, resp *backend.QueryDataResponse) {
+		if !cacheable(resp) {
+			return
+		}
+
+		b, err := json.Marshal(resp)
+		if err != nil {
+			c.log.FromContext(ctx).Debug("Failed to encode query response", "error", err)
+			return
+		}
+		if c.maxValueSize > 0 && len(b) > c.maxValueSize {
+			c.log.FromContext(ctx).Debug("Query response too large to cache", "size", len(b), "max", c.maxValueSize)
+			return
+		}
+		if err := c.cache.Set(ctx, key, b, ttl); err != nil {
+			c.log.FromContext(ctx).Warn("Failed to write the query cache", "error", err)
+		}
+	}
+}
+
+func (c *queryCache) ttlFor(settings *backend.DataSourceInstanceSettings) time.Duration {
+	if ttl := datasourceTTL(settings); ttl > 0 {
+		return ttl
+	}
+	return c.ttl
+}
@@ -0,0 +1,186 @@
+package caching
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/infra/remotecache"
+	"github.com/grafana/grafana/pkg/services/contexthandler/ctxkey"
+	contextmodel "github.com/grafana/grafana/pkg/services/contexthandler/model"
+	"github.com/grafana/grafana/pkg/setting"
+	"github.com/grafana/grafana/pkg/web"
+)
+
+func newTestCachingService(t *testing.T) (*OSSCachingService, *remotecache.FakeCacheStorage) {
+	t.Helper()
+
+	cfg := setting.NewCfg()
+	cfg.QueryCaching = setting.QueryCachingSettings{Enabled: true, TTL: time.Minute}
+	store := remotecache.NewFakeCacheStorage()
+	return ProvideCachingService(cfg, store), &store
+}
+
+func newReqContext(t *testing.T) (context.Context, *contextmodel.ReqContext) {
+	t.Helper()
+
+	req, err := http.NewRequest(http.MethodPost, "/api/ds/query", nil)
+	require.NoError(t, err)
+	reqCtx := &contextmodel.ReqContext{
+		Context: &web.Context{
+			Req:  req,
+			Resp: web.NewResponseWriter(req.Method, httptest.NewRecorder()),
+		},
+	}
+	return ctxkey.Set(context.Background(), reqCtx), reqCtx
+}
+
+func newQueryRequest(from time.Time, jsonData string, model string) *backend.QueryDataRequest {
+	return &backend.QueryDataRequest{
+		PluginContext: backend.PluginContext{
+			OrgID: 1,
+			DataSourceInstanceSettings: &backend.DataSourceInstanceSettings{
+				UID:      "prom",
+				JSONData: []byte(jsonData),
+			},
+		},
+		Queries: []backend.DataQuery{{
+			RefID:     "A",
+			Interval:  time.Minute,
+			TimeRange: backend.TimeRange{From: from, To: from.Add(time.Hour)},
+			JSON:      []byte(model),
+		}},
+	}
+}
+
+func timeSeriesResponse() *backend.QueryDataResponse {
+	return &backend.QueryDataResponse{Responses: backend.Responses{
+		"A": {Frames: data.Frames{data.NewFrame("",
+			data.NewField("time", nil, []time.Time{time.Unix(0, 0)}),
+			data.NewField("value", nil, []float64{1}),
+		)}},
+	}}
+}
+
+func TestOSSCachingServiceQueries(t *testing.T) {
+	start := time.Date(2024, 1, 1, 10, 0, 5, 0, time.UTC)
+
+	t.Run("disabled by default", func(t *testing.T) {
+		s := ProvideCachingService(setting.NewCfg(), remotecache.NewFakeCacheStorage())
+		ctx, reqCtx := newReqContext(t)
+
+		hit, cr := s.HandleQueryRequest(ctx, newQueryRequest(start, `{}`, `{"expr":"up"}`))
+		require.False(t, hit)
+		require.Nil(t, cr.UpdateCacheFn)
+		require.Empty(t, reqCtx.Resp.Header().Get(XCacheHeader))
+	})
+
+	t.Run("misses then hits", func(t *testing.T) {
+		s, _ := newTestCachingService(t)
+
+		ctx, reqCtx := newReqContext(t)
+		hit, cr := s.HandleQueryRequest(ctx, newQueryRequest(start, `{}`, `{"expr":"up","legendFormat":"{{job}}"}`))
+		require.False(t, hit)
+		require.Equal(t, StatusMiss, reqCtx.Resp.Header().Get(XCacheHeader))
+		require.NotNil(t, cr.UpdateCacheFn)
+		cr.UpdateCacheFn(ctx, timeSeriesResponse())
+
+		// same query, fields in another order
+		ctx, reqCtx = newReqContext(t)
+		hit, cr = s.HandleQueryRequest(ctx, newQueryRequest(start, `{}`, `{"legendFormat":"{{job}}","expr":"up","requestId":"Q2"}`))
+		require.True(t, hit)
+		require.Equal(t, StatusHit, reqCtx.Resp.Header().Get(XCacheHeader))
+		require.Len(t, cr.Response.Responses["A"].Frames, 1)
+
+		ctx, _ = newReqContext(t)
+		hit, _ = s.HandleQueryRequest(ctx, newQueryRequest(start, `{}`, `{"expr":"down"}`))
+		require.False(t, hit)
+	})
+
+	t.Run("responses need the exact time range", func(t *testing.T) {
+		s, _ := newTestCachingService(t)
+
+		ctx, _ := newReqContext(t)
+		_, cr := s.HandleQueryRequest(ctx, newQueryRequest(start, `{}`, `{"expr":"up"}`))
+		cr.UpdateCacheFn(ctx, timeSeriesResponse())
+
+		ctx, _ = newReqContext(t)
+		hit, _ := s.HandleQueryRequest(ctx, newQueryRequest(start.Add(30*time.Second), `{}`, `{"expr":"up"}`))
+		require.False(t, hit)
+
+		ctx, _ = newReqContext(t)
+		hit, _ = s.HandleQueryRequest(ctx, newQueryRequest(start, `{}`, `{"expr":"up"}`))
+		require.True(t, hit)
+	})
+
+	t.Run("errors are not cached", func(t *testing.T) {
+		s, store := newTestCachingService(t)
+
+		ctx, _ := newReqContext(t)
+		_, cr := s.HandleQueryRequest(ctx, newQueryRequest(start, `{}`, `{"expr":"up"}`))
+		cr.UpdateCacheFn(ctx, &backend.QueryDataResponse{Responses: backend.Responses{
+			"A": backend.ErrDataResponse(backend.StatusBadRequest, "parse error"),
+		}})
+		require.Empty(t, store.Storage)
+	})
+
+	t.Run("skip header bypasses the cache", func(t *testing.T) {
+		s, _ := newTestCachingService(t)
+
+		ctx, _ := newReqContext(t)
+		_, cr := s.HandleQueryRequest(ctx, newQueryRequest(start, `{}`, `{"expr":"up"}`))
+		cr.UpdateCacheFn(ctx, timeSeriesResponse())
+
+		ctx, reqCtx := newReqContext(t)
+		reqCtx.Req.Header.Set(skipCacheHeader, "true")
+		hit, cr := s.HandleQueryRequest(ctx, newQueryRequest(start, `{}`, `{"expr":"up"}`))
+		require.False(t, hit)
+		require.NotNil(t, cr.UpdateCacheFn)
+		require.Equal(t, StatusBypass, reqCtx.Resp.Header().Get(XCacheHeader))
+	})
+
+	t.Run("data sources forwarding the user identity are cached per user", func(t *testing.T) {
+		s, _ := newTestCachingService(t)
+
+		query := func(login string) *backend.QueryDataRequest {
+			req := newQueryRequest(start, `{"oauthPassThru":true}`, `{"expr":"up"}`)
+			req.PluginContext.User = &backend.User{Login: login}
+			return req
+		}
+
+		ctx, _ := newReqContext(t)
+		_, cr := s.HandleQueryRequest(ctx, query("alice"))
+		cr.UpdateCacheFn(ctx, timeSeriesResponse())
+
+		ctx, _ = newReqContext(t)
+		hit, _ := s.HandleQueryRequest(ctx, query("bob"))
+		require.False(t, hit)
+
+		ctx, _ = newReqContext(t)
+		hit, _ = s.HandleQueryRequest(ctx, query("alice"))
+		require.True(t, hit)
+	})
+}
+
+func TestDatasourceTTL(t *testing.T) {
+	tests := []struct {
+		jsonData string
+		ttl      time.Duration
+	}{
+		{jsonData: `{}`, ttl: 0},
+		{jsonData: `{"queryCachingTTL":60000}`, ttl: time.Minute},
+		{jsonData: `{"queryCachingTTL":"30000"}`, ttl: 30 * time.Second},
+		{jsonData: `{"queryCachingTTL":-1}`, ttl: 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.jsonData, func(t *testing.T) {
+			require.Equal(t, tt.ttl, datasourceTTL(&backend.DataSourceInstanceSettings{JSONData: []byte(tt.jsonData)}))
+		})
+	}
+}
@@ -4,6 +4,10 @@ import (
 	"context"
 
 	"github.com/grafana/grafana-plugin-sdk-go/backend"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/infra/remotecache"
+	"github.com/grafana/grafana/pkg/setting"
 )
 
 const (
@@ -35,8 +39,17 @@ type CachedResourceDataResponse struct {
 	UpdateCacheFn CacheResourceResponseFn
 }
 
-func ProvideCachingService() *OSSCachingService {
-	return &OSSCachingService{}
+func ProvideCachingService(cfg *setting.Cfg, cache remotecache.CacheStorage) *OSSCachingService {
+	s := &OSSCachingService{}
+	if cfg.QueryCaching.Enabled {
+		s.queries = &queryCache{
+			cache:        cache,
+			ttl:          cfg.QueryCaching.TTL,
+			maxValueSize: cfg.QueryCaching.MaxValueSize,
+			log:          log.New("query-cache"),
+		}
+	}
+	return s
 }
 
 type CachingService interface {
@@ -49,12 +62,17 @@ type CachingService interface {
 	HandleResourceRequest(context.Context, *backend.CallResourceRequest) (bool, CachedResourceDataResponse)
 }
 
-// Implementation of interface - does nothing
+// Implementation of interface - caches query results in the remote cache when query caching is enabled,
+// resource requests are never cached
 type OSSCachingService struct {
+	queries *queryCache
 }
 
 func (s *OSSCachingService) HandleQueryRequest(ctx context.Context, req *backend.QueryDataRequest) (bool, CachedQueryDataResponse) {
-	return false, CachedQueryDataResponse{}
+	if s.queries == nil || req == nil {
+		return false, CachedQueryDataResponse{}
+	}
+	return s.queries.handleQueryRequest(ctx, req)
 }
 
 func (s *OSSCachingService) HandleResourceRequest(ctx context.Context, req *backend.CallResourceRequest) (bool, CachedResourceDataResponse) {
@@ -2,7 +2,9 @@ package dashboards
 
 import (
 	"errors"
+	"fmt"
 
+	"github.com/grafana/grafana/pkg/components/dashdiffs"
 	"github.com/grafana/grafana/pkg/util"
 )
 
@@ -160,6 +162,29 @@ func (e DashboardErr) Body() util.DynMap {
 	return util.DynMap{"status": e.Status, "message": e.Error()}
 }
 
+// DashboardMergeConflictError is returned when the changes saved from an
+// outdated version of a dashboard can't be merged with the changes saved
+// since that version. In the conflicts, ours are the changes being saved and
+// theirs the changes saved since.
+type DashboardMergeConflictError struct {
+	Conflicts []dashdiffs.Conflict
+}
+
+func (e DashboardMergeConflictError) Error() string {
+	return fmt.Sprintf("%s: %d conflicting changes", ErrDashboardVersionMismatch.Reason, len(e.Conflicts))
+}
+
+func (e DashboardMergeConflictError) Unwrap() error {
+	return ErrDashboardVersionMismatch
+}
+
+// Body returns the error's response body.
+func (e DashboardMergeConflictError) Body() util.DynMap {
+	body := ErrDashboardVersionMismatch.Body()
+	body["conflicts"] = e.Conflicts
+	return body
+}
+
 type UpdatePluginDashboardError struct {
 	PluginId string
 }
@@ -297,6 +297,9 @@ type SaveDashboardDTO struct {
 	Message   string
 	Overwrite bool
 	Dashboard *Dashboard
+	// Merged is set when the dashboard was saved from an outdated version, and
+	// merged with the changes saved since that version.
+	Merged bool
 }
 
 type DashboardSearchProjection struct {
@@ -13,12 +13,15 @@ import (
 
 	"github.com/grafana/grafana-plugin-sdk-go/backend/gtime"
 
+	"github.com/grafana/grafana/pkg/components/dashdiffs"
+	"github.com/grafana/grafana/pkg/components/simplejson"
 	"github.com/grafana/grafana/pkg/infra/log"
 	"github.com/grafana/grafana/pkg/infra/metrics"
 	"github.com/grafana/grafana/pkg/services/accesscontrol"
 	"github.com/grafana/grafana/pkg/services/auth/identity"
 	"github.com/grafana/grafana/pkg/services/dashboards"
 	"github.com/grafana/grafana/pkg/services/dashboards/dashboardaccess"
+	dashver "github.com/grafana/grafana/pkg/services/dashboardversion"
 	"github.com/grafana/grafana/pkg/services/datasources"
 	"github.com/grafana/grafana/pkg/services/featuremgmt"
 	"github.com/grafana/grafana/pkg/services/folder"
@@ -353,6 +356,12 @@ func (dr *DashboardServiceImpl) SaveDashboard(ctx context.Context, dto *dashboar
 	}
 
 	cmd, err := dr.BuildSaveDashboardCommand(ctx, dto, !allowUiUpdate)
+	if errors.Is(err, dashboards.ErrDashboardVersionMismatch) && !dto.Overwrite {
+		// the dashboard was saved by someone else since it was loaded
+		if err = dr.mergeConcurrentChanges(ctx, dto); err == nil {
+			cmd, err = dr.BuildSaveDashboardCommand(ctx, dto, !allowUiUpdate)
+		}
+	}
 	if err != nil {
 		return nil, err
 	}
@@ -369,6 +378,47 @@ func (dr *DashboardServiceImpl) SaveDashboard(ctx context.Context, dto *dashboar
 
 	return dash, nil
 }
+
+// mergeConcurrentChanges merges the changes of a dashboard saved from an
+// outdated version with the changes saved since that version, using the
+// version as the base of a three-way merge. It returns the version mismatch
+// error when the version is no longer kept, and the conflicting paths when
+// both changes can't be merged.
+func (dr *DashboardServiceImpl) mergeConcurrentChanges(ctx context.Context, dto *dashboards.SaveDashboardDTO) error {
+	dash := dto.Dashboard
+	if dash.Version == 0 {
+		return dashboards.ErrDashboardVersionMismatch
+	}
+
+	existing, err := dr.dashboardStore.GetDashboard(ctx, &dashboards.GetDashboardQuery{ID: dash.ID, UID: dash.UID, OrgID: dto.OrgID})
+	if err != nil {
+		return err
+	}
+
+	base, err := dr.dashboardStore.GetDashboardVersionData(ctx, dto.OrgID, existing.ID, dash.Version)
+	if err != nil {
+		if errors.Is(err, dashver.ErrDashboardVersionNotFound) {
+			return dashboards.ErrDashboardVersionMismatch
+		}
+		return err
+	}
+
+	merged, conflicts := dashdiffs.Merge(base.MustMap(), dash.Data.MustMap(), existing.Data.MustMap())
+	if len(conflicts) > 0 {
+		return dashboards.DashboardMergeConflictError{Conflicts: conflicts}
+	}
+
+	dr.log.Info("Merged concurrent dashboard changes", "dashboardUid", existing.UID, "baseVersion", dash.Version,
+		"version", existing.Version)
+	dash.Data = simplejson.NewFromAny(merged)
+	dash.SetID(existing.ID)
+	dash.SetUID(existing.UID)
+	dash.SetVersion(existing.Version)
+	dash.Title = strings.TrimSpace(dash.Data.Get("title").MustString())
+	dto.Merged = true
+	return nil
+}
+
 func (dr *DashboardServiceImpl) GetSoftDeletedDashboard(ctx context.Context, orgID int64, uid string) (*dashboards.Dashboard, error) {
 	return dr.dashboardStore.GetSoftDeletedDashboard(ctx, orgID, uid)
 }
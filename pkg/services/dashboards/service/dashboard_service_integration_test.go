@@ -554,6 +554,50 @@ func TestIntegrationIntegratedDashboardService(t *testing.T) {
 						require.NoError(t, err)
 					})
 
+				permissionScenario(t, "When updating an existing dashboard from an outdated version", canSave,
+					func(t *testing.T, sc *permissionScenarioContext) {
+						cmd := dashboards.SaveDashboardCommand{
+							OrgID: 1,
+							Dashboard: simplejson.NewFromAny(map[string]any{
+								"uid":         sc.savedDashInFolder.UID,
+								"title":       sc.savedDashInFolder.Title,
+								"description": "Saved by someone else",
+								"version":     sc.savedDashInFolder.Version,
+							}),
+							FolderUID: sc.savedDashInFolder.FolderUID,
+							Overwrite: shouldOverwrite,
+						}
+						res := callSaveWithResult(t, cmd, sc.sqlStore)
+						require.Equal(t, sc.savedDashInFolder.Version+1, res.Version)
+
+						cmd.Dashboard = simplejson.NewFromAny(map[string]any{
+							"uid":     sc.savedDashInFolder.UID,
+							"title":   "Updated title",
+							"version": sc.savedDashInFolder.Version,
+						})
+						res = callSaveWithResult(t, cmd, sc.sqlStore)
+						require.Equal(t, sc.savedDashInFolder.Version+2, res.Version)
+
+						dash, err := sc.dashboardStore.GetDashboard(context.Background(), &dashboards.GetDashboardQuery{
+							ID:    sc.savedDashInFolder.ID,
+							OrgID: cmd.OrgID,
+						})
+						require.NoError(t, err)
+						assert.Equal(t, "Updated title", dash.Title)
+						assert.Equal(t, "Saved by someone else", dash.Data.Get("description").MustString())
+
+						cmd.Dashboard = simplejson.NewFromAny(map[string]any{
+							"uid":         sc.savedDashInFolder.UID,
+							"title":       sc.savedDashInFolder.Title,
+							"description": "Conflicting change",
+							"version":     sc.savedDashInFolder.Version,
+						})
+						err = callSaveWithError(t, cmd, sc.sqlStore)
+						var conflictErr dashboards.DashboardMergeConflictError
+						require.ErrorAs(t, err, &conflictErr)
+						assert.Equal(t, "description", conflictErr.Conflicts[0].Path)
+					})
+
 				permissionScenario(t, "When creating a dashboard with same name as dashboard in other folder",
 					canSave, func(t *testing.T, sc *permissionScenarioContext) {
 						cmd := dashboards.SaveDashboardCommand{
@@ -11,6 +11,7 @@ import (
 	"github.com/grafana/grafana/pkg/infra/appcontext"
 	"github.com/grafana/grafana/pkg/infra/log"
 	"github.com/grafana/grafana/pkg/services/dashboards"
+	dashver "github.com/grafana/grafana/pkg/services/dashboardversion"
 	"github.com/grafana/grafana/pkg/services/featuremgmt"
 	"github.com/grafana/grafana/pkg/services/folder"
 	"github.com/grafana/grafana/pkg/services/folder/foldertest"
@@ -223,3 +224,85 @@ func TestDashboardService(t *testing.T) {
 		})
 	})
 }
+
+func TestDashboardServiceMergeConcurrentChanges(t *testing.T) {
+	origNewDashboardGuardian := guardian.New
+	defer func() { guardian.New = origNewDashboardGuardian }()
+	guardian.MockDashboardGuardian(&guardian.FakeDashboardGuardian{CanSaveValue: true})
+
+	dashboardJSON := func(t *testing.T, text string) *simplejson.Json {
+		t.Helper()
+		data, err := simplejson.NewJson([]byte(text))
+		require.NoError(t, err)
+		return data
+	}
+
+	setup := func(t *testing.T, saved string) (*DashboardServiceImpl, *dashboards.FakeDashboardStore) {
+		fakeStore := dashboards.NewFakeDashboardStore(t)
+		service := &DashboardServiceImpl{
+			cfg:            setting.NewCfg(),
+			log:            log.New("test.logger"),
+			dashboardStore: fakeStore,
+			folderService:  foldertest.NewFakeService(),
+			features:       featuremgmt.WithFeatures(),
+		}
+
+		fakeStore.On("ValidateDashboardBeforeSave", mock.Anything, mock.Anything, false).Return(false, dashboards.ErrDashboardVersionMismatch).Once()
+		fakeStore.On("GetDashboard", mock.Anything, mock.AnythingOfType("*dashboards.GetDashboardQuery")).
+			Return(dashboards.NewDashboardFromJson(dashboardJSON(t, saved)), nil).Once()
+		return service, fakeStore
+	}
+
+	base := `{"id": 3, "uid": "abc", "version": 2, "title": "Dash", "panels": [{"id": 1, "title": "CPU"}, {"id": 2, "title": "Memory"}]}`
+	newDTO := func(t *testing.T, text string) *dashboards.SaveDashboardDTO {
+		return &dashboards.SaveDashboardDTO{
+			OrgID:     1,
+			User:      &user.SignedInUser{UserID: 1, OrgID: 1},
+			Dashboard: dashboards.NewDashboardFromJson(dashboardJSON(t, text)),
+		}
+	}
+
+	t.Run("Should save the merged changes", func(t *testing.T) {
+		service, fakeStore := setup(t, `{"id": 3, "uid": "abc", "version": 3, "title": "Dash", "panels": [{"id": 1, "title": "CPU"}, {"id": 2, "title": "Memory usage"}]}`)
+		fakeStore.On("GetDashboardVersionData", mock.Anything, int64(1), int64(3), 2).Return(dashboardJSON(t, base), nil).Once()
+		fakeStore.On("ValidateDashboardBeforeSave", mock.Anything, mock.Anything, false).Return(false, nil).Once()
+
+		var saved dashboards.SaveDashboardCommand
+		fakeStore.On("SaveDashboard", mock.Anything, mock.AnythingOfType("dashboards.SaveDashboardCommand")).Run(func(args mock.Arguments) {
+			saved = args.Get(1).(dashboards.SaveDashboardCommand)
+		}).Return(&dashboards.Dashboard{ID: 3, Data: simplejson.New()}, nil).Once()
+
+		dto := newDTO(t, `{"id": 3, "uid": "abc", "version": 2, "title": "Dash", "panels": [{"id": 1, "title": "CPU usage"}, {"id": 2, "title": "Memory"}]}`)
+		_, err := service.SaveDashboard(context.Background(), dto, true)
+		require.NoError(t, err)
+
+		require.Equal(t, 3, saved.Dashboard.Get("version").MustInt())
+		require.Equal(t, "CPU usage", saved.Dashboard.Get("panels").GetIndex(0).Get("title").MustString())
+		require.Equal(t, "Memory usage", saved.Dashboard.Get("panels").GetIndex(1).Get("title").MustString())
+	})
+
+	t.Run("Should return the conflicting paths", func(t *testing.T) {
+		service, fakeStore := setup(t, `{"id": 3, "uid": "abc", "version": 3, "title": "Dash", "panels": [{"id": 1, "title": "Processor"}, {"id": 2, "title": "Memory"}]}`)
+		fakeStore.On("GetDashboardVersionData", mock.Anything, int64(1), int64(3), 2).Return(dashboardJSON(t, base), nil).Once()
+
+		dto := newDTO(t, `{"id": 3, "uid": "abc", "version": 2, "title": "Dash", "panels": [{"id": 1, "title": "CPU usage"}, {"id": 2, "title": "Memory"}]}`)
+		_, err := service.SaveDashboard(context.Background(), dto, true)
+		require.ErrorIs(t, err, dashboards.ErrDashboardVersionMismatch)
+
+		var conflictErr dashboards.DashboardMergeConflictError
+		require.ErrorAs(t, err, &conflictErr)
+		require.Len(t, conflictErr.Conflicts, 1)
+		require.Equal(t, "panels[id=1].title", conflictErr.Conflicts[0].Path)
+		require.Equal(t, "CPU usage", conflictErr.Conflicts[0].Ours)
+		require.Equal(t, "Processor", conflictErr.Conflicts[0].Theirs)
+	})
+
+	t.Run("Should return version mismatch when the base version is not kept", func(t *testing.T) {
+		service, fakeStore := setup(t, `{"id": 3, "uid": "abc", "version": 3, "title": "Dash"}`)
+		fakeStore.On("GetDashboardVersionData", mock.Anything, int64(1), int64(3), 2).Return(nil, dashver.ErrDashboardVersionNotFound).Once()
+
+		dto := newDTO(t, `{"id": 3, "uid": "abc", "version": 2, "title": "Dash"}`)
+		_, err := service.SaveDashboard(context.Background(), dto, true)
+		require.Equal(t, dashboards.ErrDashboardVersionMismatch, err)
+	})
+}
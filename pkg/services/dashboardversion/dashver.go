@@ -8,4 +8,10 @@ type Service interface {
 	Get(context.Context, *GetDashboardVersionQuery) (*DashboardVersionDTO, error)
 	DeleteExpired(context.Context, *DeleteExpiredVersionsCommand) error
 	List(context.Context, *ListDashboardVersionsQuery) ([]*DashboardVersionDTO, error)
+	// Diff returns the structured diff between two versions of a dashboard.
+	Diff(context.Context, *DiffDashboardVersionsQuery) (*DashboardVersionDiff, error)
+	// Blame returns the last version changing each panel of the latest version of a dashboard.
+	Blame(context.Context, *BlameDashboardVersionsQuery) ([]*PanelBlame, error)
+	// Merge reapplies the changes made by a version to the panels onto the given dashboard.
+	Merge(context.Context, *MergeDashboardVersionCommand) (*MergeDashboardVersionResult, error)
 }
@@ -2,8 +2,11 @@ package dashverimpl
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
 
+	"github.com/grafana/grafana/pkg/components/dashdiffs"
+	"github.com/grafana/grafana/pkg/components/simplejson"
 	"github.com/grafana/grafana/pkg/infra/db"
 	"github.com/grafana/grafana/pkg/infra/log"
 	"github.com/grafana/grafana/pkg/services/dashboards"
@@ -14,6 +17,7 @@ import (
 const (
 	maxVersionsToDeletePerBatch = 100
 	maxVersionDeletionBatches   = 50
+	blameVersionsPerPage        = 100
 )
 
 type Service struct {
@@ -129,6 +133,194 @@ func (s *Service) List(ctx context.Context, query *dashver.ListDashboardVersions
 	return dtos, nil
 }
 
+// Diff computes the structured diff between two versions of a dashboard.
+func (s *Service) Diff(ctx context.Context, query *dashver.DiffDashboardVersionsQuery) (*dashver.DashboardVersionDiff, error) {
+	newVersion, err := s.Get(ctx, &dashver.GetDashboardVersionQuery{
+		DashboardID:  query.DashboardID,
+		DashboardUID: query.DashboardUID,
+		OrgID:        query.OrgID,
+		Version:      query.NewVersion,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	baseVersion := query.BaseVersion
+	if baseVersion == 0 {
+		baseVersion = newVersion.ParentVersion
+	}
+	base, err := s.getVersionData(ctx, newVersion, query.OrgID, baseVersion)
+	if err != nil {
+		return nil, err
+	}
+
+	return &dashver.DashboardVersionDiff{
+		BaseVersion: baseVersion,
+		NewVersion:  newVersion.Version,
+		Changes:     dashdiffs.StructuredDiff(base, newVersion.Data.MustMap()),
+	}, nil
+}
+
+// Blame walks the versions of a dashboard from the newest, and returns the
+// version which last changed each panel of the latest version. The panels
+// which were not changed since the oldest version kept are attributed to
+// that version. The versions are listed page by page, and only the panels of
+// the newer version are kept, so the walk stops once the change of every
+// panel is found.
+func (s *Service) Blame(ctx context.Context, query *dashver.BlameDashboardVersionsQuery) ([]*dashver.PanelBlame, error) {
+	var latest []map[string]any
+	blame := map[int64]*dashver.PanelBlame{}
+	// the panels of the newer version whose change is not found yet
+	pending := map[int64]map[string]any{}
+
+walk:
+	for start := 0; ; start += blameVersionsPerPage {
+		page, err := s.List(ctx, &dashver.ListDashboardVersionsQuery{
+			DashboardID:  query.DashboardID,
+			DashboardUID: query.DashboardUID,
+			OrgID:        query.OrgID,
+			Limit:        blameVersionsPerPage,
+			Start:        start,
+		})
+		if err != nil {
+			if start > 0 && errors.Is(err, dashver.ErrNoVersionsForDashboardID) {
+				break
+			}
+			return nil, err
+		}
+
+		for i, v := range page {
+			panels := dashboardPanels(v.Data)
+			if start == 0 && i == 0 {
+				latest = panels
+				pending = panelsByID(panels)
+				for id := range pending {
+					blame[id] = versionBlame(id, v)
+				}
+			} else {
+				older := panelsByID(panels)
+				for id, panel := range pending {
+					prev, ok := older[id]
+					if !ok || !samePanel(prev, panel) {
+						delete(pending, id)
+						continue
+					}
+					pending[id] = prev
+					blame[id] = versionBlame(id, v)
+				}
+			}
+			if len(pending) == 0 {
+				break walk
+			}
+		}
+		if len(page) < blameVersionsPerPage {
+			break
+		}
+	}
+
+	res := make([]*dashver.PanelBlame, 0, len(latest))
+	for _, panel := range latest {
+		id, ok := panelID(panel)
+		if !ok || blame[id] == nil {
+			continue
+		}
+		b := blame[id]
+		b.Title, _ = panel["title"].(string)
+		res = append(res, b)
+		// panel ids should be unique, only report the first panel with an id
+		delete(blame, id)
+	}
+	return res, nil
+}
+
+// panelsByID returns the panels with an id, by id.
+func panelsByID(panels []map[string]any) map[int64]map[string]any {
+	byID := make(map[int64]map[string]any, len(panels))
+	for _, panel := range panels {
+		if id, ok := panelID(panel); ok {
+			byID[id] = panel
+		}
+	}
+	return byID
+}
+
+func versionBlame(panelID int64, v *dashver.DashboardVersionDTO) *dashver.PanelBlame {
+	return &dashver.PanelBlame{
+		PanelID:   panelID,
+		Version:   v.Version,
+		Created:   v.Created,
+		CreatedBy: v.CreatedBy,
+	}
+}
+
+// Merge reapplies the changes made to the panels from the base version to
+// the given version onto the current dashboard. The other fields of the
+// current dashboard are kept.
+func (s *Service) Merge(ctx context.Context, cmd *dashver.MergeDashboardVersionCommand) (*dashver.MergeDashboardVersionResult, error) {
+	version, err := s.Get(ctx, &dashver.GetDashboardVersionQuery{
+		DashboardID:  cmd.DashboardID,
+		DashboardUID: cmd.DashboardUID,
+		OrgID:        cmd.OrgID,
+		Version:      cmd.Version,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	baseVersion := cmd.BaseVersion
+	if baseVersion == 0 {
+		baseVersion = version.ParentVersion
+	}
+	base, err := s.getVersionData(ctx, version, cmd.OrgID, baseVersion)
+	if err != nil {
+		return nil, err
+	}
+
+	current := cmd.Current.MustMap()
+	merged, conflicts := dashdiffs.Merge(onlyPanels(base), onlyPanels(current), onlyPanels(version.Data.MustMap()))
+
+	data := make(map[string]any, len(current))
+	for k, v := range current {
+		data[k] = v
+	}
+	delete(data, "panels")
+	if panels, ok := merged["panels"]; ok {
+		data["panels"] = panels
+	}
+	return &dashver.MergeDashboardVersionResult{
+		Data:      simplejson.NewFromAny(data),
+		Conflicts: conflicts,
+	}, nil
+}
+
+// onlyPanels returns the panels of a dashboard data, keeping the panels key
+// absent when the dashboard has none, so merges tell missing and empty apart.
+func onlyPanels(data map[string]any) map[string]any {
+	res := map[string]any{}
+	if panels, ok := data["panels"]; ok {
+		res["panels"] = panels
+	}
+	return res
+}
+
+// getVersionData returns the data of another version of the dashboard of the
+// given version, or an empty dashboard for the version 0.
+func (s *Service) getVersionData(ctx context.Context, of *dashver.DashboardVersionDTO, orgID int64, version int) (map[string]any, error) {
+	if version == 0 {
+		return map[string]any{}, nil
+	}
+	res, err := s.Get(ctx, &dashver.GetDashboardVersionQuery{
+		DashboardID:  of.DashboardID,
+		DashboardUID: of.DashboardUID,
+		OrgID:        orgID,
+		Version:      version,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return res.Data.MustMap(), nil
+}
+
 // getDashUIDMaybeEmpty is a helper function which takes a dashboardID and
 // returns the UID. If the dashboard is not found, it will return an empty
 // string.
@@ -163,3 +355,51 @@ func (s *Service) getDashIDMaybeEmpty(ctx context.Context, uid string) (int64, e
 	}
 	return result.ID, nil
 }
+
+// dashboardPanels returns the panels of a dashboard, including the panels of
+// collapsed rows.
+func dashboardPanels(data *simplejson.Json) []map[string]any {
+	panels := []map[string]any{}
+	for _, p := range data.Get("panels").MustArray() {
+		panel, ok := p.(map[string]any)
+		if !ok {
+			continue
+		}
+		panels = append(panels, panel)
+		if nested, ok := panel["panels"].([]any); ok {
+			for _, n := range nested {
+				if np, ok := n.(map[string]any); ok {
+					panels = append(panels, np)
+				}
+			}
+		}
+	}
+	return panels
+}
+
+func panelID(panel map[string]any) (int64, bool) {
+	switch id := panel["id"].(type) {
+	case json.Number:
+		v, err := id.Int64()
+		return v, err == nil
+	case float64:
+		return int64(id), true
+	}
+	return 0, false
+}
+
+// samePanel compares two panels, ignoring their position and the panels of
+// rows, which are compared on their own.
+func samePanel(a, b map[string]any) bool {
+	return dashdiffs.Equal(withoutLayout(a), withoutLayout(b))
+}
+
+func withoutLayout(panel map[string]any) map[string]any {
+	out := make(map[string]any, len(panel))
+	for k, v := range panel {
+		if k != "gridPos" && k != "panels" {
+			out[k] = v
+		}
+	}
+	return out
+}
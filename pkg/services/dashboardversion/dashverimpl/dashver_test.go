@@ -8,6 +8,7 @@ import (
 	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/require"
 
+	"github.com/grafana/grafana/pkg/components/dashdiffs"
 	"github.com/grafana/grafana/pkg/components/simplejson"
 	"github.com/grafana/grafana/pkg/infra/log"
 	"github.com/grafana/grafana/pkg/services/dashboards"
@@ -146,12 +147,157 @@ func TestListDashboardVersions(t *testing.T) {
 	})
 }
 
+func dashboardData(t *testing.T, text string) *simplejson.Json {
+	t.Helper()
+	data, err := simplejson.NewJson([]byte(text))
+	require.NoError(t, err)
+	return data
+}
+
+func TestDiffDashboardVersions(t *testing.T) {
+	dashboardVersionStore := newDashboardVersionStoreFake()
+	dashboardVersionService := Service{store: dashboardVersionStore, dashSvc: dashboards.NewFakeDashboardService(t)}
+	dashboardVersionStore.ExpectedDashboardVersionsByVersion = map[int]*dashver.DashboardVersion{
+		1: {DashboardID: 42, Version: 1, Data: dashboardData(t, `{"title": "A", "panels": [{"id": 1, "title": "CPU"}]}`)},
+		2: {DashboardID: 42, Version: 2, ParentVersion: 1, Data: dashboardData(t, `{"title": "A", "panels": [{"id": 1, "title": "CPU usage"}]}`)},
+		3: {DashboardID: 42, Version: 3, ParentVersion: 2, Data: dashboardData(t, `{"title": "B", "panels": [{"id": 1, "title": "CPU usage"}]}`)},
+	}
+
+	t.Run("compares a version with its parent", func(t *testing.T) {
+		res, err := dashboardVersionService.Diff(context.Background(), &dashver.DiffDashboardVersionsQuery{
+			DashboardID: 42, DashboardUID: "uid", NewVersion: 3,
+		})
+		require.NoError(t, err)
+		require.Equal(t, 2, res.BaseVersion)
+		require.Equal(t, []dashdiffs.Change{{Path: "title", Op: dashdiffs.ChangeReplace, Old: "A", New: "B"}}, res.Changes)
+	})
+
+	t.Run("compares any two versions", func(t *testing.T) {
+		res, err := dashboardVersionService.Diff(context.Background(), &dashver.DiffDashboardVersionsQuery{
+			DashboardID: 42, DashboardUID: "uid", BaseVersion: 1, NewVersion: 3,
+		})
+		require.NoError(t, err)
+		require.Len(t, res.Changes, 2)
+		require.Equal(t, "panels[id=1].title", res.Changes[0].Path)
+	})
+
+	t.Run("returns an error for unknown versions", func(t *testing.T) {
+		_, err := dashboardVersionService.Diff(context.Background(), &dashver.DiffDashboardVersionsQuery{
+			DashboardID: 42, DashboardUID: "uid", BaseVersion: 7, NewVersion: 3,
+		})
+		require.ErrorIs(t, err, dashver.ErrDashboardVersionNotFound)
+	})
+}
+
+func TestBlameDashboardVersions(t *testing.T) {
+	dashboardVersionStore := newDashboardVersionStoreFake()
+	dashboardVersionService := Service{store: dashboardVersionStore, dashSvc: dashboards.NewFakeDashboardService(t)}
+	// listed from the newest
+	dashboardVersionStore.ExpectedListVersions = []*dashver.DashboardVersion{
+		{DashboardID: 42, Version: 4, CreatedBy: 3, Data: dashboardData(t, `{"panels": [
+			{"id": 3, "title": "Disk", "gridPos": {"y": 0}},
+			{"id": 1, "title": "CPU usage", "gridPos": {"y": 8}},
+			{"id": 5, "type": "row", "title": "Row", "collapsed": true, "panels": [{"id": 2, "title": "Memory"}]}
+		]}`)},
+		{DashboardID: 42, Version: 3, CreatedBy: 2, Data: dashboardData(t, `{"panels": [
+			{"id": 1, "title": "CPU usage", "gridPos": {"y": 0}},
+			{"id": 2, "title": "Memory"}
+		]}`)},
+		{DashboardID: 42, Version: 2, CreatedBy: 2, Data: dashboardData(t, `{"panels": [
+			{"id": 1, "title": "CPU usage", "gridPos": {"y": 0}},
+			{"id": 2, "title": "Memory"}
+		]}`)},
+		{DashboardID: 42, Version: 1, CreatedBy: 1, Data: dashboardData(t, `{"panels": [
+			{"id": 1, "title": "CPU", "gridPos": {"y": 0}},
+			{"id": 2, "title": "Memory"}
+		]}`)},
+	}
+
+	res, err := dashboardVersionService.Blame(context.Background(), &dashver.BlameDashboardVersionsQuery{
+		DashboardID: 42, DashboardUID: "uid",
+	})
+	require.NoError(t, err)
+	require.Equal(t, []*dashver.PanelBlame{
+		{PanelID: 3, Title: "Disk", Version: 4, CreatedBy: 3},
+		{PanelID: 1, Title: "CPU usage", Version: 2, CreatedBy: 2},
+		{PanelID: 5, Title: "Row", Version: 4, CreatedBy: 3},
+		{PanelID: 2, Title: "Memory", Version: 1, CreatedBy: 1},
+	}, res)
+
+	t.Run("lists the versions page by page until the change of every panel is found", func(t *testing.T) {
+		versions := func(count int, changed int) []*dashver.DashboardVersion {
+			versions := make([]*dashver.DashboardVersion, 0, count)
+			for v := count; v > 0; v-- {
+				title := "CPU"
+				if v >= changed {
+					title = "CPU usage"
+				}
+				versions = append(versions, &dashver.DashboardVersion{DashboardID: 42, Version: v, Data: dashboardData(t, `{"panels": [{"id": 1, "title": "`+title+`"}]}`)})
+			}
+			return versions
+		}
+
+		dashboardVersionStore := newDashboardVersionStoreFake()
+		dashboardVersionService := Service{store: dashboardVersionStore, dashSvc: dashboards.NewFakeDashboardService(t)}
+		dashboardVersionStore.ExpectedListVersions = versions(2*blameVersionsPerPage+10, 2*blameVersionsPerPage)
+		res, err := dashboardVersionService.Blame(context.Background(), &dashver.BlameDashboardVersionsQuery{DashboardID: 42, DashboardUID: "uid"})
+		require.NoError(t, err)
+		require.Equal(t, []*dashver.PanelBlame{{PanelID: 1, Title: "CPU usage", Version: 2 * blameVersionsPerPage}}, res)
+		require.Len(t, dashboardVersionStore.ListQueries, 1)
+
+		dashboardVersionStore = newDashboardVersionStoreFake()
+		dashboardVersionService = Service{store: dashboardVersionStore, dashSvc: dashboards.NewFakeDashboardService(t)}
+		dashboardVersionStore.ExpectedListVersions = versions(2*blameVersionsPerPage+10, 1)
+		res, err = dashboardVersionService.Blame(context.Background(), &dashver.BlameDashboardVersionsQuery{DashboardID: 42, DashboardUID: "uid"})
+		require.NoError(t, err)
+		require.Equal(t, []*dashver.PanelBlame{{PanelID: 1, Title: "CPU usage", Version: 1}}, res)
+		require.Len(t, dashboardVersionStore.ListQueries, 3)
+	})
+}
+
+func TestMergeDashboardVersion(t *testing.T) {
+	dashboardVersionStore := newDashboardVersionStoreFake()
+	dashboardVersionService := Service{store: dashboardVersionStore, dashSvc: dashboards.NewFakeDashboardService(t)}
+	dashboardVersionStore.ExpectedDashboardVersionsByVersion = map[int]*dashver.DashboardVersion{
+		1: {DashboardID: 42, Version: 1, Data: dashboardData(t, `{"title": "A", "panels": [{"id": 1, "title": "CPU"}, {"id": 2, "title": "Memory"}]}`)},
+		2: {DashboardID: 42, Version: 2, ParentVersion: 1, Data: dashboardData(t, `{"title": "C", "refresh": "1m", "panels": [{"id": 1, "title": "CPU usage"}, {"id": 2, "title": "Memory"}]}`)},
+	}
+
+	t.Run("reapplies the changes of a version to the panels", func(t *testing.T) {
+		res, err := dashboardVersionService.Merge(context.Background(), &dashver.MergeDashboardVersionCommand{
+			DashboardID:  42,
+			DashboardUID: "uid",
+			Version:      2,
+			Current:      dashboardData(t, `{"version": 5, "title": "B", "panels": [{"id": 1, "title": "CPU"}, {"id": 2, "title": "RAM"}]}`),
+		})
+		require.NoError(t, err)
+		require.Empty(t, res.Conflicts)
+		require.Equal(t, dashboardData(t, `{"version": 5, "title": "B", "panels": [{"id": 1, "title": "CPU usage"}, {"id": 2, "title": "RAM"}]}`).MustMap(), res.Data.MustMap())
+	})
+
+	t.Run("returns the conflicts", func(t *testing.T) {
+		res, err := dashboardVersionService.Merge(context.Background(), &dashver.MergeDashboardVersionCommand{
+			DashboardID:  42,
+			DashboardUID: "uid",
+			Version:      2,
+			Current:      dashboardData(t, `{"title": "B", "panels": [{"id": 1, "title": "Processor"}, {"id": 2, "title": "Memory"}]}`),
+		})
+		require.NoError(t, err)
+		require.Len(t, res.Conflicts, 1)
+		require.Equal(t, "panels[id=1].title", res.Conflicts[0].Path)
+	})
+}
+
 type FakeDashboardVersionStore struct {
 	ExpectedDashboardVersion *dashver.DashboardVersion
-	ExptectedDeletedVersions int64
-	ExpectedVersions         []any
-	ExpectedListVersions     []*dashver.DashboardVersion
-	ExpectedError            error
+	// ExpectedDashboardVersionsByVersion is used by Get when set
+	ExpectedDashboardVersionsByVersion map[int]*dashver.DashboardVersion
+	ExptectedDeletedVersions           int64
+	ExpectedVersions                   []any
+	ExpectedListVersions               []*dashver.DashboardVersion
+	ExpectedError                      error
+	// ListQueries are the queries List was called with
+	ListQueries []*dashver.ListDashboardVersionsQuery
 }
 
 func newDashboardVersionStoreFake() *FakeDashboardVersionStore {
@@ -159,6 +305,13 @@ func newDashboardVersionStoreFake() *FakeDashboardVersionStore {
 }
 
 func (f *FakeDashboardVersionStore) Get(ctx context.Context, query *dashver.GetDashboardVersionQuery) (*dashver.DashboardVersion, error) {
+	if f.ExpectedDashboardVersionsByVersion != nil {
+		v, ok := f.ExpectedDashboardVersionsByVersion[query.Version]
+		if !ok {
+			return nil, dashver.ErrDashboardVersionNotFound
+		}
+		return v, nil
+	}
 	return f.ExpectedDashboardVersion, f.ExpectedError
 }
 
@@ -171,5 +324,11 @@ func (f *FakeDashboardVersionStore) DeleteBatch(ctx context.Context, cmd *dashve
 }
 
 func (f *FakeDashboardVersionStore) List(ctx context.Context, query *dashver.ListDashboardVersionsQuery) ([]*dashver.DashboardVersion, error) {
-	return f.ExpectedListVersions, f.ExpectedError
+	f.ListQueries = append(f.ListQueries, query)
+	versions := f.ExpectedListVersions
+	if query.Limit > 0 {
+		start := min(query.Start, len(versions))
+		versions = versions[start:min(start+query.Limit, len(versions))]
+	}
+	return versions, f.ExpectedError
 }
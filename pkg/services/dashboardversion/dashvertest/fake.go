@@ -10,6 +10,9 @@ type FakeDashboardVersionService struct {
 	ExpectedDashboardVersion     *dashver.DashboardVersionDTO
 	ExpectedDashboardVersions    []*dashver.DashboardVersionDTO
 	ExpectedListDashboarVersions []*dashver.DashboardVersionDTO
+	ExpectedDiff                 *dashver.DashboardVersionDiff
+	ExpectedBlame                []*dashver.PanelBlame
+	ExpectedMergeResult          *dashver.MergeDashboardVersionResult
 	counter                      int
 	ExpectedError                error
 }
@@ -33,3 +36,15 @@ func (f *FakeDashboardVersionService) DeleteExpired(ctx context.Context, cmd *da
 func (f *FakeDashboardVersionService) List(ctx context.Context, query *dashver.ListDashboardVersionsQuery) ([]*dashver.DashboardVersionDTO, error) {
 	return f.ExpectedListDashboarVersions, f.ExpectedError
 }
+
+func (f *FakeDashboardVersionService) Diff(ctx context.Context, query *dashver.DiffDashboardVersionsQuery) (*dashver.DashboardVersionDiff, error) {
+	return f.ExpectedDiff, f.ExpectedError
+}
+
+func (f *FakeDashboardVersionService) Blame(ctx context.Context, query *dashver.BlameDashboardVersionsQuery) ([]*dashver.PanelBlame, error) {
+	return f.ExpectedBlame, f.ExpectedError
+}
+
+func (f *FakeDashboardVersionService) Merge(ctx context.Context, cmd *dashver.MergeDashboardVersionCommand) (*dashver.MergeDashboardVersionResult, error) {
+	return f.ExpectedMergeResult, f.ExpectedError
+}
@@ -4,6 +4,7 @@ import (
 	"errors"
 	"time"
 
+	"github.com/grafana/grafana/pkg/components/dashdiffs"
 	"github.com/grafana/grafana/pkg/components/simplejson"
 )
 
@@ -94,3 +95,63 @@ type DashboardVersionMeta struct {
 	Data          *simplejson.Json `json:"data"`
 	CreatedBy     string           `json:"createdBy"`
 }
+
+// DiffDashboardVersionsQuery is used to compare two versions of a dashboard.
+// The parent of NewVersion is used when BaseVersion is not set.
+type DiffDashboardVersionsQuery struct {
+	DashboardID  int64
+	DashboardUID string
+	OrgID        int64
+	BaseVersion  int
+	NewVersion   int
+}
+
+type DashboardVersionDiff struct {
+	BaseVersion int                `json:"baseVersion"`
+	NewVersion  int                `json:"newVersion"`
+	Changes     []dashdiffs.Change `json:"changes"`
+}
+
+type BlameDashboardVersionsQuery struct {
+	DashboardID  int64
+	DashboardUID string
+	OrgID        int64
+}
+
+// PanelBlame is the last version changing a panel. Moving or resizing a
+// panel is not considered a change, since it happens to the other panels
+// every time one is added or resized.
+type PanelBlame struct {
+	PanelID   int64     `json:"panelId"`
+	Title     string    `json:"title"`
+	Version   int       `json:"version"`
+	Created   time.Time `json:"created"`
+	CreatedBy int64     `json:"createdBy"`
+}
+
+// PanelBlameMeta extends the PanelBlame with the name associated with the
+// UserId, overriding the field with the same name from the PanelBlame model.
+type PanelBlameMeta struct {
+	PanelID   int64     `json:"panelId"`
+	Title     string    `json:"title"`
+	Version   int       `json:"version"`
+	Created   time.Time `json:"created"`
+	CreatedBy string    `json:"createdBy"`
+}
+
+// MergeDashboardVersionCommand is used to reapply the changes made to the
+// panels from BaseVersion to Version onto the Current dashboard data. The
+// parent of Version is used when BaseVersion is not set.
+type MergeDashboardVersionCommand struct {
+	DashboardID  int64
+	DashboardUID string
+	OrgID        int64
+	Version      int
+	BaseVersion  int
+	Current      *simplejson.Json
+}
+
+type MergeDashboardVersionResult struct {
+	Data      *simplejson.Json     `json:"data"`
+	Conflicts []dashdiffs.Conflict `json:"conflicts"`
+}
@@ -14,9 +14,11 @@ import (
 	"github.com/grafana/grafana/pkg/infra/tracing"
 	contextmodel "github.com/grafana/grafana/pkg/services/contexthandler/model"
 	"github.com/grafana/grafana/pkg/services/datasources"
+	"github.com/grafana/grafana/pkg/services/datasources/lbac"
 	"github.com/grafana/grafana/pkg/services/featuremgmt"
 	"github.com/grafana/grafana/pkg/services/oauthtoken"
 	"github.com/grafana/grafana/pkg/services/pluginsintegration/pluginstore"
+	"github.com/grafana/grafana/pkg/services/querylimit"
 	"github.com/grafana/grafana/pkg/services/secrets"
 	"github.com/grafana/grafana/pkg/services/validations"
 	"github.com/grafana/grafana/pkg/setting"
@@ -27,7 +29,8 @@ import (
 func ProvideService(dataSourceCache datasources.CacheService, plugReqValidator validations.PluginRequestValidator,
 	pluginStore pluginstore.Store, cfg *setting.Cfg, httpClientProvider httpclient.Provider,
 	oauthTokenService *oauthtoken.Service, dsService datasources.DataSourceService,
-	tracer tracing.Tracer, secretsService secrets.Service, features featuremgmt.FeatureToggles) *DataSourceProxyService {
+	tracer tracing.Tracer, secretsService secrets.Service, features featuremgmt.FeatureToggles,
+	limiter *querylimit.Limiter) *DataSourceProxyService {
 	return &DataSourceProxyService{
 		DataSourceCache:        dataSourceCache,
 		PluginRequestValidator: plugReqValidator,
@@ -39,6 +42,7 @@ func ProvideService(dataSourceCache datasources.CacheService, plugReqValidator v
 		tracer:                 tracer,
 		secretsService:         secretsService,
 		features:               features,
+		limiter:                limiter,
 	}
 }
 
@@ -53,6 +57,7 @@ type DataSourceProxyService struct {
 	tracer                 tracing.Tracer
 	secretsService         secrets.Service
 	features               featuremgmt.FeatureToggles
+	limiter                *querylimit.Limiter
 }
 
 func (p *DataSourceProxyService) ProxyDataSourceRequest(c *contextmodel.ReqContext) {
@@ -114,6 +119,14 @@ func (p *DataSourceProxyService) proxyDatasourceRequest(c *contextmodel.ReqConte
 		return
 	}
 
+	// requests are proxied as they are, so label policies can't be applied to them
+	if p.features.IsEnabled(c.Req.Context(), featuremgmt.FlagTeamHttpHeaders) {
+		if err := lbac.CheckResourceAccess(ds, c.SignedInUser); err != nil {
+			c.WriteErr(err)
+			return
+		}
+	}
+
 	// find plugin
 	plugin, exists := p.pluginStore.Plugin(c.Req.Context(), ds.Type)
 	if !exists {
@@ -123,7 +136,7 @@ func (p *DataSourceProxyService) proxyDatasourceRequest(c *contextmodel.ReqConte
 
 	proxyPath := getProxyPath(c)
 	proxy, err := pluginproxy.NewDataSourceProxy(ds, plugin.Routes, c, proxyPath, p.Cfg, p.HTTPClientProvider,
-		p.OAuthTokenService, p.DataSourcesService, p.tracer, p.features)
+		p.OAuthTokenService, p.DataSourcesService, p.tracer, p.features, p.limiter)
 	if err != nil {
 		var urlValidationError datasource.URLValidationError
 		if errors.As(err, &urlValidationError) {
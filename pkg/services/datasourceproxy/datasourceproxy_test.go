@@ -9,11 +9,14 @@ import (
 	"net/url"
 	"testing"
 
+	"github.com/grafana/grafana/pkg/components/simplejson"
 	"github.com/grafana/grafana/pkg/infra/log"
 	"github.com/grafana/grafana/pkg/plugins"
 	contextmodel "github.com/grafana/grafana/pkg/services/contexthandler/model"
 	"github.com/grafana/grafana/pkg/services/datasources"
+	"github.com/grafana/grafana/pkg/services/featuremgmt"
 	"github.com/grafana/grafana/pkg/services/pluginsintegration/pluginstore"
+	"github.com/grafana/grafana/pkg/services/user"
 	"github.com/grafana/grafana/pkg/web"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -96,6 +99,7 @@ func TestDatasourceProxy_proxyDatasourceRequest(t *testing.T) {
 			p := DataSourceProxyService{
 				PluginRequestValidator: &fakePluginRequestValidator{},
 				pluginStore:            pluginStore,
+				features:               featuremgmt.WithFeatures(),
 			}
 
 			responseRecorder := httptest.NewRecorder()
@@ -129,6 +133,39 @@ func TestDatasourceProxy_proxyDatasourceRequest(t *testing.T) {
 	}
 }
 
+func TestDatasourceProxy_labelPolicies(t *testing.T) {
+	p := DataSourceProxyService{
+		PluginRequestValidator: &fakePluginRequestValidator{},
+		pluginStore:            &pluginstore.FakePluginStore{},
+		features:               featuremgmt.WithFeatures(featuremgmt.FlagTeamHttpHeaders),
+	}
+
+	responseRecorder := httptest.NewRecorder()
+	c := &contextmodel.ReqContext{
+		Context: &web.Context{
+			Req:  &http.Request{URL: &url.URL{}},
+			Resp: web.NewResponseWriter("GET", responseRecorder),
+		},
+		SignedInUser: &user.SignedInUser{Teams: []int64{1}},
+		Logger:       log.NewNopLogger(),
+	}
+
+	p.proxyDatasourceRequest(c, &datasources.DataSource{
+		UID:  "prom",
+		Type: datasources.DS_PROMETHEUS,
+		URL:  "http://localhost:9090",
+		JsonData: simplejson.NewFromAny(map[string]any{
+			"teamHttpHeaders": map[string]any{
+				"headers": map[string]any{
+					"1": []any{map[string]any{"header": "X-Prom-Label-Policy", "value": `1:{namespace="team-a"}`}},
+				},
+			},
+		}),
+	})
+
+	require.Equal(t, http.StatusForbidden, responseRecorder.Result().StatusCode)
+}
+
 type fakePluginRequestValidator struct{}
 
 func (rv *fakePluginRequestValidator) Validate(_ string, _ *http.Request) error {
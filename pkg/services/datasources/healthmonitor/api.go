@@ -0,0 +1,77 @@
+package healthmonitor
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/grafana/grafana/pkg/api/response"
+	"github.com/grafana/grafana/pkg/api/routing"
+	"github.com/grafana/grafana/pkg/services/accesscontrol"
+	contextmodel "github.com/grafana/grafana/pkg/services/contexthandler/model"
+	"github.com/grafana/grafana/pkg/services/datasources"
+	"github.com/grafana/grafana/pkg/web"
+)
+
+const (
+	defaultHistoryLimit = 100
+	maxHistoryLimit     = 1000
+)
+
+func (s *Service) registerRoutes(router routing.RouteRegister, ac accesscontrol.AccessControl) {
+	authorize := accesscontrol.Middleware(ac)
+	uidScope := datasources.ScopeProvider.GetResourceScopeUID(accesscontrol.Parameter(":uid"))
+	router.Group("/api/datasources", func(datasourceRoute routing.RouteRegister) {
+		datasourceRoute.Get("/uid/:uid/health/history", authorize(accesscontrol.EvalPermission(datasources.ActionRead, uidScope)), routing.Wrap(s.getHistory))
+	})
+}
+
+// swagger:route GET /datasources/uid/{uid}/health/history datasources getDataSourceHealthHistory
+//
+// Get the history of the background health checks of a data source.
+//
+// Responses:
+// 200: getDataSourceHealthHistoryResponse
+// 400: badRequestError
+// 401: unauthorisedError
+// 403: forbiddenError
+// 404: notFoundError
+// 500: internalServerError
+func (s *Service) getHistory(c *contextmodel.ReqContext) response.Response {
+	limit := defaultHistoryLimit
+	if raw := c.Query("limit"); raw != "" {
+		var err error
+		limit, err = strconv.Atoi(raw)
+		if err != nil || limit < 1 {
+			return response.Error(http.StatusBadRequest, "limit is invalid", err)
+		}
+		limit = min(limit, maxHistoryLimit)
+	}
+
+	history, err := s.GetHistory(c.Req.Context(), GetHistoryQuery{
+		OrgID:         c.SignedInUser.GetOrgID(),
+		DatasourceUID: web.Params(c.Req)[":uid"],
+		Limit:         limit,
+	})
+	if err != nil {
+		return response.ErrOrFallback(http.StatusInternalServerError, "Failed to get health history", err)
+	}
+	return response.JSON(http.StatusOK, history)
+}
+
+// swagger:parameters getDataSourceHealthHistory
+type GetDataSourceHealthHistoryParams struct {
+	// in:path
+	// required:true
+	UID string `json:"uid"`
+	// Maximum number of checks to return, newest first.
+	// in:query
+	// required:false
+	// default:100
+	Limit int `json:"limit"`
+}
+
+// swagger:response getDataSourceHealthHistoryResponse
+type GetDataSourceHealthHistoryResponse struct {
+	// in: body
+	Body HealthHistory `json:"body"`
+}
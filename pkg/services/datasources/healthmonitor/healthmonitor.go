@@ -0,0 +1,290 @@
+// Package healthmonitor checks the health of all data sources in the background, like the "Save & test"
+// button does, and keeps the history of the checks.
+package healthmonitor
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/prometheus/client_golang/prometheus"
+	"golang.org/x/sync/errgroup"
+
+	"github.com/grafana/grafana/pkg/api/routing"
+	"github.com/grafana/grafana/pkg/infra/db"
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/infra/serverlock"
+	"github.com/grafana/grafana/pkg/plugins"
+	"github.com/grafana/grafana/pkg/services/accesscontrol"
+	"github.com/grafana/grafana/pkg/services/annotations"
+	"github.com/grafana/grafana/pkg/services/auth/identity"
+	"github.com/grafana/grafana/pkg/services/datasources"
+	"github.com/grafana/grafana/pkg/services/oauthtoken"
+	"github.com/grafana/grafana/pkg/services/org"
+	"github.com/grafana/grafana/pkg/services/pluginsintegration/plugincontext"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+// pluginContextProvider is implemented by *plugincontext.Provider.
+type pluginContextProvider interface {
+	GetWithDataSource(ctx context.Context, pluginID string, user identity.Requester, ds *datasources.DataSource) (backend.PluginContext, error)
+}
+
+type Service struct {
+	cfg            setting.DatasourceHealthSettings
+	store          store
+	serverLock     *serverlock.ServerLockService
+	dsService      datasources.DataSourceService
+	pluginClient   plugins.Client
+	pCtxProvider   pluginContextProvider
+	annotationRepo annotations.Repository
+	metrics        *metrics
+	log            log.Logger
+	now            func() time.Time
+
+	mu sync.Mutex
+	// monitored are the labels of the status metric of each data source, to remove the metric of deleted data
+	// sources.
+	monitored map[string][]string
+}
+
+func ProvideService(
+	cfg *setting.Cfg,
+	database db.DB,
+	serverLockService *serverlock.ServerLockService,
+	routeRegister routing.RouteRegister,
+	ac accesscontrol.AccessControl,
+	dsService datasources.DataSourceService,
+	pluginClient plugins.Client,
+	pCtxProvider *plugincontext.Provider,
+	annotationRepo annotations.Repository,
+	reg prometheus.Registerer,
+) *Service {
+	s := &Service{
+		cfg:            cfg.DatasourceHealth,
+		store:          &sqlStore{db: database},
+		serverLock:     serverLockService,
+		dsService:      dsService,
+		pluginClient:   pluginClient,
+		pCtxProvider:   pCtxProvider,
+		annotationRepo: annotationRepo,
+		metrics:        newMetrics(reg),
+		log:            log.New("datasource.health"),
+		now:            time.Now,
+		monitored:      map[string][]string{},
+	}
+	s.registerRoutes(routeRegister, ac)
+	return s
+}
+
+func (s *Service) IsDisabled() bool {
+	return !s.cfg.Enabled
+}
+
+// Run checks all data sources every interval, and removes the checks older than the retention. Runs are
+// locked, so only one instance of a high availability setup checks the data sources in each interval.
+// The status metric is set by the instance running the checks, other instances keep the status of the
+// last checks they ran.
+func (s *Service) Run(ctx context.Context) error {
+	ticker := time.NewTicker(s.cfg.Interval)
+	defer ticker.Stop()
+
+	for {
+		if err := s.serverLock.LockAndExecute(ctx, "datasource health checks", s.cfg.Interval, s.run); err != nil {
+			s.log.Warn("Failed to lock health checks", "error", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
+
+func (s *Service) run(ctx context.Context) {
+	s.checkAll(ctx)
+	if deleted, err := s.store.DeleteOlderThan(ctx, s.now().Add(-s.cfg.HistoryRetention)); err != nil {
+		s.log.Warn("Failed to delete old health checks", "error", err)
+	} else if deleted > 0 {
+		s.log.Debug("Deleted old health checks", "count", deleted)
+	}
+}
+
+func (s *Service) checkAll(ctx context.Context) {
+	dataSources, err := s.dsService.GetAllDataSources(ctx, &datasources.GetAllDataSourcesQuery{})
+	if err != nil {
+		s.log.Error("Failed to list data sources", "error", err)
+		return
+	}
+
+	g, ctx := errgroup.WithContext(ctx)
+	g.SetLimit(s.cfg.MaxConcurrent)
+	seen := make(map[string]bool, len(dataSources))
+	for _, ds := range dataSources {
+		seen[metricKey(ds)] = true
+		g.Go(func() error {
+			s.checkDataSource(ctx, ds)
+			return nil
+		})
+	}
+	_ = g.Wait()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for key, labels := range s.monitored {
+		if !seen[key] {
+			s.metrics.status.DeleteLabelValues(labels...)
+			delete(s.monitored, key)
+		}
+	}
+}
+
+// checkDataSource checks a data source and records the result.
+func (s *Service) checkDataSource(ctx context.Context, ds *datasources.DataSource) {
+	ctx, cancel := context.WithTimeout(ctx, s.cfg.Timeout)
+	defer cancel()
+
+	check := s.check(ctx, ds)
+	if check == nil {
+		return
+	}
+	s.metrics.checks.WithLabelValues(ds.Type, string(check.Status)).Inc()
+	s.metrics.duration.WithLabelValues(ds.Type).Observe(float64(check.LatencyMs) / 1000)
+	labels := []string{strconv.FormatInt(ds.OrgID, 10), ds.UID, ds.Type}
+	healthy := 0.0
+	if check.Status == StatusOK {
+		healthy = 1
+	}
+	s.metrics.status.WithLabelValues(labels...).Set(healthy)
+	s.mu.Lock()
+	s.monitored[metricKey(ds)] = labels
+	s.mu.Unlock()
+
+	previous, err := s.store.List(ctx, GetHistoryQuery{OrgID: ds.OrgID, DatasourceUID: ds.UID, Limit: 1})
+	if err != nil {
+		s.log.Warn("Failed to get last health check", "datasource", ds.UID, "error", err)
+		return
+	}
+	if err := s.store.Insert(ctx, check); err != nil {
+		s.log.Warn("Failed to save health check", "datasource", ds.UID, "error", err)
+		return
+	}
+
+	var last *HealthCheck
+	if len(previous) > 0 {
+		last = previous[0]
+	}
+	if s.cfg.Annotations {
+		s.annotateChange(ctx, ds, last, check)
+	}
+}
+
+// check runs the health check of a data source. It returns nil for data sources without health check, like the
+// ones of frontend plugins, and for data sources forwarding the OAuth identity of the user, which can't be
+// checked without a user.
+func (s *Service) check(ctx context.Context, ds *datasources.DataSource) *HealthCheck {
+	if oauthtoken.IsOAuthPassThruEnabled(ds) {
+		return nil
+	}
+
+	start := s.now()
+	result := &HealthCheck{OrgID: ds.OrgID, DatasourceUID: ds.UID, Checked: start.UnixMilli()}
+
+	user := accesscontrol.BackgroundUser("datasource_health", ds.OrgID, org.RoleAdmin, []accesscontrol.Permission{
+		{Action: datasources.ActionQuery, Scope: datasources.ScopeProvider.GetResourceScopeUID(ds.UID)},
+	})
+	pCtx, err := s.pCtxProvider.GetWithDataSource(ctx, ds.Type, user, ds)
+	if err != nil {
+		if errors.Is(err, plugins.ErrPluginNotRegistered) {
+			return nil
+		}
+		result.Status, result.Message = StatusError, err.Error()
+		return result
+	}
+
+	resp, err := s.pluginClient.CheckHealth(ctx, &backend.CheckHealthRequest{
+		PluginContext: pCtx,
+		Headers:       map[string]string{},
+	})
+	result.LatencyMs = s.now().Sub(start).Milliseconds()
+	switch {
+	case errors.Is(err, plugins.ErrMethodNotImplemented), errors.Is(err, plugins.ErrPluginNotRegistered):
+		return nil
+	case err != nil:
+		result.Status, result.Message = StatusError, err.Error()
+	case resp.Status == backend.HealthStatusOk:
+		result.Status, result.Message = StatusOK, resp.Message
+	case resp.Status == backend.HealthStatusError:
+		result.Status, result.Message = StatusError, resp.Message
+	default:
+		result.Status, result.Message = StatusUnknown, resp.Message
+	}
+	return result
+}
+
+// annotateChange creates an org annotation when a data source becomes unhealthy or recovers.
+func (s *Service) annotateChange(ctx context.Context, ds *datasources.DataSource, last *HealthCheck, check *HealthCheck) {
+	var text string
+	switch {
+	case check.Status == StatusError && (last == nil || last.Status != StatusError):
+		text = fmt.Sprintf("Data source %s is unhealthy: %s", ds.Name, check.Message)
+	case check.Status == StatusOK && last != nil && last.Status == StatusError:
+		text = fmt.Sprintf("Data source %s is healthy again", ds.Name)
+	default:
+		return
+	}
+
+	prevState := string(StatusUnknown)
+	if last != nil {
+		prevState = string(last.Status)
+	}
+	err := s.annotationRepo.Save(ctx, &annotations.Item{
+		OrgID:     ds.OrgID,
+		Epoch:     check.Checked,
+		EpochEnd:  check.Checked,
+		Text:      text,
+		PrevState: prevState,
+		NewState:  string(check.Status),
+		Tags:      []string{"datasource-health", "datasource:" + ds.UID},
+	})
+	if err != nil {
+		s.log.Warn("Failed to save health annotation", "datasource", ds.UID, "error", err)
+	}
+}
+
+// GetHistory returns the last health checks of a data source.
+func (s *Service) GetHistory(ctx context.Context, query GetHistoryQuery) (*HealthHistory, error) {
+	if _, err := s.dsService.GetDataSource(ctx, &datasources.GetDataSourceQuery{OrgID: query.OrgID, UID: query.DatasourceUID}); err != nil {
+		if errors.Is(err, datasources.ErrDataSourceNotFound) {
+			return nil, ErrDataSourceNotFound.Errorf("data source %s not found", query.DatasourceUID)
+		}
+		return nil, err
+	}
+
+	checks, err := s.store.List(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+
+	history := &HealthHistory{UID: query.DatasourceUID, Status: StatusUnknown, Checks: checks}
+	if len(checks) > 0 {
+		history.Status = checks[0].Status
+		ok := 0
+		for _, c := range checks {
+			if c.Status == StatusOK {
+				ok++
+			}
+		}
+		history.Uptime = float64(ok) / float64(len(checks))
+	}
+	return history, nil
+}
+
+func metricKey(ds *datasources.DataSource) string {
+	return fmt.Sprintf("%d/%s", ds.OrgID, ds.UID)
+}
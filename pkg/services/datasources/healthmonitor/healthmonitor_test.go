@@ -0,0 +1,220 @@
+package healthmonitor
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/components/simplejson"
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/plugins"
+	"github.com/grafana/grafana/pkg/services/annotations"
+	"github.com/grafana/grafana/pkg/services/annotations/annotationstest"
+	"github.com/grafana/grafana/pkg/services/auth/identity"
+	"github.com/grafana/grafana/pkg/services/datasources"
+	fakeDatasources "github.com/grafana/grafana/pkg/services/datasources/fakes"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+func TestCheckAll(t *testing.T) {
+	prom := &datasources.DataSource{OrgID: 1, UID: "prom", Name: "Prometheus", Type: "prometheus"}
+	loki := &datasources.DataSource{OrgID: 1, UID: "loki", Name: "Loki", Type: "loki"}
+	frontend := &datasources.DataSource{OrgID: 2, UID: "frontend", Name: "Frontend", Type: "frontend"}
+
+	results := map[string]*backend.CheckHealthResult{
+		"prom": {Status: backend.HealthStatusOk, Message: "Successfully queried the Prometheus API."},
+		"loki": {Status: backend.HealthStatusError, Message: "connection refused"},
+	}
+	var mu sync.Mutex
+	client := &fakePluginClient{checkHealth: func(ctx context.Context, req *backend.CheckHealthRequest) (*backend.CheckHealthResult, error) {
+		mu.Lock()
+		defer mu.Unlock()
+		uid := req.PluginContext.DataSourceInstanceSettings.UID
+		if uid == "frontend" {
+			return nil, plugins.ErrMethodNotImplemented
+		}
+		return results[uid], nil
+	}}
+
+	s, store, annotationsRepo := newTestService(client, prom, loki, frontend)
+	s.cfg.Annotations = true
+
+	s.checkAll(context.Background())
+
+	require.Len(t, store.checks, 2)
+	history, err := s.GetHistory(context.Background(), GetHistoryQuery{OrgID: 1, DatasourceUID: "loki", Limit: 10})
+	require.NoError(t, err)
+	require.Equal(t, StatusError, history.Status)
+	require.Equal(t, "connection refused", history.Checks[0].Message)
+
+	require.Equal(t, 1.0, testutil.ToFloat64(s.metrics.status.WithLabelValues("1", "prom", "prometheus")))
+	require.Equal(t, 0.0, testutil.ToFloat64(s.metrics.status.WithLabelValues("1", "loki", "loki")))
+	require.Equal(t, 1.0, testutil.ToFloat64(s.metrics.checks.WithLabelValues("loki", "error")))
+
+	t.Run("annotates data sources becoming unhealthy once", func(t *testing.T) {
+		require.Equal(t, 1, annotationsRepo.Len())
+		for _, item := range annotationsRepo.Items() {
+			require.Equal(t, "Data source Loki is unhealthy: connection refused", item.Text)
+			require.Equal(t, []string{"datasource-health", "datasource:loki"}, item.Tags)
+		}
+
+		s.checkAll(context.Background())
+		require.Equal(t, 1, annotationsRepo.Len())
+	})
+
+	t.Run("annotates recoveries", func(t *testing.T) {
+		mu.Lock()
+		results["loki"] = &backend.CheckHealthResult{Status: backend.HealthStatusOk}
+		mu.Unlock()
+
+		s.checkAll(context.Background())
+		require.Equal(t, 2, annotationsRepo.Len())
+
+		history, err := s.GetHistory(context.Background(), GetHistoryQuery{OrgID: 1, DatasourceUID: "loki", Limit: 10})
+		require.NoError(t, err)
+		require.Equal(t, StatusOK, history.Status)
+		require.Len(t, history.Checks, 3)
+		require.InDelta(t, 1.0/3, history.Uptime, 0.001)
+	})
+
+	t.Run("removes the metrics of deleted data sources", func(t *testing.T) {
+		s.dsService.(*fakeDatasources.FakeDataSourceService).DataSources = []*datasources.DataSource{prom}
+		s.checkAll(context.Background())
+		require.Equal(t, 1, testutil.CollectAndCount(s.metrics.status))
+	})
+}
+
+func TestCheck(t *testing.T) {
+	ds := &datasources.DataSource{OrgID: 1, UID: "ds", Type: "prometheus"}
+
+	t.Run("plugin errors fail the check", func(t *testing.T) {
+		s, _, _ := newTestService(&fakePluginClient{checkHealth: func(ctx context.Context, req *backend.CheckHealthRequest) (*backend.CheckHealthResult, error) {
+			return nil, errors.New("plugin crashed")
+		}}, ds)
+
+		check := s.check(context.Background(), ds)
+		require.Equal(t, StatusError, check.Status)
+		require.Equal(t, "plugin crashed", check.Message)
+	})
+
+	t.Run("records the latency", func(t *testing.T) {
+		s, _, _ := newTestService(&fakePluginClient{checkHealth: func(ctx context.Context, req *backend.CheckHealthRequest) (*backend.CheckHealthResult, error) {
+			return &backend.CheckHealthResult{Status: backend.HealthStatusUnknown}, nil
+		}}, ds)
+		now := time.Now()
+		s.now = func() time.Time {
+			now = now.Add(250 * time.Millisecond)
+			return now
+		}
+
+		check := s.check(context.Background(), ds)
+		require.Equal(t, StatusUnknown, check.Status)
+		require.Equal(t, int64(250), check.LatencyMs)
+	})
+
+	t.Run("data sources forwarding the OAuth identity are not checked", func(t *testing.T) {
+		oauth := &datasources.DataSource{OrgID: 1, UID: "oauth", Type: "prometheus", JsonData: simplejson.NewFromAny(map[string]any{"oauthPassThru": true})}
+		s, _, _ := newTestService(&fakePluginClient{checkHealth: func(ctx context.Context, req *backend.CheckHealthRequest) (*backend.CheckHealthResult, error) {
+			t.Fatal("health check of a data source forwarding the OAuth identity")
+			return nil, nil
+		}}, oauth)
+
+		require.Nil(t, s.check(context.Background(), oauth))
+	})
+
+	t.Run("unknown data sources have no history", func(t *testing.T) {
+		s, _, _ := newTestService(&fakePluginClient{}, ds)
+		_, err := s.GetHistory(context.Background(), GetHistoryQuery{OrgID: 1, DatasourceUID: "other", Limit: 10})
+		require.True(t, errors.Is(err, ErrDataSourceNotFound))
+	})
+}
+
+func newTestService(client *fakePluginClient, dataSources ...*datasources.DataSource) (*Service, *fakeStore, annotationsRepo) {
+	store := &fakeStore{}
+	repo := annotationstest.NewFakeAnnotationsRepo()
+	return &Service{
+		cfg: setting.DatasourceHealthSettings{
+			Enabled:       true,
+			Interval:      time.Minute,
+			Timeout:       time.Second,
+			MaxConcurrent: 2,
+		},
+		store:          store,
+		dsService:      &fakeDatasources.FakeDataSourceService{DataSources: dataSources},
+		pluginClient:   client,
+		pCtxProvider:   fakePluginContextProvider{},
+		annotationRepo: repo,
+		metrics:        newMetrics(prometheus.NewRegistry()),
+		log:            log.NewNopLogger(),
+		now:            time.Now,
+		monitored:      map[string][]string{},
+	}, store, repo
+}
+
+type annotationsRepo interface {
+	Len() int
+	Items() map[int64]annotations.Item
+}
+
+type fakePluginClient struct {
+	plugins.Client
+	checkHealth backend.CheckHealthHandlerFunc
+}
+
+func (c *fakePluginClient) CheckHealth(ctx context.Context, req *backend.CheckHealthRequest) (*backend.CheckHealthResult, error) {
+	if c.checkHealth == nil {
+		return nil, plugins.ErrMethodNotImplemented
+	}
+	return c.checkHealth(ctx, req)
+}
+
+type fakePluginContextProvider struct{}
+
+func (fakePluginContextProvider) GetWithDataSource(ctx context.Context, pluginID string, user identity.Requester, ds *datasources.DataSource) (backend.PluginContext, error) {
+	return backend.PluginContext{
+		OrgID:                      ds.OrgID,
+		PluginID:                   pluginID,
+		DataSourceInstanceSettings: &backend.DataSourceInstanceSettings{UID: ds.UID, Type: ds.Type},
+	}, nil
+}
+
+type fakeStore struct {
+	mu     sync.Mutex
+	checks []*HealthCheck
+}
+
+func (s *fakeStore) Insert(ctx context.Context, check *HealthCheck) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	check.ID = int64(len(s.checks) + 1)
+	s.checks = append(s.checks, check)
+	return nil
+}
+
+func (s *fakeStore) List(ctx context.Context, query GetHistoryQuery) ([]*HealthCheck, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var checks []*HealthCheck
+	for _, c := range s.checks {
+		if c.OrgID == query.OrgID && c.DatasourceUID == query.DatasourceUID {
+			checks = append(checks, c)
+		}
+	}
+	sort.Slice(checks, func(i, j int) bool { return checks[i].ID > checks[j].ID })
+	if len(checks) > query.Limit {
+		checks = checks[:query.Limit]
+	}
+	return checks, nil
+}
+
+func (s *fakeStore) DeleteOlderThan(ctx context.Context, olderThan time.Time) (int64, error) {
+	return 0, nil
+}
@@ -0,0 +1,36 @@
+package healthmonitor
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+type metrics struct {
+	status   *prometheus.GaugeVec
+	duration *prometheus.HistogramVec
+	checks   *prometheus.CounterVec
+}
+
+func newMetrics(reg prometheus.Registerer) *metrics {
+	return &metrics{
+		status: promauto.With(reg).NewGaugeVec(prometheus.GaugeOpts{
+			Namespace: "grafana",
+			Subsystem: "datasource_health",
+			Name:      "status",
+			Help:      "Status of the last health check of a data source run by this instance, 1 if healthy and 0 otherwise.",
+		}, []string{"org_id", "datasource_uid", "datasource_type"}),
+		duration: promauto.With(reg).NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: "grafana",
+			Subsystem: "datasource_health",
+			Name:      "check_duration_seconds",
+			Help:      "Duration of data source health checks.",
+			Buckets:   prometheus.ExponentialBuckets(0.01, 4, 6),
+		}, []string{"datasource_type"}),
+		checks: promauto.With(reg).NewCounterVec(prometheus.CounterOpts{
+			Namespace: "grafana",
+			Subsystem: "datasource_health",
+			Name:      "checks_total",
+			Help:      "Number of data source health checks by status.",
+		}, []string{"datasource_type", "status"}),
+	}
+}
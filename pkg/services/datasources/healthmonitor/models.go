@@ -0,0 +1,48 @@
+package healthmonitor
+
+import (
+	"github.com/grafana/grafana/pkg/util/errutil"
+)
+
+var ErrDataSourceNotFound = errutil.NotFound("datasourceHealth.notFound", errutil.WithPublicMessage("Data source not found"))
+
+type Status string
+
+const (
+	StatusOK      Status = "ok"
+	StatusError   Status = "error"
+	StatusUnknown Status = "unknown"
+)
+
+// HealthCheck is the result of a health check of a data source.
+type HealthCheck struct {
+	ID            int64  `json:"-" xorm:"pk autoincr 'id'"`
+	OrgID         int64  `json:"-" xorm:"org_id"`
+	DatasourceUID string `json:"-" xorm:"datasource_uid"`
+	Status        Status `json:"status"`
+	Message       string `json:"message,omitempty"`
+	LatencyMs     int64  `json:"latencyMs" xorm:"latency_ms"`
+	// Checked is the time of the check, in epoch milliseconds.
+	Checked int64 `json:"checked"`
+}
+
+func (HealthCheck) TableName() string {
+	return "data_source_health"
+}
+
+// HealthHistory is the response of the health history API.
+type HealthHistory struct {
+	UID string `json:"uid"`
+	// Status is the status of the last check, unknown if the data source wasn't checked yet.
+	Status Status `json:"status"`
+	// Uptime is the share of checks in the history that succeeded.
+	Uptime float64 `json:"uptime"`
+	// Checks are the checks of the data source, newest first.
+	Checks []*HealthCheck `json:"checks"`
+}
+
+type GetHistoryQuery struct {
+	OrgID         int64
+	DatasourceUID string
+	Limit         int
+}
@@ -0,0 +1,50 @@
+package healthmonitor
+
+import (
+	"context"
+	"time"
+
+	"github.com/grafana/grafana/pkg/infra/db"
+)
+
+type store interface {
+	Insert(ctx context.Context, check *HealthCheck) error
+	// List returns the last checks of a data source, newest first.
+	List(ctx context.Context, query GetHistoryQuery) ([]*HealthCheck, error)
+	DeleteOlderThan(ctx context.Context, olderThan time.Time) (int64, error)
+}
+
+type sqlStore struct {
+	db db.DB
+}
+
+func (s *sqlStore) Insert(ctx context.Context, check *HealthCheck) error {
+	return s.db.WithDbSession(ctx, func(sess *db.Session) error {
+		_, err := sess.Insert(check)
+		return err
+	})
+}
+
+func (s *sqlStore) List(ctx context.Context, query GetHistoryQuery) ([]*HealthCheck, error) {
+	checks := make([]*HealthCheck, 0)
+	err := s.db.WithDbSession(ctx, func(sess *db.Session) error {
+		return sess.Where("org_id = ? AND datasource_uid = ?", query.OrgID, query.DatasourceUID).
+			Desc("checked").Desc("id").
+			Limit(query.Limit).
+			Find(&checks)
+	})
+	return checks, err
+}
+
+func (s *sqlStore) DeleteOlderThan(ctx context.Context, olderThan time.Time) (int64, error) {
+	var deleted int64
+	err := s.db.WithTransactionalDbSession(ctx, func(sess *db.Session) error {
+		res, err := sess.Exec("DELETE FROM data_source_health WHERE checked < ?", olderThan.UnixMilli())
+		if err != nil {
+			return err
+		}
+		deleted, err = res.RowsAffected()
+		return err
+	})
+	return deleted, err
+}
@@ -0,0 +1,52 @@
+package healthmonitor
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/infra/db"
+	"github.com/grafana/grafana/pkg/tests/testsuite"
+)
+
+func TestMain(m *testing.M) {
+	testsuite.Run(m)
+}
+
+func TestIntegrationStore(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test")
+	}
+
+	store := &sqlStore{db: db.InitTestDB(t)}
+	ctx := context.Background()
+	now := time.Now()
+
+	for i, status := range []Status{StatusOK, StatusError, StatusOK} {
+		err := store.Insert(ctx, &HealthCheck{
+			OrgID:         1,
+			DatasourceUID: "ds",
+			Status:        status,
+			LatencyMs:     int64(i),
+			Checked:       now.Add(time.Duration(i-2) * time.Hour).UnixMilli(),
+		})
+		require.NoError(t, err)
+	}
+	require.NoError(t, store.Insert(ctx, &HealthCheck{OrgID: 2, DatasourceUID: "ds", Status: StatusOK, Checked: now.UnixMilli()}))
+
+	checks, err := store.List(ctx, GetHistoryQuery{OrgID: 1, DatasourceUID: "ds", Limit: 2})
+	require.NoError(t, err)
+	require.Len(t, checks, 2)
+	require.Equal(t, int64(2), checks[0].LatencyMs)
+	require.Equal(t, StatusError, checks[1].Status)
+
+	deleted, err := store.DeleteOlderThan(ctx, now.Add(-90*time.Minute))
+	require.NoError(t, err)
+	require.Equal(t, int64(1), deleted)
+
+	checks, err = store.List(ctx, GetHistoryQuery{OrgID: 1, DatasourceUID: "ds", Limit: 10})
+	require.NoError(t, err)
+	require.Len(t, checks, 2)
+}
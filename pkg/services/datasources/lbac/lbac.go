@@ -0,0 +1,300 @@
+// Package lbac enforces label based access control on Prometheus and Loki data sources. Admins attach label
+// policies to teams in the teamHttpHeaders setting of a data source, and the matchers of the policies of the
+// teams of a user are injected into every selector of the user's queries.
+package lbac
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/prometheus/prometheus/model/labels"
+	"github.com/prometheus/prometheus/promql/parser"
+
+	"github.com/grafana/grafana/pkg/components/simplejson"
+	"github.com/grafana/grafana/pkg/services/auth/identity"
+	"github.com/grafana/grafana/pkg/services/datasources"
+	"github.com/grafana/grafana/pkg/util/errutil"
+)
+
+// PolicyHeader is the team header holding a label policy, its value is `<tenant>:<selector>`.
+const PolicyHeader = "X-Prom-Label-Policy"
+
+var (
+	ErrNoPolicy            = errutil.Forbidden("lbac.noPolicy", errutil.WithPublicMessage("Access to this data source is restricted to teams with a label policy"))
+	ErrConflictingPolicies = errutil.BadRequest("lbac.conflictingPolicies", errutil.WithPublicMessage("The label policies of your teams can't be combined, ask an admin to align them"))
+	ErrInvalidQuery        = errutil.BadRequest("lbac.invalidQuery", errutil.WithPublicMessage("The query could not be checked against the label policies of the data source"))
+	ErrPolicyNotApplied    = errutil.BadRequest("lbac.policyNotApplied", errutil.WithPublicMessage("The query must include the label matchers of the label policies of your teams"))
+	ErrResourcesRestricted = errutil.Forbidden("lbac.resourcesRestricted", errutil.WithPublicMessage("Only queries are allowed for users with label policies on this data source"))
+)
+
+type language int
+
+const (
+	promQL language = iota + 1
+	logQL
+)
+
+// languages are the query languages of the data sources label policies apply to, by plugin id.
+var languages = map[string]language{
+	datasources.DS_PROMETHEUS:             promQL,
+	"grafana-amazonprometheus-datasource": promQL,
+	"grafana-azureprometheus-datasource":  promQL,
+	datasources.DS_LOKI:                   logQL,
+}
+
+// Policies are the label matchers to inject into the queries of a user.
+type Policies struct {
+	lang     language
+	matchers []*labels.Matcher
+}
+
+// PoliciesFor returns the policies that apply to the queries of a user, or nil if the queries aren't
+// restricted. A user in several teams with policies may see the series of any of them. ErrNoPolicy is returned
+// for users without policy when the data source restricts access to teams with policies.
+func PoliciesFor(ds *datasources.DataSource, user identity.Requester) (*Policies, error) {
+	lang, ok := languages[ds.Type]
+	if !ok || user == nil {
+		return nil, nil
+	}
+
+	teamHeaders, err := ds.TeamHTTPHeaders()
+	if err != nil || teamHeaders == nil {
+		return nil, err
+	}
+
+	var selectors [][]*labels.Matcher
+	for _, teamID := range user.GetTeams() {
+		for _, header := range teamHeaders.Headers[strconv.FormatInt(teamID, 10)] {
+			if http.CanonicalHeaderKey(header.Header) != PolicyHeader {
+				continue
+			}
+			matchers, err := parsePolicy(header.Value)
+			if err != nil {
+				return nil, err
+			}
+			selectors = append(selectors, matchers)
+		}
+	}
+
+	if len(selectors) == 0 {
+		if teamHeaders.RestrictAccess {
+			return nil, ErrNoPolicy.Errorf("user has no label policy for data source %s", ds.UID)
+		}
+		return nil, nil
+	}
+
+	matchers, err := union(selectors)
+	if err != nil {
+		return nil, err
+	}
+	return &Policies{lang: lang, matchers: matchers}, nil
+}
+
+// parsePolicy parses a policy like `1234:{namespace="team-a"}`, the tenant is left to the data source.
+func parsePolicy(value string) ([]*labels.Matcher, error) {
+	_, selector, ok := strings.Cut(strings.TrimSpace(value), ":")
+	if !ok {
+		return nil, fmt.Errorf("invalid label policy %q", value)
+	}
+	return parser.ParseMetricSelector(selector)
+}
+
+// union combines the selectors of several policies into one. Selectors match series by all their matchers, so
+// policies can only be combined when they restrict the same label, in which case the values are joined into
+// one regular expression.
+func union(selectors [][]*labels.Matcher) ([]*labels.Matcher, error) {
+	var distinct [][]*labels.Matcher
+	for _, s := range selectors {
+		if !slices.ContainsFunc(distinct, func(d []*labels.Matcher) bool { return sameMatchers(d, s) }) {
+			distinct = append(distinct, s)
+		}
+	}
+	if len(distinct) == 1 {
+		return distinct[0], nil
+	}
+
+	name := distinct[0][0].Name
+	alternatives := make([]string, 0, len(distinct))
+	for _, s := range distinct {
+		if len(s) != 1 || s[0].Name != name {
+			return nil, ErrConflictingPolicies.Errorf("policies restrict different labels")
+		}
+		switch s[0].Type {
+		case labels.MatchEqual:
+			alternatives = append(alternatives, regexp.QuoteMeta(s[0].Value))
+		case labels.MatchRegexp:
+			alternatives = append(alternatives, "(?:"+s[0].Value+")")
+		default:
+			return nil, ErrConflictingPolicies.Errorf("policies with negative matchers can't be combined")
+		}
+	}
+
+	m, err := labels.NewMatcher(labels.MatchRegexp, name, strings.Join(alternatives, "|"))
+	if err != nil {
+		return nil, err
+	}
+	return []*labels.Matcher{m}, nil
+}
+
+func sameMatchers(a, b []*labels.Matcher) bool {
+	return len(a) == len(b) && !slices.ContainsFunc(a, func(m *labels.Matcher) bool { return !containsMatcher(b, m) })
+}
+
+func containsMatcher(matchers []*labels.Matcher, m *labels.Matcher) bool {
+	return slices.ContainsFunc(matchers, func(o *labels.Matcher) bool {
+		return o.Name == m.Name && o.Type == m.Type && o.Value == m.Value
+	})
+}
+
+// Rewrite injects the matchers of the policies into every selector of a query.
+func (p *Policies) Rewrite(query string) (string, error) {
+	if p == nil || strings.TrimSpace(query) == "" {
+		return query, nil
+	}
+
+	var rewritten string
+	var err error
+	switch p.lang {
+	case promQL:
+		rewritten, err = rewritePromQL(query, p.matchers)
+	case logQL:
+		rewritten, err = rewriteLogQL(query, p.matchers)
+	default:
+		return query, nil
+	}
+	if err != nil {
+		return "", ErrInvalidQuery.Errorf("failed to inject label policies: %w", err)
+	}
+	return rewritten, nil
+}
+
+// RewriteModel injects the matchers of the policies into the expression of a query model, as sent by
+// dashboards, Explore and alert rules.
+func (p *Policies) RewriteModel(model json.RawMessage) (json.RawMessage, error) {
+	if p == nil {
+		return model, nil
+	}
+
+	query, err := simplejson.NewJson(model)
+	if err != nil {
+		return nil, ErrInvalidQuery.Errorf("failed to read query model: %w", err)
+	}
+	expr, ok := query.CheckGet("expr")
+	if !ok {
+		return model, nil
+	}
+	rewritten, err := p.Rewrite(expr.MustString())
+	if err != nil {
+		return nil, err
+	}
+	query.Set("expr", rewritten)
+	return query.MarshalJSON()
+}
+
+// CheckModel returns ErrPolicyNotApplied if the expression of a query model doesn't have the matchers of the
+// policies of a user yet. Scheduled evaluations of alert rules don't run as a user, so queries of rules are
+// checked when they are saved instead of being rewritten when they run.
+func CheckModel(ds *datasources.DataSource, user identity.Requester, model json.RawMessage) error {
+	policies, err := PoliciesFor(ds, user)
+	if err != nil || policies == nil {
+		return err
+	}
+
+	query, err := simplejson.NewJson(model)
+	if err != nil {
+		return ErrInvalidQuery.Errorf("failed to read query model: %w", err)
+	}
+	expr := query.Get("expr").MustString()
+	rewritten, err := policies.Rewrite(expr)
+	if err != nil {
+		return err
+	}
+	if rewritten != expr {
+		return ErrPolicyNotApplied.Errorf("query of data source %s must be %s", ds.UID, rewritten)
+	}
+	return nil
+}
+
+// CheckResourceAccess returns an error for users with label policies on the data source. Requests other than
+// queries, like resource calls, streams and the data source proxy, can't be rewritten, so they're rejected.
+func CheckResourceAccess(ds *datasources.DataSource, user identity.Requester) error {
+	policies, err := PoliciesFor(ds, user)
+	if err != nil {
+		return err
+	}
+	if policies != nil {
+		return ErrResourcesRestricted.Errorf("user has label policies for data source %s", ds.UID)
+	}
+	return nil
+}
+
+// formatMatchers formats matchers for a selector, like `namespace="team-a", env!="dev"`.
+func formatMatchers(matchers []*labels.Matcher) string {
+	parts := make([]string, 0, len(matchers))
+	for _, m := range matchers {
+		parts = append(parts, m.String())
+	}
+	return strings.Join(parts, ", ")
+}
+
+// missingMatchers returns the matchers a selector doesn't have yet, so rewriting a query twice doesn't repeat
+// them.
+func missingMatchers(selector []*labels.Matcher, matchers []*labels.Matcher) []*labels.Matcher {
+	var missing []*labels.Matcher
+	for _, m := range matchers {
+		if !containsMatcher(selector, m) {
+			missing = append(missing, m)
+		}
+	}
+	return missing
+}
+
+// closingBrace returns the index of the brace closing the one at open, skipping quoted strings.
+func closingBrace(query string, open int) (int, error) {
+	for i := open + 1; i < len(query); i++ {
+		switch query[i] {
+		case '"', '\'', '`':
+			end, err := stringEnd(query, i)
+			if err != nil {
+				return 0, err
+			}
+			i = end
+		case '}':
+			return i, nil
+		}
+	}
+	return 0, fmt.Errorf("unclosed selector at %d", open)
+}
+
+// stringEnd returns the index of the quote closing the string starting at start. Raw strings don't have escapes.
+func stringEnd(query string, start int) (int, error) {
+	quote := query[start]
+	for i := start + 1; i < len(query); i++ {
+		switch query[i] {
+		case '\\':
+			if quote != '`' {
+				i++
+			}
+		case quote:
+			return i, nil
+		}
+	}
+	return 0, fmt.Errorf("unclosed string at %d", start)
+}
+
+// insertMatchers inserts matchers into the selector between the braces at open and close.
+func insertMatchers(query string, open int, close int, matchers []*labels.Matcher) string {
+	inner := strings.TrimSpace(query[open+1 : close])
+	sep := ", "
+	if inner == "" {
+		sep = ""
+	} else if strings.HasSuffix(inner, ",") {
+		sep = " "
+	}
+	return query[:open+1] + inner + sep + formatMatchers(matchers) + query[close:]
+}
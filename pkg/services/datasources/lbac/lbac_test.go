@@ -0,0 +1,294 @@
+package lbac
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/prometheus/prometheus/model/labels"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/components/simplejson"
+	"github.com/grafana/grafana/pkg/services/datasources"
+	"github.com/grafana/grafana/pkg/services/user"
+)
+
+func matcher(t *testing.T, typ labels.MatchType, name, value string) *labels.Matcher {
+	t.Helper()
+	m, err := labels.NewMatcher(typ, name, value)
+	require.NoError(t, err)
+	return m
+}
+
+func TestRewritePromQL(t *testing.T) {
+	team := []*labels.Matcher{matcher(t, labels.MatchEqual, "namespace", "team-a")}
+
+	tests := []struct {
+		name     string
+		query    string
+		expected string
+	}{
+		{
+			name:     "metric name",
+			query:    `up`,
+			expected: `up{namespace="team-a"}`,
+		},
+		{
+			name:     "selector with matchers",
+			query:    `up{job="api"}`,
+			expected: `up{job="api", namespace="team-a"}`,
+		},
+		{
+			name:     "selector with trailing comma",
+			query:    `up{job="api",}`,
+			expected: `up{job="api", namespace="team-a"}`,
+		},
+		{
+			name:     "empty braces",
+			query:    `up{}`,
+			expected: `up{namespace="team-a"}`,
+		},
+		{
+			name:     "name matcher only",
+			query:    `{__name__="up"}`,
+			expected: `{__name__="up", namespace="team-a"}`,
+		},
+		{
+			name:     "braces in label values",
+			query:    `up{path="/{id}"}`,
+			expected: `up{path="/{id}", namespace="team-a"}`,
+		},
+		{
+			name:     "range vectors, functions and aggregations",
+			query:    `sum by (job) (rate(http_requests_total{code=~"5.."}[5m])) / sum by (job) (rate(http_requests_total[5m]))`,
+			expected: `sum by (job) (rate(http_requests_total{code=~"5..", namespace="team-a"}[5m])) / sum by (job) (rate(http_requests_total{namespace="team-a"}[5m]))`,
+		},
+		{
+			name:     "offset and subquery",
+			query:    `max_over_time(rate(up[1m])[1h:5m] offset 1d)`,
+			expected: `max_over_time(rate(up{namespace="team-a"}[1m])[1h:5m] offset 1d)`,
+		},
+		{
+			name:     "selector offset",
+			query:    `up offset 5m`,
+			expected: `up{namespace="team-a"} offset 5m`,
+		},
+		{
+			name:     "grafana variables",
+			query:    `rate(up[$__rate_interval]) * $__interval_ms / ${__range_s}`,
+			expected: `rate(up{namespace="team-a"}[$__rate_interval]) * $__interval_ms / ${__range_s}`,
+		},
+		{
+			name:     "matcher already present",
+			query:    `up{namespace="team-a"}`,
+			expected: `up{namespace="team-a"}`,
+		},
+		{
+			name:     "conflicting matcher is kept",
+			query:    `up{namespace="team-b"}`,
+			expected: `up{namespace="team-b", namespace="team-a"}`,
+		},
+		{
+			name:     "scalars",
+			query:    `vector(1) + 2`,
+			expected: `vector(1) + 2`,
+		},
+		{
+			name:     "keeps formatting",
+			query:    "sum(\n  up\n) by (job)",
+			expected: "sum(\n  up{namespace=\"team-a\"}\n) by (job)",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rewritten, err := rewritePromQL(tt.query, team)
+			require.NoError(t, err)
+			require.Equal(t, tt.expected, rewritten)
+		})
+	}
+
+	t.Run("invalid query", func(t *testing.T) {
+		_, err := rewritePromQL(`sum(up`, team)
+		require.Error(t, err)
+	})
+}
+
+func TestRewriteLogQL(t *testing.T) {
+	team := []*labels.Matcher{matcher(t, labels.MatchEqual, "namespace", "team-a")}
+
+	tests := []struct {
+		name     string
+		query    string
+		expected string
+	}{
+		{
+			name:     "stream selector",
+			query:    `{app="api"}`,
+			expected: `{app="api", namespace="team-a"}`,
+		},
+		{
+			name:     "pipeline with braces in strings",
+			query:    `{app="api"} |= "{" | json | line_format "{{.msg}}" | label_format x=` + "`{{.y}}`",
+			expected: `{app="api", namespace="team-a"} |= "{" | json | line_format "{{.msg}}" | label_format x=` + "`{{.y}}`",
+		},
+		{
+			name:     "metric queries",
+			query:    `sum by (level) (count_over_time({app="api"} |= "error" [$__auto])) / sum(count_over_time({app="api"}[$__auto]))`,
+			expected: `sum by (level) (count_over_time({app="api", namespace="team-a"} |= "error" [$__auto])) / sum(count_over_time({app="api", namespace="team-a"}[$__auto]))`,
+		},
+		{
+			name:     "escaped quotes",
+			query:    `{app="api"} |= "say \"}\""`,
+			expected: `{app="api", namespace="team-a"} |= "say \"}\""`,
+		},
+		{
+			name:     "comments",
+			query:    "{app=\"api\"} # {ignored}\n|= \"x\"",
+			expected: "{app=\"api\", namespace=\"team-a\"} # {ignored}\n|= \"x\"",
+		},
+		{
+			name:     "matcher already present",
+			query:    `{namespace="team-a"}`,
+			expected: `{namespace="team-a"}`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rewritten, err := rewriteLogQL(tt.query, team)
+			require.NoError(t, err)
+			require.Equal(t, tt.expected, rewritten)
+		})
+	}
+
+	t.Run("no stream selector", func(t *testing.T) {
+		_, err := rewriteLogQL(`"api"`, team)
+		require.Error(t, err)
+	})
+
+	t.Run("unclosed selector", func(t *testing.T) {
+		_, err := rewriteLogQL(`{app="api"`, team)
+		require.Error(t, err)
+	})
+}
+
+func TestPoliciesFor(t *testing.T) {
+	dataSource := func(dsType string, restrict bool, headers map[string]any) *datasources.DataSource {
+		return &datasources.DataSource{
+			UID:  "ds",
+			Type: dsType,
+			JsonData: simplejson.NewFromAny(map[string]any{
+				"teamHttpHeaders": map[string]any{
+					"restrictAccess": restrict,
+					"headers":        headers,
+				},
+			}),
+		}
+	}
+	policy := func(selector string) []any {
+		return []any{map[string]any{"header": PolicyHeader, "value": "1:" + selector}}
+	}
+	headers := map[string]any{
+		"1": policy(`{namespace="team-a"}`),
+		"2": policy(`{namespace=~"team-b|team-c"}`),
+		"3": policy(`{cluster="eu"}`),
+		"4": policy(`{namespace="team-a"}`),
+	}
+
+	t.Run("no policies", func(t *testing.T) {
+		policies, err := PoliciesFor(&datasources.DataSource{Type: datasources.DS_PROMETHEUS, JsonData: simplejson.New()}, &user.SignedInUser{Teams: []int64{1}})
+		require.NoError(t, err)
+		require.Nil(t, policies)
+	})
+
+	t.Run("other data source types", func(t *testing.T) {
+		policies, err := PoliciesFor(dataSource("elasticsearch", true, headers), &user.SignedInUser{})
+		require.NoError(t, err)
+		require.Nil(t, policies)
+	})
+
+	t.Run("user without policy", func(t *testing.T) {
+		policies, err := PoliciesFor(dataSource(datasources.DS_PROMETHEUS, false, headers), &user.SignedInUser{Teams: []int64{9}})
+		require.NoError(t, err)
+		require.Nil(t, policies)
+
+		_, err = PoliciesFor(dataSource(datasources.DS_PROMETHEUS, true, headers), &user.SignedInUser{Teams: []int64{9}})
+		require.True(t, errors.Is(err, ErrNoPolicy))
+	})
+
+	t.Run("single team", func(t *testing.T) {
+		policies, err := PoliciesFor(dataSource(datasources.DS_LOKI, true, headers), &user.SignedInUser{Teams: []int64{1}})
+		require.NoError(t, err)
+		rewritten, err := policies.Rewrite(`{app="api"}`)
+		require.NoError(t, err)
+		require.Equal(t, `{app="api", namespace="team-a"}`, rewritten)
+	})
+
+	t.Run("teams with the same policy", func(t *testing.T) {
+		policies, err := PoliciesFor(dataSource(datasources.DS_PROMETHEUS, true, headers), &user.SignedInUser{Teams: []int64{1, 4}})
+		require.NoError(t, err)
+		rewritten, err := policies.Rewrite(`up`)
+		require.NoError(t, err)
+		require.Equal(t, `up{namespace="team-a"}`, rewritten)
+	})
+
+	t.Run("teams restricting the same label", func(t *testing.T) {
+		policies, err := PoliciesFor(dataSource(datasources.DS_PROMETHEUS, true, headers), &user.SignedInUser{Teams: []int64{1, 2}})
+		require.NoError(t, err)
+		rewritten, err := policies.Rewrite(`up`)
+		require.NoError(t, err)
+		require.Equal(t, `up{namespace=~"team-a|(?:team-b|team-c)"}`, rewritten)
+	})
+
+	t.Run("teams restricting different labels", func(t *testing.T) {
+		_, err := PoliciesFor(dataSource(datasources.DS_PROMETHEUS, true, headers), &user.SignedInUser{Teams: []int64{1, 3}})
+		require.True(t, errors.Is(err, ErrConflictingPolicies))
+	})
+
+	t.Run("invalid queries are rejected", func(t *testing.T) {
+		policies, err := PoliciesFor(dataSource(datasources.DS_PROMETHEUS, true, headers), &user.SignedInUser{Teams: []int64{1}})
+		require.NoError(t, err)
+		_, err = policies.Rewrite(`sum(up`)
+		require.True(t, errors.Is(err, ErrInvalidQuery))
+	})
+}
+
+func TestModels(t *testing.T) {
+	ds := &datasources.DataSource{
+		UID:  "ds",
+		Type: datasources.DS_PROMETHEUS,
+		JsonData: simplejson.NewFromAny(map[string]any{
+			"teamHttpHeaders": map[string]any{
+				"restrictAccess": true,
+				"headers": map[string]any{
+					"1": []any{map[string]any{"header": PolicyHeader, "value": `1:{namespace="team-a"}`}},
+				},
+			},
+		}),
+	}
+	usr := &user.SignedInUser{Teams: []int64{1}}
+
+	t.Run("rewrites models", func(t *testing.T) {
+		policies, err := PoliciesFor(ds, usr)
+		require.NoError(t, err)
+
+		model, err := policies.RewriteModel([]byte(`{"refId":"A","expr":"up"}`))
+		require.NoError(t, err)
+		require.JSONEq(t, `{"refId":"A","expr":"up{namespace=\"team-a\"}"}`, string(model))
+
+		model, err = policies.RewriteModel([]byte(`{"refId":"A","type":"math"}`))
+		require.NoError(t, err)
+		require.JSONEq(t, `{"refId":"A","type":"math"}`, string(model))
+	})
+
+	t.Run("checks models", func(t *testing.T) {
+		err := CheckModel(ds, usr, []byte(`{"refId":"A","expr":"up"}`))
+		require.True(t, errors.Is(err, ErrPolicyNotApplied))
+
+		require.NoError(t, CheckModel(ds, usr, []byte(`{"refId":"A","expr":"up{namespace=\"team-a\"}"}`)))
+	})
+
+	t.Run("checks resource access", func(t *testing.T) {
+		require.True(t, errors.Is(CheckResourceAccess(ds, usr), ErrResourcesRestricted))
+		require.True(t, errors.Is(CheckResourceAccess(ds, &user.SignedInUser{Teams: []int64{2}}), ErrNoPolicy))
+		require.NoError(t, CheckResourceAccess(&datasources.DataSource{Type: datasources.DS_PROMETHEUS, JsonData: simplejson.New()}, usr))
+	})
+}
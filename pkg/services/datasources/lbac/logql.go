@@ -0,0 +1,52 @@
+package lbac
+
+import (
+	"errors"
+
+	"github.com/prometheus/prometheus/model/labels"
+	"github.com/prometheus/prometheus/promql/parser"
+)
+
+// rewriteLogQL injects matchers into every stream selector of a LogQL query. Outside of strings and comments,
+// braces only appear around stream selectors, which share their syntax with PromQL selectors.
+func rewriteLogQL(query string, matchers []*labels.Matcher) (string, error) {
+	found := false
+	for i := 0; i < len(query); i++ {
+		switch query[i] {
+		case '"', '\'', '`':
+			end, err := stringEnd(query, i)
+			if err != nil {
+				return "", err
+			}
+			i = end
+		case '#':
+			for i < len(query) && query[i] != '\n' {
+				i++
+			}
+		case '{':
+			closing, err := closingBrace(query, i)
+			if err != nil {
+				return "", err
+			}
+			found = true
+
+			selector, err := parser.ParseMetricSelector(query[i : closing+1])
+			if err != nil {
+				return "", err
+			}
+			if missing := missingMatchers(selector, matchers); len(missing) > 0 {
+				query = insertMatchers(query, i, closing, missing)
+				closing, err = closingBrace(query, i)
+				if err != nil {
+					return "", err
+				}
+			}
+			i = closing
+		}
+	}
+
+	if !found {
+		return "", errors.New("no stream selector found")
+	}
+	return query, nil
+}
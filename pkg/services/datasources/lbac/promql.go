@@ -0,0 +1,103 @@
+package lbac
+
+import (
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/prometheus/prometheus/model/labels"
+	"github.com/prometheus/prometheus/promql/parser"
+)
+
+// grafanaVariable matches the variables interpolated by the data source, like $__rate_interval, which the
+// PromQL parser doesn't understand.
+var grafanaVariable = regexp.MustCompile(`\$__\w+|\$\{__\w+(?::\w+)?\}`)
+
+// rewritePromQL injects matchers into every vector selector of a PromQL query. Selectors are edited in the
+// query text rather than printed from the syntax tree, to keep the formatting and variables of the query.
+func rewritePromQL(query string, matchers []*labels.Matcher) (string, error) {
+	expr, err := parser.ParseExpr(maskVariables(query))
+	if err != nil {
+		return "", err
+	}
+
+	type selector struct {
+		start, end int
+		missing    []*labels.Matcher
+	}
+	var selectors []selector
+	parser.Inspect(expr, func(node parser.Node, _ []parser.Node) error {
+		if vs, ok := node.(*parser.VectorSelector); ok {
+			if missing := missingMatchers(vs.LabelMatchers, matchers); len(missing) > 0 {
+				pos := vs.PositionRange()
+				selectors = append(selectors, selector{start: int(pos.Start), end: int(pos.End), missing: missing})
+			}
+		}
+		return nil
+	})
+
+	// edit from the end, so the positions of the selectors before stay valid
+	sort.Slice(selectors, func(i, j int) bool { return selectors[i].start > selectors[j].start })
+	for _, s := range selectors {
+		query, err = injectIntoSelector(query, s.start, s.end, s.missing)
+		if err != nil {
+			return "", err
+		}
+	}
+	return query, nil
+}
+
+// injectIntoSelector adds matchers to the selector starting at start, like `up`, `up{job="api"}` or
+// `{__name__="up"}`. The selector may be followed by modifiers up to end, like `offset 5m`.
+func injectIntoSelector(query string, start int, end int, matchers []*labels.Matcher) (string, error) {
+	i := start
+	for i < end && isMetricNameChar(query[i]) {
+		i++
+	}
+	nameEnd := i
+	for i < end && (query[i] == ' ' || query[i] == '\t' || query[i] == '\n') {
+		i++
+	}
+
+	if i < end && query[i] == '{' {
+		closing, err := closingBrace(query, i)
+		if err != nil {
+			return "", err
+		}
+		return insertMatchers(query, i, closing, matchers), nil
+	}
+	return query[:nameEnd] + "{" + formatMatchers(matchers) + "}" + query[nameEnd:], nil
+}
+
+func isMetricNameChar(c byte) bool {
+	return c == '_' || c == ':' || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z') || (c >= '0' && c <= '9')
+}
+
+// maskVariables replaces Grafana variables with values of the same length the parser accepts, so positions in
+// the parsed query are positions in the original query. Variables used as durations are replaced by a
+// duration, the others by a number.
+func maskVariables(query string) string {
+	var sb strings.Builder
+	last := 0
+	for _, loc := range grafanaVariable.FindAllStringIndex(query, -1) {
+		start, end := loc[0], loc[1]
+		sb.WriteString(query[last:start])
+		if isDuration(query, start, end) {
+			sb.WriteString(strings.Repeat("0", end-start-2) + "1s")
+		} else {
+			sb.WriteString(strings.Repeat("0", end-start-1) + "1")
+		}
+		last = end
+	}
+	sb.WriteString(query[last:])
+	return sb.String()
+}
+
+// isDuration returns whether the variable between start and end is used as a duration: a range, the step of a
+// subquery or an offset.
+func isDuration(query string, start int, end int) bool {
+	before := strings.TrimRight(query[:start], " \t\n")
+	after := strings.TrimLeft(query[end:], " \t\n")
+	return strings.HasSuffix(before, "[") || strings.HasSuffix(before, ":") || strings.HasSuffix(before, "offset") ||
+		strings.HasPrefix(after, "]") || strings.HasPrefix(after, ":")
+}
@@ -1302,6 +1302,31 @@ var (
 			Stage:       FeatureStageExperimental,
 			Owner:       grafanaPartnerPluginsSquad,
 		},
+		{
+			Name:        "liveAccessControl",
+			Description: "Enables fine-grained access control and audit logging for Grafana Live channels",
+			Stage:       FeatureStageExperimental,
+			Owner:       grafanaAppPlatformSquad,
+		},
+		{
+			Name:        "alertingLiveStreamingRules",
+			Description: "Enables alert rules that are evaluated on frames pushed to Grafana Live channels",
+			Stage:       FeatureStageExperimental,
+			Owner:       grafanaAlertingSquad,
+		},
+		{
+			Name:        "lokiBackendQuerySplitting",
+			Description: "Split long range Loki queries in the backend by time interval and stream shard",
+			Stage:       FeatureStageExperimental,
+			Owner:       grafanaObservabilityLogsSquad,
+		},
+		{
+			Name:         "influxdbSchemaResources",
+			Description:  "Load the InfluxDB query editor schema from backend resources",
+			Stage:        FeatureStageExperimental,
+			FrontendOnly: true,
+			Owner:        grafanaObservabilityMetricsSquad,
+		},
 	}
 )
 
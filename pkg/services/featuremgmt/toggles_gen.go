@@ -702,4 +702,20 @@ const (
 	// FlagAzureMonitorPrometheusExemplars
 	// Allows configuration of Azure Monitor as a data source that can provide Prometheus exemplars
 	FlagAzureMonitorPrometheusExemplars = "azureMonitorPrometheusExemplars"
+
+	// FlagLiveAccessControl
+	// Enables fine-grained access control and audit logging for Grafana Live channels
+	FlagLiveAccessControl = "liveAccessControl"
+
+	// FlagAlertingLiveStreamingRules
+	// Enables alert rules that are evaluated on frames pushed to Grafana Live channels
+	FlagAlertingLiveStreamingRules = "alertingLiveStreamingRules"
+
+	// FlagLokiBackendQuerySplitting
+	// Split long range Loki queries in the backend by time interval and stream shard
+	FlagLokiBackendQuerySplitting = "lokiBackendQuerySplitting"
+
+	// FlagInfluxdbSchemaResources
+	// Load the InfluxDB query editor schema from backend resources
+	FlagInfluxdbSchemaResources = "influxdbSchemaResources"
 )
@@ -0,0 +1,148 @@
+package live
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/services/accesscontrol"
+	"github.com/grafana/grafana/pkg/services/auth/identity"
+	"github.com/grafana/grafana/pkg/services/featuremgmt"
+	"github.com/grafana/grafana/pkg/services/org"
+)
+
+// Live related actions
+const (
+	ActionSubscribe = "live:subscribe"
+	ActionPublish   = "live:publish"
+)
+
+// Live related scopes. Channel scopes use the channel ID without org prefix,
+// so access to a subset of streams can be granted with a wildcard, e.g.
+// `live:channels:stream/factory-1/*`.
+var (
+	ScopeChannelsRoot = "live:channels"
+	ScopeChannelsAll  = accesscontrol.Scope(ScopeChannelsRoot, "*")
+)
+
+var auditLogger = log.New("live.audit")
+
+// auditLogInterval is the minimum interval between two audit log entries for the
+// same allowed access, publishing clients check access on every message.
+const auditLogInterval = time.Minute
+
+// auditLogMaxKeys bounds the number of decisions remembered by the audit limiter.
+const auditLogMaxKeys = 10000
+
+var channelAuditLimiter = &auditLimiter{last: map[string]time.Time{}}
+
+// auditLimiter deduplicates audit log entries of the same decision.
+type auditLimiter struct {
+	mu   sync.Mutex
+	last map[string]time.Time
+}
+
+func (l *auditLimiter) allow(key string, now time.Time) bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if t, ok := l.last[key]; ok && now.Sub(t) < auditLogInterval {
+		return false
+	}
+	if len(l.last) >= auditLogMaxKeys {
+		for k, t := range l.last {
+			if now.Sub(t) >= auditLogInterval {
+				delete(l.last, k)
+			}
+		}
+		if len(l.last) >= auditLogMaxKeys {
+			l.last = map[string]time.Time{}
+		}
+	}
+	l.last[key] = now
+	return true
+}
+
+// ScopeChannel returns the scope for a single channel.
+func ScopeChannel(channel string) string {
+	return accesscontrol.Scope(ScopeChannelsRoot, channel)
+}
+
+// ScopeChannelNamespace returns the scope covering all channels in a namespace,
+// e.g. all paths a push endpoint can write to for `stream/factory-1`.
+func ScopeChannelNamespace(scope, namespace string) string {
+	return accesscontrol.Scope(ScopeChannelsRoot, scope+"/"+namespace+"/*")
+}
+
+// FixedRoles returns the fixed roles granting Live permissions. They keep the
+// behaviour of the basic roles when liveAccessControl is enabled: viewers can
+// subscribe and editors can publish to any channel, while channel rules and
+// plugins apply their own checks on top.
+func FixedRoles() []accesscontrol.RoleRegistration {
+	subscriberRole := accesscontrol.RoleRegistration{
+		Role: accesscontrol.RoleDTO{
+			Name:        "fixed:live:subscriber",
+			DisplayName: "Subscriber",
+			Description: "Subscribe to all Grafana Live channels",
+			Group:       "Live",
+			Permissions: []accesscontrol.Permission{
+				{Action: ActionSubscribe, Scope: ScopeChannelsAll},
+			},
+		},
+		Grants: []string{string(org.RoleViewer)},
+	}
+
+	publisherRole := accesscontrol.RoleRegistration{
+		Role: accesscontrol.RoleDTO{
+			Name:        "fixed:live:publisher",
+			DisplayName: "Publisher",
+			Description: "Publish and push data to all Grafana Live channels",
+			Group:       "Live",
+			Permissions: []accesscontrol.Permission{
+				{Action: ActionPublish, Scope: ScopeChannelsAll},
+			},
+		},
+		Grants: []string{string(org.RoleEditor)},
+	}
+
+	return []accesscontrol.RoleRegistration{subscriberRole, publisherRole}
+}
+
+// CheckChannelAccess evaluates fine-grained permissions for a channel scope and
+// writes an audit log entry with the result. Denied access is always logged,
+// the same allowed access is logged at most once per auditLogInterval. It
+// always allows access when liveAccessControl feature toggle is disabled.
+func (g *GrafanaLive) CheckChannelAccess(ctx context.Context, user identity.Requester, action string, scope string, transport string) (bool, error) {
+	if g.Features == nil || !g.Features.IsEnabled(ctx, featuremgmt.FlagLiveAccessControl) {
+		return true, nil
+	}
+	allowed, err := g.accessControl.Evaluate(ctx, user, accesscontrol.EvalPermission(action, scope))
+	if err != nil {
+		auditLogger.Error("Error evaluating channel permissions", "action", action, "scope", scope, "error", err)
+		return false, err
+	}
+
+	namespaceID, userID := user.GetNamespacedID()
+	args := []any{
+		"action", action,
+		"scope", scope,
+		"transport", transport,
+		"orgId", user.GetOrgID(),
+		"namespace", namespaceID,
+		"userId", userID,
+		"login", user.GetLogin(),
+		"allowed", allowed,
+	}
+	if !allowed {
+		auditLogger.Warn("Channel access denied", args...)
+		return false, nil
+	}
+
+	key := fmt.Sprintf("%d/%s:%s/%s/%s/%s", user.GetOrgID(), namespaceID, userID, transport, action, scope)
+	if channelAuditLimiter.allow(key, time.Now()) {
+		auditLogger.Info("Channel access", args...)
+	}
+	return true, nil
+}
@@ -0,0 +1,78 @@
+package live
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/live"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/services/accesscontrol/acimpl"
+	"github.com/grafana/grafana/pkg/services/featuremgmt"
+	"github.com/grafana/grafana/pkg/services/user"
+)
+
+func TestGrafanaLive_CheckChannelAccess(t *testing.T) {
+	signedInUser := &user.SignedInUser{
+		OrgID: 1,
+		Permissions: map[int64]map[string][]string{
+			1: {
+				ActionSubscribe: {"live:channels:stream/factory-1/*"},
+				ActionPublish:   {ScopeChannelNamespace(live.ScopeStream, "factory-2")},
+			},
+		},
+	}
+
+	tests := []struct {
+		name    string
+		action  string
+		scope   string
+		allowed bool
+	}{
+		{name: "subscribe to channel matching pattern", action: ActionSubscribe, scope: ScopeChannel("stream/factory-1/temperature"), allowed: true},
+		{name: "subscribe to channel outside pattern", action: ActionSubscribe, scope: ScopeChannel("stream/factory-2/temperature"), allowed: false},
+		{name: "subscribe to plugin channel", action: ActionSubscribe, scope: ScopeChannel("plugin/testdata/random-2s-stream"), allowed: false},
+		{name: "push to granted stream", action: ActionPublish, scope: ScopeChannelNamespace(live.ScopeStream, "factory-2"), allowed: true},
+		{name: "publish to single channel of granted stream", action: ActionPublish, scope: ScopeChannel("stream/factory-2/pressure"), allowed: true},
+		{name: "push to another stream", action: ActionPublish, scope: ScopeChannelNamespace(live.ScopeStream, "factory-1"), allowed: false},
+	}
+
+	t.Run("feature toggle enabled", func(t *testing.T) {
+		features := featuremgmt.WithFeatures(featuremgmt.FlagLiveAccessControl)
+		g := &GrafanaLive{Features: features, accessControl: acimpl.ProvideAccessControl(features)}
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				allowed, err := g.CheckChannelAccess(context.Background(), signedInUser, tt.action, tt.scope, "ws")
+				require.NoError(t, err)
+				require.Equal(t, tt.allowed, allowed)
+			})
+		}
+	})
+
+	t.Run("feature toggle disabled", func(t *testing.T) {
+		features := featuremgmt.WithFeatures()
+		g := &GrafanaLive{Features: features, accessControl: acimpl.ProvideAccessControl(features)}
+		for _, tt := range tests {
+			allowed, err := g.CheckChannelAccess(context.Background(), signedInUser, tt.action, tt.scope, "ws")
+			require.NoError(t, err)
+			require.True(t, allowed)
+		}
+	})
+}
+
+func TestAuditLimiter(t *testing.T) {
+	l := &auditLimiter{last: map[string]time.Time{}}
+	now := time.Now()
+
+	require.True(t, l.allow("a", now))
+	require.False(t, l.allow("a", now.Add(time.Second)))
+	require.True(t, l.allow("b", now.Add(time.Second)))
+	require.True(t, l.allow("a", now.Add(auditLogInterval)))
+
+	for i := 0; i < auditLogMaxKeys; i++ {
+		l.allow(fmt.Sprint(i), now)
+	}
+	require.LessOrEqual(t, len(l.last), auditLogMaxKeys)
+}
@@ -97,6 +97,7 @@ func ProvideService(plugCtxProvider *plugincontext.Provider, cfg *setting.Cfg, r
 		},
 		usageStatsService: usageStatsService,
 		orgService:        orgService,
+		accessControl:     accessControl,
 	}
 
 	logger.Debug("GrafanaLive initialization", "ha", g.IsHA())
@@ -298,16 +299,24 @@ func ProvideService(plugCtxProvider *plugincontext.Provider, cfg *setting.Cfg, r
 
 	g.pushWebsocketHandler = func(ctx *contextmodel.ReqContext) {
 		user := ctx.SignedInUser
+		streamID := web.Params(ctx.Req)[":streamId"]
+		if !g.canPushWebsocket(ctx, ScopeChannelNamespace(live.ScopeStream, streamID)) {
+			return
+		}
 		newCtx := livecontext.SetContextSignedUser(ctx.Req.Context(), user)
-		newCtx = livecontext.SetContextStreamID(newCtx, web.Params(ctx.Req)[":streamId"])
+		newCtx = livecontext.SetContextStreamID(newCtx, streamID)
 		r := ctx.Req.WithContext(newCtx)
 		pushWSHandler.ServeHTTP(ctx.Resp, r)
 	}
 
 	g.pushPipelineWebsocketHandler = func(ctx *contextmodel.ReqContext) {
 		user := ctx.SignedInUser
+		channelID := web.Params(ctx.Req)["*"]
+		if !g.canPushWebsocket(ctx, ScopeChannel(channelID)) {
+			return
+		}
 		newCtx := livecontext.SetContextSignedUser(ctx.Req.Context(), user)
-		newCtx = livecontext.SetContextChannelID(newCtx, web.Params(ctx.Req)["*"])
+		newCtx = livecontext.SetContextChannelID(newCtx, channelID)
 		r := ctx.Req.WithContext(newCtx)
 		pushPipelineWSHandler.ServeHTTP(ctx.Resp, r)
 	}
@@ -326,6 +335,22 @@ func ProvideService(plugCtxProvider *plugincontext.Provider, cfg *setting.Cfg, r
 	return g, nil
 }
 
+// canPushWebsocket checks channel access before upgrading push connection and
+// writes an error response if access is not allowed.
+func (g *GrafanaLive) canPushWebsocket(ctx *contextmodel.ReqContext, scope string) bool {
+	allowed, err := g.CheckChannelAccess(ctx.Req.Context(), ctx.SignedInUser, ActionPublish, scope, "ws")
+	if err != nil {
+		logger.Error("Error checking channel access", "scope", scope, "error", err)
+		ctx.Resp.WriteHeader(http.StatusInternalServerError)
+		return false
+	}
+	if !allowed {
+		ctx.Resp.WriteHeader(http.StatusForbidden)
+		return false
+	}
+	return true
+}
+
 func setupRedisLiveEngine(g *GrafanaLive, node *centrifuge.Node) error {
 	redisAddress := g.Cfg.LiveHAEngineAddress
 	redisPassword := g.Cfg.LiveHAEnginePassword
@@ -379,6 +404,7 @@ type GrafanaLive struct {
 	pluginClient          plugins.Client
 	queryDataService      query.Service
 	orgService            org.Service
+	accessControl         accesscontrol.AccessControl
 
 	node         *centrifuge.Node
 	surveyCaller *survey.Caller
@@ -619,6 +645,17 @@ func (g *GrafanaLive) handleOnSubscribe(ctx context.Context, client *centrifuge.
 		return centrifuge.SubscribeReply{}, centrifuge.ErrorPermissionDenied
 	}
 
+	allowed, err := g.CheckChannelAccess(client.Context(), user, ActionSubscribe, ScopeChannel(channel), "ws")
+	if err != nil {
+		logger.Error("Error checking channel access", "user", client.UserID(), "client", client.ID(), "channel", e.Channel, "error", err)
+		return centrifuge.SubscribeReply{}, centrifuge.ErrorInternal
+	}
+	if !allowed {
+		// using HTTP error codes for WS errors too.
+		code, text := subscribeStatusToHTTPError(backend.SubscribeStreamStatusPermissionDenied)
+		return centrifuge.SubscribeReply{}, &centrifuge.Error{Code: uint32(code), Message: text}
+	}
+
 	var reply model.SubscribeReply
 	var status backend.SubscribeStreamStatus
 	var ruleFound bool
@@ -720,6 +757,17 @@ func (g *GrafanaLive) handleOnPublish(ctx context.Context, client *centrifuge.Cl
 		return centrifuge.PublishReply{}, centrifuge.ErrorPermissionDenied
 	}
 
+	allowed, err := g.CheckChannelAccess(client.Context(), user, ActionPublish, ScopeChannel(channel), "ws")
+	if err != nil {
+		logger.Error("Error checking channel access", "user", client.UserID(), "client", client.ID(), "channel", e.Channel, "error", err)
+		return centrifuge.PublishReply{}, centrifuge.ErrorInternal
+	}
+	if !allowed {
+		// using HTTP error codes for WS errors too.
+		code, text := publishStatusToHTTPError(backend.PublishStreamStatusPermissionDenied)
+		return centrifuge.PublishReply{}, &centrifuge.Error{Code: uint32(code), Message: text}
+	}
+
 	if g.Pipeline != nil {
 		rule, ok, err := g.Pipeline.Get(user.GetOrgID(), channel)
 		if err != nil {
@@ -955,6 +1003,15 @@ func (g *GrafanaLive) HandleHTTPPublish(ctx *contextmodel.ReqContext) response.R
 	user := ctx.SignedInUser
 	channel := cmd.Channel
 
+	allowed, err := g.CheckChannelAccess(ctx.Req.Context(), user, ActionPublish, ScopeChannel(channel), "http")
+	if err != nil {
+		logger.Error("Error checking channel access", "user", user, "channel", channel, "error", err)
+		return response.Error(http.StatusInternalServerError, http.StatusText(http.StatusInternalServerError), nil)
+	}
+	if !allowed {
+		return response.Error(http.StatusForbidden, http.StatusText(http.StatusForbidden), nil)
+	}
+
 	if g.Pipeline != nil {
 		rule, ok, err := g.Pipeline.Get(user.GetOrgID(), channel)
 		if err != nil {
@@ -42,6 +42,7 @@ type Runner struct {
 	publisher      model.ChannelPublisher
 	localPublisher LocalPublisher
 	frameCache     FrameCache
+	frameHandlers  []FrameHandler
 }
 
 type LocalPublisher interface {
@@ -113,6 +114,26 @@ func (r *Runner) GetManagedChannels(orgID int64) ([]*ManagedChannel, error) {
 	return channels, nil
 }
 
+// FrameHandler is notified about every frame pushed to a managed stream, for example to evaluate
+// streaming alert rules. Handlers are called synchronously after the frame is published and must not block.
+type FrameHandler func(ctx context.Context, orgID int64, channel string, frame *data.Frame)
+
+// AddFrameHandler registers a handler called for frames pushed to any managed stream.
+func (r *Runner) AddFrameHandler(h FrameHandler) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.frameHandlers = append(r.frameHandlers, h)
+}
+
+func (r *Runner) handleFrame(ctx context.Context, orgID int64, channel string, frame *data.Frame) {
+	r.mu.RLock()
+	handlers := r.frameHandlers
+	r.mu.RUnlock()
+	for _, h := range handlers {
+		h(ctx, orgID, channel, frame)
+	}
+}
+
 // GetOrCreateStream -- for now this will create new manager for each key.
 // Eventually, the stream behavior will need to be configured explicitly
 func (r *Runner) GetOrCreateStream(orgID int64, scope string, namespace string) (*NamespaceStream, error) {
@@ -126,6 +147,7 @@ func (r *Runner) GetOrCreateStream(orgID int64, scope string, namespace string)
 	s, ok := r.streams[orgID][prefix]
 	if !ok {
 		s = NewNamespaceStream(orgID, scope, namespace, r.publisher, r.localPublisher, r.frameCache)
+		s.frameHandler = r.handleFrame
 		r.streams[orgID][prefix] = s
 	}
 	return s, nil
@@ -141,6 +163,7 @@ type NamespaceStream struct {
 	frameCache     FrameCache
 	rateMu         sync.RWMutex
 	rates          map[string][60]rateEntry
+	frameHandler   FrameHandler
 }
 
 type rateEntry struct {
@@ -197,9 +220,17 @@ func (s *NamespaceStream) Push(ctx context.Context, path string, frame *data.Fra
 	logger.Debug("Publish data to channel", "channel", channel, "dataLength", len(frameJSON))
 	s.incRate(path, time.Now().Unix())
 	if s.scope == live.ScopeDatasource || s.scope == live.ScopePlugin {
-		return s.localPublisher.PublishLocal(orgchannel.PrependOrgID(s.orgID, channel), frameJSON)
+		err = s.localPublisher.PublishLocal(orgchannel.PrependOrgID(s.orgID, channel), frameJSON)
+	} else {
+		err = s.publisher(s.orgID, channel, frameJSON)
+	}
+	if err != nil {
+		return err
+	}
+	if s.frameHandler != nil {
+		s.frameHandler(ctx, s.orgID, channel, frame)
 	}
-	return s.publisher(s.orgID, channel, frameJSON)
+	return nil
 }
 
 func (s *NamespaceStream) incRate(path string, nowUnix int64) {
@@ -83,3 +83,23 @@ func TestGetManagedStreams(t *testing.T) {
 	require.NoError(t, err)
 	require.Len(t, managedChannels, 7) // Not affected by other org.
 }
+
+func TestRunner_FrameHandler(t *testing.T) {
+	publisher := &testPublisher{t: t}
+	runner := NewRunner(publisher.publish, nil, NewMemoryFrameCache())
+
+	type handledFrame struct {
+		orgID   int64
+		channel string
+		name    string
+	}
+	var handled []handledFrame
+	runner.AddFrameHandler(func(_ context.Context, orgID int64, channel string, frame *data.Frame) {
+		handled = append(handled, handledFrame{orgID: orgID, channel: channel, name: frame.Name})
+	})
+
+	s, err := runner.GetOrCreateStream(2, "stream", "factory-1")
+	require.NoError(t, err)
+	require.NoError(t, s.Push(context.Background(), "cpu", data.NewFrame("cpu")))
+	require.Equal(t, []handledFrame{{orgID: 2, channel: "stream/factory-1/cpu", name: "cpu"}}, handled)
+}
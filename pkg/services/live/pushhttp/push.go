@@ -48,6 +48,10 @@ func (g *Gateway) Run(ctx context.Context) error {
 func (g *Gateway) Handle(ctx *contextmodel.ReqContext) {
 	streamID := web.Params(ctx.Req)[":streamId"]
 
+	if !g.checkAccess(ctx, live.ScopeChannelNamespace(liveDto.ScopeStream, streamID)) {
+		return
+	}
+
 	stream, err := g.GrafanaLive.ManagedStreamRunner.GetOrCreateStream(ctx.SignedInUser.OrgID, liveDto.ScopeStream, streamID)
 	if err != nil {
 		logger.Error("Error getting stream", "error", err)
@@ -101,6 +105,10 @@ func (g *Gateway) Handle(ctx *contextmodel.ReqContext) {
 func (g *Gateway) HandlePipelinePush(ctx *contextmodel.ReqContext) {
 	channelID := web.Params(ctx.Req)["*"]
 
+	if !g.checkAccess(ctx, live.ScopeChannel(channelID)) {
+		return
+	}
+
 	body, err := io.ReadAll(ctx.Req.Body)
 	if err != nil {
 		logger.Error("Error reading body", "error", err)
@@ -131,3 +139,17 @@ func (g *Gateway) HandlePipelinePush(ctx *contextmodel.ReqContext) {
 
 	ctx.Resp.WriteHeader(http.StatusOK)
 }
+
+func (g *Gateway) checkAccess(ctx *contextmodel.ReqContext, scope string) bool {
+	allowed, err := g.GrafanaLive.CheckChannelAccess(ctx.Req.Context(), ctx.SignedInUser, live.ActionPublish, scope, "http")
+	if err != nil {
+		logger.Error("Error checking channel access", "error", err, "scope", scope)
+		ctx.Resp.WriteHeader(http.StatusInternalServerError)
+		return false
+	}
+	if !allowed {
+		ctx.Resp.WriteHeader(http.StatusForbidden)
+		return false
+	}
+	return true
+}
@@ -0,0 +1,415 @@
+package pushmqtt
+
+import (
+	"bufio"
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"strings"
+	"time"
+
+	liveDto "github.com/grafana/grafana-plugin-sdk-go/live"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/services/auth/identity"
+	"github.com/grafana/grafana/pkg/services/authn"
+	"github.com/grafana/grafana/pkg/services/live"
+	"github.com/grafana/grafana/pkg/services/live/convert"
+	"github.com/grafana/grafana/pkg/services/org"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+var (
+	logger = log.New("live.push_mqtt")
+)
+
+// Defaults.
+const (
+	DefaultMessageSizeLimit = 1024 * 1024 // 1MB
+	DefaultConnectTimeout   = 10 * time.Second
+	// DefaultAccessCacheTTL is how long a connection reuses access decisions
+	// before the client is authenticated again with the token it connected with.
+	DefaultAccessCacheTTL = time.Minute
+)
+
+// errInvalidMessage marks publications with a malformed topic or payload.
+var errInvalidMessage = errors.New("invalid message")
+
+// Authenticator resolves the token a client sends in the CONNECT password field.
+type Authenticator interface {
+	Authenticate(ctx context.Context, token string) (identity.Requester, error)
+}
+
+// authnAuthenticator authenticates MQTT clients with the same API keys and
+// service account tokens accepted by the HTTP push endpoints.
+type authnAuthenticator struct {
+	authnService authn.Service
+}
+
+func (a *authnAuthenticator) Authenticate(ctx context.Context, token string) (identity.Requester, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, "/api/live/push", nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Authorization", "Bearer "+token)
+	user, err := a.authnService.Authenticate(ctx, &authn.Request{HTTPRequest: req})
+	if err != nil {
+		return nil, err
+	}
+	return user, nil
+}
+
+func ProvideService(cfg *setting.Cfg, live *live.GrafanaLive, authnService authn.Service) *Gateway {
+	return NewGateway(cfg, live, &authnAuthenticator{authnService: authnService})
+}
+
+// NewGateway creates new Gateway.
+func NewGateway(cfg *setting.Cfg, live *live.GrafanaLive, authenticator Authenticator) *Gateway {
+	return &Gateway{
+		Cfg:           cfg,
+		GrafanaLive:   live,
+		authenticator: authenticator,
+		converter:     convert.NewConverter(),
+	}
+}
+
+// Gateway accepts MQTT client connections and translates PUBLISH packets to
+// Grafana Live publications. Every topic is mapped to a channel in the stream
+// scope, i.e. topic `factory-1/temperature` becomes `stream/factory-1/temperature`.
+// If a pipeline channel rule exists for the channel the payload goes through
+// the Live Pipeline, otherwise it's converted from Influx line protocol and
+// pushed to the managed stream named by the first topic level. The rest of the
+// topic is the channel path, a topic without path publishes every measurement
+// to its own channel like the HTTP push endpoint does.
+//
+// Publications that fail are rejected: they are acknowledged and dropped so that
+// clients do not redeliver them forever, the connection stays open.
+type Gateway struct {
+	Cfg         *setting.Cfg
+	GrafanaLive *live.GrafanaLive
+
+	authenticator Authenticator
+	converter     *convert.Converter
+}
+
+// IsDisabled returns true if the MQTT listener is not enabled in configuration.
+func (g *Gateway) IsDisabled() bool {
+	return !g.Cfg.LiveMQTTEnabled || g.Cfg.LiveMaxConnections == 0
+}
+
+// Run Gateway.
+func (g *Gateway) Run(ctx context.Context) error {
+	logger.Info("Live MQTT Gateway initialization", "address", g.Cfg.LiveMQTTAddress)
+
+	listener, err := g.listen()
+	if err != nil {
+		return err
+	}
+	go func() {
+		<-ctx.Done()
+		_ = listener.Close()
+	}()
+
+	for {
+		conn, err := listener.Accept()
+		if err != nil {
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			if errors.Is(err, net.ErrClosed) {
+				return err
+			}
+			logger.Warn("Error accepting MQTT connection", "error", err)
+			time.Sleep(50 * time.Millisecond)
+			continue
+		}
+		go g.serveConn(ctx, conn)
+	}
+}
+
+func (g *Gateway) listen() (net.Listener, error) {
+	if g.Cfg.LiveMQTTCertFile == "" {
+		return net.Listen("tcp", g.Cfg.LiveMQTTAddress)
+	}
+	cert, err := tls.LoadX509KeyPair(g.Cfg.LiveMQTTCertFile, g.Cfg.LiveMQTTCertKey)
+	if err != nil {
+		return nil, fmt.Errorf("could not load MQTT certificate: %w", err)
+	}
+	return tls.Listen("tcp", g.Cfg.LiveMQTTAddress, &tls.Config{
+		Certificates: []tls.Certificate{cert},
+		MinVersion:   tls.VersionTLS12,
+	})
+}
+
+func (g *Gateway) serveConn(ctx context.Context, conn net.Conn) {
+	defer func() { _ = conn.Close() }()
+
+	sizeLimit := g.Cfg.LiveMQTTMessageSizeLimit
+	if sizeLimit == 0 {
+		sizeLimit = DefaultMessageSizeLimit
+	}
+	r := bufio.NewReader(conn)
+
+	_ = conn.SetReadDeadline(time.Now().Add(DefaultConnectTimeout))
+	p, err := readPacket(r, sizeLimit)
+	if err != nil {
+		logger.Debug("Error reading MQTT CONNECT", "error", err, "remote", conn.RemoteAddr().String())
+		return
+	}
+	if p.Type != packetConnect {
+		logger.Debug("First MQTT packet is not CONNECT", "type", p.Type, "remote", conn.RemoteAddr().String())
+		return
+	}
+	connect, err := decodeConnect(p)
+	if err != nil {
+		logger.Debug("Malformed MQTT CONNECT", "error", err, "remote", conn.RemoteAddr().String())
+		return
+	}
+
+	user, code := g.authenticate(ctx, connect)
+	if err := writeConnack(conn, code); err != nil || code != connackAccepted {
+		return
+	}
+
+	logger.Debug("MQTT client connected", "clientId", connect.ClientID, "orgId", user.GetOrgID(), "remote", conn.RemoteAddr().String())
+	started := time.Now()
+	s := newSession(string(connect.Password), user)
+
+	for {
+		if connect.KeepAlive > 0 {
+			// The server must disconnect a client that sends nothing within
+			// one and a half times the keep alive period (MQTT 3.1.1, 3.1.2.10).
+			_ = conn.SetReadDeadline(time.Now().Add(time.Duration(connect.KeepAlive) * 1500 * time.Millisecond))
+		} else {
+			_ = conn.SetReadDeadline(time.Time{})
+		}
+		p, err := readPacket(r, sizeLimit)
+		if err != nil {
+			logger.Debug("Error reading MQTT connection", "error", err, "clientId", connect.ClientID)
+			return
+		}
+		if err := g.handlePacket(ctx, conn, s, p); err != nil {
+			switch {
+			case errors.Is(err, errSessionRevoked):
+				logger.Info("Closing MQTT connection", "error", err, "clientId", connect.ClientID)
+			case !errors.Is(err, errDisconnect):
+				logger.Error("Error handling MQTT packet", "error", err, "clientId", connect.ClientID, "type", p.Type)
+			}
+			logger.Debug("MQTT client disconnected", "clientId", connect.ClientID, "elapsed", time.Since(started))
+			return
+		}
+	}
+}
+
+func (g *Gateway) authenticate(ctx context.Context, connect connectPacket) (identity.Requester, byte) {
+	if connect.ProtocolName != "MQTT" && connect.ProtocolName != "MQIsdp" {
+		return nil, connackUnacceptableProtocolVersion
+	}
+	if connect.ProtocolLevel != 3 && connect.ProtocolLevel != 4 {
+		return nil, connackUnacceptableProtocolVersion
+	}
+	if len(connect.Password) == 0 {
+		return nil, connackBadUsernameOrPassword
+	}
+	user, err := g.authenticator.Authenticate(ctx, string(connect.Password))
+	if err != nil {
+		logger.Debug("MQTT client authentication failed", "error", err, "clientId", connect.ClientID)
+		return nil, connackBadUsernameOrPassword
+	}
+	// Same requirement as for HTTP and WebSocket push endpoints.
+	if !user.HasRole(org.RoleAdmin) {
+		return nil, connackNotAuthorized
+	}
+	return user, connackAccepted
+}
+
+var (
+	errDisconnect     = errors.New("client disconnected")
+	errAccessDenied   = errors.New("access denied")
+	errSessionRevoked = errors.New("session revoked")
+)
+
+// session keeps state of an authenticated client connection.
+type session struct {
+	token string
+	user  identity.Requester
+	// access caches channel access checks by scope until accessExpires. Then
+	// the client is authenticated again, so that revoked tokens and permission
+	// changes apply to open connections.
+	access        map[string]bool
+	accessExpires time.Time
+}
+
+func newSession(token string, user identity.Requester) *session {
+	return &session{
+		token:         token,
+		user:          user,
+		access:        map[string]bool{},
+		accessExpires: time.Now().Add(DefaultAccessCacheTTL),
+	}
+}
+
+// refresh authenticates the client again once cached access decisions expire.
+func (g *Gateway) refresh(ctx context.Context, s *session) error {
+	if time.Now().Before(s.accessExpires) {
+		return nil
+	}
+	user, err := g.authenticator.Authenticate(ctx, s.token)
+	if err != nil {
+		return fmt.Errorf("%w: %v", errSessionRevoked, err)
+	}
+	if !user.HasRole(org.RoleAdmin) {
+		return fmt.Errorf("%w: admin role required", errSessionRevoked)
+	}
+	s.user = user
+	s.access = map[string]bool{}
+	s.accessExpires = time.Now().Add(DefaultAccessCacheTTL)
+	return nil
+}
+
+func (g *Gateway) checkAccess(ctx context.Context, s *session, scope string) error {
+	if err := g.refresh(ctx, s); err != nil {
+		return err
+	}
+	allowed, ok := s.access[scope]
+	if !ok {
+		var err error
+		allowed, err = g.GrafanaLive.CheckChannelAccess(ctx, s.user, live.ActionPublish, scope, "mqtt")
+		if err != nil {
+			return err
+		}
+		s.access[scope] = allowed
+	}
+	if !allowed {
+		return fmt.Errorf("%w: %s", errAccessDenied, scope)
+	}
+	return nil
+}
+
+func (g *Gateway) handlePacket(ctx context.Context, conn net.Conn, s *session, p packet) error {
+	switch p.Type {
+	case packetPublish:
+		pub, err := decodePublish(p)
+		if err != nil {
+			return err
+		}
+		if err := g.handlePublish(ctx, s, pub); err != nil {
+			if errors.Is(err, errSessionRevoked) {
+				return err
+			}
+			if errors.Is(err, errInvalidMessage) || errors.Is(err, errAccessDenied) {
+				logger.Warn("Rejected MQTT publication", "error", err, "topic", pub.Topic)
+			} else {
+				logger.Error("Error processing MQTT publication", "error", err, "topic", pub.Topic)
+			}
+		}
+		switch pub.QoS {
+		case 1:
+			return writePacketID(conn, packetPuback, 0, pub.PacketID)
+		case 2:
+			return writePacketID(conn, packetPubrec, 0, pub.PacketID)
+		}
+		return nil
+	case packetPubrel:
+		packetID, err := decodePacketID(p)
+		if err != nil {
+			return err
+		}
+		return writePacketID(conn, packetPubcomp, 0, packetID)
+	case packetSubscribe:
+		packetID, n, err := decodeSubscribe(p)
+		if err != nil {
+			return err
+		}
+		body := make([]byte, 2, 2+n)
+		body[0], body[1] = byte(packetID>>8), byte(packetID)
+		for i := 0; i < n; i++ {
+			body = append(body, subackFailure)
+		}
+		return writePacket(conn, packetSuback, 0, body)
+	case packetUnsubscribe:
+		packetID, err := decodePacketID(p)
+		if err != nil {
+			return err
+		}
+		return writePacketID(conn, packetUnsuback, 0, packetID)
+	case packetPingreq:
+		return writePacket(conn, packetPingresp, 0, nil)
+	case packetDisconnect:
+		return errDisconnect
+	default:
+		return fmt.Errorf("%w: %d", errUnsupportedPacket, p.Type)
+	}
+}
+
+func (g *Gateway) handlePublish(ctx context.Context, s *session, pub publishPacket) error {
+	channelID, channel, err := topicToChannel(pub.Topic)
+	if err != nil {
+		return err
+	}
+	logger.Debug("Live Push request",
+		"protocol", "mqtt",
+		"channel", channelID,
+		"bodyLength", len(pub.Payload),
+	)
+
+	if g.GrafanaLive.Pipeline != nil {
+		_, ruleFound, err := g.GrafanaLive.Pipeline.Get(s.user.GetOrgID(), channelID)
+		if err != nil {
+			return err
+		}
+		if ruleFound {
+			if err := g.checkAccess(ctx, s, live.ScopeChannel(channelID)); err != nil {
+				return err
+			}
+			_, err := g.GrafanaLive.Pipeline.ProcessInput(ctx, s.user.GetOrgID(), channelID, pub.Payload)
+			return err
+		}
+	}
+
+	if err := g.checkAccess(ctx, s, live.ScopeChannelNamespace(liveDto.ScopeStream, channel.Namespace)); err != nil {
+		return err
+	}
+	stream, err := g.GrafanaLive.ManagedStreamRunner.GetOrCreateStream(s.user.GetOrgID(), liveDto.ScopeStream, channel.Namespace)
+	if err != nil {
+		return err
+	}
+
+	frameFormat := g.Cfg.LiveMQTTFrameFormat
+	if frameFormat == "" {
+		frameFormat = "labels_column"
+	}
+	metricFrames, err := g.converter.Convert(pub.Payload, frameFormat)
+	if err != nil {
+		return fmt.Errorf("%w: %v", errInvalidMessage, err)
+	}
+	for _, mf := range metricFrames {
+		path := channel.Path
+		if path == "" {
+			path = mf.Key()
+		}
+		if err := stream.Push(ctx, path, mf.Frame()); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// topicToChannel maps an MQTT topic name to a Live channel in the stream scope.
+func topicToChannel(topic string) (string, liveDto.Channel, error) {
+	if topic == "" || strings.ContainsAny(topic, "+#") {
+		return "", liveDto.Channel{}, fmt.Errorf("%w: bad topic name %q", errInvalidMessage, topic)
+	}
+	channelID := strings.TrimPrefix(topic, "/")
+	if !strings.HasPrefix(channelID, liveDto.ScopeStream+"/") {
+		channelID = liveDto.ScopeStream + "/" + channelID
+	}
+	channel, err := liveDto.ParseChannel(channelID)
+	if err != nil {
+		return "", liveDto.Channel{}, fmt.Errorf("%w: topic %q is not a valid stream channel: %v", errInvalidMessage, topic, err)
+	}
+	return channelID, channel, nil
+}
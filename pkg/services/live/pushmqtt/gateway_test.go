@@ -0,0 +1,269 @@
+package pushmqtt
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/binary"
+	"errors"
+	"net"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/services/auth/identity"
+	"github.com/grafana/grafana/pkg/services/live"
+	"github.com/grafana/grafana/pkg/services/live/managedstream"
+	"github.com/grafana/grafana/pkg/services/org"
+	"github.com/grafana/grafana/pkg/services/user"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+type testAuthenticator struct {
+	tokens map[string]*user.SignedInUser
+}
+
+func (a *testAuthenticator) Authenticate(_ context.Context, token string) (identity.Requester, error) {
+	u, ok := a.tokens[token]
+	if !ok {
+		return nil, errors.New("invalid token")
+	}
+	return u, nil
+}
+
+type publication struct {
+	orgID   int64
+	channel string
+}
+
+type testPublisher struct {
+	mu           sync.Mutex
+	publications []publication
+	err          error
+}
+
+func (p *testPublisher) publish(orgID int64, channel string, _ []byte) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.err != nil {
+		return p.err
+	}
+	p.publications = append(p.publications, publication{orgID: orgID, channel: channel})
+	return nil
+}
+
+func setupGateway(t *testing.T) (net.Conn, *testPublisher) {
+	t.Helper()
+
+	publisher := &testPublisher{}
+	g := NewGateway(setting.NewCfg(), &live.GrafanaLive{
+		ManagedStreamRunner: managedstream.NewRunner(publisher.publish, nil, managedstream.NewMemoryFrameCache()),
+	}, &testAuthenticator{tokens: map[string]*user.SignedInUser{
+		"admin-token":  {OrgID: 2, OrgRole: org.RoleAdmin},
+		"viewer-token": {OrgID: 2, OrgRole: org.RoleViewer},
+	}})
+
+	client, server := net.Pipe()
+	go g.serveConn(context.Background(), server)
+	t.Cleanup(func() { _ = client.Close() })
+	return client, publisher
+}
+
+func encodeString(s string) []byte {
+	out := make([]byte, 2, 2+len(s))
+	binary.BigEndian.PutUint16(out, uint16(len(s)))
+	return append(out, s...)
+}
+
+func connectBody(password string) []byte {
+	var body []byte
+	body = append(body, encodeString("MQTT")...)
+	body = append(body, 4)              // protocol level
+	body = append(body, 0x80|0x40|0x02) // username, password, clean session
+	body = append(body, 0, 60)          // keep alive
+	body = append(body, encodeString("device-1")...)
+	body = append(body, encodeString("grafana")...)
+	body = append(body, encodeString(password)...)
+	return body
+}
+
+func publishBody(topic string, packetID uint16, payload string) []byte {
+	body := encodeString(topic)
+	if packetID > 0 {
+		body = binary.BigEndian.AppendUint16(body, packetID)
+	}
+	return append(body, payload...)
+}
+
+func connect(t *testing.T, conn net.Conn, password string) byte {
+	t.Helper()
+	require.NoError(t, writePacket(conn, packetConnect, 0, connectBody(password)))
+	p, err := readPacket(bufio.NewReader(conn), 0)
+	require.NoError(t, err)
+	require.Equal(t, packetConnack, p.Type)
+	require.Len(t, p.Body, 2)
+	return p.Body[1]
+}
+
+func TestGateway_Connect(t *testing.T) {
+	t.Run("accepts admin token", func(t *testing.T) {
+		conn, _ := setupGateway(t)
+		require.Equal(t, connackAccepted, connect(t, conn, "admin-token"))
+	})
+
+	t.Run("rejects unknown token", func(t *testing.T) {
+		conn, _ := setupGateway(t)
+		require.Equal(t, connackBadUsernameOrPassword, connect(t, conn, "unknown"))
+	})
+
+	t.Run("rejects non admin token", func(t *testing.T) {
+		conn, _ := setupGateway(t)
+		require.Equal(t, connackNotAuthorized, connect(t, conn, "viewer-token"))
+	})
+}
+
+func TestGateway_Publish(t *testing.T) {
+	conn, publisher := setupGateway(t)
+	require.Equal(t, connackAccepted, connect(t, conn, "admin-token"))
+	r := bufio.NewReader(conn)
+
+	payload := "cpu,host=a usage=1 1624000000000000000"
+	require.NoError(t, writePacket(conn, packetPublish, 1<<1, publishBody("factory-1", 7, payload)))
+
+	p, err := readPacket(r, 0)
+	require.NoError(t, err)
+	require.Equal(t, packetPuback, p.Type)
+	packetID, err := decodePacketID(p)
+	require.NoError(t, err)
+	require.Equal(t, uint16(7), packetID)
+
+	require.NoError(t, writePacket(conn, packetPingreq, 0, nil))
+	p, err = readPacket(r, 0)
+	require.NoError(t, err)
+	require.Equal(t, packetPingresp, p.Type)
+
+	publisher.mu.Lock()
+	defer publisher.mu.Unlock()
+	require.Equal(t, []publication{{orgID: 2, channel: "stream/factory-1/cpu"}}, publisher.publications)
+}
+
+func TestGateway_PublishTopicPath(t *testing.T) {
+	conn, publisher := setupGateway(t)
+	require.Equal(t, connackAccepted, connect(t, conn, "admin-token"))
+	r := bufio.NewReader(conn)
+
+	payload := "cpu,host=a usage=1 1624000000000000000\nmem,host=a used=2 1624000000000000000"
+	require.NoError(t, writePacket(conn, packetPublish, 1<<1, publishBody("factory-1/line-a/temperature", 7, payload)))
+
+	p, err := readPacket(r, 0)
+	require.NoError(t, err)
+	require.Equal(t, packetPuback, p.Type)
+
+	publisher.mu.Lock()
+	defer publisher.mu.Unlock()
+	require.Equal(t, []publication{
+		{orgID: 2, channel: "stream/factory-1/line-a/temperature"},
+		{orgID: 2, channel: "stream/factory-1/line-a/temperature"},
+	}, publisher.publications)
+}
+
+func TestGateway_PublishErrorKeepsConnection(t *testing.T) {
+	conn, publisher := setupGateway(t)
+	require.Equal(t, connackAccepted, connect(t, conn, "admin-token"))
+	r := bufio.NewReader(conn)
+
+	publisher.mu.Lock()
+	publisher.err = errors.New("boom")
+	publisher.mu.Unlock()
+
+	payload := "cpu,host=a usage=1 1624000000000000000"
+	require.NoError(t, writePacket(conn, packetPublish, 1<<1, publishBody("factory-1", 7, payload)))
+	p, err := readPacket(r, 0)
+	require.NoError(t, err)
+	require.Equal(t, packetPuback, p.Type)
+
+	require.NoError(t, writePacket(conn, packetPingreq, 0, nil))
+	p, err = readPacket(r, 0)
+	require.NoError(t, err)
+	require.Equal(t, packetPingresp, p.Type)
+}
+
+func TestGateway_SubscribeRejected(t *testing.T) {
+	conn, _ := setupGateway(t)
+	require.Equal(t, connackAccepted, connect(t, conn, "admin-token"))
+
+	body := []byte{0, 3}
+	body = append(body, encodeString("factory-1/#")...)
+	body = append(body, 0)
+	require.NoError(t, writePacket(conn, packetSubscribe, 0x02, body))
+
+	p, err := readPacket(bufio.NewReader(conn), 0)
+	require.NoError(t, err)
+	require.Equal(t, packetSuback, p.Type)
+	require.Equal(t, []byte{0, 3, subackFailure}, p.Body)
+}
+
+func TestGateway_RefreshSession(t *testing.T) {
+	authenticator := &testAuthenticator{tokens: map[string]*user.SignedInUser{
+		"admin-token": {OrgID: 2, OrgRole: org.RoleAdmin},
+	}}
+	g := NewGateway(setting.NewCfg(), &live.GrafanaLive{}, authenticator)
+
+	s := newSession("admin-token", authenticator.tokens["admin-token"])
+	s.access["live:channels:stream/factory-1/*"] = true
+	require.NoError(t, g.refresh(context.Background(), s))
+	require.Len(t, s.access, 1, "decisions are reused before they expire")
+
+	updated := &user.SignedInUser{OrgID: 2, OrgRole: org.RoleAdmin, Login: "updated"}
+	authenticator.tokens["admin-token"] = updated
+	s.accessExpires = time.Now().Add(-time.Second)
+	require.NoError(t, g.refresh(context.Background(), s))
+	require.Empty(t, s.access)
+	require.Same(t, updated, s.user)
+	require.True(t, s.accessExpires.After(time.Now()))
+
+	delete(authenticator.tokens, "admin-token")
+	s.accessExpires = time.Now().Add(-time.Second)
+	require.ErrorIs(t, g.refresh(context.Background(), s), errSessionRevoked)
+
+	authenticator.tokens["admin-token"] = &user.SignedInUser{OrgID: 2, OrgRole: org.RoleViewer}
+	require.ErrorIs(t, g.refresh(context.Background(), s), errSessionRevoked)
+}
+
+func TestTopicToChannel(t *testing.T) {
+	tests := []struct {
+		topic   string
+		channel string
+		invalid bool
+	}{
+		{topic: "factory-1", channel: "stream/factory-1"},
+		{topic: "factory-1/temperature", channel: "stream/factory-1/temperature"},
+		{topic: "/factory-1/temperature", channel: "stream/factory-1/temperature"},
+		{topic: "stream/factory-1/temperature", channel: "stream/factory-1/temperature"},
+		{topic: "factory-1/+", invalid: true},
+		{topic: "factory-1/#", invalid: true},
+		{topic: "", invalid: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.topic, func(t *testing.T) {
+			channelID, _, err := topicToChannel(tt.topic)
+			if tt.invalid {
+				require.ErrorIs(t, err, errInvalidMessage)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tt.channel, channelID)
+		})
+	}
+}
+
+func TestRemainingLength(t *testing.T) {
+	for _, length := range []int{0, 127, 128, 16383, 16384, 2097151, 2097152, 268435455} {
+		encoded := encodeRemainingLength(length)
+		decoded, err := readRemainingLength(bytes.NewReader(encoded))
+		require.NoError(t, err)
+		require.Equal(t, length, decoded)
+	}
+}
@@ -0,0 +1,292 @@
+package pushmqtt
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+)
+
+// Control packet types as defined by MQTT 3.1.1 (section 2.2.1).
+const (
+	packetConnect     byte = 1
+	packetConnack     byte = 2
+	packetPublish     byte = 3
+	packetPuback      byte = 4
+	packetPubrec      byte = 5
+	packetPubrel      byte = 6
+	packetPubcomp     byte = 7
+	packetSubscribe   byte = 8
+	packetSuback      byte = 9
+	packetUnsubscribe byte = 10
+	packetUnsuback    byte = 11
+	packetPingreq     byte = 12
+	packetPingresp    byte = 13
+	packetDisconnect  byte = 14
+)
+
+// CONNACK return codes.
+const (
+	connackAccepted                    byte = 0x00
+	connackUnacceptableProtocolVersion byte = 0x01
+	connackIdentifierRejected          byte = 0x02
+	connackBadUsernameOrPassword       byte = 0x04
+	connackNotAuthorized               byte = 0x05
+)
+
+// subackFailure is returned for every topic filter in SUBSCRIBE since the
+// gateway only ingests data and does not deliver messages back to clients.
+const subackFailure byte = 0x80
+
+var (
+	errMalformedPacket   = errors.New("malformed MQTT packet")
+	errPacketTooLarge    = errors.New("MQTT packet exceeds message size limit")
+	errUnsupportedPacket = errors.New("unsupported MQTT packet type")
+)
+
+// packet is a raw MQTT control packet split into its fixed header and body.
+type packet struct {
+	Type  byte
+	Flags byte
+	Body  []byte
+}
+
+// connectPacket holds the parts of CONNECT the gateway cares about.
+type connectPacket struct {
+	ProtocolName  string
+	ProtocolLevel byte
+	KeepAlive     uint16
+	ClientID      string
+	Username      string
+	Password      []byte
+}
+
+// publishPacket is a decoded PUBLISH.
+type publishPacket struct {
+	Topic    string
+	QoS      byte
+	Retain   bool
+	PacketID uint16
+	Payload  []byte
+}
+
+func readPacket(r *bufio.Reader, sizeLimit int) (packet, error) {
+	header, err := r.ReadByte()
+	if err != nil {
+		return packet{}, err
+	}
+	length, err := readRemainingLength(r)
+	if err != nil {
+		return packet{}, err
+	}
+	if sizeLimit > 0 && length > sizeLimit {
+		return packet{}, errPacketTooLarge
+	}
+	body := make([]byte, length)
+	if _, err := io.ReadFull(r, body); err != nil {
+		return packet{}, err
+	}
+	return packet{Type: header >> 4, Flags: header & 0x0f, Body: body}, nil
+}
+
+// readRemainingLength decodes the variable length integer from the fixed header.
+func readRemainingLength(r io.ByteReader) (int, error) {
+	var value, multiplier = 0, 1
+	for i := 0; i < 4; i++ {
+		b, err := r.ReadByte()
+		if err != nil {
+			return 0, err
+		}
+		value += int(b&0x7f) * multiplier
+		if b&0x80 == 0 {
+			return value, nil
+		}
+		multiplier *= 128
+	}
+	return 0, errMalformedPacket
+}
+
+func encodeRemainingLength(length int) []byte {
+	var out []byte
+	for {
+		b := byte(length % 128)
+		length /= 128
+		if length > 0 {
+			b |= 0x80
+		}
+		out = append(out, b)
+		if length == 0 {
+			return out
+		}
+	}
+}
+
+func writePacket(w io.Writer, packetType byte, flags byte, body []byte) error {
+	buf := make([]byte, 0, len(body)+5)
+	buf = append(buf, packetType<<4|flags&0x0f)
+	buf = append(buf, encodeRemainingLength(len(body))...)
+	buf = append(buf, body...)
+	_, err := w.Write(buf)
+	return err
+}
+
+func writeConnack(w io.Writer, returnCode byte) error {
+	return writePacket(w, packetConnack, 0, []byte{0, returnCode})
+}
+
+func writePacketID(w io.Writer, packetType byte, flags byte, packetID uint16) error {
+	body := make([]byte, 2)
+	binary.BigEndian.PutUint16(body, packetID)
+	return writePacket(w, packetType, flags, body)
+}
+
+// bodyReader walks over a packet body decoding MQTT primitive types.
+type bodyReader struct {
+	buf []byte
+	pos int
+}
+
+func (b *bodyReader) remaining() int {
+	return len(b.buf) - b.pos
+}
+
+func (b *bodyReader) byte() (byte, error) {
+	if b.remaining() < 1 {
+		return 0, errMalformedPacket
+	}
+	v := b.buf[b.pos]
+	b.pos++
+	return v, nil
+}
+
+func (b *bodyReader) uint16() (uint16, error) {
+	if b.remaining() < 2 {
+		return 0, errMalformedPacket
+	}
+	v := binary.BigEndian.Uint16(b.buf[b.pos:])
+	b.pos += 2
+	return v, nil
+}
+
+func (b *bodyReader) bytes() ([]byte, error) {
+	n, err := b.uint16()
+	if err != nil {
+		return nil, err
+	}
+	if b.remaining() < int(n) {
+		return nil, errMalformedPacket
+	}
+	v := b.buf[b.pos : b.pos+int(n)]
+	b.pos += int(n)
+	return v, nil
+}
+
+func (b *bodyReader) string() (string, error) {
+	v, err := b.bytes()
+	return string(v), err
+}
+
+func (b *bodyReader) rest() []byte {
+	v := b.buf[b.pos:]
+	b.pos = len(b.buf)
+	return v
+}
+
+func decodeConnect(p packet) (connectPacket, error) {
+	var c connectPacket
+	r := &bodyReader{buf: p.Body}
+
+	var err error
+	if c.ProtocolName, err = r.string(); err != nil {
+		return c, err
+	}
+	if c.ProtocolLevel, err = r.byte(); err != nil {
+		return c, err
+	}
+	flags, err := r.byte()
+	if err != nil {
+		return c, err
+	}
+	if flags&0x01 != 0 {
+		// Reserved flag must be zero.
+		return c, errMalformedPacket
+	}
+	if c.KeepAlive, err = r.uint16(); err != nil {
+		return c, err
+	}
+	if c.ClientID, err = r.string(); err != nil {
+		return c, err
+	}
+	if flags&0x04 != 0 {
+		// Will topic and message are accepted but ignored.
+		if _, err := r.string(); err != nil {
+			return c, err
+		}
+		if _, err := r.bytes(); err != nil {
+			return c, err
+		}
+	}
+	if flags&0x80 != 0 {
+		if c.Username, err = r.string(); err != nil {
+			return c, err
+		}
+	}
+	if flags&0x40 != 0 {
+		if c.Password, err = r.bytes(); err != nil {
+			return c, err
+		}
+	}
+	return c, nil
+}
+
+func decodePublish(p packet) (publishPacket, error) {
+	pub := publishPacket{
+		QoS:    (p.Flags >> 1) & 0x03,
+		Retain: p.Flags&0x01 != 0,
+	}
+	if pub.QoS > 2 {
+		return pub, errMalformedPacket
+	}
+	r := &bodyReader{buf: p.Body}
+	var err error
+	if pub.Topic, err = r.string(); err != nil {
+		return pub, err
+	}
+	if pub.QoS > 0 {
+		if pub.PacketID, err = r.uint16(); err != nil {
+			return pub, err
+		}
+	}
+	pub.Payload = r.rest()
+	return pub, nil
+}
+
+// decodeSubscribe returns the packet identifier and the number of topic
+// filters in a SUBSCRIBE packet.
+func decodeSubscribe(p packet) (uint16, int, error) {
+	r := &bodyReader{buf: p.Body}
+	packetID, err := r.uint16()
+	if err != nil {
+		return 0, 0, err
+	}
+	var n int
+	for r.remaining() > 0 {
+		if _, err := r.string(); err != nil {
+			return 0, 0, err
+		}
+		if _, err := r.byte(); err != nil {
+			return 0, 0, err
+		}
+		n++
+	}
+	if n == 0 {
+		return 0, 0, fmt.Errorf("%w: SUBSCRIBE without topic filters", errMalformedPacket)
+	}
+	return packetID, n, nil
+}
+
+func decodePacketID(p packet) (uint16, error) {
+	r := &bodyReader{buf: p.Body}
+	return r.uint16()
+}
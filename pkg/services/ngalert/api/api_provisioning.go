@@ -11,6 +11,7 @@ import (
 	"github.com/grafana/grafana/pkg/infra/log"
 	"github.com/grafana/grafana/pkg/services/auth/identity"
 	contextmodel "github.com/grafana/grafana/pkg/services/contexthandler/model"
+	"github.com/grafana/grafana/pkg/services/datasources"
 	"github.com/grafana/grafana/pkg/services/featuremgmt"
 	"github.com/grafana/grafana/pkg/services/folder"
 	"github.com/grafana/grafana/pkg/services/ngalert/api/hcl"
@@ -31,6 +32,7 @@ type ProvisioningSrv struct {
 	muteTimings         MuteTimingService
 	alertRules          AlertRuleService
 	folderSvc           folder.Service
+	dataSourceCache     datasources.CacheService
 
 	// XXX: Used to flag recording rules, remove when FT is removed
 	featureManager featuremgmt.FeatureToggles
@@ -349,6 +351,10 @@ func (srv *ProvisioningSrv) RoutePostAlertRule(c *contextmodel.ReqContext, ar de
 		)
 	}
 
+	if err := srv.validateLabelPolicies(c, upstreamModel); err != nil {
+		return response.ErrOrFallback(http.StatusBadRequest, "failed to validate label policies", err)
+	}
+
 	provenance := determineProvenance(c)
 	createdAlertRule, err := srv.alertRules.CreateAlertRule(c.Req.Context(), c.SignedInUser, upstreamModel, alerting_models.Provenance(provenance))
 	if errors.Is(err, alerting_models.ErrAlertRuleFailedValidation) {
@@ -387,6 +393,10 @@ func (srv *ProvisioningSrv) RoutePutAlertRule(c *contextmodel.ReqContext, ar def
 
 	updated.OrgID = c.SignedInUser.GetOrgID()
 	updated.UID = UID
+	if err := srv.validateLabelPolicies(c, updated); err != nil {
+		return response.ErrOrFallback(http.StatusBadRequest, "failed to validate label policies", err)
+	}
+
 	provenance := determineProvenance(c)
 	updatedAlertRule, err := srv.alertRules.UpdateAlertRule(c.Req.Context(), c.SignedInUser, updated, alerting_models.Provenance(provenance))
 	if errors.Is(err, alerting_models.ErrAlertRuleUniqueConstraintViolation) {
@@ -505,6 +515,11 @@ func (srv *ProvisioningSrv) RoutePutAlertRuleGroup(c *contextmodel.ReqContext, a
 	if err != nil {
 		ErrResp(http.StatusBadRequest, err, "")
 	}
+	for _, rule := range groupModel.Rules {
+		if err := srv.validateLabelPolicies(c, rule); err != nil {
+			return response.ErrOrFallback(http.StatusBadRequest, "failed to validate label policies", err)
+		}
+	}
 	provenance := determineProvenance(c)
 	err = srv.alertRules.ReplaceRuleGroup(c.Req.Context(), c.SignedInUser, groupModel, alerting_models.Provenance(provenance))
 	if errors.Is(err, alerting_models.ErrAlertRuleUniqueConstraintViolation) {
@@ -531,6 +546,15 @@ func (srv *ProvisioningSrv) RouteDeleteAlertRuleGroup(c *contextmodel.ReqContext
 	return response.JSON(http.StatusNoContent, "")
 }
 
+// validateLabelPolicies checks that the queries of a rule include the label policies of the teams of the user,
+// like rules saved with the ruler API.
+func (srv *ProvisioningSrv) validateLabelPolicies(c *contextmodel.ReqContext, rule alerting_models.AlertRule) error {
+	if !srv.featureManager.IsEnabled(c.Req.Context(), featuremgmt.FlagTeamHttpHeaders) {
+		return nil
+	}
+	return validateLabelPolicies(c.Req.Context(), srv.dataSourceCache, c.SignedInUser, &rule)
+}
+
 func determineProvenance(ctx *contextmodel.ReqContext) definitions.Provenance {
 	if _, disabled := ctx.Req.Header[disableProvenanceHeaderName]; disabled {
 		return definitions.Provenance(alerting_models.ProvenanceNone)
@@ -17,6 +17,7 @@ import (
 	"github.com/grafana/grafana/pkg/services/auth/identity"
 	contextmodel "github.com/grafana/grafana/pkg/services/contexthandler/model"
 	"github.com/grafana/grafana/pkg/services/dashboards"
+	"github.com/grafana/grafana/pkg/services/datasources"
 	"github.com/grafana/grafana/pkg/services/featuremgmt"
 	authz "github.com/grafana/grafana/pkg/services/ngalert/accesscontrol"
 	apimodels "github.com/grafana/grafana/pkg/services/ngalert/api/tooling/definitions"
@@ -54,9 +55,10 @@ type RulerSrv struct {
 	conditionValidator ConditionValidator
 	authz              RuleAccessControlService
 
-	amConfigStore  AMConfigStore
-	amRefresher    AMRefresher
-	featureManager featuremgmt.FeatureToggles
+	amConfigStore   AMConfigStore
+	amRefresher     AMRefresher
+	featureManager  featuremgmt.FeatureToggles
+	dataSourceCache datasources.CacheService
 }
 
 var (
@@ -330,6 +332,14 @@ func (srv RulerSrv) RoutePostNameRulesConfig(c *contextmodel.ReqContext, ruleGro
 		return ErrResp(http.StatusBadRequest, err, "")
 	}
 
+	if srv.featureManager.IsEnabled(c.Req.Context(), featuremgmt.FlagTeamHttpHeaders) {
+		for _, rule := range rules {
+			if err := validateLabelPolicies(c.Req.Context(), srv.dataSourceCache, c.SignedInUser, &rule.AlertRule); err != nil {
+				return response.ErrOrFallback(http.StatusBadRequest, "failed to validate label policies", err)
+			}
+		}
+	}
+
 	groupKey := ngmodels.AlertRuleGroupKey{
 		OrgID:        c.SignedInUser.GetOrgID(),
 		NamespaceUID: namespace.UID,
@@ -560,6 +570,7 @@ func toGettableExtendedRuleNode(r ngmodels.AlertRule, provenanceRecords map[stri
 			IsPaused:             r.IsPaused,
 			NotificationSettings: AlertRuleNotificationSettingsFromNotificationSettings(r.NotificationSettings),
 			Record:               ApiRecordFromModelRecord(r.Record),
+			LiveChannel:          r.LiveChannel,
 		},
 	}
 	forDuration := model.Duration(r.For)
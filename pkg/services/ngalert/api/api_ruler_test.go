@@ -10,6 +10,7 @@ import (
 	"net/http/httptest"
 	"net/url"
 	"slices"
+	"strconv"
 	"testing"
 	"time"
 
@@ -17,12 +18,15 @@ import (
 	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/require"
 
+	"github.com/grafana/grafana/pkg/components/simplejson"
 	"github.com/grafana/grafana/pkg/infra/log"
 	ac "github.com/grafana/grafana/pkg/services/accesscontrol"
 	"github.com/grafana/grafana/pkg/services/accesscontrol/acimpl"
 	contextmodel "github.com/grafana/grafana/pkg/services/contexthandler/model"
 	"github.com/grafana/grafana/pkg/services/dashboards"
 	"github.com/grafana/grafana/pkg/services/datasources"
+	dsfakes "github.com/grafana/grafana/pkg/services/datasources/fakes"
+	"github.com/grafana/grafana/pkg/services/datasources/lbac"
 	"github.com/grafana/grafana/pkg/services/featuremgmt"
 	"github.com/grafana/grafana/pkg/services/folder"
 	"github.com/grafana/grafana/pkg/services/ngalert/accesscontrol"
@@ -712,3 +716,45 @@ func createPermissionsForRules(rules []*models.AlertRule, orgID int64) map[int64
 	}
 	return map[int64]map[string][]string{orgID: permissions}
 }
+
+func TestValidateLabelPolicies(t *testing.T) {
+	ds := &datasources.DataSource{
+		UID:  "prom",
+		Type: datasources.DS_PROMETHEUS,
+		JsonData: simplejson.NewFromAny(map[string]any{
+			"teamHttpHeaders": map[string]any{
+				"headers": map[string]any{
+					"1": []any{map[string]any{"header": "X-Prom-Label-Policy", "value": `1:{namespace="team-a"}`}},
+				},
+			},
+		}),
+	}
+	cache := &dsfakes.FakeCacheService{DataSources: []*datasources.DataSource{ds}}
+	rule := func(expr string) *models.AlertRule {
+		return &models.AlertRule{
+			Title: "rule",
+			Data: []models.AlertQuery{
+				{RefID: "A", DatasourceUID: "prom", Model: json.RawMessage(`{"refId":"A","expr":` + strconv.Quote(expr) + `}`)},
+				{RefID: "B", DatasourceUID: "__expr__", Model: json.RawMessage(`{"refId":"B","type":"threshold","expression":"A"}`)},
+			},
+		}
+	}
+	usr := &user.SignedInUser{OrgID: 1, Teams: []int64{1}}
+
+	t.Run("rejects queries without the matchers of the policies", func(t *testing.T) {
+		r := rule("rate(errors_total[5m])")
+		err := validateLabelPolicies(context.Background(), cache, usr, r)
+		require.ErrorIs(t, err, lbac.ErrPolicyNotApplied)
+		require.JSONEq(t, `{"refId":"A","expr":"rate(errors_total[5m])"}`, string(r.Data[0].Model))
+	})
+
+	t.Run("accepts queries with the matchers of the policies", func(t *testing.T) {
+		err := validateLabelPolicies(context.Background(), cache, usr, rule(`rate(errors_total{namespace="team-a"}[5m])`))
+		require.NoError(t, err)
+	})
+
+	t.Run("accepts any query of users without policy", func(t *testing.T) {
+		err := validateLabelPolicies(context.Background(), cache, &user.SignedInUser{OrgID: 1}, rule("up"))
+		require.NoError(t, err)
+	})
+}
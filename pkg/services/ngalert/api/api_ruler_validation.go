@@ -1,12 +1,17 @@
 package api
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"sort"
 	"strings"
 	"time"
 
+	"github.com/grafana/grafana/pkg/expr"
+	"github.com/grafana/grafana/pkg/services/auth/identity"
+	"github.com/grafana/grafana/pkg/services/datasources"
+	"github.com/grafana/grafana/pkg/services/datasources/lbac"
 	"github.com/grafana/grafana/pkg/services/featuremgmt"
 	apimodels "github.com/grafana/grafana/pkg/services/ngalert/api/tooling/definitions"
 	ngmodels "github.com/grafana/grafana/pkg/services/ngalert/models"
@@ -144,6 +149,11 @@ func validateAlertingRuleFields(in *apimodels.PostableExtendedRuleNode, newRule
 		}
 	}
 
+	if err := ngmodels.ValidateLiveChannel(in.GrafanaManagedAlert.LiveChannel); err != nil {
+		return ngmodels.AlertRule{}, err
+	}
+	newRule.LiveChannel = in.GrafanaManagedAlert.LiveChannel
+
 	newRule.For, err = validateForInterval(in)
 	if err != nil {
 		return ngmodels.AlertRule{}, err
@@ -178,6 +188,7 @@ func validateRecordingRuleFields(in *apimodels.PostableExtendedRuleNode, newRule
 	newRule.Condition = ""
 	newRule.For = 0
 	newRule.NotificationSettings = nil
+	newRule.LiveChannel = ""
 
 	return newRule, nil
 }
@@ -344,3 +355,26 @@ func validateNotificationSettings(n *apimodels.AlertRuleNotificationSettings) ([
 		s,
 	}, nil
 }
+
+// validateLabelPolicies checks that the queries of a rule include the label policies of the teams of the user.
+// Queries made on behalf of a user are restricted by the plugin client, but scheduled evaluations of rules don't
+// run as a user, so the queries are checked when the rule is saved and stored as they are.
+func validateLabelPolicies(ctx context.Context, cache datasources.CacheService, user identity.Requester, rule *ngmodels.AlertRule) error {
+	for _, query := range rule.Data {
+		if expr.NodeTypeFromDatasourceUID(query.DatasourceUID) != expr.TypeDatasourceNode {
+			continue
+		}
+		ds, err := cache.GetDatasourceByUID(ctx, query.DatasourceUID, user, false)
+		if err != nil {
+			if errors.Is(err, datasources.ErrDataSourceNotFound) {
+				// rejected by the validation of the data sources of the rule
+				continue
+			}
+			return err
+		}
+		if err := lbac.CheckModel(ds, user, query.Model); err != nil {
+			return fmt.Errorf("rule %q, query %s: %w", rule.Title, query.RefID, err)
+		}
+	}
+	return nil
+}
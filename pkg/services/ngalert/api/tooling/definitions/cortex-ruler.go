@@ -500,6 +500,9 @@ type PostableGrafanaRule struct {
 	IsPaused             *bool                          `json:"is_paused" yaml:"is_paused"`
 	NotificationSettings *AlertRuleNotificationSettings `json:"notification_settings" yaml:"notification_settings"`
 	Record               *Record                        `json:"record" yaml:"record"`
+	// LiveChannel makes the rule a streaming rule evaluated on frames pushed to the Grafana Live channel.
+	// example: stream/factory-1/*
+	LiveChannel string `json:"live_channel,omitempty" yaml:"live_channel,omitempty"`
 }
 
 // swagger:model
@@ -521,6 +524,7 @@ type GettableGrafanaRule struct {
 	IsPaused             bool                           `json:"is_paused" yaml:"is_paused"`
 	NotificationSettings *AlertRuleNotificationSettings `json:"notification_settings,omitempty" yaml:"notification_settings,omitempty"`
 	Record               *Record                        `json:"record,omitempty" yaml:"record,omitempty"`
+	LiveChannel          string                         `json:"live_channel,omitempty" yaml:"live_channel,omitempty"`
 }
 
 // AlertQuery represents a single query associated with an alert definition.
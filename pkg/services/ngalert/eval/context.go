@@ -19,6 +19,9 @@ type EvaluationContext struct {
 	Ctx                   context.Context
 	User                  identity.Requester
 	AlertingResultsReader AlertingResultsReader
+	// StreamingFrame is set when a streaming rule is evaluated for a frame pushed to a Grafana Live channel.
+	// All data source queries of the condition are answered with this frame instead of querying the data source.
+	StreamingFrame *data.Frame
 }
 
 func NewContext(ctx context.Context, user identity.Requester) EvaluationContext {
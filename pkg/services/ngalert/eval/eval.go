@@ -800,7 +800,7 @@ func (e *evaluatorImpl) Validate(ctx EvaluationContext, condition models.Conditi
 		case expr.TypeCMDNode:
 		}
 	}
-	_, err = e.create(condition, req)
+	_, err = e.create(condition, req, e.expressionService)
 	return err
 }
 
@@ -815,11 +815,15 @@ func (e *evaluatorImpl) Create(ctx EvaluationContext, condition models.Condition
 	if err != nil {
 		return nil, err
 	}
-	return e.create(condition, req)
+	expressionService := e.expressionService
+	if ctx.StreamingFrame != nil {
+		expressionService = expressionService.WithDataService(streamingDataHandler{frame: ctx.StreamingFrame})
+	}
+	return e.create(condition, req, expressionService)
 }
 
-func (e *evaluatorImpl) create(condition models.Condition, req *expr.Request) (ConditionEvaluator, error) {
-	pipeline, err := e.expressionService.BuildPipeline(req)
+func (e *evaluatorImpl) create(condition models.Condition, req *expr.Request, expressionService *expr.Service) (ConditionEvaluator, error) {
+	pipeline, err := expressionService.BuildPipeline(req)
 	if err != nil {
 		return nil, err
 	}
@@ -828,7 +832,7 @@ func (e *evaluatorImpl) create(condition models.Condition, req *expr.Request) (C
 		if node.RefID() == condition.Condition {
 			return &conditionEvaluator{
 				pipeline:          pipeline,
-				expressionService: e.expressionService,
+				expressionService: expressionService,
 				condition:         condition,
 				evalTimeout:       e.evaluationTimeout,
 			}, nil
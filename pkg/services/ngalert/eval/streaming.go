@@ -0,0 +1,35 @@
+package eval
+
+import (
+	"context"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+)
+
+// streamingDataHandler answers data source queries with a frame that was pushed to a Grafana Live channel.
+// It lets streaming rules reuse their queries and expressions without querying the data source:
+// every data source query of the rule receives a copy of the frame, and expressions are applied as usual.
+type streamingDataHandler struct {
+	frame *data.Frame
+}
+
+func (h streamingDataHandler) QueryData(_ context.Context, req *backend.QueryDataRequest) (*backend.QueryDataResponse, error) {
+	resp := backend.NewQueryDataResponse()
+	for _, q := range req.Queries {
+		frame := copyFrame(h.frame)
+		frame.RefID = q.RefID
+		resp.Responses[q.RefID] = backend.DataResponse{Frames: data.Frames{frame}}
+	}
+	return resp, nil
+}
+
+// copyFrame returns a deep copy of the frame. The same frame can be evaluated by several rules concurrently,
+// and the expression engine is allowed to modify the frames it receives.
+func copyFrame(frame *data.Frame) *data.Frame {
+	c := frame.EmptyCopy()
+	for i := 0; i < frame.Rows(); i++ {
+		c.AppendRow(frame.RowCopy(i)...)
+	}
+	return c
+}
@@ -0,0 +1,42 @@
+package eval
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStreamingDataHandler(t *testing.T) {
+	ts := time.Unix(1624000000, 0)
+	frame := data.NewFrame("cpu",
+		data.NewField("time", nil, []time.Time{ts, ts.Add(time.Second)}),
+		data.NewField("usage", data.Labels{"host": "a"}, []float64{1, 2}),
+	)
+
+	h := streamingDataHandler{frame: frame}
+	resp, err := h.QueryData(context.Background(), &backend.QueryDataRequest{
+		Queries: []backend.DataQuery{{RefID: "A"}, {RefID: "B"}},
+	})
+	require.NoError(t, err)
+	require.Len(t, resp.Responses, 2)
+
+	for _, refID := range []string{"A", "B"} {
+		r := resp.Responses[refID]
+		require.NoError(t, r.Error)
+		require.Len(t, r.Frames, 1)
+		require.Equal(t, refID, r.Frames[0].RefID)
+		require.Equal(t, 2, r.Frames[0].Rows())
+		require.Equal(t, data.Labels{"host": "a"}, r.Frames[0].Fields[1].Labels)
+	}
+
+	t.Run("frames are copies of the pushed frame", func(t *testing.T) {
+		resp.Responses["A"].Frames[0].Fields[1].Set(0, 10.0)
+		require.Equal(t, 1.0, frame.Fields[1].At(0))
+		require.Equal(t, 1.0, resp.Responses["B"].Frames[0].Fields[1].At(0))
+		require.Empty(t, frame.RefID)
+	})
+}
@@ -0,0 +1,27 @@
+package livestream
+
+import (
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/services/featuremgmt"
+	"github.com/grafana/grafana/pkg/services/live"
+	"github.com/grafana/grafana/pkg/services/ngalert"
+)
+
+var logger = log.New("ngalert.livestream")
+
+// Service connects Grafana Live managed streams to the alerting scheduler. Frames pushed to managed streams
+// trigger the evaluation of streaming alert rules, i.e. rules with a live channel.
+type Service struct{}
+
+func ProvideService(features featuremgmt.FeatureToggles, ng *ngalert.AlertNG, grafanaLive *live.GrafanaLive) *Service {
+	s := &Service{}
+	if !features.IsEnabledGlobally(featuremgmt.FlagAlertingLiveStreamingRules) {
+		return s
+	}
+	if ng.IsDisabled() || grafanaLive.ManagedStreamRunner == nil {
+		logger.Warn("Streaming alert rules are enabled but unified alerting or Grafana Live is not available")
+		return s
+	}
+	grafanaLive.ManagedStreamRunner.AddFrameHandler(ng.ProcessLiveFrame)
+	return s
+}
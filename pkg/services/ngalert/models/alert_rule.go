@@ -16,6 +16,7 @@ import (
 	"github.com/google/go-cmp/cmp/cmpopts"
 
 	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/grafana/grafana-plugin-sdk-go/live"
 	prommodels "github.com/prometheus/common/model"
 
 	alertingModels "github.com/grafana/alerting/models"
@@ -267,6 +268,9 @@ type AlertRule struct {
 	Labels               map[string]string
 	IsPaused             bool
 	NotificationSettings []NotificationSettings `xorm:"notification_settings"` // we use slice to workaround xorm mapping that does not serialize a struct to JSON unless it's a slice
+	// LiveChannel makes the rule a streaming rule. It is the Grafana Live channel, for example `stream/factory-1/cpu`,
+	// or a channel prefix ending with `*`, whose frames trigger the evaluation of the rule.
+	LiveChannel string `xorm:"live_channel"`
 }
 
 // AlertRuleWithOptionals This is to avoid having to pass in additional arguments deep in the call stack. Alert rule
@@ -532,6 +536,13 @@ func (alertRule *AlertRule) ValidateAlertRule(cfg setting.UnifiedAlertingSetting
 		return err
 	}
 
+	if err := ValidateLiveChannel(alertRule.LiveChannel); err != nil {
+		return err
+	}
+	if alertRule.LiveChannel != "" && alertRule.Type() == RuleTypeRecording {
+		return fmt.Errorf("%w: recording rules cannot be streaming rules", ErrAlertRuleFailedValidation)
+	}
+
 	if alertRule.For < 0 {
 		return fmt.Errorf("%w: field `for` cannot be negative", ErrAlertRuleFailedValidation)
 	}
@@ -597,6 +608,35 @@ func (alertRule *AlertRule) GetFolderKey() FolderKey {
 	}
 }
 
+// GetLiveChannel returns the Grafana Live channel the rule is evaluated on, or "" if the rule is not a streaming rule.
+func (alertRule *AlertRule) GetLiveChannel() string {
+	if alertRule.Type() != RuleTypeAlerting {
+		return ""
+	}
+	return alertRule.LiveChannel
+}
+
+// ValidateLiveChannel checks the channel of a streaming rule. Rules are evaluated on frames pushed to managed streams,
+// so the channel must be a stream channel, for example `stream/factory-1/cpu`. A trailing `*` matches every channel
+// with the prefix, the prefix must include the stream, for example `stream/factory-1/*`.
+func ValidateLiveChannel(channel string) error {
+	if channel == "" {
+		return nil
+	}
+	ch := channel
+	if prefix, ok := strings.CutSuffix(channel, "*"); ok {
+		if strings.Contains(prefix, "*") || !strings.HasSuffix(prefix, "/") {
+			return fmt.Errorf("%w: live channel %q can only end with a wildcard after a `/`", ErrAlertRuleFailedValidation, channel)
+		}
+		ch = prefix + "x"
+	}
+	parsed, err := live.ParseChannel(ch)
+	if err != nil || !parsed.IsValid() || parsed.Scope != live.ScopeStream {
+		return fmt.Errorf("%w: live channel %q must be a stream channel like `stream/<stream>/<path>`", ErrAlertRuleFailedValidation, channel)
+	}
+	return nil
+}
+
 func (alertRule *AlertRule) Type() RuleType {
 	if alertRule.Record != nil {
 		return RuleTypeRecording
@@ -631,6 +671,7 @@ type AlertRuleVersion struct {
 	Labels               map[string]string
 	IsPaused             bool
 	NotificationSettings []NotificationSettings `xorm:"notification_settings"` // we use slice to workaround xorm mapping that does not serialize a struct to JSON unless it's a slice
+	LiveChannel          string                 `xorm:"live_channel"`
 }
 
 // GetAlertRuleByUIDQuery is the query for retrieving/deleting an alert rule by UID and organisation ID.
@@ -17,6 +17,7 @@ import (
 	"github.com/stretchr/testify/require"
 	"gopkg.in/yaml.v3"
 
+	"github.com/grafana/grafana/pkg/setting"
 	"github.com/grafana/grafana/pkg/util"
 	"github.com/grafana/grafana/pkg/util/cmputil"
 )
@@ -864,6 +865,41 @@ func TestSortByGroupIndex(t *testing.T) {
 	})
 }
 
+func TestValidateLiveChannel(t *testing.T) {
+	testCases := []struct {
+		channel string
+		valid   bool
+	}{
+		{channel: "", valid: true},
+		{channel: "stream/factory-1/cpu", valid: true},
+		{channel: "stream/factory-1/line-a/cpu", valid: true},
+		{channel: "stream/factory-1/*", valid: true},
+		{channel: "stream/factory-1/line-*", valid: false},
+		{channel: "stream/*", valid: false},
+		{channel: "*", valid: false},
+		{channel: "stream/factory-1", valid: false},
+		{channel: "plugin/testdata/random-2s-stream", valid: false},
+		{channel: "stream/factory 1/cpu", valid: false},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.channel, func(t *testing.T) {
+			err := ValidateLiveChannel(tc.channel)
+			if tc.valid {
+				require.NoError(t, err)
+			} else {
+				require.ErrorIs(t, err, ErrAlertRuleFailedValidation)
+			}
+		})
+	}
+
+	t.Run("recording rules cannot be streaming rules", func(t *testing.T) {
+		rule := RuleGen.With(RuleMuts.WithLiveChannel("stream/factory-1/cpu"), RuleMuts.WithAllRecordingRules()).GenerateRef()
+		err := rule.ValidateAlertRule(setting.UnifiedAlertingSettings{BaseInterval: time.Duration(rule.IntervalSeconds) * time.Second})
+		require.ErrorIs(t, err, ErrAlertRuleFailedValidation)
+		require.ErrorContains(t, err, "streaming rules")
+	})
+}
+
 func TestTimeRangeYAML(t *testing.T) {
 	yamlRaw := "from: 600\nto: 0\n"
 	var rtr RelativeTimeRange
@@ -483,6 +483,12 @@ func (a *AlertRuleMutators) WithNoNotificationSettings() AlertRuleMutator {
 	}
 }
 
+func (a *AlertRuleMutators) WithLiveChannel(channel string) AlertRuleMutator {
+	return func(rule *AlertRule) {
+		rule.LiveChannel = channel
+	}
+}
+
 func (a *AlertRuleMutators) WithIsPaused(paused bool) AlertRuleMutator {
 	return func(rule *AlertRule) {
 		rule.IsPaused = paused
@@ -604,6 +610,7 @@ func CopyRule(r *AlertRule, mutators ...AlertRuleMutator) *AlertRule {
 		ExecErrState:    r.ExecErrState,
 		For:             r.For,
 		Record:          r.Record,
+		LiveChannel:     r.LiveChannel,
 	}
 
 	if r.DashboardUID != nil {
@@ -7,6 +7,7 @@ import (
 	"time"
 
 	"github.com/benbjohnson/clock"
+	"github.com/grafana/grafana-plugin-sdk-go/data"
 	"github.com/prometheus/alertmanager/featurecontrol"
 	"github.com/prometheus/alertmanager/matchers/compat"
 	"golang.org/x/sync/errgroup"
@@ -455,6 +456,14 @@ func (ng *AlertNG) Run(ctx context.Context) error {
 	return children.Wait()
 }
 
+// ProcessLiveFrame evaluates streaming alert rules that match the Grafana Live channel against a pushed frame.
+func (ng *AlertNG) ProcessLiveFrame(ctx context.Context, orgID int64, channel string, frame *data.Frame) {
+	if ng.schedule == nil {
+		return
+	}
+	ng.schedule.ProcessLiveFrame(ctx, orgID, channel, frame)
+}
+
 // IsDisabled returns true if the alerting service is disabled for this instance.
 func (ng *AlertNG) IsDisabled() bool {
 	if ng.Cfg == nil {
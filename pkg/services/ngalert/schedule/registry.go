@@ -12,6 +12,8 @@ import (
 	"time"
 	"unsafe"
 
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+
 	"github.com/grafana/grafana/pkg/services/ngalert/models"
 )
 
@@ -45,6 +47,15 @@ func (r *ruleRegistry) getOrCreate(context context.Context, item *models.AlertRu
 	return rule, !ok
 }
 
+// get returns the rule routine for the key if it exists in the registry.
+func (r *ruleRegistry) get(key models.AlertRuleKey) (Rule, bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	rule, ok := r.rules[key]
+	return rule, ok
+}
+
 func (r *ruleRegistry) exists(key models.AlertRuleKey) bool {
 	r.mu.Lock()
 	defer r.mu.Unlock()
@@ -85,6 +96,8 @@ type Evaluation struct {
 	scheduledAt time.Time
 	rule        *models.AlertRule
 	folderTitle string
+	// frame is set if the evaluation was triggered by a frame pushed to a Grafana Live channel.
+	frame *data.Frame
 }
 
 func (e *Evaluation) Fingerprint() fingerprint {
@@ -114,6 +127,13 @@ func (r *alertRulesRegistry) get(k models.AlertRuleKey) *models.AlertRule {
 	return r.rules[k]
 }
 
+// folderTitle returns the title of the folder of the rules in the registry.
+func (r *alertRulesRegistry) folderTitle(k models.FolderKey) string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.folderTitles[k]
+}
+
 // set replaces all rules in the registry. Returns difference between previous and the new current version of the registry
 func (r *alertRulesRegistry) set(rules []*models.AlertRule, folders map[models.FolderKey]string) diff {
 	r.mu.Lock()
@@ -295,6 +315,10 @@ func (r ruleWithFolder) Fingerprint() fingerprint {
 	} else {
 		writeInt(0)
 	}
+	// written only for streaming rules, so the fingerprint of the other rules doesn't change
+	if rule.LiveChannel != "" {
+		writeString(rule.LiveChannel)
+	}
 
 	for _, setting := range rule.NotificationSettings {
 		binary.LittleEndian.PutUint64(tmp, uint64(setting.Fingerprint()))
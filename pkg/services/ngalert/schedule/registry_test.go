@@ -204,6 +204,7 @@ func TestRuleWithFolderFingerprint(t *testing.T) {
 			NotificationSettings: []models.NotificationSettings{
 				models.NotificationSettingsGen()(),
 			},
+			LiveChannel: "stream/factory-1/cpu",
 		}
 		r2 := &models.AlertRule{
 			ID:        2,
@@ -243,6 +244,7 @@ func TestRuleWithFolderFingerprint(t *testing.T) {
 			NotificationSettings: []models.NotificationSettings{
 				models.NotificationSettingsGen()(),
 			},
+			LiveChannel: "stream/factory-2/*",
 		}
 
 		excludedFields := map[string]struct{}{
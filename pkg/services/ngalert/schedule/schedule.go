@@ -9,6 +9,7 @@ import (
 	"time"
 
 	"github.com/benbjohnson/clock"
+	"github.com/grafana/grafana-plugin-sdk-go/data"
 	"golang.org/x/sync/errgroup"
 
 	"github.com/grafana/grafana/pkg/infra/log"
@@ -29,6 +30,8 @@ type ScheduleService interface {
 	// Run the scheduler until the context is canceled or the scheduler returns
 	// an error. The scheduler is terminated when this function returns.
 	Run(context.Context) error
+	// ProcessLiveFrame evaluates streaming alert rules that match the Grafana Live channel against the frame.
+	ProcessLiveFrame(ctx context.Context, orgID int64, channel string, frame *data.Frame)
 }
 
 // retryDelay represents how long to wait between each failed rule evaluation.
@@ -92,6 +95,9 @@ type schedule struct {
 	// last evaluated.
 	schedulableAlertRules alertRulesRegistry
 
+	// streamingRules indexes the schedulable streaming rules by their Grafana Live channel.
+	streamingRules *streamingRuleIndex
+
 	tracer tracing.Tracer
 
 	recordingWriter writer.Writer
@@ -140,6 +146,7 @@ func NewScheduler(cfg SchedulerCfg, stateManager *state.Manager) *schedule {
 		stateManager:          stateManager,
 		minRuleInterval:       cfg.MinRuleInterval,
 		schedulableAlertRules: alertRulesRegistry{rules: make(map[ngmodels.AlertRuleKey]*ngmodels.AlertRule)},
+		streamingRules:        newStreamingRuleIndex(),
 		alertsSender:          cfg.AlertSender,
 		tracer:                cfg.Tracer,
 		recordingWriter:       cfg.RecordingWriter,
@@ -292,6 +299,11 @@ func (sch *schedule) processTick(ctx context.Context, dispatcherGroup *errgroup.
 		itemFrequency := item.IntervalSeconds / int64(sch.baseInterval.Seconds())
 		offset := jitterOffsetInTicks(item, sch.baseInterval, sch.jitterEvaluations)
 		isReadyToRun := item.IntervalSeconds != 0 && (tickNum%itemFrequency)-offset == 0
+		var streamingFrame *data.Frame
+		if isReadyToRun && sch.isStreamingRule(ctx, item) {
+			streamingFrame = sch.streamingNoDataFrame(item, tick)
+			isReadyToRun = streamingFrame != nil
+		}
 
 		var folderTitle string
 		if !sch.disableGrafanaFolder {
@@ -309,6 +321,7 @@ func (sch *schedule) processTick(ctx context.Context, dispatcherGroup *errgroup.
 				scheduledAt: tick,
 				rule:        item,
 				folderTitle: folderTitle,
+				frame:       streamingFrame,
 			}})
 		}
 		if _, isUpdated := updated[key]; isUpdated && !isReadyToRun {
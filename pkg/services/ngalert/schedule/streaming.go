@@ -0,0 +1,194 @@
+package schedule
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+
+	"github.com/grafana/grafana/pkg/services/featuremgmt"
+	ngmodels "github.com/grafana/grafana/pkg/services/ngalert/models"
+)
+
+// streamingMinEvaluationInterval is the minimum interval between two evaluations of a streaming rule triggered by
+// frames. Frames pushed more often update the time of the last frame but do not trigger an evaluation.
+const streamingMinEvaluationInterval = time.Second
+
+// isStreamingRule returns true if the rule is evaluated on frames pushed to Grafana Live instead of scheduler ticks.
+func (sch *schedule) isStreamingRule(ctx context.Context, rule *ngmodels.AlertRule) bool {
+	if sch.featureToggles == nil || !sch.featureToggles.IsEnabled(ctx, featuremgmt.FlagAlertingLiveStreamingRules) {
+		return false
+	}
+	return rule.GetLiveChannel() != ""
+}
+
+// ProcessLiveFrame triggers the evaluation of streaming rules of the organization that match the channel.
+// The frame is used as the result of the data source queries of the rules, so the state is updated and
+// notifications are sent without waiting for the next tick. A rule is evaluated at most once per
+// streamingMinEvaluationInterval, and if a rule is still being evaluated when another frame arrives,
+// only the latest pending frame is evaluated next.
+func (sch *schedule) ProcessLiveFrame(ctx context.Context, orgID int64, channel string, frame *data.Frame) {
+	if frame == nil || sch.featureToggles == nil || !sch.featureToggles.IsEnabled(ctx, featuremgmt.FlagAlertingLiveStreamingRules) {
+		return
+	}
+	now := sch.clock.Now()
+	for _, key := range sch.streamingRules.match(orgID, channel) {
+		if !sch.streamingRules.frameReceived(key, now) {
+			continue
+		}
+		rule := sch.schedulableAlertRules.get(key)
+		if rule == nil {
+			continue
+		}
+		routine, ok := sch.registry.get(key)
+		if !ok {
+			// the routine is created on the next tick after the rule is added.
+			sch.log.Debug("Skip streaming evaluation of a rule that is not scheduled yet", append(key.LogContext(), "channel", channel)...)
+			continue
+		}
+
+		var folderTitle string
+		if !sch.disableGrafanaFolder {
+			folderTitle = sch.schedulableAlertRules.folderTitle(rule.GetFolderKey())
+		}
+		e := &Evaluation{
+			scheduledAt: now,
+			rule:        rule,
+			folderTitle: folderTitle,
+			frame:       frame,
+		}
+		go func() {
+			success, dropped := routine.Eval(e)
+			if !success {
+				sch.log.Debug("Streaming evaluation was canceled because evaluation routine was stopped", append(key.LogContext(), "channel", channel)...)
+				return
+			}
+			if dropped != nil {
+				sch.log.Debug("Frame dropped because alert rule evaluation is too slow", append(key.LogContext(), "channel", channel)...)
+				sch.metrics.EvaluationMissed.WithLabelValues(fmt.Sprint(key.OrgID), rule.Title).Inc()
+			}
+		}()
+	}
+}
+
+// streamingNoDataFrame returns the frame a streaming rule is evaluated with on a tick, or nil if the rule must not
+// be evaluated. Streaming rules are evaluated when frames are pushed to their channel, the tick only evaluates them
+// without data when no frame arrived during the rule interval, so that the NoData state applies to silent streams.
+func (sch *schedule) streamingNoDataFrame(rule *ngmodels.AlertRule, tick time.Time) *data.Frame {
+	if !sch.streamingRules.isSilent(rule.GetKey(), tick, time.Duration(rule.IntervalSeconds)*time.Second) {
+		return nil
+	}
+	return data.NewFrame("")
+}
+
+type channelPrefix struct {
+	prefix string
+	key    ngmodels.AlertRuleKey
+}
+
+// streamingRuleIndex indexes the streaming rules by the channel they are evaluated on, so that frames do not go
+// through all the rules, and keeps track of the frames received by every rule.
+type streamingRuleIndex struct {
+	mu sync.Mutex
+	// byChannel contains the rules of every organization with an exact channel, byPrefix the rules with a channel prefix.
+	byChannel map[int64]map[string][]ngmodels.AlertRuleKey
+	byPrefix  map[int64][]channelPrefix
+	// lastFrame is the time the rule received the last frame, lastEval the time the last frame triggered an evaluation.
+	lastFrame map[ngmodels.AlertRuleKey]time.Time
+	lastEval  map[ngmodels.AlertRuleKey]time.Time
+}
+
+func newStreamingRuleIndex() *streamingRuleIndex {
+	return &streamingRuleIndex{
+		byChannel: map[int64]map[string][]ngmodels.AlertRuleKey{},
+		byPrefix:  map[int64][]channelPrefix{},
+		lastFrame: map[ngmodels.AlertRuleKey]time.Time{},
+		lastEval:  map[ngmodels.AlertRuleKey]time.Time{},
+	}
+}
+
+// rebuild replaces the index with the streaming rules. Rules that are new to the index are considered to have
+// received a frame at the time of the rebuild, so that they are not evaluated as NoData before their first interval.
+func (idx *streamingRuleIndex) rebuild(rules []*ngmodels.AlertRule, now time.Time) {
+	byChannel := map[int64]map[string][]ngmodels.AlertRuleKey{}
+	byPrefix := map[int64][]channelPrefix{}
+	lastFrame := map[ngmodels.AlertRuleKey]time.Time{}
+	lastEval := map[ngmodels.AlertRuleKey]time.Time{}
+
+	idx.mu.Lock()
+	defer idx.mu.Unlock()
+
+	for _, rule := range rules {
+		channel := rule.GetLiveChannel()
+		if channel == "" {
+			continue
+		}
+		key := rule.GetKey()
+		if prefix, ok := strings.CutSuffix(channel, "*"); ok {
+			byPrefix[key.OrgID] = append(byPrefix[key.OrgID], channelPrefix{prefix: prefix, key: key})
+		} else {
+			if byChannel[key.OrgID] == nil {
+				byChannel[key.OrgID] = map[string][]ngmodels.AlertRuleKey{}
+			}
+			byChannel[key.OrgID][channel] = append(byChannel[key.OrgID][channel], key)
+		}
+
+		if t, ok := idx.lastFrame[key]; ok {
+			lastFrame[key] = t
+		} else {
+			lastFrame[key] = now
+		}
+		if t, ok := idx.lastEval[key]; ok {
+			lastEval[key] = t
+		}
+	}
+
+	idx.byChannel = byChannel
+	idx.byPrefix = byPrefix
+	idx.lastFrame = lastFrame
+	idx.lastEval = lastEval
+}
+
+// match returns the keys of the streaming rules of the organization that are evaluated on the channel.
+func (idx *streamingRuleIndex) match(orgID int64, channel string) []ngmodels.AlertRuleKey {
+	idx.mu.Lock()
+	defer idx.mu.Unlock()
+
+	exact := idx.byChannel[orgID][channel]
+	keys := make([]ngmodels.AlertRuleKey, 0, len(exact))
+	keys = append(keys, exact...)
+	for _, p := range idx.byPrefix[orgID] {
+		if strings.HasPrefix(channel, p.prefix) {
+			keys = append(keys, p.key)
+		}
+	}
+	return keys
+}
+
+// frameReceived records a frame for the rule and returns true if the frame should trigger an evaluation.
+func (idx *streamingRuleIndex) frameReceived(key ngmodels.AlertRuleKey, now time.Time) bool {
+	idx.mu.Lock()
+	defer idx.mu.Unlock()
+
+	if _, ok := idx.lastFrame[key]; !ok {
+		return false
+	}
+	idx.lastFrame[key] = now
+	if last, ok := idx.lastEval[key]; ok && now.Sub(last) < streamingMinEvaluationInterval {
+		return false
+	}
+	idx.lastEval[key] = now
+	return true
+}
+
+// isSilent returns true if the rule did not receive any frame during the interval.
+func (idx *streamingRuleIndex) isSilent(key ngmodels.AlertRuleKey, now time.Time, interval time.Duration) bool {
+	idx.mu.Lock()
+	defer idx.mu.Unlock()
+
+	last, ok := idx.lastFrame[key]
+	return ok && now.Sub(last) >= interval
+}
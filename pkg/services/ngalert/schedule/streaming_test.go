@@ -0,0 +1,170 @@
+package schedule
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/benbjohnson/clock"
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/sync/errgroup"
+
+	"github.com/grafana/grafana/pkg/services/featuremgmt"
+	"github.com/grafana/grafana/pkg/services/ngalert/eval"
+	"github.com/grafana/grafana/pkg/services/ngalert/models"
+)
+
+type fakeStreamingEvaluatorFactory struct {
+	frames chan *data.Frame
+}
+
+func (f *fakeStreamingEvaluatorFactory) Validate(_ eval.EvaluationContext, _ models.Condition) error {
+	return nil
+}
+
+func (f *fakeStreamingEvaluatorFactory) Create(ctx eval.EvaluationContext, _ models.Condition) (eval.ConditionEvaluator, error) {
+	f.frames <- ctx.StreamingFrame
+	return fakeConditionEvaluator{}, nil
+}
+
+type fakeConditionEvaluator struct{}
+
+func (fakeConditionEvaluator) EvaluateRaw(_ context.Context, _ time.Time) (*backend.QueryDataResponse, error) {
+	return backend.NewQueryDataResponse(), nil
+}
+
+func (fakeConditionEvaluator) Evaluate(_ context.Context, now time.Time) (eval.Results, error) {
+	return eval.Results{{State: eval.Alerting, Instance: data.Labels{}, EvaluatedAt: now}}, nil
+}
+
+func TestProcessLiveFrame(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+	dispatcherGroup, ctx := errgroup.WithContext(ctx)
+
+	const orgID int64 = 1
+	ruleStore := newFakeRulesStore()
+	gen := models.RuleGen
+	streamingRule := gen.With(
+		gen.WithOrgID(orgID),
+		gen.WithInterval(time.Second),
+		gen.WithLiveChannel("stream/factory-1/*"),
+	).GenerateRef()
+	regularRule := gen.With(gen.WithOrgID(orgID), gen.WithInterval(time.Second)).GenerateRef()
+	ruleStore.PutRule(ctx, streamingRule, regularRule)
+
+	evaluator := &fakeStreamingEvaluatorFactory{frames: make(chan *data.Frame, 10)}
+	sch := setupScheduler(t, ruleStore, nil, nil, nil, evaluator)
+	sch.featureToggles = featuremgmt.WithFeatures(featuremgmt.FlagAlertingLiveStreamingRules)
+	mockedClock := sch.clock.(*clock.Mock)
+
+	receive := func(t *testing.T) *data.Frame {
+		t.Helper()
+		select {
+		case frame := <-evaluator.frames:
+			return frame
+		case <-time.After(time.Second):
+			t.Fatal("rule was not evaluated")
+		}
+		return nil
+	}
+	expectNoEvaluation := func(t *testing.T) {
+		t.Helper()
+		select {
+		case <-evaluator.frames:
+			t.Fatal("unexpected evaluation")
+		case <-time.After(100 * time.Millisecond):
+		}
+	}
+
+	t.Run("streaming rules are not evaluated on tick", func(t *testing.T) {
+		mockedClock.Set(time.Unix(1, 0))
+		scheduled, _, _ := sch.processTick(ctx, dispatcherGroup, time.Unix(1, 0))
+		require.Len(t, scheduled, 1)
+		require.Equal(t, regularRule.GetKey(), scheduled[0].rule.GetKey())
+		require.Nil(t, receive(t))
+	})
+
+	t.Run("streaming rules are evaluated on frames pushed to matching channel", func(t *testing.T) {
+		frame := data.NewFrame("cpu", data.NewField("value", nil, []float64{1}))
+		sch.ProcessLiveFrame(ctx, orgID, "stream/factory-1/cpu", frame)
+		require.Same(t, frame, receive(t))
+	})
+
+	t.Run("frames pushed right after an evaluation are throttled", func(t *testing.T) {
+		frame := data.NewFrame("cpu", data.NewField("value", nil, []float64{2}))
+		sch.ProcessLiveFrame(ctx, orgID, "stream/factory-1/cpu", frame)
+		expectNoEvaluation(t)
+
+		mockedClock.Add(streamingMinEvaluationInterval)
+		sch.ProcessLiveFrame(ctx, orgID, "stream/factory-1/cpu", frame)
+		require.Same(t, frame, receive(t))
+	})
+
+	t.Run("frames pushed to other channels or orgs are ignored", func(t *testing.T) {
+		mockedClock.Add(streamingMinEvaluationInterval)
+		frame := data.NewFrame("cpu", data.NewField("value", nil, []float64{1}))
+		sch.ProcessLiveFrame(ctx, orgID, "stream/factory-2/cpu", frame)
+		sch.ProcessLiveFrame(ctx, orgID+1, "stream/factory-1/cpu", frame)
+		expectNoEvaluation(t)
+	})
+
+	t.Run("streaming rules are evaluated without data on tick when the stream is silent", func(t *testing.T) {
+		tick := mockedClock.Now().Add(2 * time.Second)
+		mockedClock.Set(tick)
+		scheduled, _, _ := sch.processTick(ctx, dispatcherGroup, tick)
+		require.Len(t, scheduled, 2)
+
+		var noData *data.Frame
+		for i := 0; i < 2; i++ {
+			if frame := receive(t); frame != nil {
+				noData = frame
+			}
+		}
+		require.NotNil(t, noData)
+		require.Empty(t, noData.Fields)
+	})
+
+	t.Run("streaming rules are evaluated on tick when feature is disabled", func(t *testing.T) {
+		sch.featureToggles = featuremgmt.WithFeatures()
+		scheduled, _, _ := sch.processTick(ctx, dispatcherGroup, mockedClock.Now().Add(time.Second))
+		require.Len(t, scheduled, 2)
+		for _, item := range scheduled {
+			require.Nil(t, item.frame)
+		}
+	})
+}
+
+func TestStreamingRuleIndex(t *testing.T) {
+	gen := models.RuleGen
+	exact := gen.With(gen.WithOrgID(1), gen.WithLiveChannel("stream/factory-1/cpu")).GenerateRef()
+	prefix := gen.With(gen.WithOrgID(1), gen.WithLiveChannel("stream/factory-1/*")).GenerateRef()
+	otherOrg := gen.With(gen.WithOrgID(2), gen.WithLiveChannel("stream/factory-1/cpu")).GenerateRef()
+	regular := gen.With(gen.WithOrgID(1)).GenerateRef()
+
+	now := time.Unix(100, 0)
+	idx := newStreamingRuleIndex()
+	idx.rebuild([]*models.AlertRule{exact, prefix, otherOrg, regular}, now)
+
+	require.ElementsMatch(t, []models.AlertRuleKey{exact.GetKey(), prefix.GetKey()}, idx.match(1, "stream/factory-1/cpu"))
+	require.Equal(t, []models.AlertRuleKey{prefix.GetKey()}, idx.match(1, "stream/factory-1/memory"))
+	require.Equal(t, []models.AlertRuleKey{otherOrg.GetKey()}, idx.match(2, "stream/factory-1/cpu"))
+	require.Empty(t, idx.match(1, "stream/factory-2/cpu"))
+
+	t.Run("new rules get one interval before they are silent", func(t *testing.T) {
+		require.False(t, idx.isSilent(exact.GetKey(), now.Add(time.Second), 10*time.Second))
+		require.True(t, idx.isSilent(exact.GetKey(), now.Add(10*time.Second), 10*time.Second))
+		require.False(t, idx.isSilent(regular.GetKey(), now.Add(10*time.Second), 10*time.Second))
+	})
+
+	t.Run("state of frames is kept when the index is rebuilt", func(t *testing.T) {
+		require.True(t, idx.frameReceived(exact.GetKey(), now.Add(5*time.Second)))
+		idx.rebuild([]*models.AlertRule{exact, prefix}, now.Add(6*time.Second))
+		require.False(t, idx.frameReceived(exact.GetKey(), now.Add(5*time.Second)), "evaluation is throttled")
+		require.False(t, idx.isSilent(exact.GetKey(), now.Add(10*time.Second), 10*time.Second))
+		require.Empty(t, idx.match(2, "stream/factory-1/cpu"), "deleted rules are removed")
+		require.False(t, idx.frameReceived(otherOrg.GetKey(), now.Add(10*time.Second)))
+	})
+}
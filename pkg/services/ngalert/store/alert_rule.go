@@ -163,6 +163,7 @@ func (st DBstore) InsertAlertRules(ctx context.Context, rules []ngmodels.AlertRu
 				Labels:               r.Labels,
 				Record:               r.Record,
 				NotificationSettings: r.NotificationSettings,
+				LiveChannel:          r.LiveChannel,
 			})
 		}
 		if len(newRules) > 0 {
@@ -244,6 +245,7 @@ func (st DBstore) UpdateAlertRules(ctx context.Context, rules []ngmodels.UpdateR
 				Annotations:          r.New.Annotations,
 				Labels:               r.New.Labels,
 				NotificationSettings: r.New.NotificationSettings,
+				LiveChannel:          r.New.LiveChannel,
 			})
 		}
 		if len(ruleVersions) > 0 {
@@ -0,0 +1,121 @@
+package clientmiddleware
+
+import (
+	"context"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+
+	"github.com/grafana/grafana/pkg/components/simplejson"
+	"github.com/grafana/grafana/pkg/plugins"
+	"github.com/grafana/grafana/pkg/services/auth/identity"
+	"github.com/grafana/grafana/pkg/services/contexthandler"
+	"github.com/grafana/grafana/pkg/services/datasources"
+	"github.com/grafana/grafana/pkg/services/datasources/lbac"
+)
+
+// NewLabelPolicyMiddleware creates a new plugins.ClientMiddleware that will
+// inject the label policies of the teams of the signed in user into the
+// queries of outgoing plugins.Client requests. Resource and stream requests
+// can't be restricted, so they are rejected for users with label policies.
+func NewLabelPolicyMiddleware() plugins.ClientMiddleware {
+	return plugins.ClientMiddlewareFunc(func(next plugins.Client) plugins.Client {
+		return &LabelPolicyMiddleware{
+			baseMiddleware: baseMiddleware{
+				next: next,
+			},
+		}
+	})
+}
+
+type LabelPolicyMiddleware struct {
+	baseMiddleware
+}
+
+// dataSource returns the data source of a request and the signed in user it is made for. Requests without
+// HTTP request context, like scheduled alert rule evaluations, aren't made on behalf of a user and are skipped.
+func (m *LabelPolicyMiddleware) dataSource(ctx context.Context, pCtx backend.PluginContext) (*datasources.DataSource, identity.Requester, error) {
+	reqCtx := contexthandler.FromContext(ctx)
+	if reqCtx == nil || reqCtx.SignedInUser == nil || pCtx.DataSourceInstanceSettings == nil {
+		return nil, nil, nil
+	}
+
+	settings := pCtx.DataSourceInstanceSettings
+	jsonData, err := simplejson.NewJson(settings.JSONData)
+	if err != nil {
+		return nil, nil, err
+	}
+	ds := &datasources.DataSource{
+		ID:       settings.ID,
+		UID:      settings.UID,
+		OrgID:    pCtx.OrgID,
+		Type:     pCtx.PluginID,
+		JsonData: jsonData,
+	}
+	return ds, reqCtx.SignedInUser, nil
+}
+
+func (m *LabelPolicyMiddleware) checkAccess(ctx context.Context, pCtx backend.PluginContext) error {
+	ds, user, err := m.dataSource(ctx, pCtx)
+	if err != nil || ds == nil {
+		return err
+	}
+	return lbac.CheckResourceAccess(ds, user)
+}
+
+func (m *LabelPolicyMiddleware) QueryData(ctx context.Context, req *backend.QueryDataRequest) (*backend.QueryDataResponse, error) {
+	if req == nil {
+		return m.next.QueryData(ctx, req)
+	}
+
+	ds, user, err := m.dataSource(ctx, req.PluginContext)
+	if err != nil {
+		return nil, err
+	}
+	if ds == nil {
+		return m.next.QueryData(ctx, req)
+	}
+	policies, err := lbac.PoliciesFor(ds, user)
+	if err != nil {
+		return nil, err
+	}
+	if policies == nil {
+		return m.next.QueryData(ctx, req)
+	}
+
+	// the queries are copied, so the request of the caller keeps its queries
+	queries := make([]backend.DataQuery, len(req.Queries))
+	for i, q := range req.Queries {
+		q.JSON, err = policies.RewriteModel(q.JSON)
+		if err != nil {
+			return nil, err
+		}
+		queries[i] = q
+	}
+	req.Queries = queries
+
+	return m.next.QueryData(ctx, req)
+}
+
+func (m *LabelPolicyMiddleware) CallResource(ctx context.Context, req *backend.CallResourceRequest, sender backend.CallResourceResponseSender) error {
+	if req == nil {
+		return m.next.CallResource(ctx, req, sender)
+	}
+
+	if err := m.checkAccess(ctx, req.PluginContext); err != nil {
+		return err
+	}
+
+	return m.next.CallResource(ctx, req, sender)
+}
+
+func (m *LabelPolicyMiddleware) SubscribeStream(ctx context.Context, req *backend.SubscribeStreamRequest) (*backend.SubscribeStreamResponse, error) {
+	if req == nil {
+		return m.next.SubscribeStream(ctx, req)
+	}
+
+	if err := m.checkAccess(ctx, req.PluginContext); err != nil {
+		return nil, err
+	}
+
+	return m.next.SubscribeStream(ctx, req)
+}
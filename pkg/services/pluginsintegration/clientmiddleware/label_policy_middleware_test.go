@@ -0,0 +1,102 @@
+package clientmiddleware
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/plugins/manager/client/clienttest"
+	"github.com/grafana/grafana/pkg/services/contexthandler/ctxkey"
+	contextmodel "github.com/grafana/grafana/pkg/services/contexthandler/model"
+	"github.com/grafana/grafana/pkg/services/datasources/lbac"
+	"github.com/grafana/grafana/pkg/services/user"
+	"github.com/grafana/grafana/pkg/web"
+)
+
+func TestLabelPolicyMiddleware(t *testing.T) {
+	pluginContext := backend.PluginContext{
+		PluginID: "prometheus",
+		DataSourceInstanceSettings: &backend.DataSourceInstanceSettings{
+			UID: "prom",
+			JSONData: []byte(`{"teamHttpHeaders": {"restrictAccess": true, "headers": {
+				"1": [{"header": "X-Prom-Label-Policy", "value": "1:{namespace=\"team-a\"}"}]
+			}}}`),
+		},
+	}
+	userContext := func(teams ...int64) context.Context {
+		return context.WithValue(context.Background(), ctxkey.Key{}, &contextmodel.ReqContext{
+			Context:      &web.Context{Req: &http.Request{}},
+			SignedInUser: &user.SignedInUser{Teams: teams},
+		})
+	}
+	queryDataRequest := func() *backend.QueryDataRequest {
+		return &backend.QueryDataRequest{
+			PluginContext: pluginContext,
+			Queries:       []backend.DataQuery{{RefID: "A", JSON: json.RawMessage(`{"expr":"up"}`)}},
+		}
+	}
+
+	t.Run("Should inject the label policies into queries", func(t *testing.T) {
+		cdt := clienttest.NewClientDecoratorTest(t, clienttest.WithMiddlewares(NewLabelPolicyMiddleware()))
+
+		req := queryDataRequest()
+		_, err := cdt.Decorator.QueryData(userContext(1), req)
+		require.NoError(t, err)
+
+		require.JSONEq(t, `{"expr":"up{namespace=\"team-a\"}"}`, string(cdt.QueryDataReq.Queries[0].JSON))
+	})
+
+	t.Run("Should reject queries of users without label policy", func(t *testing.T) {
+		cdt := clienttest.NewClientDecoratorTest(t, clienttest.WithMiddlewares(NewLabelPolicyMiddleware()))
+
+		_, err := cdt.Decorator.QueryData(userContext(2), queryDataRequest())
+		require.True(t, errors.Is(err, lbac.ErrNoPolicy))
+		require.Nil(t, cdt.QueryDataReq)
+	})
+
+	t.Run("Should not change queries without HTTP request context", func(t *testing.T) {
+		cdt := clienttest.NewClientDecoratorTest(t, clienttest.WithMiddlewares(NewLabelPolicyMiddleware()))
+
+		_, err := cdt.Decorator.QueryData(context.Background(), queryDataRequest())
+		require.NoError(t, err)
+
+		require.JSONEq(t, `{"expr":"up"}`, string(cdt.QueryDataReq.Queries[0].JSON))
+	})
+
+	t.Run("Should reject resource requests of users with label policies", func(t *testing.T) {
+		cdt := clienttest.NewClientDecoratorTest(t, clienttest.WithMiddlewares(NewLabelPolicyMiddleware()))
+
+		err := cdt.Decorator.CallResource(userContext(1), &backend.CallResourceRequest{
+			PluginContext: pluginContext,
+			Path:          "api/v1/query",
+		}, nopCallResourceSender)
+		require.True(t, errors.Is(err, lbac.ErrResourcesRestricted))
+		require.Nil(t, cdt.CallResourceReq)
+	})
+
+	t.Run("Should reject stream requests of users with label policies", func(t *testing.T) {
+		cdt := clienttest.NewClientDecoratorTest(t, clienttest.WithMiddlewares(NewLabelPolicyMiddleware()))
+
+		_, err := cdt.Decorator.SubscribeStream(userContext(1), &backend.SubscribeStreamRequest{
+			PluginContext: pluginContext,
+		})
+		require.True(t, errors.Is(err, lbac.ErrResourcesRestricted))
+	})
+
+	t.Run("Should pass on requests to other data sources", func(t *testing.T) {
+		cdt := clienttest.NewClientDecoratorTest(t, clienttest.WithMiddlewares(NewLabelPolicyMiddleware()))
+
+		pCtx := pluginContext
+		pCtx.PluginID = "elasticsearch"
+		err := cdt.Decorator.CallResource(userContext(1), &backend.CallResourceRequest{
+			PluginContext: pCtx,
+		}, nopCallResourceSender)
+		require.NoError(t, err)
+		require.NotNil(t, cdt.CallResourceReq)
+	})
+}
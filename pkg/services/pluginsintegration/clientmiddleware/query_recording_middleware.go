@@ -0,0 +1,225 @@
+package clientmiddleware
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+
+	"github.com/grafana/grafana/pkg/components/simplejson"
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/plugins"
+	"github.com/grafana/grafana/pkg/queryrecording"
+	"github.com/grafana/grafana/pkg/services/accesscontrol"
+	"github.com/grafana/grafana/pkg/services/auth/identity"
+	"github.com/grafana/grafana/pkg/services/contexthandler"
+	"github.com/grafana/grafana/pkg/services/datasources"
+	"github.com/grafana/grafana/pkg/services/datasources/lbac"
+	"github.com/grafana/grafana/pkg/services/oauthtoken"
+)
+
+const (
+	queryRecordingModeRecord = "record"
+	queryRecordingModeReplay = "replay"
+
+	// testDataPluginID is the id of the data source replaying the recordings of other data sources.
+	testDataPluginID = "grafana-testdata-datasource"
+)
+
+// NewQueryRecordingMiddleware creates a new plugins.ClientMiddleware that will
+// record the query responses of data sources in dir, or replay them, depending on
+// the queryRecording field of the data source settings. Data sources forwarding
+// the identity of the user aren't recorded. Queries of the testdata data source
+// replaying the recordings of a data source are rejected for users who can't
+// query that data source, or whose queries of it are restricted by label policies.
+func NewQueryRecordingMiddleware(dir string, dataSourceCache datasources.CacheService) plugins.ClientMiddleware {
+	return plugins.ClientMiddlewareFunc(func(next plugins.Client) plugins.Client {
+		return &QueryRecordingMiddleware{
+			baseMiddleware: baseMiddleware{
+				next: next,
+			},
+			store:           queryrecording.NewStore(dir),
+			dataSourceCache: dataSourceCache,
+			log:             log.New("query_recording_middleware"),
+			now:             time.Now,
+		}
+	})
+}
+
+type QueryRecordingMiddleware struct {
+	baseMiddleware
+
+	store           *queryrecording.Store
+	dataSourceCache datasources.CacheService
+	log             log.Logger
+	now             func() time.Time
+}
+
+func (m *QueryRecordingMiddleware) QueryData(ctx context.Context, req *backend.QueryDataRequest) (*backend.QueryDataResponse, error) {
+	if req == nil || req.PluginContext.DataSourceInstanceSettings == nil {
+		return m.next.QueryData(ctx, req)
+	}
+
+	if req.PluginContext.PluginID == testDataPluginID {
+		return m.queryRecordedQueries(ctx, req)
+	}
+
+	settings := req.PluginContext.DataSourceInstanceSettings
+	switch queryRecordingMode(settings.JSONData) {
+	case queryRecordingModeRecord:
+		// the responses of these data sources depend on the user, so they must not be replayed to other users
+		if forwardsIdentity(settings.JSONData) {
+			m.log.FromContext(ctx).Warn("Not recording queries of a data source forwarding the user identity", "datasource", settings.UID)
+			return m.next.QueryData(ctx, req)
+		}
+		now := m.now()
+		resp, err := m.next.QueryData(ctx, req)
+		if err == nil && resp != nil {
+			m.record(ctx, req.PluginContext.OrgID, settings, req.Queries, resp, now)
+		}
+		return resp, err
+	case queryRecordingModeReplay:
+		return m.replay(ctx, req.PluginContext.OrgID, settings, req.Queries), nil
+	default:
+		return m.next.QueryData(ctx, req)
+	}
+}
+
+func (m *QueryRecordingMiddleware) record(ctx context.Context, orgID int64, settings *backend.DataSourceInstanceSettings, queries []backend.DataQuery, resp *backend.QueryDataResponse, now time.Time) {
+	ds := queryrecording.DataSourceRef{OrgID: orgID, UID: settings.UID, Type: settings.Type}
+	for _, q := range queries {
+		dr, ok := resp.Responses[q.RefID]
+		if !ok {
+			continue
+		}
+
+		f, err := queryrecording.New(ds, q, dr, now)
+		if err == nil {
+			err = m.store.Save(f)
+		}
+		if err != nil {
+			m.log.FromContext(ctx).Error("Failed to record query", "datasource", ds.UID, "refId", q.RefID, "error", err)
+		}
+	}
+}
+
+func (m *QueryRecordingMiddleware) replay(ctx context.Context, orgID int64, settings *backend.DataSourceInstanceSettings, queries []backend.DataQuery) *backend.QueryDataResponse {
+	resp := backend.NewQueryDataResponse()
+	now := m.now()
+	for _, q := range queries {
+		f, err := m.store.Find(orgID, settings.UID, q, now)
+		switch {
+		case errors.Is(err, queryrecording.ErrNotFound):
+			resp.Responses[q.RefID] = backend.ErrDataResponse(backend.StatusNotFound, err.Error())
+		case err != nil:
+			m.log.FromContext(ctx).Error("Failed to replay query", "datasource", settings.UID, "refId", q.RefID, "error", err)
+			resp.Responses[q.RefID] = backend.ErrDataResponse(backend.StatusInternal, "failed to replay query")
+		default:
+			resp.Responses[q.RefID] = f.Replay(q.TimeRange)
+		}
+	}
+	return resp
+}
+
+// queryRecordedQueries runs the queries of the testdata data source, and rejects the ones replaying the
+// recordings of data sources the signed in user can't replay. Requests without HTTP request context aren't
+// made on behalf of a user, so they can't replay recordings.
+func (m *QueryRecordingMiddleware) queryRecordedQueries(ctx context.Context, req *backend.QueryDataRequest) (*backend.QueryDataResponse, error) {
+	reqCtx := contexthandler.FromContext(ctx)
+	denied := backend.Responses{}
+	allowed := make([]backend.DataQuery, 0, len(req.Queries))
+	for _, q := range req.Queries {
+		uid := recordedDataSource(q.JSON)
+		if uid == "" {
+			allowed = append(allowed, q)
+			continue
+		}
+		if reqCtx == nil || reqCtx.SignedInUser == nil {
+			denied[q.RefID] = backend.ErrDataResponse(backend.StatusForbidden, fmt.Sprintf("access denied to recorded data source %s", uid))
+			continue
+		}
+		if err := m.checkReplayAccess(ctx, reqCtx.SignedInUser, uid); err != nil {
+			denied[q.RefID] = backend.ErrDataResponse(backend.StatusForbidden, fmt.Sprintf("access denied to recorded data source %s: %s", uid, err))
+			continue
+		}
+		allowed = append(allowed, q)
+	}
+	if len(denied) == 0 {
+		return m.next.QueryData(ctx, req)
+	}
+
+	resp := &backend.QueryDataResponse{Responses: backend.Responses{}}
+	if len(allowed) > 0 {
+		var err error
+		resp, err = m.next.QueryData(ctx, &backend.QueryDataRequest{
+			PluginContext: req.PluginContext,
+			Headers:       req.Headers,
+			Queries:       allowed,
+		})
+		if err != nil || resp == nil {
+			return resp, err
+		}
+		if resp.Responses == nil {
+			resp.Responses = backend.Responses{}
+		}
+	}
+	for refID, dr := range denied {
+		resp.Responses[refID] = dr
+	}
+	return resp, nil
+}
+
+// checkReplayAccess returns an error when a user can't replay the recordings of a data source. Recorded responses
+// can't be restricted by the label policies of the user, so they are only replayed to users without policies.
+func (m *QueryRecordingMiddleware) checkReplayAccess(ctx context.Context, user identity.Requester, uid string) error {
+	eval := accesscontrol.EvalPermission(datasources.ActionQuery, datasources.ScopeProvider.GetResourceScopeUID(uid))
+	if !eval.Evaluate(user.GetPermissions()) {
+		return errors.New("missing query permission")
+	}
+	ds, err := m.dataSourceCache.GetDatasourceByUID(ctx, uid, user, false)
+	if err != nil {
+		return errors.New("data source not found")
+	}
+	policies, err := lbac.PoliciesFor(ds, user)
+	if err != nil || policies != nil {
+		return errors.New("queries are restricted by label policies")
+	}
+	return nil
+}
+
+// recordedDataSource returns the uid of the data source a query of the testdata data source replays the
+// recordings of, if any.
+func recordedDataSource(model json.RawMessage) string {
+	var query struct {
+		ScenarioID string `json:"scenarioId"`
+		Recording  struct {
+			Datasource string `json:"datasource"`
+		} `json:"recording"`
+	}
+	if err := json.Unmarshal(model, &query); err != nil || query.ScenarioID != "recorded_query" {
+		return ""
+	}
+	return query.Recording.Datasource
+}
+
+// forwardsIdentity returns whether a data source forwards the OAuth identity of the user.
+func forwardsIdentity(jsonData json.RawMessage) bool {
+	settings, err := simplejson.NewJson(jsonData)
+	return err == nil && oauthtoken.IsOAuthPassThruEnabled(&datasources.DataSource{JsonData: settings})
+}
+
+func queryRecordingMode(jsonData json.RawMessage) string {
+	if len(jsonData) == 0 {
+		return ""
+	}
+	var settings struct {
+		QueryRecording string `json:"queryRecording"`
+	}
+	if err := json.Unmarshal(jsonData, &settings); err != nil {
+		return ""
+	}
+	return settings.QueryRecording
+}
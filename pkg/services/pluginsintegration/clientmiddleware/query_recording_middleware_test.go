@@ -0,0 +1,186 @@
+package clientmiddleware
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/components/simplejson"
+	"github.com/grafana/grafana/pkg/plugins/manager/client/clienttest"
+	"github.com/grafana/grafana/pkg/services/contexthandler/ctxkey"
+	contextmodel "github.com/grafana/grafana/pkg/services/contexthandler/model"
+	"github.com/grafana/grafana/pkg/services/datasources"
+	datasourcesfakes "github.com/grafana/grafana/pkg/services/datasources/fakes"
+	"github.com/grafana/grafana/pkg/services/user"
+	"github.com/grafana/grafana/pkg/web"
+)
+
+func TestQueryRecordingMiddleware(t *testing.T) {
+	dir := t.TempDir()
+	now := time.Now()
+	tr := backend.TimeRange{From: now.Add(-time.Hour), To: now}
+
+	newRequest := func(mode string, expr string) *backend.QueryDataRequest {
+		return &backend.QueryDataRequest{
+			PluginContext: backend.PluginContext{
+				OrgID:    1,
+				PluginID: "prometheus",
+				DataSourceInstanceSettings: &backend.DataSourceInstanceSettings{
+					UID:      "prom",
+					Type:     "prometheus",
+					JSONData: json.RawMessage(`{"queryRecording":"` + mode + `"}`),
+				},
+			},
+			Queries: []backend.DataQuery{
+				{RefID: "A", TimeRange: tr, JSON: json.RawMessage(`{"refId":"A","expr":"` + expr + `"}`)},
+			},
+		}
+	}
+
+	dataSourceCache := &datasourcesfakes.FakeCacheService{DataSources: []*datasources.DataSource{
+		{UID: "prom", Type: datasources.DS_PROMETHEUS, JsonData: simplejson.New()},
+		{UID: "restricted", Type: datasources.DS_PROMETHEUS, JsonData: simplejson.NewFromAny(map[string]any{
+			"teamHttpHeaders": map[string]any{"headers": map[string]any{
+				"1": []any{map[string]any{"header": "X-Prom-Label-Policy", "value": `1:{namespace="team-a"}`}},
+			}},
+		})},
+	}}
+
+	newClient := func(t *testing.T) *clienttest.ClientDecoratorTest {
+		cdt := clienttest.NewClientDecoratorTest(t, clienttest.WithMiddlewares(NewQueryRecordingMiddleware(dir, dataSourceCache)))
+		cdt.TestClient.QueryDataFunc = func(ctx context.Context, req *backend.QueryDataRequest) (*backend.QueryDataResponse, error) {
+			cdt.QueryDataReq = req
+			return &backend.QueryDataResponse{Responses: backend.Responses{
+				"A": {Frames: data.Frames{data.NewFrame("up",
+					data.NewField("time", nil, []time.Time{now}),
+					data.NewField("value", nil, []float64{1}),
+				)}},
+			}}, nil
+		}
+		return cdt
+	}
+
+	t.Run("Should query the data source when recording is not enabled", func(t *testing.T) {
+		cdt := newClient(t)
+
+		resp, err := cdt.Decorator.QueryData(context.Background(), newRequest("", "up"))
+		require.NoError(t, err)
+		require.NotNil(t, cdt.QueryDataReq)
+		require.Len(t, resp.Responses["A"].Frames, 1)
+	})
+
+	t.Run("Should record responses and replay them", func(t *testing.T) {
+		cdt := newClient(t)
+		_, err := cdt.Decorator.QueryData(context.Background(), newRequest(queryRecordingModeRecord, "up"))
+		require.NoError(t, err)
+		require.NotNil(t, cdt.QueryDataReq)
+
+		cdt = newClient(t)
+		resp, err := cdt.Decorator.QueryData(context.Background(), newRequest(queryRecordingModeReplay, "up"))
+		require.NoError(t, err)
+		require.Nil(t, cdt.QueryDataReq)
+		require.NoError(t, resp.Responses["A"].Error)
+		require.Len(t, resp.Responses["A"].Frames, 1)
+		require.Equal(t, 1.0, resp.Responses["A"].Frames[0].Fields[1].At(0))
+	})
+
+	t.Run("Should not record data sources forwarding the user identity", func(t *testing.T) {
+		cdt := newClient(t)
+		req := newRequest(queryRecordingModeRecord, "forwarded")
+		req.PluginContext.DataSourceInstanceSettings.JSONData = json.RawMessage(`{"queryRecording":"record","oauthPassThru":true}`)
+		_, err := cdt.Decorator.QueryData(context.Background(), req)
+		require.NoError(t, err)
+		require.NotNil(t, cdt.QueryDataReq)
+
+		cdt = newClient(t)
+		resp, err := cdt.Decorator.QueryData(context.Background(), newRequest(queryRecordingModeReplay, "forwarded"))
+		require.NoError(t, err)
+		require.Equal(t, backend.StatusNotFound, resp.Responses["A"].Status)
+	})
+
+	t.Run("Should return an error for queries that were not recorded", func(t *testing.T) {
+		cdt := newClient(t)
+
+		resp, err := cdt.Decorator.QueryData(context.Background(), newRequest(queryRecordingModeReplay, "down"))
+		require.NoError(t, err)
+		require.Nil(t, cdt.QueryDataReq)
+		require.Equal(t, backend.StatusNotFound, resp.Responses["A"].Status)
+		require.Error(t, resp.Responses["A"].Error)
+	})
+	t.Run("Should not replay the recordings of another organization", func(t *testing.T) {
+		cdt := newClient(t)
+
+		req := newRequest(queryRecordingModeReplay, "up")
+		req.PluginContext.OrgID = 2
+		resp, err := cdt.Decorator.QueryData(context.Background(), req)
+		require.NoError(t, err)
+		require.Equal(t, backend.StatusNotFound, resp.Responses["A"].Status)
+	})
+
+	t.Run("Should reject recorded queries of data sources the user can't query", func(t *testing.T) {
+		cdt := newClient(t)
+
+		ctx := context.WithValue(context.Background(), ctxkey.Key{}, &contextmodel.ReqContext{
+			Context: &web.Context{Req: &http.Request{}},
+			SignedInUser: &user.SignedInUser{OrgID: 1, Permissions: map[int64]map[string][]string{
+				1: {datasources.ActionQuery: {datasources.ScopeProvider.GetResourceScopeUID("prom")}},
+			}},
+		})
+		recordedQuery := func(refID string, uid string) backend.DataQuery {
+			return backend.DataQuery{RefID: refID, TimeRange: tr, JSON: json.RawMessage(`{"scenarioId":"recorded_query","recording":{"datasource":"` + uid + `","query":{"expr":"up"}}}`)}
+		}
+		req := &backend.QueryDataRequest{
+			PluginContext: backend.PluginContext{
+				OrgID:                      1,
+				PluginID:                   testDataPluginID,
+				DataSourceInstanceSettings: &backend.DataSourceInstanceSettings{UID: "testdata"},
+			},
+			Queries: []backend.DataQuery{recordedQuery("A", "prom"), recordedQuery("B", "secret")},
+		}
+
+		resp, err := cdt.Decorator.QueryData(ctx, req)
+		require.NoError(t, err)
+		require.Len(t, cdt.QueryDataReq.Queries, 1)
+		require.Equal(t, "A", cdt.QueryDataReq.Queries[0].RefID)
+		require.NoError(t, resp.Responses["A"].Error)
+		require.Equal(t, backend.StatusForbidden, resp.Responses["B"].Status)
+
+		cdt = newClient(t)
+		resp, err = cdt.Decorator.QueryData(context.Background(), req)
+		require.NoError(t, err)
+		require.Nil(t, cdt.QueryDataReq)
+		require.Equal(t, backend.StatusForbidden, resp.Responses["A"].Status)
+	})
+
+	t.Run("Should reject recorded queries of data sources with label policies for the user", func(t *testing.T) {
+		cdt := newClient(t)
+
+		ctx := context.WithValue(context.Background(), ctxkey.Key{}, &contextmodel.ReqContext{
+			Context: &web.Context{Req: &http.Request{}},
+			SignedInUser: &user.SignedInUser{OrgID: 1, Teams: []int64{1}, Permissions: map[int64]map[string][]string{
+				1: {datasources.ActionQuery: {datasources.ScopeProvider.GetResourceScopeUID("restricted")}},
+			}},
+		})
+		req := &backend.QueryDataRequest{
+			PluginContext: backend.PluginContext{
+				OrgID:                      1,
+				PluginID:                   testDataPluginID,
+				DataSourceInstanceSettings: &backend.DataSourceInstanceSettings{UID: "testdata"},
+			},
+			Queries: []backend.DataQuery{
+				{RefID: "A", TimeRange: tr, JSON: json.RawMessage(`{"scenarioId":"recorded_query","recording":{"datasource":"restricted","query":{"expr":"up"}}}`)},
+			},
+		}
+
+		resp, err := cdt.Decorator.QueryData(ctx, req)
+		require.NoError(t, err)
+		require.Nil(t, cdt.QueryDataReq)
+		require.Equal(t, backend.StatusForbidden, resp.Responses["A"].Status)
+	})
+}
@@ -78,6 +78,8 @@ type PluginInstanceCfg struct {
 
 	SigV4AuthEnabled    bool
 	SigV4VerboseLogging bool
+
+	QueryRecordingPath string
 }
 
 // ProvidePluginInstanceConfig returns a new PluginInstanceCfg.
@@ -101,6 +103,11 @@ func ProvidePluginInstanceConfig(cfg *setting.Cfg, settingProvider setting.Provi
 		cfg.Azure = &azsettings.AzureSettings{}
 	}
 
+	var queryRecordingPath string
+	if cfg.QueryRecording.Enabled {
+		queryRecordingPath = cfg.QueryRecording.Path
+	}
+
 	return &PluginInstanceCfg{
 		GrafanaAppURL:                       cfg.AppURL,
 		Features:                            features,
@@ -125,6 +132,7 @@ func ProvidePluginInstanceConfig(cfg *setting.Cfg, settingProvider setting.Provi
 		ResponseLimit:                       cfg.ResponseLimit,
 		SigV4AuthEnabled:                    cfg.SigV4AuthEnabled,
 		SigV4VerboseLogging:                 cfg.SigV4VerboseLogging,
+		QueryRecordingPath:                  queryRecordingPath,
 	}, nil
 }
 
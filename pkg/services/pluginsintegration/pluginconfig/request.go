@@ -10,6 +10,7 @@ import (
 	"github.com/grafana/grafana-aws-sdk/pkg/awsds"
 	"github.com/grafana/grafana-azure-sdk-go/v2/azsettings"
 	"github.com/grafana/grafana/pkg/plugins/auth"
+	"github.com/grafana/grafana/pkg/queryrecording"
 
 	"github.com/grafana/grafana-plugin-sdk-go/backend"
 	"github.com/grafana/grafana-plugin-sdk-go/backend/proxy"
@@ -165,6 +166,11 @@ func (s *RequestConfigProvider) PluginRequestConfig(ctx context.Context, pluginI
 		m[awsds.SigV4VerboseLoggingEnvVarKeyName] = strconv.FormatBool(s.cfg.SigV4VerboseLogging)
 	}
 
+	// The testdata data source replays the recorded queries
+	if s.cfg.QueryRecordingPath != "" && pluginID == "grafana-testdata-datasource" {
+		m[queryrecording.PathConfigKey] = s.cfg.QueryRecordingPath
+	}
+
 	if externalService != nil {
 		m[backend.AppClientSecret] = externalService.ClientSecret
 	}
@@ -225,6 +225,31 @@ func TestRequestConfigProvider_PluginRequestConfig_SQL(t *testing.T) {
 	})
 }
 
+func TestRequestConfigProvider_PluginRequestConfig_queryRecording(t *testing.T) {
+	t.Run("Forwards the recordings path to the testdata data source", func(t *testing.T) {
+		cfg := setting.NewCfg()
+		cfg.QueryRecording = setting.QueryRecordingSettings{Enabled: true, Path: "/var/lib/grafana/recordings"}
+
+		pCfg, err := ProvidePluginInstanceConfig(cfg, setting.ProvideProvider(cfg), featuremgmt.WithFeatures())
+		require.NoError(t, err)
+
+		p := NewRequestConfigProvider(pCfg)
+		require.Equal(t, "/var/lib/grafana/recordings", p.PluginRequestConfig(context.Background(), "grafana-testdata-datasource", nil)["GF_QUERY_RECORDING_PATH"])
+		require.NotContains(t, p.PluginRequestConfig(context.Background(), "prometheus", nil), "GF_QUERY_RECORDING_PATH")
+	})
+
+	t.Run("Doesn't forward the recordings path when recording is disabled", func(t *testing.T) {
+		cfg := setting.NewCfg()
+		cfg.QueryRecording = setting.QueryRecordingSettings{Enabled: false, Path: "/var/lib/grafana/recordings"}
+
+		pCfg, err := ProvidePluginInstanceConfig(cfg, setting.ProvideProvider(cfg), featuremgmt.WithFeatures())
+		require.NoError(t, err)
+
+		p := NewRequestConfigProvider(pCfg)
+		require.NotContains(t, p.PluginRequestConfig(context.Background(), "grafana-testdata-datasource", nil), "GF_QUERY_RECORDING_PATH")
+	})
+}
+
 func TestRequestConfigProvider_PluginRequestConfig_concurrentQueryCount(t *testing.T) {
 	t.Run("Uses the configured concurrent query count", func(t *testing.T) {
 		cfg := setting.NewCfg()
@@ -76,6 +76,7 @@ type AlertRuleV1 struct {
 	IsPaused             values.BoolValue        `json:"isPaused" yaml:"isPaused"`
 	NotificationSettings *NotificationSettingsV1 `json:"notification_settings" yaml:"notification_settings"`
 	Record               *RecordV1               `json:"record" yaml:"record"`
+	LiveChannel          values.StringValue      `json:"live_channel" yaml:"live_channel"`
 }
 
 func (rule *AlertRuleV1) mapToModel(orgID int64) (models.AlertRule, error) {
@@ -147,6 +148,10 @@ func (rule *AlertRuleV1) mapToModel(orgID int64) (models.AlertRule, error) {
 		}
 		alertRule.Record = &record
 	}
+	alertRule.LiveChannel = strings.TrimSpace(rule.LiveChannel.Value())
+	if err := models.ValidateLiveChannel(alertRule.LiveChannel); err != nil {
+		return models.AlertRule{}, fmt.Errorf("rule '%s' failed to parse: %w", alertRule.Title, err)
+	}
 	return alertRule, nil
 }
 
@@ -21,6 +21,7 @@ import (
 	"github.com/grafana/grafana/pkg/services/contexthandler"
 	"github.com/grafana/grafana/pkg/services/datasources"
 	"github.com/grafana/grafana/pkg/services/pluginsintegration/plugincontext"
+	"github.com/grafana/grafana/pkg/services/querylimit"
 	"github.com/grafana/grafana/pkg/services/validations"
 	"github.com/grafana/grafana/pkg/setting"
 	"github.com/grafana/grafana/pkg/tsdb/grafanads"
@@ -44,6 +45,7 @@ func ProvideService(
 	pluginRequestValidator validations.PluginRequestValidator,
 	pluginClient plugins.Client,
 	pCtxProvider *plugincontext.Provider,
+	limiter *querylimit.Limiter,
 ) *ServiceImpl {
 	g := &ServiceImpl{
 		cfg:                    cfg,
@@ -52,8 +54,11 @@ func ProvideService(
 		pluginRequestValidator: pluginRequestValidator,
 		pluginClient:           pluginClient,
 		pCtxProvider:           pCtxProvider,
+		limiter:                limiter,
 		log:                    log.New("query_data"),
 		concurrentQueryLimit:   cfg.SectionWithEnvOverrides("query").Key("concurrent_query_limit").MustInt(runtime.NumCPU()),
+		splitMaxParallel:       max(1, cfg.SectionWithEnvOverrides("query").Key("split_max_parallel").MustInt(4)),
+		splitMaxChunks:         max(1, cfg.SectionWithEnvOverrides("query").Key("split_max_chunks").MustInt(100)),
 	}
 	g.log.Info("Query Service initialization")
 	return g
@@ -75,8 +80,11 @@ type ServiceImpl struct {
 	pluginRequestValidator validations.PluginRequestValidator
 	pluginClient           plugins.Client
 	pCtxProvider           *plugincontext.Provider
+	limiter                *querylimit.Limiter
 	log                    log.Logger
 	concurrentQueryLimit   int
+	splitMaxParallel       int
+	splitMaxChunks         int
 }
 
 // Run ServiceImpl.
@@ -209,6 +217,7 @@ func (s *ServiceImpl) handleExpressions(ctx context.Context, user identity.Reque
 		exprReq.OrgId = user.GetOrgID()
 	}
 
+	var dsUIDs []string
 	for _, pq := range parsedReq.getFlattenedQueries() {
 		if pq.datasource == nil {
 			return nil, ErrMissingDataSourceInfo.Build(errutil.TemplateData{
@@ -217,6 +226,9 @@ func (s *ServiceImpl) handleExpressions(ctx context.Context, user identity.Reque
 				},
 			})
 		}
+		if !expr.IsDataSource(pq.datasource.UID) {
+			dsUIDs = append(dsUIDs, pq.datasource.UID)
+		}
 
 		exprReq.Queries = append(exprReq.Queries, expr.Query{
 			JSON:          pq.query.JSON,
@@ -232,6 +244,12 @@ func (s *ServiceImpl) handleExpressions(ctx context.Context, user identity.Reque
 		})
 	}
 
+	release, err := s.acquire(ctx, user, dsUIDs...)
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+
 	qdr, err := s.expressionService.TransformData(ctx, time.Now(), &exprReq) // use time now because all queries have absolute time range
 	if err != nil {
 		return nil, fmt.Errorf("expression request error: %w", err)
@@ -268,9 +286,35 @@ func (s *ServiceImpl) handleQuerySingleDatasource(ctx context.Context, user iden
 		req.Queries = append(req.Queries, q.query)
 	}
 
+	if chunk := splitDuration(ds); chunk > 0 {
+		return s.queryDataSplit(ctx, user, ds.UID, req, chunk)
+	}
+	return s.limitedQueryData(ctx, user, ds.UID, req)
+}
+
+// limitedQueryData sends a request to a data source once the query limits of the user and data source allow it.
+func (s *ServiceImpl) limitedQueryData(ctx context.Context, user identity.Requester, dsUID string, req *backend.QueryDataRequest) (*backend.QueryDataResponse, error) {
+	release, err := s.acquire(ctx, user, dsUID)
+	if err != nil {
+		return nil, err
+	}
+	defer release()
 	return s.pluginClient.QueryData(ctx, req)
 }
 
+// acquire waits for the query limits of the user and data sources, and sets the Retry-After header of the
+// response when the queries are rejected.
+func (s *ServiceImpl) acquire(ctx context.Context, user identity.Requester, dsUIDs ...string) (func(), error) {
+	release, err := s.limiter.Acquire(ctx, querylimit.SourceQuery, user, dsUIDs...)
+	if err != nil {
+		if reqCtx := contexthandler.FromContext(ctx); reqCtx != nil && reqCtx.Resp != nil {
+			querylimit.SetRetryAfter(reqCtx.Resp.Header(), err)
+		}
+		return nil, err
+	}
+	return release, nil
+}
+
 // parseRequest parses a request into parsed queries grouped by datasource uid
 func (s *ServiceImpl) parseMetricRequest(ctx context.Context, user identity.Requester, skipDSCache bool, reqDTO dtos.MetricRequest) (*parsedRequest, error) {
 	if len(reqDTO.Queries) == 0 {
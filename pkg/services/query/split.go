@@ -0,0 +1,351 @@
+package query
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/grafana/grafana-plugin-sdk-go/backend/gtime"
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"golang.org/x/sync/errgroup"
+
+	"github.com/grafana/grafana/pkg/components/simplejson"
+	"github.com/grafana/grafana/pkg/services/auth/identity"
+	"github.com/grafana/grafana/pkg/services/datasources"
+)
+
+// splitDurationKey is the JSON data setting data sources opt in to query splitting with, like "1d".
+const splitDurationKey = "querySplitDuration"
+
+// splitDuration returns the duration of the chunks the queries of a data source are split into, 0 if they
+// aren't split.
+func splitDuration(ds *datasources.DataSource) time.Duration {
+	if ds.JsonData == nil {
+		return 0
+	}
+	raw := strings.TrimSpace(ds.JsonData.Get(splitDurationKey).MustString())
+	if raw == "" {
+		return 0
+	}
+	d, err := gtime.ParseDuration(raw)
+	if err != nil || d <= 0 {
+		return 0
+	}
+	return d
+}
+
+// rangeVariables matches the variables depending on the whole time range of a query, like $__range and
+// $__interval_ms. Their values would change with the range of each chunk.
+var rangeVariables = regexp.MustCompile(`\$\{?__(range|interval_ms)`)
+
+// canSplit returns whether a query asks for a time series over its time range. Only queries declaring the time
+// series format are split: instant queries only return the values at the end of the range, and tables can't be
+// merged. Queries using variables of the time range aren't split either.
+func canSplit(q backend.DataQuery) bool {
+	if q.QueryType == "instant" || rangeVariables.Match(q.JSON) {
+		return false
+	}
+	model, err := simplejson.NewJson(q.JSON)
+	if err != nil {
+		return false
+	}
+	return model.Get("format").MustString() == "time_series" && !model.Get("instant").MustBool(false)
+}
+
+// alignedChunks aligns a time range to the interval of the query, and splits it into chunks of the given
+// duration. Chunks start at multiples of the duration, so the chunks of two overlapping ranges are the same and
+// their results can be cached.
+func alignedChunks(tr backend.TimeRange, interval time.Duration, chunk time.Duration) []backend.TimeRange {
+	if interval > 0 {
+		tr.From = tr.From.Truncate(interval)
+		if to := tr.To.Truncate(interval); to.Before(tr.To) {
+			tr.To = to.Add(interval)
+		}
+		// chunks hold whole intervals, so no interval spans two chunks
+		if chunk%interval != 0 {
+			chunk = (chunk/interval + 1) * interval
+		}
+	}
+	if !tr.From.Before(tr.To) {
+		return []backend.TimeRange{tr}
+	}
+
+	var chunks []backend.TimeRange
+	for from := tr.From; from.Before(tr.To); {
+		to := from.Truncate(chunk).Add(chunk)
+		if to.After(tr.To) {
+			to = tr.To
+		}
+		chunks = append(chunks, backend.TimeRange{From: from, To: to})
+		from = to
+	}
+	return chunks
+}
+
+// splitQuery returns the chunks of a query, with max data points scaled to keep the resolution of the query. When
+// the query would have more than maxChunks chunks, the duration of the chunks is raised to a multiple of the
+// given duration, so the chunks still start at the same times for every range.
+func splitQuery(q backend.DataQuery, chunk time.Duration, maxChunks int) []backend.DataQuery {
+	ranges := alignedChunks(q.TimeRange, q.Interval, chunk)
+	if maxChunks > 0 && len(ranges) > maxChunks {
+		chunk *= time.Duration((len(ranges) + maxChunks - 1) / maxChunks)
+		ranges = alignedChunks(q.TimeRange, q.Interval, chunk)
+		// the first and last chunks can be partial
+		for len(ranges) > maxChunks {
+			chunk *= 2
+			ranges = alignedChunks(q.TimeRange, q.Interval, chunk)
+		}
+	}
+	total := q.TimeRange.Duration()
+	queries := make([]backend.DataQuery, 0, len(ranges))
+	for _, tr := range ranges {
+		cq := q
+		cq.TimeRange = tr
+		if q.MaxDataPoints > 0 && total > 0 {
+			cq.MaxDataPoints = max(1, int64(float64(q.MaxDataPoints)*float64(tr.Duration())/float64(total)+0.5))
+		}
+		queries = append(queries, cq)
+	}
+	return queries
+}
+
+// queryDataSplit runs the queries of a request to a data source that opted in to query splitting. Queries over
+// more than one chunk run as one request per chunk, with at most splitMaxParallel requests at once and at most
+// splitMaxChunks chunks, and the frames of the chunks are merged. Each request counts against the query limits of
+// the user and data source. Queries with chunks returning frames without time field run again over their whole
+// time range.
+func (s *ServiceImpl) queryDataSplit(ctx context.Context, user identity.Requester, dsUID string, req *backend.QueryDataRequest, chunk time.Duration) (*backend.QueryDataResponse, error) {
+	// the requests for each chunk, in time order, and the queries that aren't split
+	var chunkRequests [][]backend.DataQuery
+	var split, whole []backend.DataQuery
+	for _, q := range req.Queries {
+		if !canSplit(q) {
+			whole = append(whole, q)
+			continue
+		}
+		split = append(split, q)
+		chunks := splitQuery(q, chunk, s.splitMaxChunks)
+		for i, cq := range chunks {
+			if i == len(chunkRequests) {
+				chunkRequests = append(chunkRequests, nil)
+			}
+			chunkRequests[i] = append(chunkRequests[i], cq)
+		}
+	}
+	if len(chunkRequests) <= 1 {
+		return s.limitedQueryData(ctx, user, dsUID, req)
+	}
+	if len(whole) > 0 {
+		chunkRequests = append(chunkRequests, whole)
+	}
+
+	responses := make([]*backend.QueryDataResponse, len(chunkRequests))
+	g, gctx := errgroup.WithContext(ctx)
+	g.SetLimit(s.splitMaxParallel)
+	var mu sync.Mutex
+	for i, queries := range chunkRequests {
+		g.Go(func() error {
+			resp, err := s.limitedQueryData(gctx, user, dsUID, &backend.QueryDataRequest{
+				PluginContext: req.PluginContext,
+				Headers:       req.Headers,
+				Queries:       queries,
+			})
+			if err != nil {
+				return err
+			}
+			mu.Lock()
+			responses[i] = resp
+			mu.Unlock()
+			return nil
+		})
+	}
+	if err := g.Wait(); err != nil {
+		return nil, err
+	}
+
+	merged := mergeChunkResponses(req.Queries, responses)
+
+	var unmerged []backend.DataQuery
+	for _, q := range split {
+		if !hasTimeFields(responses, q.RefID) {
+			unmerged = append(unmerged, q)
+		}
+	}
+	if len(unmerged) > 0 {
+		resp, err := s.limitedQueryData(ctx, user, dsUID, &backend.QueryDataRequest{
+			PluginContext: req.PluginContext,
+			Headers:       req.Headers,
+			Queries:       unmerged,
+		})
+		if err != nil {
+			return nil, err
+		}
+		for _, q := range unmerged {
+			merged.Responses[q.RefID] = resp.Responses[q.RefID]
+		}
+	}
+	return merged, nil
+}
+
+// hasTimeFields returns whether all frames returned for a query by the chunks have a time field.
+func hasTimeFields(chunks []*backend.QueryDataResponse, refID string) bool {
+	for _, chunk := range chunks {
+		if chunk == nil {
+			continue
+		}
+		for _, f := range chunk.Responses[refID].Frames {
+			if timeFieldIndex(f) < 0 {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// mergeChunkResponses merges the responses of the chunks of the queries, in time order.
+func mergeChunkResponses(queries []backend.DataQuery, chunks []*backend.QueryDataResponse) *backend.QueryDataResponse {
+	merged := backend.NewQueryDataResponse()
+	for _, q := range queries {
+		var parts []backend.DataResponse
+		for _, chunk := range chunks {
+			if chunk == nil {
+				continue
+			}
+			if dr, ok := chunk.Responses[q.RefID]; ok {
+				parts = append(parts, dr)
+			}
+		}
+
+		res := backend.DataResponse{}
+		var frames [][]*data.Frame
+		for _, part := range parts {
+			if part.Error != nil && res.Error == nil {
+				res.Error, res.Status, res.ErrorSource = part.Error, part.Status, part.ErrorSource
+			}
+			frames = append(frames, part.Frames)
+		}
+		if res.Error == nil && len(parts) > 0 {
+			res.Status = parts[len(parts)-1].Status
+		}
+		res.Frames = mergeFrames(frames)
+		merged.Responses[q.RefID] = res
+	}
+	return merged
+}
+
+// mergeFrames merges the frames of the chunks of a query. Frames of the same series are concatenated, sorted by
+// time and stripped of the rows duplicated at the boundaries of the chunks. Frames without time field can't be
+// merged, the ones of the last chunk are kept, but queries returning them are run again without splitting.
+func mergeFrames(chunks [][]*data.Frame) data.Frames {
+	var order []string
+	series := map[string][]*data.Frame{}
+	for _, frames := range chunks {
+		for _, f := range frames {
+			key := frameKey(f)
+			if _, ok := series[key]; !ok {
+				order = append(order, key)
+			}
+			if timeFieldIndex(f) < 0 {
+				series[key] = []*data.Frame{f}
+				continue
+			}
+			series[key] = append(series[key], f)
+		}
+	}
+
+	merged := make(data.Frames, 0, len(order))
+	for _, key := range order {
+		merged = append(merged, concatFrames(series[key]))
+	}
+	return merged
+}
+
+// frameKey identifies the frames of one series in the chunks of a query.
+func frameKey(f *data.Frame) string {
+	var sb strings.Builder
+	sb.WriteString(f.Name)
+	for _, field := range f.Fields {
+		sb.WriteString("\x00")
+		sb.WriteString(field.Name)
+		sb.WriteString(field.Labels.String())
+		sb.WriteString(field.Type().ItemTypeString())
+	}
+	return sb.String()
+}
+
+func timeFieldIndex(f *data.Frame) int {
+	for i, field := range f.Fields {
+		if t := field.Type(); t == data.FieldTypeTime || t == data.FieldTypeNullableTime {
+			return i
+		}
+	}
+	return -1
+}
+
+// concatFrames concatenates frames with the same fields, sorted by time. Rows with the same time and the same
+// values in their string fields, which are the labels of long frames, are deduplicated: the last one is kept.
+func concatFrames(frames []*data.Frame) *data.Frame {
+	first := frames[0]
+	if len(frames) == 1 {
+		return first
+	}
+	timeIdx := timeFieldIndex(first)
+
+	type row struct {
+		frame, index int
+		time         time.Time
+		key          string
+	}
+	var rows []row
+	for fi, f := range frames {
+		n, _ := f.RowLen()
+		for ri := 0; ri < n; ri++ {
+			t, ok := f.Fields[timeIdx].ConcreteAt(ri)
+			if !ok {
+				continue
+			}
+			rows = append(rows, row{frame: fi, index: ri, time: t.(time.Time), key: dimensionKey(f, ri)})
+		}
+	}
+	sort.SliceStable(rows, func(i, j int) bool { return rows[i].time.Before(rows[j].time) })
+
+	out := &data.Frame{Name: first.Name, RefID: first.RefID, Meta: first.Meta}
+	for _, field := range first.Fields {
+		f := data.NewFieldFromFieldType(field.Type(), 0)
+		f.Name, f.Labels, f.Config = field.Name, field.Labels, field.Config
+		out.Fields = append(out.Fields, f)
+	}
+
+	for i, r := range rows {
+		// rows of the same time are next to each other, keep the one from the latest chunk
+		duplicate := false
+		for j := i + 1; j < len(rows) && rows[j].time.Equal(r.time); j++ {
+			if rows[j].key == r.key {
+				duplicate = true
+				break
+			}
+		}
+		if duplicate {
+			continue
+		}
+		for fi, field := range frames[r.frame].Fields {
+			out.Fields[fi].Append(field.CopyAt(r.index))
+		}
+	}
+	return out
+}
+
+func dimensionKey(f *data.Frame, row int) string {
+	var parts []string
+	for _, field := range f.Fields {
+		if t := field.Type(); t == data.FieldTypeString || t == data.FieldTypeNullableString {
+			parts = append(parts, fmt.Sprint(field.At(row)))
+		}
+	}
+	return strings.Join(parts, "\x00")
+}
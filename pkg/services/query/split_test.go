@@ -0,0 +1,229 @@
+package query
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/components/simplejson"
+	"github.com/grafana/grafana/pkg/plugins"
+	"github.com/grafana/grafana/pkg/services/datasources"
+)
+
+func TestSplitDuration(t *testing.T) {
+	require.Equal(t, time.Duration(0), splitDuration(&datasources.DataSource{}))
+	require.Equal(t, time.Duration(0), splitDuration(&datasources.DataSource{JsonData: simplejson.New()}))
+	require.Equal(t, 24*time.Hour, splitDuration(&datasources.DataSource{JsonData: simplejson.NewFromAny(map[string]any{splitDurationKey: "1d"})}))
+	require.Equal(t, time.Duration(0), splitDuration(&datasources.DataSource{JsonData: simplejson.NewFromAny(map[string]any{splitDurationKey: "soon"})}))
+}
+
+func TestCanSplit(t *testing.T) {
+	require.True(t, canSplit(backend.DataQuery{JSON: []byte(`{"expr":"up","range":true,"format":"time_series"}`)}))
+	require.False(t, canSplit(backend.DataQuery{JSON: []byte(`{"expr":"up","range":true}`)}))
+	require.False(t, canSplit(backend.DataQuery{JSON: []byte(`{"expr":"up","format":"table"}`)}))
+	require.False(t, canSplit(backend.DataQuery{JSON: []byte(`{"expr":"up","instant":true,"format":"time_series"}`)}))
+	require.False(t, canSplit(backend.DataQuery{JSON: []byte(`{"expr":"up","instant":true,"range":true,"format":"time_series"}`)}))
+	require.False(t, canSplit(backend.DataQuery{QueryType: "instant", JSON: []byte(`{"format":"time_series"}`)}))
+	require.False(t, canSplit(backend.DataQuery{JSON: []byte(`{"expr":"increase(errors_total[$__range])","format":"time_series"}`)}))
+	require.False(t, canSplit(backend.DataQuery{JSON: []byte(`{"expr":"up","interval":"${__interval_ms}ms","format":"time_series"}`)}))
+}
+
+func TestAlignedChunks(t *testing.T) {
+	ts := func(s string) time.Time {
+		parsed, err := time.Parse(time.RFC3339, s)
+		require.NoError(t, err)
+		return parsed
+	}
+
+	t.Run("aligns the range to the interval and the chunks to the epoch", func(t *testing.T) {
+		chunks := alignedChunks(backend.TimeRange{From: ts("2024-01-01T10:07:30Z"), To: ts("2024-01-03T09:59:10Z")}, time.Minute, 24*time.Hour)
+		require.Equal(t, []backend.TimeRange{
+			{From: ts("2024-01-01T10:07:00Z"), To: ts("2024-01-02T00:00:00Z")},
+			{From: ts("2024-01-02T00:00:00Z"), To: ts("2024-01-03T00:00:00Z")},
+			{From: ts("2024-01-03T00:00:00Z"), To: ts("2024-01-03T10:00:00Z")},
+		}, chunks)
+	})
+
+	t.Run("chunks hold whole intervals", func(t *testing.T) {
+		chunks := alignedChunks(backend.TimeRange{From: ts("2024-01-01T00:00:00Z"), To: ts("2024-01-01T02:00:00Z")}, 40*time.Minute, time.Hour)
+		require.Len(t, chunks, 2)
+		require.Equal(t, 80*time.Minute, chunks[0].Duration())
+	})
+
+	t.Run("short ranges are one chunk", func(t *testing.T) {
+		chunks := alignedChunks(backend.TimeRange{From: ts("2024-01-01T10:00:00Z"), To: ts("2024-01-01T11:00:00Z")}, time.Minute, 24*time.Hour)
+		require.Len(t, chunks, 1)
+	})
+}
+
+func TestSplitQuery(t *testing.T) {
+	from := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	q := backend.DataQuery{
+		RefID:         "A",
+		TimeRange:     backend.TimeRange{From: from, To: from.Add(4 * 24 * time.Hour)},
+		Interval:      time.Hour,
+		MaxDataPoints: 1000,
+	}
+
+	chunks := splitQuery(q, 24*time.Hour, 100)
+	require.Len(t, chunks, 4)
+	for _, c := range chunks {
+		require.Equal(t, "A", c.RefID)
+		require.Equal(t, time.Hour, c.Interval)
+		require.Equal(t, int64(250), c.MaxDataPoints)
+	}
+
+	t.Run("raises the chunk duration over the max number of chunks", func(t *testing.T) {
+		q := backend.DataQuery{
+			RefID:     "A",
+			TimeRange: backend.TimeRange{From: from.Add(30 * time.Second), To: from.Add(30 * 24 * time.Hour)},
+			Interval:  time.Minute,
+		}
+
+		chunks := splitQuery(q, time.Minute, 100)
+		require.LessOrEqual(t, len(chunks), 100)
+		require.Equal(t, from, chunks[0].TimeRange.From)
+		require.Equal(t, q.TimeRange.To, chunks[len(chunks)-1].TimeRange.To)
+		for i := 1; i < len(chunks); i++ {
+			require.Equal(t, chunks[i-1].TimeRange.To, chunks[i].TimeRange.From)
+			require.Equal(t, chunks[i].TimeRange.From, chunks[i].TimeRange.From.Truncate(chunks[1].TimeRange.Duration()))
+		}
+	})
+}
+
+func TestMergeFrames(t *testing.T) {
+	at := func(minutes ...int) []time.Time {
+		times := make([]time.Time, 0, len(minutes))
+		for _, m := range minutes {
+			times = append(times, time.Unix(0, 0).Add(time.Duration(m)*time.Minute))
+		}
+		return times
+	}
+
+	t.Run("wide frames", func(t *testing.T) {
+		labels := data.Labels{"job": "api"}
+		merged := mergeFrames([][]*data.Frame{
+			{data.NewFrame("up", data.NewField("time", nil, at(0, 1, 2)), data.NewField("value", labels, []float64{1, 2, 3}))},
+			{data.NewFrame("up", data.NewField("time", nil, at(2, 3)), data.NewField("value", labels, []float64{30, 4}))},
+			{data.NewFrame("up", data.NewField("time", nil, at(4)), data.NewField("value", data.Labels{"job": "db"}, []float64{5}))},
+		})
+
+		require.Len(t, merged, 2)
+		require.Equal(t, 4, merged[0].Rows())
+		require.Equal(t, at(0, 1, 2, 3)[2], merged[0].Fields[0].At(2))
+		require.Equal(t, 30.0, merged[0].Fields[1].At(2))
+		require.Equal(t, labels, merged[0].Fields[1].Labels)
+		require.Equal(t, 1, merged[1].Rows())
+	})
+
+	t.Run("long frames", func(t *testing.T) {
+		merged := mergeFrames([][]*data.Frame{
+			{data.NewFrame("", data.NewField("time", nil, at(0, 0, 1, 1)), data.NewField("job", nil, []string{"api", "db", "api", "db"}), data.NewField("value", nil, []float64{1, 2, 3, 4}))},
+			{data.NewFrame("", data.NewField("time", nil, at(1, 1, 2)), data.NewField("job", nil, []string{"api", "db", "api"}), data.NewField("value", nil, []float64{3, 4, 5}))},
+		})
+
+		require.Len(t, merged, 1)
+		require.Equal(t, 5, merged[0].Rows())
+	})
+
+	t.Run("frames without time field", func(t *testing.T) {
+		merged := mergeFrames([][]*data.Frame{
+			{data.NewFrame("stats", data.NewField("count", nil, []int64{1}))},
+			{data.NewFrame("stats", data.NewField("count", nil, []int64{2}))},
+		})
+
+		require.Len(t, merged, 1)
+		require.Equal(t, int64(2), merged[0].Fields[0].At(0))
+	})
+}
+
+func TestQueryDataSplit(t *testing.T) {
+	from := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	to := from.Add(3 * 24 * time.Hour)
+
+	var mu sync.Mutex
+	var requests []*backend.QueryDataRequest
+	client := &splitPluginClient{queryData: func(ctx context.Context, req *backend.QueryDataRequest) (*backend.QueryDataResponse, error) {
+		mu.Lock()
+		requests = append(requests, req)
+		mu.Unlock()
+
+		resp := backend.NewQueryDataResponse()
+		for _, q := range req.Queries {
+			// one point at each end of the range, like data sources including both ends of the range
+			frame := data.NewFrame("", data.NewField("time", nil, []time.Time{q.TimeRange.From, q.TimeRange.To}), data.NewField("value", nil, []float64{1, 2}))
+			resp.Responses[q.RefID] = backend.DataResponse{Frames: data.Frames{frame}}
+		}
+		return resp, nil
+	}}
+	s := &ServiceImpl{pluginClient: client, splitMaxParallel: 2}
+
+	resp, err := s.queryDataSplit(context.Background(), nil, "ds", &backend.QueryDataRequest{
+		Queries: []backend.DataQuery{
+			{RefID: "A", TimeRange: backend.TimeRange{From: from, To: to}, Interval: time.Minute, MaxDataPoints: 300, JSON: []byte(`{"format":"time_series"}`)},
+			{RefID: "B", TimeRange: backend.TimeRange{From: from, To: to}, Interval: time.Minute, JSON: []byte(`{"instant":true}`)},
+		},
+	}, 24*time.Hour)
+	require.NoError(t, err)
+
+	// three chunks of A, and B as is
+	require.Len(t, requests, 4)
+	for _, req := range requests {
+		require.Len(t, req.Queries, 1)
+		if req.Queries[0].RefID == "A" {
+			require.Equal(t, int64(100), req.Queries[0].MaxDataPoints)
+		}
+	}
+
+	// the points of the chunk boundaries are only returned once
+	require.Equal(t, 4, resp.Responses["A"].Frames[0].Rows())
+	require.Equal(t, 2, resp.Responses["B"].Frames[0].Rows())
+
+	t.Run("short ranges are not split", func(t *testing.T) {
+		requests = nil
+		_, err := s.queryDataSplit(context.Background(), nil, "ds", &backend.QueryDataRequest{
+			Queries: []backend.DataQuery{{RefID: "A", TimeRange: backend.TimeRange{From: from, To: from.Add(time.Hour)}, JSON: []byte(`{"format":"time_series"}`)}},
+		}, 24*time.Hour)
+		require.NoError(t, err)
+		require.Len(t, requests, 1)
+	})
+
+	t.Run("queries returning frames without time field run again without splitting", func(t *testing.T) {
+		var requests []*backend.QueryDataRequest
+		s := &ServiceImpl{splitMaxParallel: 2, pluginClient: &splitPluginClient{queryData: func(ctx context.Context, req *backend.QueryDataRequest) (*backend.QueryDataResponse, error) {
+			mu.Lock()
+			requests = append(requests, req)
+			mu.Unlock()
+
+			resp := backend.NewQueryDataResponse()
+			for _, q := range req.Queries {
+				frame := data.NewFrame("total", data.NewField("value", nil, []float64{q.TimeRange.Duration().Hours()}))
+				resp.Responses[q.RefID] = backend.DataResponse{Frames: data.Frames{frame}}
+			}
+			return resp, nil
+		}}}
+
+		resp, err := s.queryDataSplit(context.Background(), nil, "ds", &backend.QueryDataRequest{
+			Queries: []backend.DataQuery{{RefID: "A", TimeRange: backend.TimeRange{From: from, To: to}, JSON: []byte(`{"format":"time_series"}`)}},
+		}, 24*time.Hour)
+		require.NoError(t, err)
+
+		// three chunks, then the whole range
+		require.Len(t, requests, 4)
+		require.Equal(t, 72.0, resp.Responses["A"].Frames[0].Fields[0].At(0))
+	})
+}
+
+type splitPluginClient struct {
+	plugins.Client
+	queryData backend.QueryDataHandlerFunc
+}
+
+func (c *splitPluginClient) QueryData(ctx context.Context, req *backend.QueryDataRequest) (*backend.QueryDataResponse, error) {
+	return c.queryData(ctx, req)
+}
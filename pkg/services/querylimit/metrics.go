@@ -0,0 +1,36 @@
+package querylimit
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+type metrics struct {
+	rejected      *prometheus.CounterVec
+	inFlight      *prometheus.GaugeVec
+	queueDuration *prometheus.HistogramVec
+}
+
+func newMetrics(reg prometheus.Registerer) *metrics {
+	return &metrics{
+		rejected: promauto.With(reg).NewCounterVec(prometheus.CounterOpts{
+			Namespace: "grafana",
+			Subsystem: "query_limits",
+			Name:      "rejected_total",
+			Help:      "Number of requests rejected by a query limit.",
+		}, []string{"source", "scope", "reason"}),
+		inFlight: promauto.With(reg).NewGaugeVec(prometheus.GaugeOpts{
+			Namespace: "grafana",
+			Subsystem: "query_limits",
+			Name:      "in_flight_requests",
+			Help:      "Number of requests in progress that passed the query limits.",
+		}, []string{"source"}),
+		queueDuration: promauto.With(reg).NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: "grafana",
+			Subsystem: "query_limits",
+			Name:      "queue_duration_seconds",
+			Help:      "Time requests waited for the query limits.",
+			Buckets:   []float64{.001, .01, .1, .25, .5, 1, 2.5, 5, 10},
+		}, []string{"source"}),
+	}
+}
@@ -0,0 +1,313 @@
+// Package querylimit limits the rate and concurrency of data source queries and proxy requests, for each user,
+// organization and data source. Requests over a limit wait in line for up to the queue timeout, and are
+// rejected with 429 Too Many Requests after that.
+package querylimit
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"net/http"
+	"slices"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"golang.org/x/sync/semaphore"
+	"golang.org/x/time/rate"
+
+	"github.com/grafana/grafana/pkg/services/auth/identity"
+	"github.com/grafana/grafana/pkg/setting"
+	"github.com/grafana/grafana/pkg/util/errutil"
+)
+
+const (
+	// SourceQuery are the queries of the query service, like /api/ds/query.
+	SourceQuery = "query"
+	// SourceProxy are the requests of the data source proxy.
+	SourceProxy = "proxy"
+)
+
+const (
+	ScopeUser       = "user"
+	ScopeOrg        = "org"
+	ScopeDatasource = "datasource"
+)
+
+// idleTimeout is how long the limits of a user, org or data source are kept after their last request.
+const idleTimeout = 10 * time.Minute
+
+var (
+	ErrRateLimited        = errutil.TooManyRequests("querylimit.rateLimited", errutil.WithPublicMessage("Too many queries, try again later"))
+	ErrConcurrencyLimited = errutil.TooManyRequests("querylimit.concurrencyLimited", errutil.WithPublicMessage("Too many queries in progress, try again later"))
+)
+
+// LimitError is returned when a request is rejected by a limit. It wraps ErrRateLimited or ErrConcurrencyLimited.
+type LimitError struct {
+	Scope      string
+	RetryAfter time.Duration
+	err        error
+}
+
+func (e *LimitError) Error() string {
+	return e.err.Error()
+}
+
+func (e *LimitError) Unwrap() error {
+	return e.err
+}
+
+// SetRetryAfter sets the Retry-After header for requests rejected by a limit.
+func SetRetryAfter(header http.Header, err error) {
+	var limitErr *LimitError
+	if header == nil || !errors.As(err, &limitErr) {
+		return
+	}
+	header.Set("Retry-After", strconv.Itoa(int(math.Ceil(limitErr.RetryAfter.Seconds()))))
+}
+
+// Limiter enforces the query limits. A nil Limiter doesn't limit anything.
+type Limiter struct {
+	cfg     setting.QueryLimitsSettings
+	now     func() time.Time
+	metrics *metrics
+
+	mu        sync.Mutex
+	entries   map[string]*entry
+	lastSweep time.Time
+}
+
+// entry holds the limits of one user, org or data source.
+type entry struct {
+	scope    string
+	limiter  *rate.Limiter
+	sem      *semaphore.Weighted
+	lastUsed time.Time
+	inUse    int
+}
+
+func ProvideService(cfg *setting.Cfg, reg prometheus.Registerer) *Limiter {
+	if !cfg.QueryLimits.Enabled {
+		return nil
+	}
+	return New(cfg.QueryLimits, reg)
+}
+
+func New(cfg setting.QueryLimitsSettings, reg prometheus.Registerer) *Limiter {
+	return &Limiter{
+		cfg:     cfg,
+		now:     time.Now,
+		metrics: newMetrics(reg),
+		entries: map[string]*entry{},
+	}
+}
+
+// Acquire waits until a request of the user to the data sources is allowed by the limits, and returns a function
+// to call once the request is done. The user and org limits are counted once for requests to several data
+// sources, like queries with expressions. A *LimitError is returned when the request waited longer than the
+// queue timeout.
+func (l *Limiter) Acquire(ctx context.Context, source string, user identity.Requester, dsUIDs ...string) (func(), error) {
+	if l == nil || user == nil {
+		return func() {}, nil
+	}
+
+	start := l.now()
+	entries := l.entriesFor(user, dsUIDs)
+	defer func() {
+		l.mu.Lock()
+		for _, e := range entries {
+			e.inUse--
+			e.lastUsed = l.now()
+		}
+		l.mu.Unlock()
+	}()
+
+	if err := l.waitForRate(ctx, source, entries, start); err != nil {
+		return nil, err
+	}
+
+	acquired, err := l.acquireSlots(ctx, source, entries, start)
+	if err != nil {
+		return nil, err
+	}
+	l.metrics.queueDuration.WithLabelValues(source).Observe(l.now().Sub(start).Seconds())
+	l.metrics.inFlight.WithLabelValues(source).Inc()
+
+	// the entries stay in use while the request is in progress, so they are not swept
+	l.mu.Lock()
+	for _, e := range entries {
+		e.inUse++
+	}
+	l.mu.Unlock()
+
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			for _, e := range acquired {
+				e.sem.Release(1)
+			}
+			l.metrics.inFlight.WithLabelValues(source).Dec()
+			l.mu.Lock()
+			for _, e := range entries {
+				e.inUse--
+				e.lastUsed = l.now()
+			}
+			l.mu.Unlock()
+		})
+	}, nil
+}
+
+// waitForRate reserves a token in every rate limit, and waits for the last one to be available.
+func (l *Limiter) waitForRate(ctx context.Context, source string, entries []*entry, start time.Time) error {
+	var reservations []*rate.Reservation
+	cancel := func() {
+		for _, r := range reservations {
+			r.CancelAt(start)
+		}
+	}
+
+	var delay time.Duration
+	var scope string
+	for _, e := range entries {
+		if e.limiter == nil {
+			continue
+		}
+		r := e.limiter.ReserveN(start, 1)
+		if !r.OK() {
+			cancel()
+			return l.reject(source, e.scope, "rate", ErrRateLimited, l.cfg.QueueTimeout)
+		}
+		reservations = append(reservations, r)
+		if d := r.DelayFrom(start); d > delay {
+			delay, scope = d, e.scope
+		}
+	}
+
+	if delay == 0 {
+		return nil
+	}
+	if delay > l.cfg.QueueTimeout {
+		cancel()
+		return l.reject(source, scope, "rate", ErrRateLimited, delay)
+	}
+
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		cancel()
+		return ctx.Err()
+	}
+}
+
+// acquireSlots takes a slot in every concurrency limit, in the order of the entries so requests to the same data
+// sources don't block each other.
+func (l *Limiter) acquireSlots(ctx context.Context, source string, entries []*entry, start time.Time) ([]*entry, error) {
+	var acquired []*entry
+	releaseAll := func() {
+		for _, e := range acquired {
+			e.sem.Release(1)
+		}
+	}
+
+	waitCtx, cancel := context.WithDeadline(ctx, start.Add(l.cfg.QueueTimeout))
+	defer cancel()
+	for _, e := range entries {
+		if e.sem == nil {
+			continue
+		}
+		if err := e.sem.Acquire(waitCtx, 1); err != nil {
+			releaseAll()
+			if ctx.Err() != nil {
+				return nil, ctx.Err()
+			}
+			return nil, l.reject(source, e.scope, "concurrency", ErrConcurrencyLimited, time.Second)
+		}
+		acquired = append(acquired, e)
+	}
+	return acquired, nil
+}
+
+func (l *Limiter) reject(source, scope, reason string, base errutil.Base, retryAfter time.Duration) error {
+	l.metrics.rejected.WithLabelValues(source, scope, reason).Inc()
+	return &LimitError{
+		Scope:      scope,
+		RetryAfter: retryAfter,
+		err:        base.Errorf("%s %s limit exceeded", scope, reason),
+	}
+}
+
+// entriesFor returns the limits that apply to a request, marked as in use so they aren't swept while waiting.
+func (l *Limiter) entriesFor(user identity.Requester, dsUIDs []string) []*entry {
+	orgID := user.GetOrgID()
+	keys := []struct{ scope, key string }{
+		{ScopeUser, fmt.Sprintf("user:%d:%s", orgID, user.GetID())},
+		{ScopeOrg, fmt.Sprintf("org:%d", orgID)},
+	}
+	uids := slices.Clone(dsUIDs)
+	slices.Sort(uids)
+	for _, uid := range slices.Compact(uids) {
+		keys = append(keys, struct{ scope, key string }{ScopeDatasource, fmt.Sprintf("datasource:%d:%s", orgID, uid)})
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := l.now()
+	if now.Sub(l.lastSweep) > idleTimeout {
+		l.sweep(now)
+	}
+
+	entries := make([]*entry, 0, len(keys))
+	for _, k := range keys {
+		e, ok := l.entries[k.key]
+		if !ok {
+			e = newEntry(k.scope, l.limitFor(k.scope))
+			l.entries[k.key] = e
+		}
+		e.inUse++
+		e.lastUsed = now
+		entries = append(entries, e)
+	}
+	return entries
+}
+
+// sweep removes the limits of users, orgs and data sources without requests for a while.
+func (l *Limiter) sweep(now time.Time) {
+	for key, e := range l.entries {
+		if e.inUse == 0 && now.Sub(e.lastUsed) > idleTimeout {
+			delete(l.entries, key)
+		}
+	}
+	l.lastSweep = now
+}
+
+func (l *Limiter) limitFor(scope string) setting.QueryLimit {
+	switch scope {
+	case ScopeUser:
+		return l.cfg.User
+	case ScopeOrg:
+		return l.cfg.Org
+	default:
+		return l.cfg.Datasource
+	}
+}
+
+func newEntry(scope string, limit setting.QueryLimit) *entry {
+	e := &entry{scope: scope}
+	if limit.Rate > 0 {
+		burst := limit.Burst
+		if burst <= 0 {
+			burst = int(math.Ceil(limit.Rate))
+		}
+		e.limiter = rate.NewLimiter(rate.Limit(limit.Rate), burst)
+	}
+	if limit.MaxConcurrent > 0 {
+		e.sem = semaphore.NewWeighted(int64(limit.MaxConcurrent))
+	}
+	return e
+}
@@ -0,0 +1,173 @@
+package querylimit
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/services/user"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+func newLimiter(cfg setting.QueryLimitsSettings) *Limiter {
+	cfg.Enabled = true
+	return New(cfg, prometheus.NewRegistry())
+}
+
+func TestLimiter(t *testing.T) {
+	alice := &user.SignedInUser{UserID: 1, OrgID: 1}
+	bob := &user.SignedInUser{UserID: 2, OrgID: 1}
+	ctx := context.Background()
+
+	t.Run("nil limiter doesn't limit", func(t *testing.T) {
+		var l *Limiter
+		release, err := l.Acquire(ctx, SourceQuery, alice, "ds")
+		require.NoError(t, err)
+		release()
+	})
+
+	t.Run("rejects requests over the concurrency limit", func(t *testing.T) {
+		l := newLimiter(setting.QueryLimitsSettings{QueueTimeout: 10 * time.Millisecond, User: setting.QueryLimit{MaxConcurrent: 1}})
+
+		release, err := l.Acquire(ctx, SourceQuery, alice, "ds")
+		require.NoError(t, err)
+
+		_, err = l.Acquire(ctx, SourceQuery, alice, "ds")
+		require.True(t, errors.Is(err, ErrConcurrencyLimited))
+		var limitErr *LimitError
+		require.True(t, errors.As(err, &limitErr))
+		require.Equal(t, ScopeUser, limitErr.Scope)
+		require.Equal(t, 1.0, testutil.ToFloat64(l.metrics.rejected.WithLabelValues(SourceQuery, ScopeUser, "concurrency")))
+
+		// other users have their own limit
+		releaseBob, err := l.Acquire(ctx, SourceQuery, bob, "ds")
+		require.NoError(t, err)
+		releaseBob()
+
+		release()
+		release() // releasing twice is a no-op
+		release, err = l.Acquire(ctx, SourceQuery, alice, "ds")
+		require.NoError(t, err)
+		release()
+	})
+
+	t.Run("queues requests until a slot is free", func(t *testing.T) {
+		l := newLimiter(setting.QueryLimitsSettings{QueueTimeout: 5 * time.Second, Org: setting.QueryLimit{MaxConcurrent: 1}})
+
+		release, err := l.Acquire(ctx, SourceProxy, alice, "ds")
+		require.NoError(t, err)
+		go func() {
+			time.Sleep(20 * time.Millisecond)
+			release()
+		}()
+
+		releaseBob, err := l.Acquire(ctx, SourceProxy, bob, "ds")
+		require.NoError(t, err)
+		require.Equal(t, 1.0, testutil.ToFloat64(l.metrics.inFlight.WithLabelValues(SourceProxy)))
+		releaseBob()
+		require.Equal(t, 0.0, testutil.ToFloat64(l.metrics.inFlight.WithLabelValues(SourceProxy)))
+	})
+
+	t.Run("stops waiting when the request is canceled", func(t *testing.T) {
+		l := newLimiter(setting.QueryLimitsSettings{QueueTimeout: 5 * time.Second, User: setting.QueryLimit{MaxConcurrent: 1}})
+
+		release, err := l.Acquire(ctx, SourceQuery, alice, "ds")
+		require.NoError(t, err)
+		defer release()
+
+		canceled, cancel := context.WithCancel(ctx)
+		cancel()
+		_, err = l.Acquire(canceled, SourceQuery, alice, "ds")
+		require.ErrorIs(t, err, context.Canceled)
+	})
+
+	t.Run("limits each data source", func(t *testing.T) {
+		l := newLimiter(setting.QueryLimitsSettings{QueueTimeout: 10 * time.Millisecond, Datasource: setting.QueryLimit{MaxConcurrent: 1}})
+
+		release, err := l.Acquire(ctx, SourceQuery, alice, "a")
+		require.NoError(t, err)
+		defer release()
+
+		releaseB, err := l.Acquire(ctx, SourceQuery, alice, "b")
+		require.NoError(t, err)
+		releaseB()
+
+		_, err = l.Acquire(ctx, SourceQuery, bob, "b", "a")
+		var limitErr *LimitError
+		require.True(t, errors.As(err, &limitErr))
+		require.Equal(t, ScopeDatasource, limitErr.Scope)
+
+		// the slot of b taken before failing on a is given back
+		releaseB, err = l.Acquire(ctx, SourceQuery, bob, "b")
+		require.NoError(t, err)
+		releaseB()
+	})
+
+	t.Run("counts requests to several data sources once for the user", func(t *testing.T) {
+		l := newLimiter(setting.QueryLimitsSettings{QueueTimeout: 10 * time.Millisecond, User: setting.QueryLimit{Rate: 1, MaxConcurrent: 1}})
+
+		release, err := l.Acquire(ctx, SourceQuery, alice, "a", "b", "a")
+		require.NoError(t, err)
+		release()
+	})
+
+	t.Run("rejects requests over the rate limit", func(t *testing.T) {
+		l := newLimiter(setting.QueryLimitsSettings{QueueTimeout: 10 * time.Millisecond, User: setting.QueryLimit{Rate: 0.5, Burst: 1}})
+
+		release, err := l.Acquire(ctx, SourceQuery, alice, "ds")
+		require.NoError(t, err)
+		release()
+
+		_, err = l.Acquire(ctx, SourceQuery, alice, "ds")
+		require.True(t, errors.Is(err, ErrRateLimited))
+		var limitErr *LimitError
+		require.True(t, errors.As(err, &limitErr))
+		require.Greater(t, limitErr.RetryAfter, time.Second)
+
+		header := http.Header{}
+		SetRetryAfter(header, err)
+		require.Equal(t, "2", header.Get("Retry-After"))
+	})
+
+	t.Run("waits for the rate limit within the queue timeout", func(t *testing.T) {
+		l := newLimiter(setting.QueryLimitsSettings{QueueTimeout: time.Second, Datasource: setting.QueryLimit{Rate: 20, Burst: 1}})
+
+		for i := 0; i < 3; i++ {
+			release, err := l.Acquire(ctx, SourceQuery, alice, "ds")
+			require.NoError(t, err)
+			release()
+		}
+	})
+
+	t.Run("forgets idle users", func(t *testing.T) {
+		l := newLimiter(setting.QueryLimitsSettings{User: setting.QueryLimit{MaxConcurrent: 1}})
+		now := time.Now()
+		l.now = func() time.Time { return now }
+
+		release, err := l.Acquire(ctx, SourceQuery, alice, "ds")
+		require.NoError(t, err)
+		require.Len(t, l.entries, 3)
+
+		// entries in use are kept
+		now = now.Add(2 * idleTimeout)
+		l.entriesFor(bob, nil)
+		require.Len(t, l.entries, 4)
+
+		release()
+		now = now.Add(2 * idleTimeout)
+		l.entriesFor(bob, nil)
+		require.Len(t, l.entries, 2)
+	})
+}
+
+func TestSetRetryAfter(t *testing.T) {
+	header := http.Header{}
+	SetRetryAfter(header, errors.New("other error"))
+	require.Empty(t, header.Get("Retry-After"))
+}
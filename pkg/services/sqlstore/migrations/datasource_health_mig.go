@@ -0,0 +1,28 @@
+package migrations
+
+import (
+	. "github.com/grafana/grafana/pkg/services/sqlstore/migrator"
+)
+
+func addDataSourceHealthMigrations(mg *Migrator) {
+	dataSourceHealthV1 := Table{
+		Name: "data_source_health",
+		Columns: []*Column{
+			{Name: "id", Type: DB_BigInt, Nullable: false, IsPrimaryKey: true, IsAutoIncrement: true},
+			{Name: "org_id", Type: DB_BigInt, Nullable: false},
+			{Name: "datasource_uid", Type: DB_NVarchar, Length: 40, Nullable: false},
+			{Name: "status", Type: DB_NVarchar, Length: 20, Nullable: false},
+			{Name: "message", Type: DB_Text, Nullable: true},
+			{Name: "latency_ms", Type: DB_BigInt, Nullable: false},
+			{Name: "checked", Type: DB_BigInt, Nullable: false},
+		},
+		Indices: []*Index{
+			{Cols: []string{"org_id", "datasource_uid", "checked"}},
+			{Cols: []string{"checked"}},
+		},
+	}
+
+	mg.AddMigration("create data_source_health table v1", NewAddTableMigration(dataSourceHealthV1))
+	mg.AddMigration("add index data_source_health.org_id-datasource_uid-checked", NewAddIndexMigration(dataSourceHealthV1, dataSourceHealthV1.Indices[0]))
+	mg.AddMigration("add index data_source_health.checked", NewAddIndexMigration(dataSourceHealthV1, dataSourceHealthV1.Indices[1]))
+}
@@ -123,6 +123,10 @@ func (oss *OSSMigrations) AddMigration(mg *Migrator) {
 	accesscontrol.AddManagedFolderAlertingSilencesActionsMigrator(mg)
 
 	ualert.AddRecordingRuleColumns(mg)
+
+	addDataSourceHealthMigrations(mg)
+
+	ualert.AddLiveChannelColumns(mg)
 }
 
 func addStarMigrations(mg *Migrator) {
@@ -0,0 +1,22 @@
+package ualert
+
+import "github.com/grafana/grafana/pkg/services/sqlstore/migrator"
+
+// AddLiveChannelColumns adds columns to alert_rule to represent streaming rules.
+func AddLiveChannelColumns(mg *migrator.Migrator) {
+	mg.AddMigration("add live_channel column to alert_rule table", migrator.NewAddColumnMigration(migrator.Table{Name: "alert_rule"}, &migrator.Column{
+		Name:     "live_channel",
+		Type:     migrator.DB_NVarchar,
+		Length:   190,
+		Nullable: false,
+		Default:  "''",
+	}))
+
+	mg.AddMigration("add live_channel column to alert_rule_version table", migrator.NewAddColumnMigration(migrator.Table{Name: "alert_rule_version"}, &migrator.Column{
+		Name:     "live_channel",
+		Type:     migrator.DB_NVarchar,
+		Length:   190,
+		Nullable: false,
+		Default:  "''",
+	}))
+}
@@ -428,6 +428,19 @@ type Cfg struct {
 	// LiveAllowedOrigins is a set of origins accepted by Live. If not provided
 	// then Live uses AppURL as the only allowed origin.
 	LiveAllowedOrigins []string
+	// LiveMQTTEnabled enables MQTT listener which maps published topics to
+	// Live stream channels.
+	LiveMQTTEnabled bool
+	// LiveMQTTAddress is an address MQTT listener binds to.
+	LiveMQTTAddress string
+	// LiveMQTTCertFile and LiveMQTTCertKey enable TLS for MQTT listener.
+	LiveMQTTCertFile string
+	LiveMQTTCertKey  string
+	// LiveMQTTMessageSizeLimit is a maximum size in bytes of MQTT packet.
+	LiveMQTTMessageSizeLimit int
+	// LiveMQTTFrameFormat is a frame format used to convert Influx line
+	// protocol payloads of topics without Pipeline channel rule.
+	LiveMQTTFrameFormat string
 
 	// Grafana.com URL, used for OAuth redirect.
 	GrafanaComURL string
@@ -453,6 +466,14 @@ type Cfg struct {
 
 	Search SearchSettings
 
+	QueryCaching QueryCachingSettings
+
+	QueryLimits QueryLimitsSettings
+
+	DatasourceHealth DatasourceHealthSettings
+
+	QueryRecording QueryRecordingSettings
+
 	SecureSocksDSProxy SecureSocksDSProxySettings
 
 	// SAML Auth
@@ -1228,6 +1249,10 @@ func (cfg *Cfg) parseINIFile(iniFile *ini.File) error {
 
 	cfg.Storage = readStorageSettings(iniFile)
 	cfg.Search = readSearchSettings(iniFile)
+	cfg.QueryCaching = readQueryCachingSettings(iniFile)
+	cfg.QueryLimits = readQueryLimitsSettings(iniFile)
+	cfg.DatasourceHealth = readDatasourceHealthSettings(iniFile)
+	cfg.QueryRecording = readQueryRecordingSettings(iniFile, cfg.DataPath)
 
 	var err error
 	cfg.SecureSocksDSProxy, err = readSecureSocksDSProxySettings(iniFile)
@@ -2001,6 +2026,16 @@ func (cfg *Cfg) readLiveSettings(iniFile *ini.File) error {
 		return err
 	}
 	cfg.LiveAllowedOrigins = originPatterns
+
+	cfg.LiveMQTTEnabled = section.Key("mqtt_enabled").MustBool(false)
+	cfg.LiveMQTTAddress = section.Key("mqtt_address").MustString(":1883")
+	cfg.LiveMQTTCertFile = section.Key("mqtt_cert_file").MustString("")
+	cfg.LiveMQTTCertKey = section.Key("mqtt_cert_key").MustString("")
+	cfg.LiveMQTTMessageSizeLimit = section.Key("mqtt_message_size_limit").MustInt(1024 * 1024)
+	if cfg.LiveMQTTMessageSizeLimit < 0 {
+		return fmt.Errorf("unexpected value %d for [live] mqtt_message_size_limit", cfg.LiveMQTTMessageSizeLimit)
+	}
+	cfg.LiveMQTTFrameFormat = section.Key("mqtt_frame_format").MustString("labels_column")
 	return nil
 }
 
@@ -0,0 +1,42 @@
+package setting
+
+import (
+	"time"
+
+	"gopkg.in/ini.v1"
+)
+
+type DatasourceHealthSettings struct {
+	// Enabled turns on the background health checks of all data sources.
+	Enabled bool
+	// Interval is the time between two health checks of a data source.
+	Interval time.Duration
+	// Timeout is how long a health check can take before it fails.
+	Timeout time.Duration
+	// MaxConcurrent is the number of data sources checked at the same time.
+	MaxConcurrent int
+	// HistoryRetention is how long health checks are kept.
+	HistoryRetention time.Duration
+	// Annotations creates an annotation when a data source becomes unhealthy or recovers.
+	Annotations bool
+}
+
+func readDatasourceHealthSettings(iniFile *ini.File) DatasourceHealthSettings {
+	s := DatasourceHealthSettings{}
+
+	section := iniFile.Section("datasource_health")
+	s.Enabled = section.Key("enabled").MustBool(false)
+	s.Interval = section.Key("interval").MustDuration(time.Minute)
+	s.Timeout = section.Key("timeout").MustDuration(30 * time.Second)
+	s.MaxConcurrent = section.Key("max_concurrent").MustInt(4)
+	s.HistoryRetention = section.Key("history_retention").MustDuration(7 * 24 * time.Hour)
+	s.Annotations = section.Key("annotations").MustBool(false)
+
+	if s.Interval < 10*time.Second {
+		s.Interval = 10 * time.Second
+	}
+	if s.MaxConcurrent < 1 {
+		s.MaxConcurrent = 1
+	}
+	return s
+}
@@ -0,0 +1,26 @@
+package setting
+
+import (
+	"time"
+
+	"gopkg.in/ini.v1"
+)
+
+type QueryCachingSettings struct {
+	// Enabled turns on the built-in query result cache, backed by the remote cache.
+	Enabled bool
+	// TTL is how long results are cached for data sources that don't set their own TTL.
+	TTL time.Duration
+	// MaxValueSize is the largest encoded response that is cached, in bytes. 0 means no limit.
+	MaxValueSize int
+}
+
+func readQueryCachingSettings(iniFile *ini.File) QueryCachingSettings {
+	s := QueryCachingSettings{}
+
+	section := iniFile.Section("query_caching")
+	s.Enabled = section.Key("enabled").MustBool(false)
+	s.TTL = section.Key("ttl").MustDuration(5 * time.Minute)
+	s.MaxValueSize = section.Key("max_value_size").MustInt(10 * 1024 * 1024)
+	return s
+}
@@ -0,0 +1,48 @@
+package setting
+
+import (
+	"time"
+
+	"gopkg.in/ini.v1"
+)
+
+type QueryLimitsSettings struct {
+	// Enabled turns on rate and concurrency limits for data source queries and proxy requests.
+	Enabled bool
+	// QueueTimeout is how long a request waits for its turn before it's rejected.
+	QueueTimeout time.Duration
+
+	User       QueryLimit
+	Org        QueryLimit
+	Datasource QueryLimit
+}
+
+// QueryLimit limits the requests of one user, org or data source. Zero values disable a limit.
+type QueryLimit struct {
+	// Rate is the number of requests per second.
+	Rate float64
+	// Burst is the number of requests that can be made at once on top of the rate.
+	Burst int
+	// MaxConcurrent is the number of requests that can be in flight.
+	MaxConcurrent int
+}
+
+func readQueryLimitsSettings(iniFile *ini.File) QueryLimitsSettings {
+	s := QueryLimitsSettings{}
+
+	section := iniFile.Section("query_limits")
+	s.Enabled = section.Key("enabled").MustBool(false)
+	s.QueueTimeout = section.Key("queue_timeout").MustDuration(10 * time.Second)
+
+	readLimit := func(scope string) QueryLimit {
+		return QueryLimit{
+			Rate:          section.Key(scope + "_rate").MustFloat64(0),
+			Burst:         section.Key(scope + "_burst").MustInt(0),
+			MaxConcurrent: section.Key(scope + "_max_concurrent").MustInt(0),
+		}
+	}
+	s.User = readLimit("user")
+	s.Org = readLimit("org")
+	s.Datasource = readLimit("datasource")
+	return s
+}
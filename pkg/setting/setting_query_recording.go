@@ -0,0 +1,23 @@
+package setting
+
+import (
+	"path/filepath"
+
+	"gopkg.in/ini.v1"
+)
+
+type QueryRecordingSettings struct {
+	// Enabled allows data sources to record their responses, or to replay them.
+	Enabled bool
+	// Path is the directory of the recordings.
+	Path string
+}
+
+func readQueryRecordingSettings(iniFile *ini.File, dataPath string) QueryRecordingSettings {
+	s := QueryRecordingSettings{}
+
+	section := iniFile.Section("query_recording")
+	s.Enabled = section.Key("enabled").MustBool(false)
+	s.Path = section.Key("path").MustString(filepath.Join(dataPath, "recordings"))
+	return s
+}
@@ -446,6 +446,103 @@ func TestIntegrationCreate(t *testing.T) {
 	})
 }
 
+func TestIntegrationSaveMergedDashboard(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test")
+	}
+
+	// Setup Grafana and its Database
+	dir, path := testinfra.CreateGrafDir(t, testinfra.GrafanaOpts{
+		DisableAnonymous: true,
+	})
+
+	grafanaListedAddr, env := testinfra.StartGrafanaEnv(t, dir, path)
+	store, cfg := env.SQLStore, env.Cfg
+	// Create user
+	createUser(t, store, cfg, user.CreateUserCommand{
+		DefaultOrgRole: string(org.RoleAdmin),
+		Password:       "admin",
+		Login:          "admin",
+	})
+
+	m := saveDashboard(t, grafanaListedAddr, `{"uid": "merged", "title": "merged", "panels": [{"id": 1, "title": "CPU"}, {"id": 2, "title": "Memory"}]}`)
+	require.Equal(t, float64(1), m["version"])
+	require.Equal(t, false, m["merged"])
+
+	// two users save changes to different panels of the first version
+	m = saveDashboard(t, grafanaListedAddr, `{"uid": "merged", "title": "merged", "version": 1, "panels": [{"id": 1, "title": "CPU usage"}, {"id": 2, "title": "Memory"}]}`)
+	require.Equal(t, float64(2), m["version"])
+	require.Equal(t, false, m["merged"])
+
+	m = saveDashboard(t, grafanaListedAddr, `{"uid": "merged", "title": "merged", "version": 1, "panels": [{"id": 1, "title": "CPU"}, {"id": 2, "title": "Memory usage"}]}`)
+	require.Equal(t, float64(3), m["version"])
+	require.Equal(t, true, m["merged"])
+
+	// the merged dashboard is loaded again before the next save, so it keeps the changes of both users
+	dash := getDashboard(t, grafanaListedAddr, "merged")
+	require.Equal(t, 3, dash.Get("version").MustInt())
+	require.Equal(t, "CPU usage", dash.Get("panels").GetIndex(0).Get("title").MustString())
+	require.Equal(t, "Memory usage", dash.Get("panels").GetIndex(1).Get("title").MustString())
+
+	dash.Set("title", "merged again")
+	b, err := dash.MarshalJSON()
+	require.NoError(t, err)
+	m = saveDashboard(t, grafanaListedAddr, string(b))
+	require.Equal(t, float64(4), m["version"])
+	require.Equal(t, false, m["merged"])
+
+	dash = getDashboard(t, grafanaListedAddr, "merged")
+	require.Equal(t, "merged again", dash.Get("title").MustString())
+	require.Equal(t, "CPU usage", dash.Get("panels").GetIndex(0).Get("title").MustString())
+	require.Equal(t, "Memory usage", dash.Get("panels").GetIndex(1).Get("title").MustString())
+}
+
+func saveDashboard(t *testing.T, grafanaListedAddr string, data string) util.DynMap {
+	t.Helper()
+
+	dashboardData, err := simplejson.NewJson([]byte(data))
+	require.NoError(t, err)
+	buf := &bytes.Buffer{}
+	err = json.NewEncoder(buf).Encode(dashboards.SaveDashboardCommand{
+		Dashboard: dashboardData,
+	})
+	require.NoError(t, err)
+	u := fmt.Sprintf("http://admin:admin@%s/api/dashboards/db", grafanaListedAddr)
+	// nolint:gosec
+	resp, err := http.Post(u, "application/json", buf)
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		err := resp.Body.Close()
+		require.NoError(t, err)
+	})
+	b, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+	require.Equal(t, http.StatusOK, resp.StatusCode, string(b))
+	var m util.DynMap
+	err = json.Unmarshal(b, &m)
+	require.NoError(t, err)
+	return m
+}
+
+func getDashboard(t *testing.T, grafanaListedAddr string, uid string) *simplejson.Json {
+	t.Helper()
+
+	u := fmt.Sprintf("http://admin:admin@%s/api/dashboards/uid/%s", grafanaListedAddr, uid)
+	// nolint:gosec
+	resp, err := http.Get(u)
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		err := resp.Body.Close()
+		require.NoError(t, err)
+	})
+	b, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+	require.Equal(t, http.StatusOK, resp.StatusCode, string(b))
+	res, err := simplejson.NewJson(b)
+	require.NoError(t, err)
+	return res.Get("dashboard")
+}
+
 func createFolder(t *testing.T, grafanaListedAddr string, title string) *dtos.Folder {
 	t.Helper()
 
@@ -245,6 +245,21 @@ type TermsAggregation struct {
 	Missing     *string                `json:"missing,omitempty"`
 }
 
+// CompositeAggregation represents a composite aggregation. Its buckets are paginated with the after key
+// returned by Elasticsearch.
+type CompositeAggregation struct {
+	Size    int              `json:"size"`
+	Sources []map[string]any `json:"sources"`
+	After   map[string]any   `json:"after,omitempty"`
+}
+
+// CompositeTermsSource represents a terms source of a composite aggregation
+type CompositeTermsSource struct {
+	Field         string `json:"field"`
+	Order         string `json:"order,omitempty"`
+	MissingBucket bool   `json:"missing_bucket,omitempty"`
+}
+
 // NestedAggregation represents a nested aggregation
 type NestedAggregation struct {
 	Path string `json:"path"`
@@ -0,0 +1,161 @@
+package es
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+
+	exp "github.com/grafana/grafana-plugin-sdk-go/experimental/errorsource"
+)
+
+const (
+	esqlPath = "_query"
+	pplPath  = "_plugins/_ppl"
+)
+
+// RawQueryRequest is a request of the ES|QL and PPL query endpoints.
+type RawQueryRequest struct {
+	Query string `json:"query"`
+	// Filter is a Query DSL filter applied before the query, used for the time range of the query.
+	Filter map[string]any `json:"filter,omitempty"`
+}
+
+// RawQueryColumn describes a column of an ES|QL or PPL response.
+type RawQueryColumn struct {
+	Name string `json:"name"`
+	Type string `json:"type"`
+}
+
+// RawQueryResponse is the response of an ES|QL or PPL query, with values grouped by column.
+type RawQueryResponse struct {
+	Columns []RawQueryColumn
+	Values  [][]any
+}
+
+type esqlResponse struct {
+	Columns []RawQueryColumn `json:"columns"`
+	Values  [][]any          `json:"values"`
+}
+
+type pplResponse struct {
+	Schema   []RawQueryColumn `json:"schema"`
+	DataRows [][]any          `json:"datarows"`
+}
+
+type rawQueryErrorResponse struct {
+	Error struct {
+		Type    string `json:"type"`
+		Reason  string `json:"reason"`
+		Details string `json:"details"`
+	} `json:"error"`
+}
+
+// ExecuteESQL executes an ES|QL query. Results are requested in columnar format, so they do not need to be
+// transposed to build data frames.
+func (c *baseClientImpl) ExecuteESQL(r *RawQueryRequest) (*RawQueryResponse, error) {
+	body := struct {
+		*RawQueryRequest
+		Columnar bool `json:"columnar"`
+	}{RawQueryRequest: r, Columnar: true}
+
+	var res esqlResponse
+	if err := c.executeRawQuery("executeESQL", esqlPath, body, &res); err != nil {
+		return nil, err
+	}
+	return &RawQueryResponse{Columns: res.Columns, Values: res.Values}, nil
+}
+
+// ExecutePPL executes a PPL query of OpenSearch compatible clusters. PPL returns rows, which are transposed
+// to columns.
+func (c *baseClientImpl) ExecutePPL(r *RawQueryRequest) (*RawQueryResponse, error) {
+	var res pplResponse
+	if err := c.executeRawQuery("executePPL", pplPath, r, &res); err != nil {
+		return nil, err
+	}
+
+	values := make([][]any, len(res.Schema))
+	for i := range values {
+		values[i] = make([]any, 0, len(res.DataRows))
+	}
+	for _, row := range res.DataRows {
+		if len(row) != len(res.Schema) {
+			return nil, fmt.Errorf("invalid PPL response: row has %d values for %d columns", len(row), len(res.Schema))
+		}
+		for i, v := range row {
+			values[i] = append(values[i], v)
+		}
+	}
+	return &RawQueryResponse{Columns: res.Schema, Values: values}, nil
+}
+
+func (c *baseClientImpl) executeRawQuery(operation string, uriPath string, body any, result any) (err error) {
+	_, span := c.tracer.Start(c.ctx, "datasource.elasticsearch.queryData."+operation, trace.WithAttributes(
+		attribute.String("url", c.ds.URL),
+	))
+	defer func() {
+		if err != nil {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, err.Error())
+		}
+		span.End()
+	}()
+
+	reqBody, err := json.Marshal(body)
+	if err != nil {
+		return err
+	}
+
+	start := time.Now()
+	res, err := c.executeRequest(http.MethodPost, uriPath, "", "application/json", reqBody)
+	if err != nil {
+		c.logger.Error("Error received from Elasticsearch", "error", err, "operation", operation, "duration", time.Since(start), "stage", StageDatabaseRequest)
+		return err
+	}
+	defer func() {
+		if err := res.Body.Close(); err != nil {
+			c.logger.Warn("Failed to close response body", "error", err)
+		}
+	}()
+
+	c.logger.Info("Response received from Elasticsearch", "operation", operation, "statusCode", res.StatusCode, "duration", time.Since(start), "stage", StageDatabaseRequest)
+
+	if res.StatusCode/100 != 2 {
+		return rawQueryError(res)
+	}
+	// numbers are decoded as json.Number to keep the precision of long values
+	dec := json.NewDecoder(res.Body)
+	dec.UseNumber()
+	if err := dec.Decode(result); err != nil {
+		c.logger.Error("Failed to decode response from Elasticsearch", "error", err, "operation", operation)
+		return err
+	}
+	return nil
+}
+
+// rawQueryError returns the error of a failed query. Invalid queries are reported as downstream errors,
+// as they are caused by the query written by the user.
+func rawQueryError(res *http.Response) error {
+	body, _ := io.ReadAll(res.Body)
+
+	var errRes rawQueryErrorResponse
+	msg := fmt.Sprintf("request failed, status: %s", res.Status)
+	if json.Unmarshal(body, &errRes) == nil && errRes.Error.Reason != "" {
+		msg = errRes.Error.Reason
+		if errRes.Error.Details != "" {
+			msg += ": " + errRes.Error.Details
+		}
+	}
+
+	err := errors.New(msg)
+	if res.StatusCode/100 == 4 {
+		return exp.DownstreamError(err, false)
+	}
+	return err
+}
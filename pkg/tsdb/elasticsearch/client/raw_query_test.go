@@ -0,0 +1,86 @@
+package es
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/infra/tracing"
+)
+
+func newRawQueryTestClient(t *testing.T, handler http.HandlerFunc) Client {
+	t.Helper()
+	ts := httptest.NewServer(handler)
+	t.Cleanup(ts.Close)
+
+	ds := DatasourceInfo{
+		URL:              ts.URL,
+		HTTPClient:       ts.Client(),
+		Database:         "logs",
+		ConfiguredFields: ConfiguredFields{TimeField: "@timestamp"},
+	}
+	c, err := NewClient(context.Background(), &ds, log.New("test", "test"), tracing.InitializeTracerForTest())
+	require.NoError(t, err)
+	return c
+}
+
+func TestClient_ExecuteESQL(t *testing.T) {
+	t.Run("requests columnar results", func(t *testing.T) {
+		c := newRawQueryTestClient(t, func(rw http.ResponseWriter, r *http.Request) {
+			assert.Equal(t, http.MethodPost, r.Method)
+			assert.Equal(t, "/_query", r.URL.Path)
+			assert.Equal(t, "application/json", r.Header.Get("Content-Type"))
+
+			var body map[string]any
+			assert.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+			assert.Equal(t, "FROM logs", body["query"])
+			assert.Equal(t, true, body["columnar"])
+			assert.NotNil(t, body["filter"])
+
+			_, _ = rw.Write([]byte(`{
+				"columns": [{"name": "host", "type": "keyword"}, {"name": "count", "type": "long"}],
+				"values": [["a", "b"], [9007199254740993, 2]]
+			}`))
+		})
+
+		res, err := c.ExecuteESQL(&RawQueryRequest{Query: "FROM logs", Filter: map[string]any{"match_all": map[string]any{}}})
+		require.NoError(t, err)
+		require.Len(t, res.Columns, 2)
+		require.Equal(t, []any{"a", "b"}, res.Values[0])
+		require.Equal(t, json.Number("9007199254740993"), res.Values[1][0])
+	})
+
+	t.Run("returns the reason of failed queries", func(t *testing.T) {
+		c := newRawQueryTestClient(t, func(rw http.ResponseWriter, r *http.Request) {
+			rw.WriteHeader(http.StatusBadRequest)
+			_, _ = rw.Write([]byte(`{"error": {"type": "verification_exception", "reason": "Unknown column [foo]"}, "status": 400}`))
+		})
+
+		_, err := c.ExecuteESQL(&RawQueryRequest{Query: "FROM logs | KEEP foo"})
+		require.ErrorContains(t, err, "Unknown column [foo]")
+	})
+}
+
+func TestClient_ExecutePPL(t *testing.T) {
+	c := newRawQueryTestClient(t, func(rw http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/_plugins/_ppl", r.URL.Path)
+		_, _ = rw.Write([]byte(`{
+			"schema": [{"name": "host", "type": "string"}, {"name": "count()", "type": "integer"}],
+			"datarows": [["a", 1], ["b", 2]],
+			"total": 2,
+			"size": 2
+		}`))
+	})
+
+	res, err := c.ExecutePPL(&RawQueryRequest{Query: "source=logs | stats count() by host"})
+	require.NoError(t, err)
+	require.Len(t, res.Columns, 2)
+	require.Equal(t, []any{"a", "b"}, res.Values[0])
+	require.Equal(t, []any{json.Number("1"), json.Number("2")}, res.Values[1])
+}
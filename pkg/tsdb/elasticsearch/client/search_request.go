@@ -303,6 +303,7 @@ type AggBuilder interface {
 	Histogram(key, field string, fn func(a *HistogramAgg, b AggBuilder)) AggBuilder
 	DateHistogram(key, field string, fn func(a *DateHistogramAgg, b AggBuilder)) AggBuilder
 	Terms(key, field string, fn func(a *TermsAggregation, b AggBuilder)) AggBuilder
+	CompositeTerms(key, field string, fn func(a *CompositeAggregation, source *CompositeTermsSource, b AggBuilder)) AggBuilder
 	Nested(key, path string, fn func(a *NestedAggregation, b AggBuilder)) AggBuilder
 	Filters(key string, fn func(a *FiltersAggregation, b AggBuilder)) AggBuilder
 	GeoHashGrid(key, field string, fn func(a *GeoHashGridAggregation, b AggBuilder)) AggBuilder
@@ -416,6 +417,31 @@ func (b *aggBuilderImpl) Terms(key, field string, fn func(a *TermsAggregation, b
 	return b
 }
 
+// CompositeTerms adds a composite aggregation with a single terms source. The source uses the key of the
+// aggregation as name, so the buckets can be converted to terms buckets.
+func (b *aggBuilderImpl) CompositeTerms(key, field string, fn func(a *CompositeAggregation, source *CompositeTermsSource, b AggBuilder)) AggBuilder {
+	source := &CompositeTermsSource{
+		Field: field,
+	}
+	innerAgg := &CompositeAggregation{
+		Sources: []map[string]any{{key: map[string]any{"terms": source}}},
+	}
+	aggDef := newAggDef(key, &aggContainer{
+		Type:        "composite",
+		Aggregation: innerAgg,
+	})
+
+	if fn != nil {
+		builder := newAggBuilder()
+		aggDef.builders = append(aggDef.builders, builder)
+		fn(innerAgg, source, builder)
+	}
+
+	b.aggDefs = append(b.aggDefs, aggDef)
+
+	return b
+}
+
 func (b *aggBuilderImpl) Nested(key, field string, fn func(a *NestedAggregation, b AggBuilder)) AggBuilder {
 	innerAgg := &NestedAggregation{
 		Path: field,
@@ -0,0 +1,219 @@
+package elasticsearch
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+
+	es "github.com/grafana/grafana/pkg/tsdb/elasticsearch/client"
+)
+
+const (
+	// defaultCompositeSeriesLimit is the default maximum number of terms fetched with a composite aggregation.
+	defaultCompositeSeriesLimit = 10000
+)
+
+// isCompositeTermsAgg returns true if a terms aggregation is sent as a composite aggregation, which returns all
+// terms page by page instead of the `size` most relevant ones.
+func isCompositeTermsAgg(bucketAgg *BucketAgg) bool {
+	return bucketAgg.Type == termsType && bucketAgg.Settings.Get("useComposite").MustBool(false)
+}
+
+// compositeSeriesLimit returns the maximum number of terms fetched for a composite terms aggregation.
+func compositeSeriesLimit(bucketAgg *BucketAgg) int {
+	limit, err := bucketAgg.Settings.Get("seriesLimit").Int()
+	if err != nil {
+		limit = stringToIntWithDefaultValue(bucketAgg.Settings.Get("seriesLimit").MustString(), defaultCompositeSeriesLimit)
+	}
+	if limit <= 0 {
+		return defaultCompositeSeriesLimit
+	}
+	return limit
+}
+
+// compositePages collects the pages of the composite aggregation of a query.
+type compositePages struct {
+	query    *Query
+	agg      *BucketAgg
+	result   map[string]any
+	limit    int
+	buckets  []any
+	afterKey map[string]any
+	err      error
+}
+
+func newCompositePages(q *Query, res *es.SearchResponse) *compositePages {
+	if res == nil || res.Error != nil || len(q.BucketAggs) == 0 || !isCompositeTermsAgg(q.BucketAggs[0]) {
+		return nil
+	}
+	agg := q.BucketAggs[0]
+	result, ok := res.Aggregations[agg.ID].(map[string]any)
+	if !ok {
+		return nil
+	}
+	p := &compositePages{
+		query:  q,
+		agg:    agg,
+		result: result,
+		limit:  compositeSeriesLimit(agg),
+	}
+	p.add(result)
+	return p
+}
+
+func (p *compositePages) add(result map[string]any) {
+	buckets, _ := result["buckets"].([]any)
+	p.buckets = append(p.buckets, buckets...)
+	p.afterKey, _ = result["after_key"].(map[string]any)
+	if len(buckets) == 0 {
+		p.afterKey = nil
+	}
+}
+
+func (p *compositePages) addPage(res *es.SearchResponse) {
+	if res == nil {
+		p.err = fmt.Errorf("failed to fetch all terms: missing response")
+		return
+	}
+	if res.Error != nil {
+		p.err = fmt.Errorf("failed to fetch all terms: %s", getErrorFromElasticResponse(res))
+		return
+	}
+	result, ok := res.Aggregations[p.agg.ID].(map[string]any)
+	if !ok {
+		p.afterKey = nil
+		return
+	}
+	p.add(result)
+}
+
+func (p *compositePages) hasNext() bool {
+	return p.err == nil && p.afterKey != nil && len(p.buckets) < p.limit
+}
+
+func (p *compositePages) limitReached() bool {
+	return len(p.buckets) > p.limit || (len(p.buckets) == p.limit && p.afterKey != nil)
+}
+
+// finish replaces the buckets of the first page with the buckets of all pages, converted to terms buckets so
+// the response is parsed like a terms aggregation.
+func (p *compositePages) finish() {
+	buckets := p.buckets
+	if len(buckets) > p.limit {
+		buckets = buckets[:p.limit]
+	}
+	missing := p.agg.Settings.Get("missing").MustString()
+	for _, b := range buckets {
+		bucket, ok := b.(map[string]any)
+		if !ok {
+			continue
+		}
+		if key, ok := bucket["key"].(map[string]any); ok {
+			bucket["key"] = key[p.agg.ID]
+			if bucket["key"] == nil {
+				bucket["key"] = missing
+			}
+		}
+	}
+	p.result["buckets"] = buckets
+	delete(p.result, "after_key")
+}
+
+// paginateCompositeAggs fetches the remaining pages of composite terms aggregations and merges them into the
+// responses of the first page. Pages of all queries are fetched together, one multisearch request per page.
+// It returns notices for the queries whose terms were truncated.
+func (e *elasticsearchDataQuery) paginateCompositeAggs(queries []*Query, responses []*es.SearchResponse) map[string]data.Notice {
+	var pages []*compositePages
+	for i, q := range queries {
+		if i >= len(responses) {
+			break
+		}
+		if p := newCompositePages(q, responses[i]); p != nil {
+			pages = append(pages, p)
+		}
+	}
+
+	for {
+		var pending []*compositePages
+		for _, p := range pages {
+			if p.hasNext() {
+				pending = append(pending, p)
+			}
+		}
+		if len(pending) == 0 {
+			break
+		}
+
+		if err := e.fetchCompositePages(pending); err != nil {
+			e.logger.Warn("Failed to fetch composite aggregation pages", "error", err)
+			for _, p := range pending {
+				p.err = err
+			}
+		}
+	}
+
+	notices := map[string]data.Notice{}
+	for _, p := range pages {
+		p.finish()
+		switch {
+		case p.err != nil:
+			notices[p.query.RefID] = data.Notice{
+				Severity: data.NoticeSeverityWarning,
+				Text:     fmt.Sprintf("Results are incomplete: %s", p.err),
+			}
+		case p.limitReached():
+			notices[p.query.RefID] = data.Notice{
+				Severity: data.NoticeSeverityWarning,
+				Text:     fmt.Sprintf("Series limit of %d terms reached, results are truncated. Refine the query or increase the series limit.", p.limit),
+			}
+		}
+	}
+	return notices
+}
+
+func (e *elasticsearchDataQuery) fetchCompositePages(pending []*compositePages) error {
+	ms := e.client.MultiSearch()
+	for _, p := range pending {
+		p.agg.After = p.afterKey
+		from := p.query.TimeRange.From.UnixNano() / int64(time.Millisecond)
+		to := p.query.TimeRange.To.UnixNano() / int64(time.Millisecond)
+		if err := e.processQuery(p.query, ms, from, to); err != nil {
+			return err
+		}
+	}
+	req, err := ms.Build()
+	if err != nil {
+		return err
+	}
+	res, err := e.client.ExecuteMultisearch(req)
+	if err != nil {
+		return err
+	}
+	for i, p := range pending {
+		var pageRes *es.SearchResponse
+		if i < len(res.Responses) {
+			pageRes = res.Responses[i]
+		}
+		p.addPage(pageRes)
+	}
+	return nil
+}
+
+// addNotices adds the notices of queries to all of their frames.
+func addNotices(result *backend.QueryDataResponse, notices map[string]data.Notice) {
+	for refID, notice := range notices {
+		res, ok := result.Responses[refID]
+		if !ok {
+			continue
+		}
+		if len(res.Frames) == 0 {
+			res.Frames = data.Frames{data.NewFrame("")}
+		}
+		for _, frame := range res.Frames {
+			frame.AppendNotices(notice)
+		}
+		result.Responses[refID] = res
+	}
+}
@@ -0,0 +1,141 @@
+package elasticsearch
+
+import (
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	es "github.com/grafana/grafana/pkg/tsdb/elasticsearch/client"
+)
+
+func compositePage(afterKey map[string]any, hosts ...any) *es.MultiSearchResponse {
+	buckets := make([]any, 0, len(hosts))
+	for _, host := range hosts {
+		buckets = append(buckets, map[string]any{
+			"key":       map[string]any{"2": host},
+			"doc_count": 1,
+			"3": map[string]any{
+				"buckets": []any{
+					map[string]any{"key": 1000, "doc_count": 1},
+				},
+			},
+		})
+	}
+	agg := map[string]any{"buckets": buckets}
+	if afterKey != nil {
+		agg["after_key"] = afterKey
+	}
+	return &es.MultiSearchResponse{
+		Responses: []*es.SearchResponse{{Aggregations: map[string]any{"2": agg}}},
+	}
+}
+
+func TestCompositeTermsPagination(t *testing.T) {
+	from := time.Date(2018, 5, 15, 17, 50, 0, 0, time.UTC)
+	to := time.Date(2018, 5, 15, 17, 55, 0, 0, time.UTC)
+
+	query := func(settings string) string {
+		return `{
+			"bucketAggs": [
+				{ "type": "terms", "field": "host", "id": "2", "settings": ` + settings + ` },
+				{ "type": "date_histogram", "field": "@timestamp", "id": "3" }
+			],
+			"metrics": [{"type": "count", "id": "1" }]
+		}`
+	}
+
+	t.Run("sends terms aggregations as composite aggregations", func(t *testing.T) {
+		c := newFakeClient()
+		c.multiSearchResponses = []*es.MultiSearchResponse{compositePage(nil, "a")}
+
+		_, err := executeElasticsearchDataQuery(c, query(`{"useComposite": true, "size": "2", "missing": "none", "order": "desc", "orderBy": "_count"}`), from, to)
+		require.NoError(t, err)
+		require.Len(t, c.multisearchRequests, 1)
+
+		sr := c.multisearchRequests[0].Requests[0]
+		require.Equal(t, "2", sr.Aggs[0].Key)
+		require.Equal(t, "composite", sr.Aggs[0].Aggregation.Type)
+		compositeAgg := sr.Aggs[0].Aggregation.Aggregation.(*es.CompositeAggregation)
+		assert.Equal(t, 2, compositeAgg.Size)
+		assert.Nil(t, compositeAgg.After)
+		source := compositeAgg.Sources[0]["2"].(map[string]any)["terms"].(*es.CompositeTermsSource)
+		assert.Equal(t, "host", source.Field)
+		assert.Equal(t, "desc", source.Order)
+		assert.True(t, source.MissingBucket)
+		require.Equal(t, "3", sr.Aggs[0].Aggregation.Aggs[0].Key)
+	})
+
+	t.Run("fetches and merges all pages", func(t *testing.T) {
+		c := newFakeClient()
+		c.multiSearchResponses = []*es.MultiSearchResponse{
+			compositePage(map[string]any{"2": "b"}, "a", "b"),
+			compositePage(map[string]any{"2": "c"}, "c"),
+			compositePage(nil),
+		}
+
+		result, err := executeElasticsearchDataQuery(c, query(`{"useComposite": true, "size": "2"}`), from, to)
+		require.NoError(t, err)
+		require.Len(t, c.multisearchRequests, 3)
+
+		secondPage := c.multisearchRequests[1].Requests[0].Aggs[0].Aggregation.Aggregation.(*es.CompositeAggregation)
+		assert.Equal(t, map[string]any{"2": "b"}, secondPage.After)
+		thirdPage := c.multisearchRequests[2].Requests[0].Aggs[0].Aggregation.Aggregation.(*es.CompositeAggregation)
+		assert.Equal(t, map[string]any{"2": "c"}, thirdPage.After)
+
+		frames := result.Responses["A"].Frames
+		require.Len(t, frames, 3)
+		for i, host := range []string{"a", "b", "c"} {
+			assert.Equal(t, data.Labels{"host": host}, frames[i].Fields[1].Labels)
+			assert.Empty(t, frames[i].Meta.Notices)
+		}
+	})
+
+	t.Run("stops at the series limit with a notice", func(t *testing.T) {
+		c := newFakeClient()
+		c.multiSearchResponses = []*es.MultiSearchResponse{
+			compositePage(map[string]any{"2": "b"}, "a", "b"),
+			compositePage(map[string]any{"2": "d"}, "c", "d"),
+		}
+
+		result, err := executeElasticsearchDataQuery(c, query(`{"useComposite": true, "size": "2", "seriesLimit": "3"}`), from, to)
+		require.NoError(t, err)
+		require.Len(t, c.multisearchRequests, 2)
+
+		frames := result.Responses["A"].Frames
+		require.Len(t, frames, 3)
+		for _, frame := range frames {
+			require.Len(t, frame.Meta.Notices, 1)
+			assert.Equal(t, data.NoticeSeverityWarning, frame.Meta.Notices[0].Severity)
+			assert.Contains(t, frame.Meta.Notices[0].Text, "Series limit of 3 terms reached")
+		}
+	})
+
+	t.Run("returns the fetched pages with a notice if a page fails", func(t *testing.T) {
+		c := newFakeClient()
+		c.multiSearchResponses = []*es.MultiSearchResponse{
+			compositePage(map[string]any{"2": "a"}, "a"),
+			{Responses: []*es.SearchResponse{{Error: map[string]any{"reason": "too many buckets"}}}},
+		}
+
+		result, err := executeElasticsearchDataQuery(c, query(`{"useComposite": true, "size": "1"}`), from, to)
+		require.NoError(t, err)
+
+		frames := result.Responses["A"].Frames
+		require.Len(t, frames, 1)
+		require.Len(t, frames[0].Meta.Notices, 1)
+		assert.Contains(t, frames[0].Meta.Notices[0].Text, "Results are incomplete")
+	})
+
+	t.Run("uses terms aggregations by default", func(t *testing.T) {
+		c := newFakeClient()
+		_, err := executeElasticsearchDataQuery(c, query(`{"size": "2"}`), from, to)
+		require.NoError(t, err)
+
+		sr := c.multisearchRequests[0].Requests[0]
+		_, ok := sr.Aggs[0].Aggregation.Aggregation.(*es.TermsAggregation)
+		require.True(t, ok)
+	})
+}
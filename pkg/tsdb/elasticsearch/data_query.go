@@ -58,6 +58,15 @@ func (e *elasticsearchDataQuery) execute() (*backend.QueryDataResponse, error) {
 		return errorsource.AddPluginErrorToResponse(e.dataQueries[0].RefID, response, err), nil
 	}
 
+	// ES|QL and PPL queries are sent to their own endpoints, one request per query.
+	rawQueries, queries := splitRawQueries(queries)
+	for _, q := range rawQueries {
+		response.Responses[q.RefID] = e.executeRawQuery(q)
+	}
+	if len(queries) == 0 {
+		return response, nil
+	}
+
 	ms := e.client.MultiSearch()
 
 	for _, q := range queries {
@@ -74,17 +83,27 @@ func (e *elasticsearchDataQuery) execute() (*backend.QueryDataResponse, error) {
 	if err != nil {
 		mqs, _ := json.Marshal(e.dataQueries)
 		e.logger.Error("Failed to build multisearch request", "error", err, "queriesLength", len(queries), "queries", string(mqs), "duration", time.Since(start), "stage", es.StagePrepareRequest)
-		return errorsource.AddPluginErrorToResponse(e.dataQueries[0].RefID, response, err), nil
+		return errorsource.AddPluginErrorToResponse(queries[0].RefID, response, err), nil
 	}
 
 	e.logger.Info("Prepared request", "queriesLength", len(queries), "duration", time.Since(start), "stage", es.StagePrepareRequest)
 	res, err := e.client.ExecuteMultisearch(req)
 	if err != nil {
 		// We are returning error containing the source that was added trough errorsource.Middleware
-		return errorsource.AddErrorToResponse(e.dataQueries[0].RefID, response, err), nil
+		return errorsource.AddErrorToResponse(queries[0].RefID, response, err), nil
 	}
 
-	return parseResponse(e.ctx, res.Responses, queries, e.client.GetConfiguredFields(), e.keepLabelsInResponse, e.logger, e.tracer)
+	notices := e.paginateCompositeAggs(queries, res.Responses)
+
+	result, err := parseResponse(e.ctx, res.Responses, queries, e.client.GetConfiguredFields(), e.keepLabelsInResponse, e.logger, e.tracer)
+	if err != nil {
+		return result, err
+	}
+	addNotices(result, notices)
+	for refID, r := range response.Responses {
+		result.Responses[refID] = r
+	}
+	return result, nil
 }
 
 func (e *elasticsearchDataQuery) processQuery(q *Query, ms *es.MultiSearchRequestBuilder, from, to int64) error {
@@ -273,6 +292,35 @@ func addTermsAgg(aggBuilder es.AggBuilder, bucketAgg *BucketAgg, metrics []*Metr
 	return aggBuilder
 }
 
+// addCompositeTermsAgg adds a terms aggregation as a composite aggregation, so all buckets can be fetched
+// page by page. The size of the terms aggregation is used as page size. Composite buckets are always sorted
+// by term, so the order setting applies to the terms and the order by and min doc count settings are ignored.
+func addCompositeTermsAgg(aggBuilder es.AggBuilder, bucketAgg *BucketAgg) es.AggBuilder {
+	aggBuilder.CompositeTerms(bucketAgg.ID, bucketAgg.Field, func(a *es.CompositeAggregation, source *es.CompositeTermsSource, b es.AggBuilder) {
+		if size, err := bucketAgg.Settings.Get("size").Int(); err == nil {
+			a.Size = size
+		} else {
+			a.Size = stringToIntWithDefaultValue(bucketAgg.Settings.Get("size").MustString(), defaultSize)
+		}
+		if a.Size <= 0 {
+			a.Size = defaultSize
+		}
+		a.After = bucketAgg.After
+		if order := bucketAgg.Settings.Get("order").MustString(); order == "asc" || order == "desc" {
+			source.Order = order
+		}
+
+		// buckets of documents without the field are only returned if a missing value is configured
+		if _, err := bucketAgg.Settings.Get("missing").String(); err == nil {
+			source.MissingBucket = true
+		}
+
+		aggBuilder = b
+	})
+
+	return aggBuilder
+}
+
 func addNestedAgg(aggBuilder es.AggBuilder, bucketAgg *BucketAgg) es.AggBuilder {
 	aggBuilder.Nested(bucketAgg.ID, bucketAgg.Field, func(a *es.NestedAggregation, b es.AggBuilder) {
 		aggBuilder = b
@@ -408,10 +456,14 @@ func processTimeSeriesQuery(q *Query, b *es.SearchRequestBuilder, from, to int64
 	aggBuilder := b.Agg()
 	// Process buckets
 	// iterate backwards to create aggregations bottom-down
-	for _, bucketAgg := range q.BucketAggs {
+	for i, bucketAgg := range q.BucketAggs {
 		bucketAgg.Settings = simplejson.NewFromAny(
 			bucketAgg.generateSettingsForDSL(),
 		)
+		if i == 0 && isCompositeTermsAgg(bucketAgg) {
+			aggBuilder = addCompositeTermsAgg(aggBuilder, bucketAgg)
+			continue
+		}
 		switch bucketAgg.Type {
 		case dateHistType:
 			aggBuilder = addDateHistogramAgg(aggBuilder, bucketAgg, from, to, defaultTimeField)
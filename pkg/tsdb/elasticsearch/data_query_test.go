@@ -1803,9 +1803,15 @@ func TestSettingsCasting(t *testing.T) {
 type fakeClient struct {
 	configuredFields    es.ConfiguredFields
 	multiSearchResponse *es.MultiSearchResponse
-	multiSearchError    error
-	builder             *es.MultiSearchRequestBuilder
-	multisearchRequests []*es.MultiSearchRequest
+	// multiSearchResponses are returned in order before multiSearchResponse, e.g. for paginated requests
+	multiSearchResponses []*es.MultiSearchResponse
+	multiSearchError     error
+	builder              *es.MultiSearchRequestBuilder
+	multisearchRequests  []*es.MultiSearchRequest
+	rawQueryResponse     *es.RawQueryResponse
+	rawQueryError        error
+	esqlRequests         []*es.RawQueryRequest
+	pplRequests          []*es.RawQueryRequest
 }
 
 func newFakeClient() *fakeClient {
@@ -1828,9 +1834,24 @@ func (c *fakeClient) GetConfiguredFields() es.ConfiguredFields {
 
 func (c *fakeClient) ExecuteMultisearch(r *es.MultiSearchRequest) (*es.MultiSearchResponse, error) {
 	c.multisearchRequests = append(c.multisearchRequests, r)
+	if len(c.multiSearchResponses) > 0 {
+		res := c.multiSearchResponses[0]
+		c.multiSearchResponses = c.multiSearchResponses[1:]
+		return res, c.multiSearchError
+	}
 	return c.multiSearchResponse, c.multiSearchError
 }
 
+func (c *fakeClient) ExecuteESQL(r *es.RawQueryRequest) (*es.RawQueryResponse, error) {
+	c.esqlRequests = append(c.esqlRequests, r)
+	return c.rawQueryResponse, c.rawQueryError
+}
+
+func (c *fakeClient) ExecutePPL(r *es.RawQueryRequest) (*es.RawQueryResponse, error) {
+	c.pplRequests = append(c.pplRequests, r)
+	return c.rawQueryResponse, c.rawQueryError
+}
+
 func (c *fakeClient) MultiSearch() *es.MultiSearchRequestBuilder {
 	c.builder = es.NewMultiSearchRequestBuilder()
 	return c.builder
@@ -226,10 +226,13 @@ type ElasticsearchDataQuery struct {
 	// List of bucket aggregations
 	BucketAggs []any `json:"bucketAggs,omitempty"`
 
+	// Format of the results of ES|QL and PPL queries, `table` or `time_series`
+	Format *string `json:"format,omitempty"`
+
 	// List of metric aggregations
 	Metrics []any `json:"metrics,omitempty"`
 
-	// Lucene query
+	// Lucene query, or the ES|QL or PPL query when the query type is `esql` or `ppl`
 	Query *string `json:"query,omitempty"`
 
 	// Name of time field
@@ -568,7 +571,11 @@ type TermsSettings struct {
 	Missing     *string     `json:"missing,omitempty"`
 	Order       *TermsOrder `json:"order,omitempty"`
 	OrderBy     *string     `json:"orderBy,omitempty"`
+	SeriesLimit *string     `json:"seriesLimit,omitempty"`
 	Size        *string     `json:"size,omitempty"`
+
+	// Fetch all terms page by page with a composite aggregation, up to the series limit
+	UseComposite *bool `json:"useComposite,omitempty"`
 }
 
 // TopMetrics defines model for TopMetrics.
@@ -10,6 +10,8 @@ import (
 // Query represents the time series query model of the datasource
 type Query struct {
 	RawQuery      string       `json:"query"`
+	QueryType     string       `json:"queryType"`
+	Format        string       `json:"format"`
 	BucketAggs    []*BucketAgg `json:"bucketAggs"`
 	Metrics       []*MetricAgg `json:"metrics"`
 	Alias         string       `json:"alias"`
@@ -26,6 +28,8 @@ type BucketAgg struct {
 	ID       string           `json:"id"`
 	Settings *simplejson.Json `json:"settings"`
 	Type     string           `json:"type"`
+	// After is the after key of the next page of a composite terms aggregation.
+	After map[string]any `json:"-"`
 }
 
 // MetricAgg represents a metric aggregation of the time series query model of the datasource
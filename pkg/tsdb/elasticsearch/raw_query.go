@@ -0,0 +1,347 @@
+package elasticsearch
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/grafana/grafana-plugin-sdk-go/experimental/errorsource"
+
+	es "github.com/grafana/grafana/pkg/tsdb/elasticsearch/client"
+)
+
+const (
+	// queryTypeESQL is the query type of raw ES|QL queries.
+	queryTypeESQL = "esql"
+	// queryTypePPL is the query type of raw PPL queries of OpenSearch compatible clusters.
+	queryTypePPL = "ppl"
+
+	// rawQueryFormatTable keeps the results of raw queries as a table, otherwise results with a date
+	// column are returned as time series.
+	rawQueryFormatTable = "table"
+)
+
+// timestamp layouts of ES|QL (ISO 8601) and PPL (`yyyy-MM-dd HH:mm:ss[.SSSSSSSSS]`) date columns
+var rawQueryTimeLayouts = []string{
+	time.RFC3339Nano,
+	"2006-01-02 15:04:05.999999999",
+	"2006-01-02T15:04:05.999999999",
+	"2006-01-02",
+}
+
+func isRawQuery(query *Query) bool {
+	return query.QueryType == queryTypeESQL || query.QueryType == queryTypePPL
+}
+
+// splitRawQueries separates ES|QL and PPL queries, which are not part of the multisearch request.
+func splitRawQueries(queries []*Query) ([]*Query, []*Query) {
+	var raw, dsl []*Query
+	for _, q := range queries {
+		if isRawQuery(q) {
+			raw = append(raw, q)
+		} else {
+			dsl = append(dsl, q)
+		}
+	}
+	return raw, dsl
+}
+
+// executeRawQuery executes an ES|QL or PPL query. The time range of the query is applied with a filter on the
+// configured time field, so the query does not have to filter by time itself.
+func (e *elasticsearchDataQuery) executeRawQuery(q *Query) backend.DataResponse {
+	if strings.TrimSpace(q.RawQuery) == "" {
+		return errorsource.Response(errorsource.DownstreamError(errors.New("query is empty"), false))
+	}
+
+	timeField := e.client.GetConfiguredFields().TimeField
+	req := &es.RawQueryRequest{
+		Query: q.RawQuery,
+		Filter: map[string]any{
+			"range": map[string]any{
+				timeField: map[string]any{
+					"gte":    q.TimeRange.From.UnixMilli(),
+					"lte":    q.TimeRange.To.UnixMilli(),
+					"format": es.DateFormatEpochMS,
+				},
+			},
+		},
+	}
+
+	var res *es.RawQueryResponse
+	var err error
+	if q.QueryType == queryTypePPL {
+		res, err = e.client.ExecutePPL(req)
+	} else {
+		res, err = e.client.ExecuteESQL(req)
+	}
+	if err != nil {
+		e.logger.Warn("Raw query failed", "queryType", q.QueryType, "error", err)
+		return errorsource.Response(err)
+	}
+
+	frame, err := rawQueryResponseToFrame(q, res)
+	if err != nil {
+		return errorsource.Response(errorsource.PluginError(err, false))
+	}
+	return backend.DataResponse{Frames: data.Frames{frame}}
+}
+
+// rawQueryResponseToFrame converts the columns of a raw query response to a frame. Results with a date column
+// and numeric columns are converted to time series unless the table format is requested, so they can be used
+// in alert rules.
+func rawQueryResponseToFrame(q *Query, res *es.RawQueryResponse) (*data.Frame, error) {
+	if len(res.Values) != len(res.Columns) {
+		return nil, fmt.Errorf("invalid response: %d value columns for %d columns", len(res.Values), len(res.Columns))
+	}
+
+	rows := 0
+	if len(res.Values) > 0 {
+		rows = len(res.Values[0])
+	}
+
+	columns := make([]rawColumn, 0, len(res.Columns))
+	for i, c := range res.Columns {
+		if len(res.Values[i]) != rows {
+			return nil, fmt.Errorf("invalid response: column %q has %d values, expected %d", c.Name, len(res.Values[i]), rows)
+		}
+		column, err := convertRawColumn(c, res.Values[i])
+		if err != nil {
+			return nil, err
+		}
+		columns = append(columns, column)
+	}
+
+	timeIndex := -1
+	hasNumbers := false
+	for i, c := range columns {
+		if timeIndex == -1 && c.fieldType.NonNullableType() == data.FieldTypeTime {
+			timeIndex = i
+		}
+		if c.fieldType.Numeric() {
+			hasNumbers = true
+		}
+	}
+	asTimeSeries := q.Format != rawQueryFormatTable && timeIndex != -1 && hasNumbers
+
+	// Time series must be sorted by time. Rows without a timestamp can not be part of a series.
+	order := make([]int, 0, rows)
+	for i := 0; i < rows; i++ {
+		if asTimeSeries && columns[timeIndex].values[i] == nil {
+			continue
+		}
+		order = append(order, i)
+	}
+	if asTimeSeries {
+		timeColumn := columns[timeIndex]
+		timeColumn.hasNull = false
+		columns[timeIndex] = timeColumn
+		sort.SliceStable(order, func(i, j int) bool {
+			return timeColumn.values[order[i]].(*time.Time).Before(*timeColumn.values[order[j]].(*time.Time))
+		})
+	}
+
+	frame := data.NewFrame("")
+	for _, c := range columns {
+		frame.Fields = append(frame.Fields, c.toField(order))
+	}
+
+	visualization := data.VisTypeTable
+	if asTimeSeries {
+		visualization = data.VisTypeGraph
+		if frame.TimeSeriesSchema().Type == data.TimeSeriesTypeLong {
+			wide, err := data.LongToWide(frame, nil)
+			if err != nil {
+				return nil, err
+			}
+			frame = wide
+		}
+	}
+	frame.RefID = q.RefID
+	if frame.Meta == nil {
+		frame.Meta = &data.FrameMeta{}
+	}
+	frame.Meta.ExecutedQueryString = q.RawQuery
+	frame.Meta.PreferredVisualization = visualization
+	return frame, nil
+}
+
+// rawColumn holds the converted values of a column, as pointers of the non nullable field type or nil.
+type rawColumn struct {
+	name      string
+	fieldType data.FieldType
+	values    []any
+	hasNull   bool
+}
+
+// toField returns the field of the column with the rows in the given order. Fields are only nullable if the
+// column contains null values, as non-nullable fields are required to build time series.
+func (c rawColumn) toField(order []int) *data.Field {
+	fieldType := c.fieldType
+	if c.hasNull {
+		fieldType = fieldType.NullableType()
+	}
+	field := data.NewFieldFromFieldType(fieldType, len(order))
+	field.Name = c.name
+	for i, row := range order {
+		v := c.values[row]
+		if v == nil {
+			continue
+		}
+		if c.hasNull {
+			field.Set(i, v)
+			continue
+		}
+		switch v := v.(type) {
+		case *time.Time:
+			field.Set(i, *v)
+		case *float64:
+			field.Set(i, *v)
+		case *int64:
+			field.Set(i, *v)
+		case *bool:
+			field.Set(i, *v)
+		case *string:
+			field.Set(i, *v)
+		}
+	}
+	return field
+}
+
+func convertRawColumn(c es.RawQueryColumn, values []any) (rawColumn, error) {
+	column := rawColumn{name: c.Name, values: make([]any, len(values))}
+
+	var convert func(v any) (any, error)
+	switch strings.ToLower(c.Type) {
+	case "date", "date_nanos", "datetime", "timestamp":
+		column.fieldType = data.FieldTypeTime
+		convert = toRawTime
+	case "long", "integer", "short", "byte", "counter_long", "counter_integer":
+		column.fieldType = data.FieldTypeInt64
+		convert = toRawInt64
+	case "double", "float", "half_float", "scaled_float", "unsigned_long", "counter_double":
+		column.fieldType = data.FieldTypeFloat64
+		convert = toRawFloat64
+	case "boolean":
+		column.fieldType = data.FieldTypeBool
+		convert = toRawBool
+	default:
+		// keyword, text, ip, version, geo types and nested values are returned as strings
+		column.fieldType = data.FieldTypeString
+		convert = toRawString
+	}
+
+	for i, v := range values {
+		if v == nil {
+			column.hasNull = true
+			continue
+		}
+		converted, err := convert(v)
+		if err != nil {
+			return column, fmt.Errorf("invalid value of column %q: %w", c.Name, err)
+		}
+		column.values[i] = converted
+	}
+	return column, nil
+}
+
+func toRawTime(v any) (any, error) {
+	switch v := v.(type) {
+	case string:
+		for _, layout := range rawQueryTimeLayouts {
+			if t, err := time.Parse(layout, v); err == nil {
+				return &t, nil
+			}
+		}
+		return nil, fmt.Errorf("unsupported time format %q", v)
+	case json.Number, float64:
+		ms, err := toRawInt64(v)
+		if err != nil {
+			return nil, err
+		}
+		t := time.UnixMilli(*ms.(*int64)).UTC()
+		return &t, nil
+	}
+	return nil, fmt.Errorf("unsupported time value %v", v)
+}
+
+func toRawInt64(v any) (any, error) {
+	var i int64
+	switch v := v.(type) {
+	case json.Number:
+		n, err := v.Int64()
+		if err != nil {
+			f, err := v.Float64()
+			if err != nil {
+				return nil, err
+			}
+			n = int64(f)
+		}
+		i = n
+	case float64:
+		i = int64(v)
+	case string:
+		n, err := strconv.ParseInt(v, 10, 64)
+		if err != nil {
+			return nil, err
+		}
+		i = n
+	default:
+		return nil, fmt.Errorf("unsupported integer value %v", v)
+	}
+	return &i, nil
+}
+
+func toRawFloat64(v any) (any, error) {
+	var f float64
+	switch v := v.(type) {
+	case json.Number:
+		n, err := v.Float64()
+		if err != nil {
+			return nil, err
+		}
+		f = n
+	case float64:
+		f = v
+	case string:
+		// non finite values are returned as strings, e.g. `NaN`
+		n, err := strconv.ParseFloat(v, 64)
+		if err != nil {
+			return nil, err
+		}
+		f = n
+	default:
+		return nil, fmt.Errorf("unsupported number value %v", v)
+	}
+	return &f, nil
+}
+
+func toRawBool(v any) (any, error) {
+	b, ok := v.(bool)
+	if !ok {
+		return nil, fmt.Errorf("unsupported boolean value %v", v)
+	}
+	return &b, nil
+}
+
+func toRawString(v any) (any, error) {
+	var s string
+	switch v := v.(type) {
+	case string:
+		s = v
+	case json.Number:
+		s = v.String()
+	default:
+		b, err := json.Marshal(v)
+		if err != nil {
+			return nil, err
+		}
+		s = string(b)
+	}
+	return &s, nil
+}
@@ -0,0 +1,154 @@
+package elasticsearch
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	es "github.com/grafana/grafana/pkg/tsdb/elasticsearch/client"
+)
+
+func TestExecuteRawQuery(t *testing.T) {
+	from := time.Date(2023, 10, 23, 12, 0, 0, 0, time.UTC)
+	to := time.Date(2023, 10, 23, 13, 0, 0, 0, time.UTC)
+
+	t.Run("sends ES|QL queries with a time range filter", func(t *testing.T) {
+		c := newFakeClient()
+		c.rawQueryResponse = &es.RawQueryResponse{
+			Columns: []es.RawQueryColumn{{Name: "host", Type: "keyword"}, {Name: "count", Type: "long"}},
+			Values:  [][]any{{"a", "b"}, {json.Number("1"), json.Number("2")}},
+		}
+
+		result, err := executeElasticsearchDataQuery(c, `{"queryType": "esql", "query": "FROM logs | STATS count = COUNT(*) BY host"}`, from, to)
+		require.NoError(t, err)
+		require.Len(t, c.esqlRequests, 1)
+		require.Empty(t, c.multisearchRequests)
+
+		req := c.esqlRequests[0]
+		assert.Equal(t, "FROM logs | STATS count = COUNT(*) BY host", req.Query)
+		assert.Equal(t, map[string]any{
+			"range": map[string]any{
+				"@timestamp": map[string]any{
+					"gte":    from.UnixMilli(),
+					"lte":    to.UnixMilli(),
+					"format": es.DateFormatEpochMS,
+				},
+			},
+		}, req.Filter)
+
+		resp := result.Responses["A"]
+		require.NoError(t, resp.Error)
+		require.Len(t, resp.Frames, 1)
+		frame := resp.Frames[0]
+		require.Len(t, frame.Fields, 2)
+		assert.Equal(t, data.FieldTypeString, frame.Fields[0].Type())
+		assert.Equal(t, data.FieldTypeInt64, frame.Fields[1].Type())
+		assert.Equal(t, int64(2), frame.Fields[1].At(1))
+		assert.Equal(t, data.VisTypeTable, frame.Meta.PreferredVisualization)
+	})
+
+	t.Run("sends PPL queries to the PPL endpoint", func(t *testing.T) {
+		c := newFakeClient()
+		c.rawQueryResponse = &es.RawQueryResponse{}
+
+		_, err := executeElasticsearchDataQuery(c, `{"queryType": "ppl", "query": "source=logs | stats count() by host"}`, from, to)
+		require.NoError(t, err)
+		require.Len(t, c.pplRequests, 1)
+		require.Empty(t, c.esqlRequests)
+	})
+
+	t.Run("returns query errors in the response", func(t *testing.T) {
+		c := newFakeClient()
+		c.rawQueryError = errors.New("Unknown index [logs]")
+
+		result, err := executeElasticsearchDataQuery(c, `{"queryType": "esql", "query": "FROM logs"}`, from, to)
+		require.NoError(t, err)
+		require.EqualError(t, result.Responses["A"].Error, "Unknown index [logs]")
+	})
+
+	t.Run("rejects empty queries", func(t *testing.T) {
+		c := newFakeClient()
+
+		result, err := executeElasticsearchDataQuery(c, `{"queryType": "esql", "query": " "}`, from, to)
+		require.NoError(t, err)
+		require.Error(t, result.Responses["A"].Error)
+		require.Empty(t, c.esqlRequests)
+	})
+}
+
+func TestRawQueryResponseToFrame(t *testing.T) {
+	res := &es.RawQueryResponse{
+		Columns: []es.RawQueryColumn{
+			{Name: "@timestamp", Type: "date"},
+			{Name: "host", Type: "keyword"},
+			{Name: "avg", Type: "double"},
+		},
+		Values: [][]any{
+			{"2023-10-23T12:02:00.000Z", "2023-10-23T12:01:00.000Z", "2023-10-23T12:01:00.000Z", "2023-10-23T12:02:00.000Z"},
+			{"a", "a", "b", "b"},
+			{json.Number("2.5"), json.Number("1.5"), nil, json.Number("4")},
+		},
+	}
+
+	t.Run("converts results with a date column to time series", func(t *testing.T) {
+		frame, err := rawQueryResponseToFrame(&Query{RefID: "A", RawQuery: "FROM metrics"}, res)
+		require.NoError(t, err)
+
+		assert.Equal(t, "A", frame.RefID)
+		assert.Equal(t, "FROM metrics", frame.Meta.ExecutedQueryString)
+		assert.Equal(t, data.VisTypeGraph, frame.Meta.PreferredVisualization)
+		require.Len(t, frame.Fields, 3)
+		assert.Equal(t, data.FieldTypeTime, frame.Fields[0].Type())
+		require.Equal(t, 2, frame.Rows())
+		assert.Equal(t, time.Date(2023, 10, 23, 12, 1, 0, 0, time.UTC), frame.Fields[0].At(0))
+
+		assert.Equal(t, data.Labels{"host": "a"}, frame.Fields[1].Labels)
+		assert.Equal(t, 1.5, *frame.Fields[1].At(0).(*float64))
+		assert.Equal(t, 2.5, *frame.Fields[1].At(1).(*float64))
+		assert.Equal(t, data.Labels{"host": "b"}, frame.Fields[2].Labels)
+		assert.Nil(t, frame.Fields[2].At(0))
+	})
+
+	t.Run("keeps results as table with table format", func(t *testing.T) {
+		frame, err := rawQueryResponseToFrame(&Query{RefID: "A", Format: rawQueryFormatTable}, res)
+		require.NoError(t, err)
+
+		require.Len(t, frame.Fields, 3)
+		require.Equal(t, 4, frame.Rows())
+		assert.Equal(t, data.FieldTypeNullableFloat64, frame.Fields[2].Type())
+		assert.Equal(t, "a", frame.Fields[1].At(0))
+	})
+
+	t.Run("converts PPL types", func(t *testing.T) {
+		frame, err := rawQueryResponseToFrame(&Query{RefID: "A"}, &es.RawQueryResponse{
+			Columns: []es.RawQueryColumn{
+				{Name: "time", Type: "timestamp"},
+				{Name: "ok", Type: "boolean"},
+				{Name: "attrs", Type: "struct"},
+			},
+			Values: [][]any{
+				{"2023-10-23 12:01:00.5"},
+				{true},
+				{map[string]any{"a": json.Number("1")}},
+			},
+		})
+		require.NoError(t, err)
+
+		assert.Equal(t, time.Date(2023, 10, 23, 12, 1, 0, 500000000, time.UTC), frame.Fields[0].At(0))
+		assert.Equal(t, true, frame.Fields[1].At(0))
+		assert.Equal(t, `{"a":1}`, frame.Fields[2].At(0))
+	})
+
+	t.Run("fails on invalid values", func(t *testing.T) {
+		_, err := rawQueryResponseToFrame(&Query{RefID: "A"}, &es.RawQueryResponse{
+			Columns: []es.RawQueryColumn{{Name: "count", Type: "long"}},
+			Values:  [][]any{{"abc"}},
+		})
+		require.Error(t, err)
+	})
+}
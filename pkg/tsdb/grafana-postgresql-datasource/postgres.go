@@ -83,6 +83,8 @@ func newPostgres(ctx context.Context, userFacingDefaultError string, rowLimit in
 		DSInfo:            dsInfo,
 		MetricColumnTypes: []string{"UNKNOWN", "TEXT", "VARCHAR", "CHAR"},
 		RowLimit:          rowLimit,
+		QueryCanceler:     postgresQueryCanceler{},
+		SchemaQueries:     postgresSchemaQueries{},
 	}
 
 	queryResultTransformer := postgresQueryResultTransformer{}
@@ -104,6 +106,15 @@ func newPostgres(ctx context.Context, userFacingDefaultError string, rowLimit in
 	return db, handler, nil
 }
 
+// CallResource serves the schema of the database
+func (s *Service) CallResource(ctx context.Context, req *backend.CallResourceRequest, sender backend.CallResourceResponseSender) error {
+	dsHandler, err := s.getDSInfo(ctx, req.PluginContext)
+	if err != nil {
+		return err
+	}
+	return dsHandler.CallResource(ctx, req, sender)
+}
+
 func (s *Service) newInstanceSettings() datasource.InstanceFactoryFunc {
 	logger := s.logger
 	return func(ctx context.Context, settings backend.DataSourceInstanceSettings) (instancemgmt.Instance, error) {
@@ -256,6 +267,21 @@ func (t *postgresQueryResultTransformer) TransformQueryError(_ log.Logger, err e
 	return err
 }
 
+// postgresQueryCanceler cancels queries with pg_cancel_backend, which also works when the cancel request of
+// the driver can not reach the server, e.g. through the secure socks proxy.
+type postgresQueryCanceler struct{}
+
+func (postgresQueryCanceler) SessionID(ctx context.Context, conn *sql.Conn) (int64, error) {
+	var pid int64
+	err := conn.QueryRowContext(ctx, "SELECT pg_backend_pid()").Scan(&pid)
+	return pid, err
+}
+
+func (postgresQueryCanceler) CancelQuery(ctx context.Context, db *sql.DB, sessionID int64) error {
+	_, err := db.ExecContext(ctx, "SELECT pg_cancel_backend($1)", sessionID)
+	return err
+}
+
 // CheckHealth pings the connected SQL database
 func (s *Service) CheckHealth(ctx context.Context, req *backend.CheckHealthRequest) (*backend.CheckHealthResult, error) {
 	dsHandler, err := s.getDSInfo(ctx, req.PluginContext)
@@ -0,0 +1,36 @@
+package postgres
+
+// postgresSchemaQueries lists the schema of a Postgres database. An empty schema refers to the first schema
+// of the search path.
+type postgresSchemaQueries struct{}
+
+func (postgresSchemaQueries) Schemas() string {
+	return `SELECT schema_name FROM information_schema.schemata
+		WHERE schema_name NOT IN ('information_schema', 'pg_catalog', 'pg_toast')
+			AND schema_name NOT LIKE 'pg_temp_%' AND schema_name NOT LIKE 'pg_toast_temp_%'
+		ORDER BY schema_name`
+}
+
+func (postgresSchemaQueries) Tables(schema string) (string, []any) {
+	return `SELECT table_name, table_type FROM information_schema.tables
+		WHERE table_schema = COALESCE(NULLIF($1, ''), current_schema())
+		ORDER BY table_name`, []any{schema}
+}
+
+func (postgresSchemaQueries) Columns(schema, table string) (string, []any) {
+	return `SELECT column_name, data_type, is_nullable FROM information_schema.columns
+		WHERE table_schema = COALESCE(NULLIF($1, ''), current_schema()) AND table_name = $2
+		ORDER BY ordinal_position`, []any{schema, table}
+}
+
+func (postgresSchemaQueries) Indexes(schema, table string) (string, []any) {
+	return `SELECT i.relname, a.attname, ix.indisunique, ix.indisprimary
+		FROM pg_index ix
+		JOIN pg_class t ON t.oid = ix.indrelid
+		JOIN pg_class i ON i.oid = ix.indexrelid
+		JOIN pg_namespace n ON n.oid = t.relnamespace
+		JOIN LATERAL unnest(ix.indkey) WITH ORDINALITY AS k(attnum, ord) ON true
+		JOIN pg_attribute a ON a.attrelid = t.oid AND a.attnum = k.attnum
+		WHERE n.nspname = COALESCE(NULLIF($1, ''), current_schema()) AND t.relname = $2
+		ORDER BY i.relname, k.ord`, []any{schema, table}
+}
@@ -38,6 +38,17 @@ const (
 	ErrorTypeServerPanic        ErrorType = "server_panic"
 )
 
+// WorkloadLatencyDistribution defines model for WorkloadQuery.LatencyDistribution.
+// +enum
+type WorkloadLatencyDistribution string
+
+const (
+	WorkloadLatencyDistributionFixed       WorkloadLatencyDistribution = "fixed"
+	WorkloadLatencyDistributionUniform     WorkloadLatencyDistribution = "uniform"
+	WorkloadLatencyDistributionNormal      WorkloadLatencyDistribution = "normal"
+	WorkloadLatencyDistributionExponential WorkloadLatencyDistribution = "exponential"
+)
+
 // TestDataQueryType defines model for TestDataQueryType.
 // +enum
 type TestDataQueryType string
@@ -65,6 +76,7 @@ const (
 	TestDataQueryTypeRandomWalkTable              TestDataQueryType = "random_walk_table"
 	TestDataQueryTypeRandomWalkWithError          TestDataQueryType = "random_walk_with_error"
 	TestDataQueryTypeRawFrame                     TestDataQueryType = "raw_frame"
+	TestDataQueryTypeRecordedQuery                TestDataQueryType = "recorded_query"
 	TestDataQueryTypeServerError500               TestDataQueryType = "server_error_500"
 	TestDataQueryTypeSimulation                   TestDataQueryType = "simulation"
 	TestDataQueryTypeSlowQuery                    TestDataQueryType = "slow_query"
@@ -73,6 +85,7 @@ const (
 	TestDataQueryTypeTrace                        TestDataQueryType = "trace"
 	TestDataQueryTypeUsa                          TestDataQueryType = "usa"
 	TestDataQueryTypeVariablesQuery               TestDataQueryType = "variables-query"
+	TestDataQueryTypeWorkload                     TestDataQueryType = "workload"
 )
 
 // TestDataQuery defines model for TestDataQuery.
@@ -110,9 +123,11 @@ type TestDataQuery struct {
 
 	Nodes     *NodesQuery      `json:"nodes,omitempty"`
 	PulseWave *PulseWaveQuery  `json:"pulseWave,omitempty"`
+	Recording *RecordingQuery  `json:"recording,omitempty"`
 	Sim       *SimulationQuery `json:"sim,omitempty"`
 	Stream    *StreamingQuery  `json:"stream,omitempty"`
 	Usa       *USAQuery        `json:"usa,omitempty"`
+	Workload  *WorkloadQuery   `json:"workload,omitempty"`
 }
 
 // CSVWave defines model for CSVWave.
@@ -139,6 +154,14 @@ type PulseWaveQuery struct {
 	TimeStep int64   `json:"timeStep,omitempty"`
 }
 
+// RecordingQuery defines model for RecordingQuery.
+type RecordingQuery struct {
+	// UID of the data source the query was recorded from
+	Datasource string `json:"datasource,omitempty"`
+	// Model of the recorded query
+	Query map[string]any `json:"query,omitempty"`
+}
+
 // SimulationQuery defines model for SimulationQuery.
 type SimulationQuery struct {
 	Config map[string]any `json:"config,omitempty"`
@@ -147,8 +170,10 @@ type SimulationQuery struct {
 		Type string  `json:"type"`
 		Uid  *string `json:"uid,omitempty"`
 	} `json:"key"`
-	Last   bool `json:"last,omitempty"`
-	Stream bool `json:"stream,omitempty"`
+	Last bool `json:"last,omitempty"`
+	// Spec of a user-defined simulation, in JSON or YAML
+	Spec   string `json:"spec,omitempty"`
+	Stream bool   `json:"stream,omitempty"`
 }
 
 // StreamingQuery defines model for StreamingQuery.
@@ -169,6 +194,32 @@ type USAQuery struct {
 	States []string `json:"states,omitempty"`
 }
 
+// WorkloadQuery defines model for WorkloadQuery.
+type WorkloadQuery struct {
+	// Seed of the generated values, errors and latencies
+	Seed int64 `json:"seed,omitempty"`
+	// Number of series, limited to the number of combinations of the label values
+	Series int64 `json:"series,omitempty"`
+	// Number of points of each series
+	Points int64 `json:"points,omitempty"`
+	// Labels of the series, with the number of values of each label
+	Labels []WorkloadLabel `json:"labels,omitempty"`
+	// Latency of the response in milliseconds, following the distribution
+	LatencyMs           float64                     `json:"latencyMs,omitempty"`
+	LatencyStddevMs     float64                     `json:"latencyStddevMs,omitempty"`
+	LatencyDistribution WorkloadLatencyDistribution `json:"latencyDistribution,omitempty"`
+	// Error percentage (the chance the query fails 0-100)
+	ErrorPercent float64 `json:"errorPercent,omitempty"`
+	// Size of a string field added to each series, in bytes per point
+	PayloadBytes int64 `json:"payloadBytes,omitempty"`
+}
+
+// WorkloadLabel defines model for WorkloadLabel.
+type WorkloadLabel struct {
+	Name        string `json:"name"`
+	Cardinality int64  `json:"cardinality"`
+}
+
 //go:embed query.types.json
 var f embed.FS
 
@@ -18,10 +18,11 @@ func TestQueryTypeDefinitions(t *testing.T) {
 				CodePath:    "./",
 			}},
 			Enums: []reflect.Type{
-				reflect.TypeOf(NodesQueryTypeRandom),         // pick an example value (not the root)
-				reflect.TypeOf(StreamingQueryTypeFetch),      // pick an example value (not the root)
-				reflect.TypeOf(ErrorTypeServerPanic),         // pick an example value (not the root)
-				reflect.TypeOf(TestDataQueryTypeAnnotations), // pick an example value (not the root)
+				reflect.TypeOf(NodesQueryTypeRandom),             // pick an example value (not the root)
+				reflect.TypeOf(StreamingQueryTypeFetch),          // pick an example value (not the root)
+				reflect.TypeOf(ErrorTypeServerPanic),             // pick an example value (not the root)
+				reflect.TypeOf(TestDataQueryTypeAnnotations),     // pick an example value (not the root)
+				reflect.TypeOf(WorkloadLatencyDistributionFixed), // pick an example value (not the root)
 			},
 		})
 	require.NoError(t, err)
@@ -0,0 +1,62 @@
+package testdatasource
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+
+	"github.com/grafana/grafana/pkg/queryrecording"
+)
+
+// handleRecordedQueryScenario replays the responses of queries recorded from other data sources of the same
+// organization. Grafana checks that the user may query the recorded data sources before the request gets here.
+func (s *Service) handleRecordedQueryScenario(ctx context.Context, req *backend.QueryDataRequest) (*backend.QueryDataResponse, error) {
+	resp := backend.NewQueryDataResponse()
+
+	var dir string
+	if req.PluginContext.GrafanaConfig != nil {
+		dir = req.PluginContext.GrafanaConfig.Get(queryrecording.PathConfigKey)
+	}
+	if dir == "" {
+		for _, q := range req.Queries {
+			resp.Responses[q.RefID] = backend.ErrDataResponse(backend.StatusBadRequest, "query recording is not enabled")
+		}
+		return resp, nil
+	}
+	store := queryrecording.NewStore(dir)
+	now := time.Now()
+
+	for _, q := range req.Queries {
+		model, err := GetJSONModel(q.JSON)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse query json: %v", err)
+		}
+
+		if model.Recording == nil || model.Recording.Datasource == "" {
+			resp.Responses[q.RefID] = backend.ErrDataResponse(backend.StatusBadRequest, "missing recorded data source")
+			continue
+		}
+
+		recorded, err := json.Marshal(model.Recording.Query)
+		if err != nil {
+			return nil, err
+		}
+
+		f, err := store.Find(req.PluginContext.OrgID, model.Recording.Datasource, backend.DataQuery{JSON: recorded, TimeRange: q.TimeRange}, now)
+		if errors.Is(err, queryrecording.ErrNotFound) {
+			resp.Responses[q.RefID] = backend.ErrDataResponse(backend.StatusNotFound, err.Error())
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		resp.Responses[q.RefID] = f.Replay(q.TimeRange)
+	}
+
+	return resp, nil
+}
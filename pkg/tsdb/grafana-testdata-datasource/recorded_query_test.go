@@ -0,0 +1,55 @@
+package testdatasource
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/queryrecording"
+)
+
+func TestRecordedQueryScenario(t *testing.T) {
+	s := &Service{}
+	dir := t.TempDir()
+	now := time.Now()
+	tr := backend.TimeRange{From: now.Add(-time.Hour), To: now}
+
+	recorded := backend.DataQuery{JSON: json.RawMessage(`{"refId":"A","expr":"up"}`), TimeRange: tr}
+	f, err := queryrecording.New(queryrecording.DataSourceRef{OrgID: 1, UID: "prom"}, recorded, backend.DataResponse{Frames: data.Frames{
+		data.NewFrame("up", data.NewField("time", nil, []time.Time{now}), data.NewField("value", nil, []float64{1})),
+	}}, now)
+	require.NoError(t, err)
+	require.NoError(t, queryrecording.NewStore(dir).Save(f))
+
+	req := &backend.QueryDataRequest{
+		PluginContext: backend.PluginContext{
+			OrgID:         1,
+			GrafanaConfig: backend.NewGrafanaCfg(map[string]string{queryrecording.PathConfigKey: dir}),
+		},
+		Queries: []backend.DataQuery{
+			{RefID: "A", TimeRange: tr, JSON: json.RawMessage(`{"scenarioId":"recorded_query","recording":{"datasource":"prom","query":{"expr":"up"}}}`)},
+			{RefID: "B", TimeRange: tr, JSON: json.RawMessage(`{"scenarioId":"recorded_query","recording":{"datasource":"prom","query":{"expr":"down"}}}`)},
+			{RefID: "C", TimeRange: tr, JSON: json.RawMessage(`{"scenarioId":"recorded_query"}`)},
+		},
+	}
+
+	resp, err := s.handleRecordedQueryScenario(context.Background(), req)
+	require.NoError(t, err)
+
+	require.NoError(t, resp.Responses["A"].Error)
+	require.Len(t, resp.Responses["A"].Frames, 1)
+	require.Equal(t, 1.0, resp.Responses["A"].Frames[0].Fields[1].At(0))
+	require.Equal(t, backend.StatusNotFound, resp.Responses["B"].Status)
+	require.Equal(t, backend.StatusBadRequest, resp.Responses["C"].Status)
+
+	t.Run("returns errors when recording is not enabled", func(t *testing.T) {
+		resp, err := s.handleRecordedQueryScenario(context.Background(), &backend.QueryDataRequest{Queries: req.Queries[:1]})
+		require.NoError(t, err)
+		require.Equal(t, backend.StatusBadRequest, resp.Responses["A"].Status)
+	})
+}
@@ -90,6 +90,14 @@ Timestamps will line up evenly on timeStepSeconds (For example, 60 seconds means
 		handler: s.handleDatapointsOutsideRangeScenario,
 	})
 
+	s.registerScenario(&Scenario{
+		ID:      kinds.TestDataQueryTypeWorkload,
+		Name:    "Workload",
+		handler: s.handleWorkloadScenario,
+		Description: `Workload generates large responses to test Grafana under load, with a configurable number of series, points, label cardinality, latency, error rate and payload size.
+The response only depends on the seed, the query and its time range, so benchmarks are reproducible.`,
+	})
+
 	s.registerScenario(&Scenario{
 		ID:          kinds.TestDataQueryTypeCsvMetricValues,
 		Name:        "CSV Metric Values",
@@ -193,6 +201,14 @@ Timestamps will line up evenly on timeStepSeconds (For example, 60 seconds means
 		handler: s.handleCsvContentScenario,
 	})
 
+	s.registerScenario(&Scenario{
+		ID:      kinds.TestDataQueryTypeRecordedQuery,
+		Name:    "Recorded Query",
+		handler: s.handleRecordedQueryScenario,
+		Description: `Recorded Query replays the response of a query recorded from another data source.
+The query must have been recorded for the same time range relative to now, and the replayed data is shifted to the current time range.`,
+	})
+
 	s.registerScenario(&Scenario{
 		ID:   kinds.TestDataQueryTypeTrace,
 		Name: "Trace",
@@ -2,8 +2,11 @@ package sims
 
 import (
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
 	"encoding/json"
 	"fmt"
+	"io"
 	"net/http"
 	"strconv"
 	"strings"
@@ -12,6 +15,7 @@ import (
 
 	"github.com/grafana/grafana-plugin-sdk-go/backend"
 	"github.com/grafana/grafana-plugin-sdk-go/backend/log"
+	"github.com/grafana/grafana-plugin-sdk-go/backend/resource/httpadapter"
 	"github.com/grafana/grafana-plugin-sdk-go/data"
 )
 
@@ -26,6 +30,9 @@ type SimulationEngine struct {
 	// Lookup by Type
 	registry map[string]simulationInfo
 
+	// The simulations declared by users
+	specs map[specRef]*registeredSpec
+
 	// The running instances
 	running map[string]Simulation
 
@@ -53,11 +60,87 @@ func (s *SimulationEngine) register(info simulationInfo) error {
 	return nil
 }
 
+// specRef identifies a simulation declared by a spec. Specs are kept per organization, and their type includes
+// a hash of their content, so a changed spec is a new simulation rather than a replacement of the running one.
+type specRef struct {
+	orgID int64
+	typ   string
+}
+
+type registeredSpec struct {
+	info simulationInfo
+	used time.Time
+}
+
+// specMaxRegistered limits the number of specs kept in memory, the least recently used ones are removed first
+const specMaxRegistered = 100
+
+// registerSpec registers the simulation declared by a spec for an organization, and returns its type.
+func (s *SimulationEngine) registerSpec(orgID int64, spec *simulationSpec) (string, error) {
+	c, err := compileSpec(spec)
+	if err != nil {
+		return "", err
+	}
+	b, err := json.Marshal(spec)
+	if err != nil {
+		return "", err
+	}
+	hash := sha256.Sum256(b)
+	ref := specRef{orgID: orgID, typ: spec.Type + "-" + hex.EncodeToString(hash[:8])}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if _, ok := s.registry[spec.Type]; ok {
+		return "", fmt.Errorf("can not replace the %s simulation", spec.Type)
+	}
+
+	if current, ok := s.specs[ref]; ok {
+		current.used = time.Now()
+		return ref.typ, nil
+	}
+
+	if len(s.specs) >= specMaxRegistered {
+		s.removeLeastRecentlyUsedSpec()
+	}
+
+	info := newSpecSimInfo(c)
+	info.Type = ref.typ
+	s.specs[ref] = &registeredSpec{info: info, used: time.Now()}
+	return ref.typ, nil
+}
+
+// removeLeastRecentlyUsedSpec removes a spec and closes its running instances, the caller must hold the mutex.
+func (s *SimulationEngine) removeLeastRecentlyUsedSpec() {
+	var oldest specRef
+	var oldestUsed time.Time
+	for ref, spec := range s.specs {
+		if oldestUsed.IsZero() || spec.used.Before(oldestUsed) {
+			oldest, oldestUsed = ref, spec.used
+		}
+	}
+
+	prefix := specRunningPrefix(oldest)
+	for key, sim := range s.running {
+		if strings.HasPrefix(key, prefix) {
+			_ = sim.Close()
+			delete(s.running, key)
+		}
+	}
+	delete(s.specs, oldest)
+}
+
+// specRunningPrefix is the prefix of the keys of the running instances of a spec.
+func specRunningPrefix(ref specRef) string {
+	return fmt.Sprintf("%d/%s/", ref.orgID, ref.typ)
+}
+
 type simulationInitializer = func() simulationInfo
 
 func NewSimulationEngine() (*SimulationEngine, error) {
 	s := &SimulationEngine{
 		registry: make(map[string]simulationInfo),
+		specs:    make(map[specRef]*registeredSpec),
 		running:  make(map[string]Simulation),
 		logger:   backend.NewLoggerWith("logger", "tsdb.sims"),
 	}
@@ -77,7 +160,7 @@ func NewSimulationEngine() (*SimulationEngine, error) {
 	return s, nil
 }
 
-func (s *SimulationEngine) Lookup(info simulationState) (Simulation, error) {
+func (s *SimulationEngine) Lookup(orgID int64, info simulationState) (Simulation, error) {
 	hz := info.Key.TickHZ
 	if hz < (1 / 60.0) {
 		return nil, fmt.Errorf("frequency is too slow")
@@ -93,16 +176,23 @@ func (s *SimulationEngine) Lookup(info simulationState) (Simulation, error) {
 	s.mutex.Lock()
 	defer s.mutex.Unlock()
 
+	t, ok := s.registry[info.Key.Type]
+	if !ok {
+		spec, found := s.specs[specRef{orgID: orgID, typ: info.Key.Type}]
+		if !found {
+			return nil, fmt.Errorf("unknown simulation type")
+		}
+		spec.used = time.Now()
+		t = spec.info
+		// the instances of the specs are not shared between organizations
+		key = fmt.Sprintf("%d/%s", orgID, key)
+	}
+
 	v, ok := s.running[key]
 	if ok {
 		return v, nil
 	}
 
-	t, ok := s.registry[info.Key.Type]
-	if !ok {
-		return nil, fmt.Errorf("unknown simulation type")
-	}
-
 	v, err := t.create(info)
 	if err == nil {
 		s.running[key] = v
@@ -114,6 +204,8 @@ type simulationQuery struct {
 	simulationState
 	Last   bool `json:"last"`
 	Stream bool `json:"stream"`
+	// Spec declares the simulation, in JSON or YAML
+	Spec string `json:"spec"`
 }
 
 type dumbQueryQrapper struct {
@@ -134,7 +226,17 @@ func (s *SimulationEngine) QueryData(ctx context.Context, req *backend.QueryData
 			sq.Key.TickHZ = 10
 		}
 
-		sim, err := s.Lookup(sq.simulationState)
+		if sq.Spec != "" {
+			spec, err := parseSimulationSpec([]byte(sq.Spec))
+			if err == nil {
+				sq.Key.Type, err = s.registerSpec(req.PluginContext.OrgID, spec)
+			}
+			if err != nil {
+				return nil, fmt.Errorf("error registering simulation: %v", err)
+			}
+		}
+
+		sim, err := s.Lookup(req.PluginContext.OrgID, sq.simulationState)
 		if err != nil {
 			return nil, fmt.Errorf("error fetching simulation: %v", err)
 		}
@@ -178,7 +280,7 @@ func (s *SimulationEngine) QueryData(ctx context.Context, req *backend.QueryData
 	return resp, nil
 }
 
-func (s *SimulationEngine) getSimFromPath(path string) (Simulation, error) {
+func (s *SimulationEngine) getSimFromPath(orgID int64, path string) (Simulation, error) {
 	idx := strings.Index(path, "sim/")
 	if idx >= 0 {
 		path = path[idx+4:]
@@ -208,7 +310,7 @@ func (s *SimulationEngine) getSimFromPath(path string) (Simulation, error) {
 		return nil, fmt.Errorf("path should match: %s", key.String())
 	}
 
-	return s.Lookup(simulationState{
+	return s.Lookup(orgID, simulationState{
 		Key: key,
 	})
 }
@@ -216,14 +318,40 @@ func (s *SimulationEngine) getSimFromPath(path string) (Simulation, error) {
 func (s *SimulationEngine) GetSimulationHandler(rw http.ResponseWriter, req *http.Request) {
 	var result any
 	path := req.URL.Path
-	if path == "/sims" {
+	orgID := httpadapter.PluginConfigFromContext(req.Context()).OrgID
+	if path == "/sims" && req.Method == "POST" {
+		// With a POST, register the simulation declared by the body
+		body, err := io.ReadAll(req.Body)
+		var spec *simulationSpec
+		var typ string
+		if err == nil {
+			spec, err = parseSimulationSpec(body)
+		}
+		if err == nil {
+			typ, err = s.registerSpec(orgID, spec)
+		}
+		if err != nil {
+			http.Error(rw, err.Error(), http.StatusBadRequest)
+			return
+		}
+		s.mutex.Lock()
+		result = s.specs[specRef{orgID: orgID, typ: typ}].info
+		s.mutex.Unlock()
+	} else if path == "/sims" {
+		s.mutex.Lock()
 		v := make([]simulationInfo, 0, len(s.registry))
 		for _, value := range s.registry {
 			v = append(v, value)
 		}
+		for ref, spec := range s.specs {
+			if ref.orgID == orgID {
+				v = append(v, spec.info)
+			}
+		}
+		s.mutex.Unlock()
 		result = v
 	} else if strings.HasPrefix(path, "/sim/") {
-		sim, err := s.getSimFromPath(path)
+		sim, err := s.getSimFromPath(orgID, path)
 		if err != nil {
 			http.Error(rw, err.Error(), http.StatusNotFound)
 			return
@@ -256,7 +384,7 @@ func (s *SimulationEngine) GetSimulationHandler(rw http.ResponseWriter, req *htt
 }
 
 func (s *SimulationEngine) SubscribeStream(ctx context.Context, req *backend.SubscribeStreamRequest) (*backend.SubscribeStreamResponse, error) {
-	sim, err := s.getSimFromPath(req.Path) // includes sim
+	sim, err := s.getSimFromPath(req.PluginContext.OrgID, req.Path) // includes sim
 	if err != nil {
 		return nil, err
 	}
@@ -272,7 +400,7 @@ func (s *SimulationEngine) SubscribeStream(ctx context.Context, req *backend.Sub
 }
 
 func (s *SimulationEngine) RunStream(ctx context.Context, req *backend.RunStreamRequest, sender *backend.StreamSender) error {
-	sim, err := s.getSimFromPath(req.Path) // includes sim
+	sim, err := s.getSimFromPath(req.PluginContext.OrgID, req.Path) // includes sim
 	if err != nil {
 		return err
 	}
@@ -10,7 +10,7 @@ import (
 func TestCoreSimulationRegistry(t *testing.T) {
 	sims, err := NewSimulationEngine()
 	require.NoError(t, err)
-	v, err := sims.Lookup(simulationState{
+	v, err := sims.Lookup(1, simulationState{
 		Key: simulationKey{
 			Type:   "flight",
 			TickHZ: 1,
@@ -40,25 +40,25 @@ func TestCoreSimulationRegistry(t *testing.T) {
 	}`, string(cfg))
 
 	path := v.GetState().Key.String()
-	found, err := sims.getSimFromPath("sim/" + path)
+	found, err := sims.getSimFromPath(1, "sim/"+path)
 	require.NoError(t, err)
 	require.Equal(t, v, found)
 
-	found, err = sims.getSimFromPath("/sim/" + path)
+	found, err = sims.getSimFromPath(1, "/sim/"+path)
 	require.NoError(t, err)
 	require.Equal(t, v, found)
 
-	found, err = sims.getSimFromPath(path)
+	found, err = sims.getSimFromPath(1, path)
 	require.NoError(t, err)
 	require.Equal(t, v, found)
 
 	// In valid paths
-	_, err = sims.getSimFromPath("flight/1.00hz")
+	_, err = sims.getSimFromPath(1, "flight/1.00hz")
 	require.Error(t, err)
 
-	_, err = sims.getSimFromPath("flight/1")
+	_, err = sims.getSimFromPath(1, "flight/1")
 	require.Error(t, err)
 
-	_, err = sims.getSimFromPath("flight/1/")
+	_, err = sims.getSimFromPath(1, "flight/1/")
 	require.Error(t, err)
 }
@@ -0,0 +1,154 @@
+package sims
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/grafana/grafana/pkg/expr/mathexp/parse"
+)
+
+// formula is a parsed math expression of a simulation spec. It is parsed like the math expressions of server side
+// expressions, and evaluated on numbers: `$name` or `${name}` variables, the `+ - * / % **` arithmetic operators,
+// the `== != > >= < <=` comparisons, the `&& || !` logical operators and the functions of formulaFuncs.
+type formula struct {
+	tree *parse.Tree
+}
+
+// formulaVars are the values of the variables of a formula by name.
+type formulaVars map[string]float64
+
+// formulaMaxLength limits the size of the parse tree of a formula.
+const formulaMaxLength = 1000
+
+// formulaFuncs are the functions available in formulas.
+var formulaFuncs = map[string]func(float64) float64{
+	"abs":   math.Abs,
+	"ceil":  math.Ceil,
+	"cos":   math.Cos,
+	"exp":   math.Exp,
+	"floor": math.Floor,
+	"log":   math.Log,
+	"round": math.Round,
+	"sin":   math.Sin,
+	"sqrt":  math.Sqrt,
+	"is_inf": func(v float64) float64 {
+		return boolToFloat(math.IsInf(v, 0))
+	},
+	"is_nan": func(v float64) float64 {
+		return boolToFloat(math.IsNaN(v))
+	},
+}
+
+// parseFuncs declares formulaFuncs to the parser, as functions of one number.
+var parseFuncs = func() map[string]parse.Func {
+	funcs := make(map[string]parse.Func, len(formulaFuncs))
+	for name := range formulaFuncs {
+		funcs[name] = parse.Func{
+			Args:   []parse.ReturnType{parse.TypeVariantSet},
+			Return: parse.TypeScalar,
+		}
+	}
+	return funcs
+}()
+
+// parseFormula parses a formula and evaluates it once with the given variables, to find references to unknown
+// variables.
+func parseFormula(text string, vars formulaVars) (*formula, error) {
+	if len(text) > formulaMaxLength {
+		return nil, fmt.Errorf("formulas can't be longer than %d characters", formulaMaxLength)
+	}
+	tree, err := parse.Parse(text, parseFuncs)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", text, err)
+	}
+
+	f := &formula{tree: tree}
+	if _, err := f.eval(vars); err != nil {
+		return nil, fmt.Errorf("%s: %w", text, err)
+	}
+	return f, nil
+}
+
+func (f *formula) eval(vars formulaVars) (float64, error) {
+	return evalNode(f.tree.Root, vars)
+}
+
+func evalNode(node parse.Node, vars formulaVars) (float64, error) {
+	switch n := node.(type) {
+	case *parse.ScalarNode:
+		return n.Float64, nil
+	case *parse.VarNode:
+		v, ok := vars[n.Name]
+		if !ok {
+			return 0, fmt.Errorf("unknown variable %q", n.Name)
+		}
+		return v, nil
+	case *parse.FuncNode:
+		v, err := evalNode(n.Args[0], vars)
+		if err != nil {
+			return 0, err
+		}
+		return formulaFuncs[n.Name](v), nil
+	case *parse.UnaryNode:
+		v, err := evalNode(n.Arg, vars)
+		if err != nil {
+			return 0, err
+		}
+		if n.OpStr == "!" {
+			return boolToFloat(v == 0), nil
+		}
+		return -v, nil
+	case *parse.BinaryNode:
+		a, err := evalNode(n.Args[0], vars)
+		if err != nil {
+			return 0, err
+		}
+		b, err := evalNode(n.Args[1], vars)
+		if err != nil {
+			return 0, err
+		}
+		return evalBinary(n.OpStr, a, b)
+	}
+	return 0, fmt.Errorf("unexpected %s", node)
+}
+
+func evalBinary(op string, a, b float64) (float64, error) {
+	switch op {
+	case "+":
+		return a + b, nil
+	case "-":
+		return a - b, nil
+	case "*":
+		return a * b, nil
+	case "/":
+		return a / b, nil
+	case "%":
+		return math.Mod(a, b), nil
+	case "**":
+		return math.Pow(a, b), nil
+	case "==":
+		return boolToFloat(a == b), nil
+	case "!=":
+		return boolToFloat(a != b), nil
+	case ">":
+		return boolToFloat(a > b), nil
+	case ">=":
+		return boolToFloat(a >= b), nil
+	case "<":
+		return boolToFloat(a < b), nil
+	case "<=":
+		return boolToFloat(a <= b), nil
+	case "&&":
+		return boolToFloat(a != 0 && b != 0), nil
+	case "||":
+		return boolToFloat(a != 0 || b != 0), nil
+	}
+	return 0, fmt.Errorf("unknown operator %q", op)
+}
+
+func boolToFloat(b bool) float64 {
+	if b {
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,61 @@
+package sims
+
+import (
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestFormula(t *testing.T) {
+	vars := formulaVars{"a": 2, "b": 3, "t": 0.5}
+
+	tests := []struct {
+		formula string
+		value   float64
+		err     string
+	}{
+		{formula: "1 + 2 * 3", value: 7},
+		{formula: "(1 + 2) * 3", value: 9},
+		{formula: "$a ** $b", value: 8},
+		{formula: "${a} - $b - 1", value: -2},
+		{formula: "-$a * 2", value: -4},
+		{formula: "7 % $b", value: 1},
+		{formula: "1.5e1 / 3", value: 5},
+		{formula: "$a > 1 && $b <= 3", value: 1},
+		{formula: "$a == 1 || !($b != 3)", value: 1},
+		{formula: "abs(sin($t * 0)) + sqrt(4) + floor(round(2.4))", value: 4},
+		{formula: "is_nan(log(-1))", value: 1},
+		{formula: "$a +", err: "unexpected EOF"},
+		{formula: "$a $b", err: "unexpected"},
+		{formula: "($a", err: "unexpected EOF"},
+		{formula: "$c", err: "unknown variable \"c\""},
+		{formula: "pow($a)", err: "non existent function pow"},
+		{formula: "abs()", err: "not enough arguments for abs"},
+		{formula: "abs(\"a\")", err: "got string"},
+		{formula: "$a # 1", err: "invalid character: #"},
+		{formula: "${a", err: "missing closing }"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.formula, func(t *testing.T) {
+			f, err := parseFormula(tt.formula, vars)
+			if tt.err != "" {
+				require.ErrorContains(t, err, tt.err)
+				return
+			}
+			require.NoError(t, err)
+			v, err := f.eval(vars)
+			require.NoError(t, err)
+			require.InDelta(t, tt.value, v, 1e-9)
+		})
+	}
+
+	t.Run("divisions by zero are not errors", func(t *testing.T) {
+		f, err := parseFormula("$a / 0", vars)
+		require.NoError(t, err)
+		v, err := f.eval(vars)
+		require.NoError(t, err)
+		require.True(t, math.IsInf(v, 1))
+	})
+}
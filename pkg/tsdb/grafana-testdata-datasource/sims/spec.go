@@ -0,0 +1,220 @@
+package sims
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"regexp"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// simulationSpec declares a simulation: its state variables, how they are updated on each step, and the events
+// that change them. Formulas use the syntax of math expressions, referencing the variables and parameters like
+// `$name`, the seconds since the start of the simulation as `$t` and the seconds since the previous step as `$dt`.
+type simulationSpec struct {
+	Type        string `json:"type"`
+	Name        string `json:"name,omitempty"`
+	Description string `json:"description,omitempty"`
+	// Seed of the noise
+	Seed int64 `json:"seed,omitempty"`
+
+	// Parameters can be changed while the simulation runs, like the config of the other simulations
+	Parameters []specParameter `json:"parameters,omitempty"`
+	Variables  []specVariable  `json:"variables"`
+	Events     []specEvent     `json:"events,omitempty"`
+}
+
+type specParameter struct {
+	Name  string  `json:"name"`
+	Value float64 `json:"value"`
+	Unit  string  `json:"unit,omitempty"`
+}
+
+type specVariable struct {
+	Name    string  `json:"name"`
+	Initial float64 `json:"initial,omitempty"`
+	// Update returns the value of the variable after a step, the variable keeps its value when empty
+	Update string `json:"update,omitempty"`
+	// Noise is the standard deviation of the random noise added each second
+	Noise float64  `json:"noise,omitempty"`
+	Min   *float64 `json:"min,omitempty"`
+	Max   *float64 `json:"max,omitempty"`
+	Unit  string   `json:"unit,omitempty"`
+}
+
+// specEvent sets variables when it is triggered, either periodically, randomly, or when a condition becomes true.
+type specEvent struct {
+	Name string `json:"name"`
+	// Every is the period of the event, like `5m`
+	Every string `json:"every,omitempty"`
+	// Rate is the mean number of events per second
+	Rate float64 `json:"rate,omitempty"`
+	// When is a condition, the event is triggered when it becomes non zero
+	When string `json:"when,omitempty"`
+	// Set are the formulas of the variables changed by the event
+	Set map[string]string `json:"set"`
+}
+
+const (
+	specMaxVariables = 100
+	specMaxEvents    = 100
+)
+
+var (
+	specTypePattern = regexp.MustCompile(`^[a-z0-9_-]+$`)
+	specNamePattern = regexp.MustCompile(`^[a-zA-Z_][a-zA-Z0-9_]*$`)
+
+	// names of the variables always available in formulas
+	specReservedNames = map[string]bool{"t": true, "dt": true, "time": true}
+)
+
+// parseSimulationSpec parses a JSON or YAML spec.
+func parseSimulationSpec(text []byte) (*simulationSpec, error) {
+	// YAML is a superset of JSON, and converting to JSON reuses the JSON field names
+	var raw any
+	if err := yaml.Unmarshal(text, &raw); err != nil {
+		return nil, fmt.Errorf("invalid simulation spec: %w", err)
+	}
+	b, err := json.Marshal(raw)
+	if err != nil {
+		return nil, fmt.Errorf("invalid simulation spec: %w", err)
+	}
+
+	spec := &simulationSpec{}
+	if err := json.Unmarshal(b, spec); err != nil {
+		return nil, fmt.Errorf("invalid simulation spec: %w", err)
+	}
+	return spec, nil
+}
+
+// compiledSpec is a validated spec with its parsed formulas.
+type compiledSpec struct {
+	spec    *simulationSpec
+	updates []*formula // by variable, nil when the variable is not updated
+	events  []compiledEvent
+}
+
+type compiledEvent struct {
+	every time.Duration
+	rate  float64
+	when  *formula
+	set   map[int]*formula // by variable index
+}
+
+func compileSpec(spec *simulationSpec) (*compiledSpec, error) {
+	if !specTypePattern.MatchString(spec.Type) {
+		return nil, fmt.Errorf("invalid simulation type %q, expecting lowercase letters, digits, '-' and '_'", spec.Type)
+	}
+	if len(spec.Variables) == 0 {
+		return nil, errors.New("the simulation has no variables")
+	}
+	if len(spec.Variables) > specMaxVariables || len(spec.Events) > specMaxEvents {
+		return nil, fmt.Errorf("a simulation can't have more than %d variables and %d events", specMaxVariables, specMaxEvents)
+	}
+
+	c := &compiledSpec{spec: spec}
+	names := map[string]bool{}
+	checkName := func(name string) error {
+		if !specNamePattern.MatchString(name) {
+			return fmt.Errorf("invalid name %q", name)
+		}
+		if specReservedNames[name] || names[name] {
+			return fmt.Errorf("duplicate or reserved name %q", name)
+		}
+		names[name] = true
+		return nil
+	}
+
+	for _, p := range spec.Parameters {
+		if err := checkName(p.Name); err != nil {
+			return nil, err
+		}
+	}
+
+	variables := map[string]int{}
+	for i, v := range spec.Variables {
+		if err := checkName(v.Name); err != nil {
+			return nil, err
+		}
+		if v.Min != nil && v.Max != nil && *v.Min > *v.Max {
+			return nil, fmt.Errorf("variable %q: min is larger than max", v.Name)
+		}
+		variables[v.Name] = i
+	}
+
+	// the formulas are run once on the initial state, to find references to unknown variables
+	vars := c.initialVars()
+
+	for _, v := range spec.Variables {
+		var update *formula
+		if v.Update != "" {
+			var err error
+			if update, err = parseFormula(v.Update, vars); err != nil {
+				return nil, fmt.Errorf("variable %q: %w", v.Name, err)
+			}
+		}
+		c.updates = append(c.updates, update)
+	}
+
+	for _, e := range spec.Events {
+		if e.Name == "" {
+			return nil, errors.New("events must have a name")
+		}
+
+		ce := compiledEvent{rate: e.Rate, set: map[int]*formula{}}
+		triggers := 0
+		if e.Every != "" {
+			d, err := time.ParseDuration(e.Every)
+			if err != nil || d < time.Second {
+				return nil, fmt.Errorf("event %q: every must be a duration of at least 1s", e.Name)
+			}
+			ce.every = d
+			triggers++
+		}
+		if e.Rate < 0 {
+			return nil, fmt.Errorf("event %q: rate can't be negative", e.Name)
+		}
+		if e.Rate > 0 {
+			triggers++
+		}
+		if e.When != "" {
+			var err error
+			if ce.when, err = parseFormula(e.When, vars); err != nil {
+				return nil, fmt.Errorf("event %q: %w", e.Name, err)
+			}
+			triggers++
+		}
+		if triggers != 1 {
+			return nil, fmt.Errorf("event %q must have one of every, rate or when", e.Name)
+		}
+
+		for name, text := range e.Set {
+			idx, ok := variables[name]
+			if !ok {
+				return nil, fmt.Errorf("event %q: unknown variable %q", e.Name, name)
+			}
+			f, err := parseFormula(text, vars)
+			if err != nil {
+				return nil, fmt.Errorf("event %q: %w", e.Name, err)
+			}
+			ce.set[idx] = f
+		}
+		c.events = append(c.events, ce)
+	}
+
+	return c, nil
+}
+
+// initialVars returns the variables of the formulas at the start of the simulation.
+func (c *compiledSpec) initialVars() formulaVars {
+	vars := formulaVars{"t": 0, "dt": 0}
+	for _, p := range c.spec.Parameters {
+		vars[p.Name] = p.Value
+	}
+	for _, v := range c.spec.Variables {
+		vars[v.Name] = v.Initial
+	}
+	return vars
+}
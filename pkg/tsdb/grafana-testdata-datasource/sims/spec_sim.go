@@ -0,0 +1,258 @@
+package sims
+
+import (
+	"fmt"
+	"hash/fnv"
+	"math"
+	"math/rand"
+	"sync"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+)
+
+const (
+	// specMaxStep is the longest step of a simulation, longer durations are split in several steps
+	specMaxStep = time.Second
+	// specMaxSteps limits the work done to catch up with the current time
+	specMaxSteps = 10000
+)
+
+type specSim struct {
+	key  simulationKey
+	spec *compiledSpec
+
+	mutex  sync.Mutex
+	params map[string]float64
+	values []float64
+	start  time.Time
+	last   time.Time
+	rng    *rand.Rand
+	// fired is set when an event was triggered since the last values
+	fired []bool
+	// when is the last result of the event conditions
+	when []bool
+}
+
+var (
+	_ Simulation = (*specSim)(nil)
+)
+
+func newSpecSim(c *compiledSpec, key simulationKey, start time.Time) *specSim {
+	h := fnv.New64a()
+	_, _ = h.Write([]byte(key.String()))
+
+	s := &specSim{
+		key:    key,
+		spec:   c,
+		params: make(map[string]float64, len(c.spec.Parameters)),
+		values: make([]float64, len(c.spec.Variables)),
+		start:  start,
+		last:   start,
+		rng:    rand.New(rand.NewSource(c.spec.Seed + int64(h.Sum64()))),
+		fired:  make([]bool, len(c.events)),
+		when:   make([]bool, len(c.events)),
+	}
+	for _, p := range c.spec.Parameters {
+		s.params[p.Name] = p.Value
+	}
+	for i, v := range c.spec.Variables {
+		s.values[i] = v.Initial
+	}
+	return s
+}
+
+func (s *specSim) GetState() simulationState {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	cfg := make(map[string]float64, len(s.params))
+	for k, v := range s.params {
+		cfg[k] = v
+	}
+	return simulationState{
+		Key:    s.key,
+		Config: cfg,
+	}
+}
+
+func (s *specSim) SetConfig(vals map[string]any) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	for k, v := range vals {
+		if _, ok := s.params[k]; !ok {
+			return fmt.Errorf("unknown parameter: %s", k)
+		}
+		f, ok := v.(float64)
+		if !ok {
+			return fmt.Errorf("parameter %s must be a number", k)
+		}
+		s.params[k] = f
+	}
+	return nil
+}
+
+func (s *specSim) NewFrame(size int) *data.Frame {
+	frame := data.NewFrameOfFieldTypes("", size, data.FieldTypeTime)
+	frame.Fields[0].Name = "time"
+	for _, v := range s.spec.spec.Variables {
+		f := data.NewFieldFromFieldType(data.FieldTypeFloat64, size)
+		f.Name = v.Name
+		if v.Unit != "" {
+			f.Config = &data.FieldConfig{Unit: v.Unit}
+		}
+		frame.Fields = append(frame.Fields, f)
+	}
+	for _, e := range s.spec.spec.Events {
+		f := data.NewFieldFromFieldType(data.FieldTypeBool, size)
+		f.Name = e.Name
+		frame.Fields = append(frame.Fields, f)
+	}
+	return frame
+}
+
+func (s *specSim) GetValues(t time.Time) map[string]any {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if t.Before(s.last) {
+		return nil // can not look backwards!
+	}
+
+	if elapsed := t.Sub(s.last); elapsed > 0 {
+		steps := int64(math.Ceil(float64(elapsed) / float64(specMaxStep)))
+		if steps > specMaxSteps {
+			steps = specMaxSteps
+		}
+		step := elapsed / time.Duration(steps)
+		for i := int64(1); i < steps; i++ {
+			s.step(s.last.Add(step))
+		}
+		s.step(t)
+	}
+
+	values := map[string]any{
+		"time": t,
+	}
+	for i, v := range s.spec.spec.Variables {
+		values[v.Name] = s.values[i]
+	}
+	for i, e := range s.spec.spec.Events {
+		values[e.Name] = s.fired[i]
+		s.fired[i] = false
+	}
+	return values
+}
+
+// step moves the simulation to t.
+func (s *specSim) step(t time.Time) {
+	dt := t.Sub(s.last).Seconds()
+
+	vars := formulaVars{
+		"t":  t.Sub(s.start).Seconds(),
+		"dt": dt,
+	}
+	for k, v := range s.params {
+		vars[k] = v
+	}
+	for i, v := range s.spec.spec.Variables {
+		vars[v.Name] = s.values[i]
+	}
+
+	next := make([]float64, len(s.values))
+	copy(next, s.values)
+
+	for i, v := range s.spec.spec.Variables {
+		if update := s.spec.updates[i]; update != nil {
+			// a formula failing at runtime keeps the previous value
+			if f, err := update.eval(vars); err == nil {
+				next[i] = f
+			}
+		}
+		if v.Noise > 0 {
+			next[i] += s.rng.NormFloat64() * v.Noise * math.Sqrt(dt)
+		}
+	}
+
+	for i, e := range s.spec.events {
+		if !s.triggered(i, e, vars, t, dt) {
+			continue
+		}
+		s.fired[i] = true
+		for idx, set := range e.set {
+			if f, err := set.eval(vars); err == nil {
+				next[idx] = f
+			}
+		}
+	}
+
+	for i, v := range s.spec.spec.Variables {
+		if v.Min != nil && next[i] < *v.Min {
+			next[i] = *v.Min
+		}
+		if v.Max != nil && next[i] > *v.Max {
+			next[i] = *v.Max
+		}
+	}
+
+	s.values = next
+	s.last = t
+}
+
+func (s *specSim) triggered(i int, e compiledEvent, vars formulaVars, t time.Time, dt float64) bool {
+	switch {
+	case e.every > 0:
+		// periods are aligned on the epoch, like the predictable scenarios
+		return s.last.UnixNano()/int64(e.every) != t.UnixNano()/int64(e.every)
+	case e.rate > 0:
+		return s.rng.Float64() < 1-math.Exp(-e.rate*dt)
+	case e.when != nil:
+		f, err := e.when.eval(vars)
+		active := err == nil && f != 0 && !math.IsNaN(f)
+		rising := active && !s.when[i]
+		s.when[i] = active
+		return rising
+	}
+	return false
+}
+
+func (s *specSim) Close() error {
+	return nil
+}
+
+func newSpecSimInfo(c *compiledSpec) simulationInfo {
+	df := data.NewFrame("")
+	for _, p := range c.spec.Parameters {
+		f := data.NewField(p.Name, nil, []float64{p.Value})
+		if p.Unit != "" {
+			f.Config = &data.FieldConfig{Unit: p.Unit}
+		}
+		df.Fields = append(df.Fields, f)
+	}
+
+	name := c.spec.Name
+	if name == "" {
+		name = c.spec.Type
+	}
+
+	return simulationInfo{
+		Type:         c.spec.Type,
+		Name:         name,
+		Description:  c.spec.Description,
+		ConfigFields: df,
+		OnlyForward:  true,
+		Spec:         c.spec,
+		create: func(state simulationState) (Simulation, error) {
+			s := newSpecSim(c, state.Key, time.Now())
+			if state.Config == nil {
+				return s, nil
+			}
+			cfg, err := asStringMap(state.Config)
+			if err != nil {
+				return nil, err
+			}
+			return s, s.SetConfig(cfg)
+		},
+	}
+}
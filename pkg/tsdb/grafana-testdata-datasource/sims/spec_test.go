@@ -0,0 +1,218 @@
+package sims
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/stretchr/testify/require"
+)
+
+const counterSpec = `
+type: counter
+name: Counter
+parameters:
+  - name: rate
+    value: 2
+variables:
+  - name: value
+    update: $value + $rate * $dt
+    max: 100
+events:
+  - name: reset
+    every: 5s
+    set:
+      value: "0"
+`
+
+func newTestSpecSim(t *testing.T, text string, start time.Time) *specSim {
+	t.Helper()
+	spec, err := parseSimulationSpec([]byte(text))
+	require.NoError(t, err)
+	c, err := compileSpec(spec)
+	require.NoError(t, err)
+	return newSpecSim(c, simulationKey{Type: spec.Type, TickHZ: 1}, start)
+}
+
+func TestSpecSimulation(t *testing.T) {
+	start := time.Unix(1000, 0)
+
+	t.Run("updates variables and triggers periodic events", func(t *testing.T) {
+		sim := newTestSpecSim(t, counterSpec, start)
+
+		v := sim.GetValues(start.Add(4 * time.Second))
+		require.Equal(t, 8.0, v["value"])
+		require.Equal(t, false, v["reset"])
+
+		v = sim.GetValues(start.Add(7 * time.Second))
+		require.Equal(t, 4.0, v["value"])
+		require.Equal(t, true, v["reset"])
+
+		require.Nil(t, sim.GetValues(start), "can not look backwards")
+	})
+
+	t.Run("uses the parameters", func(t *testing.T) {
+		sim := newTestSpecSim(t, counterSpec, start)
+		require.NoError(t, sim.SetConfig(map[string]any{"rate": 10.0}))
+		require.Equal(t, map[string]float64{"rate": 10}, sim.GetState().Config)
+
+		v := sim.GetValues(start.Add(2 * time.Second))
+		require.Equal(t, 20.0, v["value"])
+
+		require.Error(t, sim.SetConfig(map[string]any{"unknown": 1.0}))
+		require.Error(t, sim.SetConfig(map[string]any{"rate": "fast"}))
+	})
+
+	t.Run("triggers events when conditions become true", func(t *testing.T) {
+		sim := newTestSpecSim(t, `{
+			"type": "sawtooth",
+			"variables": [{"name": "v", "update": "$v + $dt", "unit": "s"}],
+			"events": [{"name": "wrap", "when": "$v >= 3", "set": {"v": "0"}}]
+		}`, start)
+
+		v := sim.GetValues(start.Add(5 * time.Second))
+		require.Equal(t, 1.0, v["v"])
+		require.Equal(t, true, v["wrap"])
+
+		frame := sim.NewFrame(0)
+		require.Len(t, frame.Fields, 3)
+		require.Equal(t, "s", frame.Fields[1].Config.Unit)
+	})
+
+	t.Run("clamps variables and supports math functions", func(t *testing.T) {
+		sim := newTestSpecSim(t, `
+type: wave
+variables:
+  - name: level
+    initial: 5
+    update: $level + 10 * $dt
+    max: 12
+  - name: wave
+    update: abs(sin($t)) + sqrt(4)
+`, start)
+
+		v := sim.GetValues(start.Add(3 * time.Second))
+		require.Equal(t, 12.0, v["level"])
+		require.InDelta(t, 2.141, v["wave"], 0.001)
+	})
+}
+
+func TestCompileSpec(t *testing.T) {
+	tests := []struct {
+		name string
+		spec string
+		err  string
+	}{
+		{name: "invalid type", spec: `{"type": "My Sim", "variables": [{"name": "a"}]}`, err: "invalid simulation type"},
+		{name: "no variables", spec: `{"type": "a"}`, err: "no variables"},
+		{name: "reserved name", spec: `{"type": "a", "variables": [{"name": "dt"}]}`, err: "reserved"},
+		{name: "duplicate name", spec: `{"type": "a", "parameters": [{"name": "a"}], "variables": [{"name": "a"}]}`, err: "duplicate"},
+		{name: "unknown variable", spec: `{"type": "a", "variables": [{"name": "a", "update": "$a + $b"}]}`, err: "variable \"a\""},
+		{name: "invalid formula", spec: `{"type": "a", "variables": [{"name": "a", "update": "$a +"}]}`, err: "variable \"a\""},
+		{name: "event without trigger", spec: `{"type": "a", "variables": [{"name": "a"}], "events": [{"name": "e"}]}`, err: "one of every, rate or when"},
+		{name: "event with two triggers", spec: `{"type": "a", "variables": [{"name": "a"}], "events": [{"name": "e", "every": "1m", "rate": 1}]}`, err: "one of every, rate or when"},
+		{name: "event setting unknown variable", spec: `{"type": "a", "variables": [{"name": "a"}], "events": [{"name": "e", "rate": 1, "set": {"b": "1"}}]}`, err: "unknown variable"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			spec, err := parseSimulationSpec([]byte(tt.spec))
+			require.NoError(t, err)
+			_, err = compileSpec(spec)
+			require.ErrorContains(t, err, tt.err)
+		})
+	}
+}
+
+func TestSpecRegistry(t *testing.T) {
+	sims, err := NewSimulationEngine()
+	require.NoError(t, err)
+
+	spec, err := parseSimulationSpec([]byte(counterSpec))
+	require.NoError(t, err)
+	typ, err := sims.registerSpec(1, spec)
+	require.NoError(t, err)
+	require.Regexp(t, `^counter-[0-9a-f]{16}$`, typ)
+	same, err := sims.registerSpec(1, spec)
+	require.NoError(t, err)
+	require.Equal(t, typ, same, "registering the same spec again is a no-op")
+
+	key := simulationKey{Type: typ, TickHZ: 1}
+	first, err := sims.Lookup(1, simulationState{Key: key})
+	require.NoError(t, err)
+	found, err := sims.Lookup(1, simulationState{Key: key})
+	require.NoError(t, err)
+	require.Same(t, first, found)
+
+	t.Run("keeps the specs per organization", func(t *testing.T) {
+		_, err := sims.Lookup(2, simulationState{Key: key})
+		require.Error(t, err)
+
+		other, err := sims.registerSpec(2, spec)
+		require.NoError(t, err)
+		require.Equal(t, typ, other)
+		found, err := sims.Lookup(2, simulationState{Key: key})
+		require.NoError(t, err)
+		require.NotSame(t, first, found)
+	})
+
+	t.Run("registers a new version of a spec next to the running one", func(t *testing.T) {
+		changed := *spec
+		changed.Name = "Counter v2"
+		v2, err := sims.registerSpec(1, &changed)
+		require.NoError(t, err)
+		require.NotEqual(t, typ, v2)
+
+		found, err := sims.Lookup(1, simulationState{Key: key})
+		require.NoError(t, err)
+		require.Same(t, first, found)
+	})
+
+	t.Run("can not replace the built-in simulations", func(t *testing.T) {
+		builtin := *spec
+		builtin.Type = "tank"
+		_, err := sims.registerSpec(1, &builtin)
+		require.Error(t, err)
+	})
+
+	t.Run("removes the least recently used specs", func(t *testing.T) {
+		for i := 0; i < specMaxRegistered; i++ {
+			s := *spec
+			s.Seed = int64(i + 1)
+			_, err := sims.registerSpec(3, &s)
+			require.NoError(t, err)
+		}
+		require.Len(t, sims.specs, specMaxRegistered)
+
+		_, err := sims.Lookup(1, simulationState{Key: key})
+		require.Error(t, err)
+		require.NotContains(t, sims.running, "1/"+key.String())
+	})
+
+	t.Run("registers specs from queries", func(t *testing.T) {
+		model, err := json.Marshal(map[string]any{
+			"sim": map[string]any{
+				"key":  map[string]any{"tick": 1, "uid": "query"},
+				"spec": counterSpec,
+				"last": true,
+			},
+		})
+		require.NoError(t, err)
+
+		resp, err := sims.QueryData(context.Background(), &backend.QueryDataRequest{
+			PluginContext: backend.PluginContext{OrgID: 1},
+			Queries: []backend.DataQuery{{
+				RefID:     "A",
+				JSON:      model,
+				TimeRange: backend.TimeRange{From: time.Now(), To: time.Now().Add(time.Minute)},
+			}},
+		})
+		require.NoError(t, err)
+		frame := resp.Responses["A"].Frames[0]
+		require.Equal(t, 1, frame.Rows())
+		require.Equal(t, "value", frame.Fields[1].Name)
+		require.Equal(t, "reset", frame.Fields[2].Name)
+	})
+}
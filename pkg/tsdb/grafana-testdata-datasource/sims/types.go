@@ -38,6 +38,9 @@ type simulationInfo struct {
 	OnlyForward  bool        `json:"forward"`
 	ConfigFields *data.Frame `json:"config"`
 
+	// Spec of the simulations declared by users
+	Spec *simulationSpec `json:"spec,omitempty"`
+
 	// Create a simulation instance
 	create func(q simulationState) (Simulation, error)
 }
@@ -0,0 +1,212 @@
+package testdatasource
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"math"
+	"math/rand"
+	"strings"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+
+	"github.com/grafana/grafana/pkg/tsdb/grafana-testdata-datasource/kinds"
+)
+
+const (
+	maxWorkloadSeries       = 100_000
+	maxWorkloadValues       = 10_000_000
+	maxWorkloadPayloadBytes = 256 << 20
+	maxWorkloadLatency      = 5 * time.Minute
+	defaultWorkloadPoints   = 100
+)
+
+// handleWorkloadScenario generates large responses, with configurable latency and errors, to test Grafana under load.
+// The response of a query only depends on the seed, the query and its time range.
+func (s *Service) handleWorkloadScenario(ctx context.Context, req *backend.QueryDataRequest) (*backend.QueryDataResponse, error) {
+	resp := backend.NewQueryDataResponse()
+
+	for _, q := range req.Queries {
+		model, err := GetJSONModel(q.JSON)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse query json: %v", err)
+		}
+
+		w := kinds.WorkloadQuery{}
+		if model.Workload != nil {
+			w = *model.Workload
+		}
+
+		rng := rand.New(rand.NewSource(workloadSeed(w.Seed, q.RefID, q.TimeRange)))
+		if latency := workloadLatency(rng, w); latency > 0 {
+			timer := time.NewTimer(latency)
+			select {
+			case <-ctx.Done():
+				timer.Stop()
+				return nil, ctx.Err()
+			case <-timer.C:
+			}
+		}
+
+		if w.ErrorPercent > 0 && rng.Float64()*100 < w.ErrorPercent {
+			resp.Responses[q.RefID] = backend.ErrDataResponse(backend.StatusInternal, "workload error")
+			continue
+		}
+
+		frames, err := workloadFrames(q, model, w)
+		if err != nil {
+			resp.Responses[q.RefID] = backend.ErrDataResponse(backend.StatusBadRequest, err.Error())
+			continue
+		}
+		resp.Responses[q.RefID] = backend.DataResponse{Frames: frames}
+	}
+
+	return resp, nil
+}
+
+func workloadSeed(seed int64, parts ...any) int64 {
+	h := fnv.New64a()
+	_, _ = fmt.Fprint(h, seed)
+	for _, p := range parts {
+		_, _ = fmt.Fprintf(h, "/%v", p)
+	}
+	return int64(h.Sum64())
+}
+
+func workloadLatency(rng *rand.Rand, w kinds.WorkloadQuery) time.Duration {
+	ms := w.LatencyMs
+	switch w.LatencyDistribution {
+	case kinds.WorkloadLatencyDistributionUniform:
+		ms += (rng.Float64()*2 - 1) * w.LatencyStddevMs * math.Sqrt(3)
+	case kinds.WorkloadLatencyDistributionNormal:
+		ms += rng.NormFloat64() * w.LatencyStddevMs
+	case kinds.WorkloadLatencyDistributionExponential:
+		ms = rng.ExpFloat64() * w.LatencyMs
+	}
+
+	latency := time.Duration(ms * float64(time.Millisecond))
+	if latency < 0 {
+		return 0
+	}
+	if latency > maxWorkloadLatency {
+		return maxWorkloadLatency
+	}
+	return latency
+}
+
+func workloadFrames(q backend.DataQuery, model kinds.TestDataQuery, w kinds.WorkloadQuery) (data.Frames, error) {
+	combinations := int64(1)
+	for _, l := range w.Labels {
+		if l.Name == "" || l.Cardinality < 1 {
+			return nil, errors.New("workload labels must have a name and a cardinality of at least 1")
+		}
+		if combinations <= maxWorkloadSeries {
+			combinations *= min(l.Cardinality, maxWorkloadSeries+1)
+		}
+	}
+
+	series := w.Series
+	if series < 1 {
+		series = 1
+	}
+	if len(w.Labels) > 0 && series > combinations {
+		series = combinations
+	}
+
+	points := w.Points
+	if points < 1 {
+		points = workloadDefaultPoints(q)
+	}
+
+	if series > maxWorkloadSeries {
+		return nil, fmt.Errorf("workload can't have more than %d series", maxWorkloadSeries)
+	}
+	if points > maxWorkloadValues || series*points > maxWorkloadValues {
+		return nil, fmt.Errorf("workload can't have more than %d values", maxWorkloadValues)
+	}
+	if w.PayloadBytes > maxWorkloadPayloadBytes || series*points*w.PayloadBytes > maxWorkloadPayloadBytes {
+		return nil, fmt.Errorf("workload payload can't be larger than %d bytes", maxWorkloadPayloadBytes)
+	}
+
+	step := q.TimeRange.Duration() / time.Duration(points)
+	times := make([]time.Time, points)
+	for j := range times {
+		times[j] = q.TimeRange.From.Add(time.Duration(j) * step)
+	}
+
+	frames := make(data.Frames, 0, series)
+	for i := int64(0); i < series; i++ {
+		rng := rand.New(rand.NewSource(workloadSeed(w.Seed, i)))
+
+		values := make([]float64, points)
+		walker := rng.Float64() * 100
+		for j := range values {
+			values[j] = walker
+			walker += rng.Float64() - 0.5
+		}
+
+		frame := data.NewFrame("",
+			data.NewField("time", nil, times).SetConfig(&data.FieldConfig{
+				Interval: float64(step.Milliseconds()),
+			}),
+			data.NewField(frameNameForQuery(q, model, int(i)), workloadLabels(w.Labels, i), values),
+		)
+
+		if w.PayloadBytes > 0 {
+			payload := make([]string, points)
+			// a single string per series keeps the memory use low, the response is as large as configured
+			content := workloadPayload(rng, w.PayloadBytes)
+			for j := range payload {
+				payload[j] = content
+			}
+			frame.Fields = append(frame.Fields, data.NewField("payload", nil, payload))
+		}
+
+		frames = append(frames, frame)
+	}
+
+	return frames, nil
+}
+
+// workloadDefaultPoints returns the number of points of the query interval in the time range.
+func workloadDefaultPoints(q backend.DataQuery) int64 {
+	if q.Interval <= 0 {
+		return defaultWorkloadPoints
+	}
+	points := int64(q.TimeRange.Duration() / q.Interval)
+	if q.MaxDataPoints > 0 && points > q.MaxDataPoints {
+		points = q.MaxDataPoints
+	}
+	if points < 1 {
+		return 1
+	}
+	return points
+}
+
+// workloadLabels returns the labels of a series, so each series has a different combination of label values.
+func workloadLabels(labels []kinds.WorkloadLabel, index int64) data.Labels {
+	if len(labels) == 0 {
+		return nil
+	}
+
+	l := make(data.Labels, len(labels))
+	for _, label := range labels {
+		l[label.Name] = fmt.Sprintf("%s-%d", label.Name, index%label.Cardinality)
+		index /= label.Cardinality
+	}
+	return l
+}
+
+const workloadPayloadChars = "abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789"
+
+func workloadPayload(rng *rand.Rand, size int64) string {
+	var b strings.Builder
+	b.Grow(int(size))
+	for i := int64(0); i < size; i++ {
+		b.WriteByte(workloadPayloadChars[rng.Intn(len(workloadPayloadChars))])
+	}
+	return b.String()
+}
@@ -0,0 +1,139 @@
+package testdatasource
+
+import (
+	"context"
+	"encoding/json"
+	"math/rand"
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/tsdb/grafana-testdata-datasource/kinds"
+)
+
+func TestWorkloadScenario(t *testing.T) {
+	s := &Service{}
+	to := time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+	tr := backend.TimeRange{From: to.Add(-time.Hour), To: to}
+
+	query := func(refID string, w kinds.WorkloadQuery) backend.DataQuery {
+		model, err := json.Marshal(kinds.TestDataQuery{ScenarioId: kinds.TestDataQueryTypeWorkload, Workload: &w})
+		require.NoError(t, err)
+		return backend.DataQuery{RefID: refID, TimeRange: tr, Interval: time.Minute, MaxDataPoints: 1000, JSON: model}
+	}
+
+	t.Run("generates series with the configured labels and points", func(t *testing.T) {
+		resp, err := s.handleWorkloadScenario(context.Background(), &backend.QueryDataRequest{
+			Queries: []backend.DataQuery{query("A", kinds.WorkloadQuery{
+				Seed:   1,
+				Series: 100,
+				Points: 20,
+				Labels: []kinds.WorkloadLabel{{Name: "pod", Cardinality: 5}, {Name: "region", Cardinality: 3}},
+			})},
+		})
+		require.NoError(t, err)
+
+		frames := resp.Responses["A"].Frames
+		// limited to the number of label combinations
+		require.Len(t, frames, 15)
+
+		seen := map[string]bool{}
+		for _, f := range frames {
+			require.Equal(t, 20, f.Rows())
+			require.Equal(t, tr.From, f.Fields[0].At(0))
+			labels := f.Fields[1].Labels
+			require.Len(t, labels, 2)
+			seen[labels.String()] = true
+		}
+		require.Len(t, seen, 15)
+		require.Equal(t, data.Labels{"pod": "pod-1", "region": "region-0"}, frames[1].Fields[1].Labels)
+		require.Equal(t, data.Labels{"pod": "pod-0", "region": "region-1"}, frames[5].Fields[1].Labels)
+	})
+
+	t.Run("is deterministic by seed", func(t *testing.T) {
+		req := &backend.QueryDataRequest{
+			Queries: []backend.DataQuery{query("A", kinds.WorkloadQuery{Seed: 42, Series: 3, Points: 50, PayloadBytes: 16})},
+		}
+		first, err := s.handleWorkloadScenario(context.Background(), req)
+		require.NoError(t, err)
+		second, err := s.handleWorkloadScenario(context.Background(), req)
+		require.NoError(t, err)
+		require.Equal(t, first.Responses["A"].Frames, second.Responses["A"].Frames)
+
+		other, err := s.handleWorkloadScenario(context.Background(), &backend.QueryDataRequest{
+			Queries: []backend.DataQuery{query("A", kinds.WorkloadQuery{Seed: 43, Series: 3, Points: 50, PayloadBytes: 16})},
+		})
+		require.NoError(t, err)
+		require.NotEqual(t, first.Responses["A"].Frames[0].Fields[1].At(0), other.Responses["A"].Frames[0].Fields[1].At(0))
+
+		payload := first.Responses["A"].Frames[0].Fields[2]
+		require.Equal(t, "payload", payload.Name)
+		require.Len(t, payload.At(0), 16)
+	})
+
+	t.Run("defaults to the points of the query interval", func(t *testing.T) {
+		resp, err := s.handleWorkloadScenario(context.Background(), &backend.QueryDataRequest{
+			Queries: []backend.DataQuery{query("A", kinds.WorkloadQuery{})},
+		})
+		require.NoError(t, err)
+		require.Len(t, resp.Responses["A"].Frames, 1)
+		require.Equal(t, 60, resp.Responses["A"].Frames[0].Rows())
+	})
+
+	t.Run("fails queries at the error rate", func(t *testing.T) {
+		resp, err := s.handleWorkloadScenario(context.Background(), &backend.QueryDataRequest{
+			Queries: []backend.DataQuery{
+				query("A", kinds.WorkloadQuery{ErrorPercent: 100}),
+				query("B", kinds.WorkloadQuery{ErrorPercent: 0}),
+			},
+		})
+		require.NoError(t, err)
+		require.Error(t, resp.Responses["A"].Error)
+		require.Equal(t, backend.StatusInternal, resp.Responses["A"].Status)
+		require.NoError(t, resp.Responses["B"].Error)
+	})
+
+	t.Run("rejects workloads over the limits", func(t *testing.T) {
+		resp, err := s.handleWorkloadScenario(context.Background(), &backend.QueryDataRequest{
+			Queries: []backend.DataQuery{
+				query("A", kinds.WorkloadQuery{Series: 10_000, Points: 10_000}),
+				query("B", kinds.WorkloadQuery{Labels: []kinds.WorkloadLabel{{Name: "pod"}}}),
+			},
+		})
+		require.NoError(t, err)
+		require.Equal(t, backend.StatusBadRequest, resp.Responses["A"].Status)
+		require.Equal(t, backend.StatusBadRequest, resp.Responses["B"].Status)
+	})
+
+	t.Run("stops waiting when the context is canceled", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		_, err := s.handleWorkloadScenario(ctx, &backend.QueryDataRequest{
+			Queries: []backend.DataQuery{query("A", kinds.WorkloadQuery{LatencyMs: 60_000})},
+		})
+		require.ErrorIs(t, err, context.Canceled)
+	})
+}
+
+func TestWorkloadLatency(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	require.Equal(t, 100*time.Millisecond, workloadLatency(rng, kinds.WorkloadQuery{LatencyMs: 100}))
+	require.Equal(t, maxWorkloadLatency, workloadLatency(rng, kinds.WorkloadQuery{LatencyMs: 1e9}))
+
+	for _, dist := range []kinds.WorkloadLatencyDistribution{
+		kinds.WorkloadLatencyDistributionUniform,
+		kinds.WorkloadLatencyDistributionNormal,
+		kinds.WorkloadLatencyDistributionExponential,
+	} {
+		var total time.Duration
+		for i := 0; i < 1000; i++ {
+			l := workloadLatency(rng, kinds.WorkloadQuery{LatencyMs: 100, LatencyStddevMs: 20, LatencyDistribution: dist})
+			require.GreaterOrEqual(t, l, time.Duration(0))
+			total += l
+		}
+		require.InDelta(t, 100, float64(total.Milliseconds())/1000, 15, dist)
+	}
+}
@@ -0,0 +1,176 @@
+package graphite
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"path"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+
+	"github.com/grafana/grafana/pkg/components/simplejson"
+	"github.com/grafana/grafana/pkg/infra/log"
+)
+
+const (
+	functionsCacheTTL = time.Hour
+	// functionsErrorCacheTTL limits how often the function index is requested from Graphite versions
+	// or proxies that fail to serve it.
+	functionsErrorCacheTTL = 5 * time.Minute
+)
+
+var (
+	// Fix for a Graphite bug: https://github.com/graphite-project/graphite-web/issues/2609
+	// Graphite 1.1.7 returns `Infinity` as a default value, which is not valid JSON.
+	infinityDefaultRegex = regexp.MustCompile(`"default": ?Infinity`)
+	quotedStringRegex    = regexp.MustCompile(`'[^']*'|"[^"]*"`)
+	functionCallRegex    = regexp.MustCompile(`([A-Za-z_][A-Za-z0-9_]*)\s*\(`)
+)
+
+// functionIndex is the cached result of the Graphite `/functions` endpoint.
+type functionIndex struct {
+	raw   json.RawMessage
+	names map[string]struct{}
+	err   error
+}
+
+func (f *functionIndex) has(name string) bool {
+	_, ok := f.names[name]
+	return ok
+}
+
+func functionsCacheKey(dsInfo *datasourceInfo) string {
+	return fmt.Sprintf("%d/%s/%s", dsInfo.Id, dsInfo.UID, dsInfo.URL)
+}
+
+// getFunctions returns the function index of the data source, fetching it from Graphite if it is not cached.
+func (s *Service) getFunctions(ctx context.Context, dsInfo *datasourceInfo) (*functionIndex, error) {
+	key := functionsCacheKey(dsInfo)
+	if cached, ok := s.functionsCache.Get(key); ok {
+		index := cached.(*functionIndex)
+		return index, index.err
+	}
+
+	index, err := s.fetchFunctions(ctx, dsInfo)
+	if err != nil {
+		if ctx.Err() == nil {
+			s.functionsCache.Set(key, &functionIndex{err: err}, functionsErrorCacheTTL)
+		}
+		return nil, err
+	}
+	s.functionsCache.Set(key, index, functionsCacheTTL)
+	return index, nil
+}
+
+func (s *Service) fetchFunctions(ctx context.Context, dsInfo *datasourceInfo) (*functionIndex, error) {
+	u, err := url.Parse(dsInfo.URL)
+	if err != nil {
+		return nil, err
+	}
+	u.Path = path.Join(u.Path, "functions")
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u.String(), nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	res, err := dsInfo.HTTPClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		if err := res.Body.Close(); err != nil {
+			logger.Warn("Failed to close response body", "error", err)
+		}
+	}()
+	if res.StatusCode/100 != 2 {
+		return nil, fmt.Errorf("request failed, status: %s", res.Status)
+	}
+	body, err := io.ReadAll(res.Body)
+	if err != nil {
+		return nil, err
+	}
+	return parseFunctionIndex(body)
+}
+
+func parseFunctionIndex(body []byte) (*functionIndex, error) {
+	body = infinityDefaultRegex.ReplaceAll(body, []byte(`"default": 1e9999`))
+
+	var functions map[string]json.RawMessage
+	if err := json.Unmarshal(body, &functions); err != nil {
+		return nil, fmt.Errorf("failed to parse Graphite functions: %w", err)
+	}
+	index := &functionIndex{raw: body, names: make(map[string]struct{}, len(functions))}
+	for name := range functions {
+		index.names[name] = struct{}{}
+	}
+	return index, nil
+}
+
+// handleFunctions serves the function index of Graphite. The response has the format of the Graphite
+// `/functions` endpoint, so it can be used by the query editor as is.
+func (s *Service) handleFunctions(ctx context.Context, dsInfo *datasourceInfo, _ *http.Request) (any, int, error) {
+	if !dsInfo.supportsFunctionIndex() {
+		return nil, http.StatusNotFound, fmt.Errorf("graphite version %s does not support function index", dsInfo.Version)
+	}
+	index, err := s.getFunctions(ctx, dsInfo)
+	if err != nil {
+		return nil, http.StatusBadGateway, err
+	}
+	return index.raw, http.StatusOK, nil
+}
+
+// functionNames returns the names of the functions called in a target. Quoted strings are ignored,
+// as they can contain arbitrary text, e.g. aliases.
+func functionNames(target string) []string {
+	target = quotedStringRegex.ReplaceAllString(target, "")
+	var names []string
+	for _, match := range functionCallRegex.FindAllStringSubmatch(target, -1) {
+		names = append(names, match[1])
+	}
+	return names
+}
+
+// validateQueries returns the queries that only use functions known to Graphite, and error responses for
+// the other ones. Queries are not validated if the function index is not available.
+func (s *Service) validateQueries(ctx context.Context, logger log.Logger, dsInfo *datasourceInfo, queries []backend.DataQuery) ([]backend.DataQuery, backend.Responses) {
+	if s.functionsCache == nil || !dsInfo.supportsFunctionIndex() {
+		return queries, nil
+	}
+	index, err := s.getFunctions(ctx, dsInfo)
+	if err != nil {
+		logger.Debug("Skipping query validation, Graphite function index is not available", "error", err)
+		return queries, nil
+	}
+
+	valid := make([]backend.DataQuery, 0, len(queries))
+	invalid := backend.Responses{}
+	for _, query := range queries {
+		model, err := simplejson.NewJson(query.JSON)
+		if err != nil {
+			valid = append(valid, query)
+			continue
+		}
+		target := model.Get(TargetFullModelField).MustString(model.Get(TargetModelField).MustString())
+
+		var unknown []string
+		for _, name := range functionNames(target) {
+			if !index.has(name) {
+				unknown = append(unknown, name)
+			}
+		}
+		if len(unknown) == 0 {
+			valid = append(valid, query)
+			continue
+		}
+		sort.Strings(unknown)
+		invalid[query.RefID] = backend.ErrDataResponse(backend.StatusBadRequest, fmt.Sprintf("unknown Graphite function: %s", strings.Join(unknown, ", ")))
+	}
+	return valid, invalid
+}
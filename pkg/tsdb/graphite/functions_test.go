@@ -0,0 +1,105 @@
+package graphite
+
+import (
+	"context"
+	"net/http"
+	"sync/atomic"
+	"testing"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/infra/tracing"
+)
+
+const testFunctions = `{
+	"alias": {"name": "alias", "group": "Alias", "params": [{"name": "seriesList", "type": "seriesList"}]},
+	"sumSeries": {"name": "sumSeries", "group": "Combine", "params": []},
+	"movingAverage": {"name": "movingAverage", "group": "Calculate", "params": [{"name": "xFilesFactor", "default": Infinity}]}
+}`
+
+func TestResourceHandler_Functions(t *testing.T) {
+	var requests atomic.Int32
+	s := newTestService(t, func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		assert.Equal(t, "/functions", r.URL.Path)
+		_, _ = w.Write([]byte(testFunctions))
+	})
+
+	resp := callResource(t, s, http.MethodGet, "/functions", "")
+	require.Equal(t, http.StatusOK, resp.Status)
+	assert.Contains(t, string(resp.Body), `"default":1e9999`)
+	assert.Contains(t, string(resp.Body), `"sumSeries"`)
+
+	resp = callResource(t, s, http.MethodGet, "/functions", "")
+	require.Equal(t, http.StatusOK, resp.Status)
+	assert.Equal(t, int32(1), requests.Load(), "function index should be cached")
+}
+
+func TestResourceHandler_FunctionsUnsupportedVersion(t *testing.T) {
+	s := newTestService(t, func(w http.ResponseWriter, r *http.Request) {
+		t.Error("unexpected request to Graphite")
+	})
+	s.im = testInstanceManager{info: datasourceInfo{Version: "1.0"}}
+
+	resp := callResource(t, s, http.MethodGet, "/functions", "")
+	require.Equal(t, http.StatusNotFound, resp.Status)
+}
+
+func TestFunctionNames(t *testing.T) {
+	tests := []struct {
+		target   string
+		expected []string
+	}{
+		{target: "apps.backend.*.count", expected: nil},
+		{target: "sumSeries(apps.*.count)", expected: []string{"sumSeries"}},
+		{target: "alias(movingAverage(apps.count, '5min'), 'avg(5min)')", expected: []string{"alias", "movingAverage"}},
+		{target: `aliasByTags(seriesByTag("name=a(b)"), 'name')`, expected: []string{"aliasByTags", "seriesByTag"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.target, func(t *testing.T) {
+			assert.Equal(t, tt.expected, functionNames(tt.target))
+		})
+	}
+}
+
+func TestQueryDataValidatesFunctions(t *testing.T) {
+	var renderRequests atomic.Int32
+	s := newTestService(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/functions" {
+			_, _ = w.Write([]byte(testFunctions))
+			return
+		}
+		renderRequests.Add(1)
+		assert.NoError(t, r.ParseForm())
+		assert.Equal(t, []string{`aliasSub(sumSeries(apps.*.count),"(^.*$)","\1 A")`}, r.PostForm["target"])
+		_, _ = w.Write([]byte(`[]`))
+	})
+	s.tracer = tracing.InitializeTracerForTest()
+
+	t.Run("returns an error for queries with unknown functions", func(t *testing.T) {
+		resp, err := s.QueryData(context.Background(), &backend.QueryDataRequest{
+			Queries: []backend.DataQuery{
+				{RefID: "A", JSON: []byte(`{"target": "sumSeries(apps.*.count)"}`)},
+				{RefID: "B", JSON: []byte(`{"target": "sumSeriez(apps.*.count)"}`)},
+			},
+		})
+		require.NoError(t, err)
+		require.Error(t, resp.Responses["B"].Error)
+		assert.Equal(t, backend.StatusBadRequest, resp.Responses["B"].Status)
+		assert.Contains(t, resp.Responses["B"].Error.Error(), "sumSeriez")
+		assert.Equal(t, int32(1), renderRequests.Load())
+	})
+
+	t.Run("does not query Graphite if all queries are invalid", func(t *testing.T) {
+		resp, err := s.QueryData(context.Background(), &backend.QueryDataRequest{
+			Queries: []backend.DataQuery{
+				{RefID: "B", JSON: []byte(`{"target": "sumSeriez(apps.*.count)"}`)},
+			},
+		})
+		require.NoError(t, err)
+		require.Error(t, resp.Responses["B"].Error)
+		assert.Equal(t, int32(1), renderRequests.Load())
+	})
+}
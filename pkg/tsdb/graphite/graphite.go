@@ -17,12 +17,14 @@ import (
 	"github.com/grafana/grafana-plugin-sdk-go/backend"
 	"github.com/grafana/grafana-plugin-sdk-go/backend/datasource"
 	"github.com/grafana/grafana-plugin-sdk-go/backend/instancemgmt"
+	"github.com/grafana/grafana-plugin-sdk-go/backend/resource/httpadapter"
 	"github.com/grafana/grafana-plugin-sdk-go/data"
 	"go.opentelemetry.io/otel/attribute"
 	"go.opentelemetry.io/otel/codes"
 
 	"github.com/grafana/grafana/pkg/components/simplejson"
 	"github.com/grafana/grafana/pkg/infra/httpclient"
+	"github.com/grafana/grafana/pkg/infra/localcache"
 	"github.com/grafana/grafana/pkg/infra/log"
 	"github.com/grafana/grafana/pkg/infra/tracing"
 	"github.com/grafana/grafana/pkg/setting"
@@ -31,26 +33,73 @@ import (
 var logger = log.New("tsdb.graphite")
 
 type Service struct {
-	im     instancemgmt.InstanceManager
-	tracer tracing.Tracer
+	im              instancemgmt.InstanceManager
+	tracer          tracing.Tracer
+	resourceHandler backend.CallResourceHandler
+	functionsCache  *localcache.CacheService
 }
 
 const (
 	TargetFullModelField = "targetFull"
 	TargetModelField     = "target"
+
+	// defaultMaxDataPoints is used when the query does not specify the maximum number of data points,
+	// e.g. for queries that do not come from a panel.
+	defaultMaxDataPoints = 500
 )
 
 func ProvideService(httpClientProvider httpclient.Provider, tracer tracing.Tracer) *Service {
-	return &Service{
-		im:     datasource.NewInstanceManager(newInstanceSettings(httpClientProvider)),
-		tracer: tracer,
+	s := &Service{
+		im:             datasource.NewInstanceManager(newInstanceSettings(httpClientProvider)),
+		tracer:         tracer,
+		functionsCache: localcache.New(functionsCacheTTL, 10*time.Minute),
 	}
+	s.resourceHandler = httpadapter.New(s.newResourceMux())
+	return s
+}
+
+func (s *Service) CallResource(ctx context.Context, req *backend.CallResourceRequest, sender backend.CallResourceResponseSender) error {
+	return s.resourceHandler.CallResource(ctx, req, sender)
 }
 
 type datasourceInfo struct {
 	HTTPClient *http.Client
 	URL        string
 	Id         int64
+	UID        string
+	// Version is the configured Graphite version, e.g. `1.1`.
+	Version        string
+	MsgpackEnabled bool
+}
+
+type jsonData struct {
+	GraphiteVersion string `json:"graphiteVersion"`
+	MsgpackEnabled  bool   `json:"msgpackEnabled"`
+}
+
+// supportsFunctionIndex returns true if the configured Graphite version has the `/functions` endpoint.
+func (ds *datasourceInfo) supportsFunctionIndex() bool {
+	return versionAtLeast(ds.Version, 1, 1)
+}
+
+// versionAtLeast compares a `major.minor` version. An empty version is treated as the latest version,
+// as the configuration editor does.
+func versionAtLeast(version string, major, minor int) bool {
+	if version == "" {
+		return true
+	}
+	parts := strings.SplitN(version, ".", 3)
+	vMajor, err := strconv.Atoi(parts[0])
+	if err != nil {
+		return false
+	}
+	vMinor := 0
+	if len(parts) > 1 {
+		if vMinor, err = strconv.Atoi(parts[1]); err != nil {
+			return false
+		}
+	}
+	return vMajor > major || (vMajor == major && vMinor >= minor)
 }
 
 func newInstanceSettings(httpClientProvider httpclient.Provider) datasource.InstanceFactoryFunc {
@@ -65,10 +114,20 @@ func newInstanceSettings(httpClientProvider httpclient.Provider) datasource.Inst
 			return nil, err
 		}
 
+		var jd jsonData
+		if len(settings.JSONData) > 0 {
+			if err := json.Unmarshal(settings.JSONData, &jd); err != nil {
+				return nil, fmt.Errorf("failed to parse data source settings: %w", err)
+			}
+		}
+
 		model := datasourceInfo{
-			HTTPClient: client,
-			URL:        settings.URL,
-			Id:         settings.ID,
+			HTTPClient:     client,
+			URL:            settings.URL,
+			Id:             settings.ID,
+			UID:            settings.UID,
+			Version:        jd.GraphiteVersion,
+			MsgpackEnabled: jd.MsgpackEnabled,
 		}
 
 		return model, nil
@@ -97,8 +156,14 @@ func (s *Service) QueryData(ctx context.Context, req *backend.QueryDataRequest)
 		return nil, err
 	}
 
+	// Queries using functions unknown to Graphite fail individually instead of failing the whole request.
+	queries, invalidResponses := s.validateQueries(ctx, logger, dsInfo, req.Queries)
+	if len(queries) == 0 {
+		return &backend.QueryDataResponse{Responses: invalidResponses}, nil
+	}
+
 	// take the first query in the request list, since all query should share the same timerange
-	q := req.Queries[0]
+	q := queries[0]
 
 	/*
 		graphite doc about from and until, with sdk we are getting absolute instead of relative time
@@ -108,13 +173,13 @@ func (s *Service) QueryData(ctx context.Context, req *backend.QueryDataRequest)
 	formData := url.Values{
 		"from":          []string{from},
 		"until":         []string{until},
-		"format":        []string{"json"},
-		"maxDataPoints": []string{"500"},
+		"format":        []string{responseFormat(dsInfo)},
+		"maxDataPoints": []string{strconv.FormatInt(maxDataPoints(queries), 10)},
 		"target":        []string{},
 	}
 
 	// Convert datasource query to graphite target request
-	targetList, emptyQueries, origRefIds, err := s.processQueries(logger, req.Queries)
+	targetList, emptyQueries, origRefIds, err := s.processQueries(logger, queries)
 	if err != nil {
 		return nil, err
 	}
@@ -123,7 +188,7 @@ func (s *Service) QueryData(ctx context.Context, req *backend.QueryDataRequest)
 	if len(emptyQueries) != 0 {
 		logger.Warn("Found query models without targets", "models without targets", strings.Join(emptyQueries, "\n"))
 		// If no queries had a valid target, return an error; otherwise, attempt with the targets we have
-		if len(emptyQueries) == len(req.Queries) {
+		if len(emptyQueries) == len(queries) {
 			return &result, errors.New("no query target found for the alert rule")
 		}
 	}
@@ -178,6 +243,9 @@ func (s *Service) QueryData(ctx context.Context, req *backend.QueryDataRequest)
 	result = backend.QueryDataResponse{
 		Responses: make(backend.Responses),
 	}
+	for refID, resp := range invalidResponses {
+		result.Responses[refID] = resp
+	}
 
 	for _, f := range frames {
 		if resp, ok := result.Responses[f.Name]; ok {
@@ -261,7 +329,22 @@ func (s *Service) parseResponse(logger log.Logger, res *http.Response) ([]Target
 	return data, nil
 }
 
+// responseFormat returns the render format requested from Graphite. msgpack is considerably cheaper to
+// decode than JSON for responses with many series, but it is opt-in: graphite-web applies maxDataPoints
+// consolidation only to JSON responses.
+func responseFormat(dsInfo *datasourceInfo) string {
+	if dsInfo.MsgpackEnabled {
+		return "msgpack"
+	}
+	return "json"
+}
+
 func (s *Service) toDataFrames(logger log.Logger, response *http.Response, origRefIds map[string]string) (frames data.Frames, error error) {
+	// Graphite versions and proxies that do not support msgpack respond with JSON.
+	if strings.HasPrefix(response.Header.Get("Content-Type"), msgpackContentType) && response.StatusCode/100 == 2 {
+		return msgpackToDataFrames(response.Body, origRefIds)
+	}
+
 	responseData, err := s.parseResponse(logger, response)
 	if err != nil {
 		return nil, err
@@ -331,6 +414,21 @@ func (s *Service) createRequest(ctx context.Context, l log.Logger, dsInfo *datas
 	return req, err
 }
 
+// maxDataPoints returns the maximum number of data points requested by the queries. All targets are
+// rendered in a single request, so the largest value is used to not lose resolution for any of them.
+func maxDataPoints(queries []backend.DataQuery) int64 {
+	var result int64
+	for _, q := range queries {
+		if q.MaxDataPoints > result {
+			result = q.MaxDataPoints
+		}
+	}
+	if result <= 0 {
+		return defaultMaxDataPoints
+	}
+	return result
+}
+
 func fixIntervalFormat(target string) string {
 	rMinute := regexp.MustCompile(`'(\d+)m'`)
 	target = rMinute.ReplaceAllStringFunc(target, func(m string) string {
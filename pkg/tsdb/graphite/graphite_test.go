@@ -18,6 +18,7 @@ import (
 	"github.com/stretchr/testify/require"
 
 	"github.com/grafana/grafana/pkg/components/simplejson"
+	"github.com/grafana/grafana/pkg/infra/tracing"
 )
 
 func TestFixIntervalFormat(t *testing.T) {
@@ -298,3 +299,31 @@ func (f fakeInstanceManager) Get(_ context.Context, _ backend.PluginContext) (in
 func (f fakeInstanceManager) Do(_ context.Context, _ backend.PluginContext, _ instancemgmt.InstanceCallbackFunc) error {
 	return nil
 }
+
+func TestMaxDataPoints(t *testing.T) {
+	t.Run("uses default when not set", func(t *testing.T) {
+		require.Equal(t, int64(defaultMaxDataPoints), maxDataPoints([]backend.DataQuery{{RefID: "A"}}))
+	})
+
+	t.Run("uses largest value of the queries", func(t *testing.T) {
+		require.Equal(t, int64(1200), maxDataPoints([]backend.DataQuery{{RefID: "A", MaxDataPoints: 300}, {RefID: "B", MaxDataPoints: 1200}}))
+	})
+
+	t.Run("is sent to graphite", func(t *testing.T) {
+		s := newTestService(t, func(w http.ResponseWriter, r *http.Request) {
+			if r.URL.Path == "/functions" {
+				_, _ = w.Write([]byte(`{}`))
+				return
+			}
+			assert.NoError(t, r.ParseForm())
+			assert.Equal(t, "1200", r.PostForm.Get("maxDataPoints"))
+			_, _ = w.Write([]byte(`[]`))
+		})
+		s.tracer = tracing.InitializeTracerForTest()
+
+		_, err := s.QueryData(context.Background(), &backend.QueryDataRequest{
+			Queries: []backend.DataQuery{{RefID: "A", MaxDataPoints: 1200, JSON: []byte(`{"target": "app.grafana.*.count"}`)}},
+		})
+		require.NoError(t, err)
+	})
+}
@@ -0,0 +1,369 @@
+package graphite
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+)
+
+// msgpackContentType is the content type of `format=msgpack` responses of graphite-web and carbonapi.
+const msgpackContentType = "application/x-msgpack"
+
+var errInvalidMsgpack = errors.New("invalid msgpack response")
+
+// msgpackReader is a minimal streaming msgpack decoder supporting the subset of the format used by
+// Graphite render responses. It avoids decoding the whole response into intermediate structures,
+// so series are converted to frames while the response is read.
+type msgpackReader struct {
+	r   *bufio.Reader
+	buf [8]byte
+}
+
+func newMsgpackReader(r io.Reader) *msgpackReader {
+	return &msgpackReader{r: bufio.NewReaderSize(r, 64*1024)}
+}
+
+func (m *msgpackReader) readN(n int) ([]byte, error) {
+	if n <= len(m.buf) {
+		if _, err := io.ReadFull(m.r, m.buf[:n]); err != nil {
+			return nil, err
+		}
+		return m.buf[:n], nil
+	}
+	b := make([]byte, n)
+	if _, err := io.ReadFull(m.r, b); err != nil {
+		return nil, err
+	}
+	return b, nil
+}
+
+func (m *msgpackReader) readUint(n int) (uint64, error) {
+	b, err := m.readN(n)
+	if err != nil {
+		return 0, err
+	}
+	switch n {
+	case 1:
+		return uint64(b[0]), nil
+	case 2:
+		return uint64(binary.BigEndian.Uint16(b)), nil
+	case 4:
+		return uint64(binary.BigEndian.Uint32(b)), nil
+	default:
+		return binary.BigEndian.Uint64(b), nil
+	}
+}
+
+// readLen reads the length of a container or string with the given 8, 16 and 32 bit length codes.
+func (m *msgpackReader) readLen(code byte, codes [3]byte) (int, bool, error) {
+	for i, c := range codes {
+		if c != 0 && code == c {
+			l, err := m.readUint(1 << i)
+			return int(l), true, err
+		}
+	}
+	return 0, false, nil
+}
+
+func (m *msgpackReader) peekNil() (bool, error) {
+	b, err := m.r.Peek(1)
+	if err != nil {
+		return false, err
+	}
+	if b[0] == 0xc0 {
+		_, err = m.r.ReadByte()
+		return true, err
+	}
+	return false, nil
+}
+
+func (m *msgpackReader) readArrayLen() (int, error) {
+	code, err := m.r.ReadByte()
+	if err != nil {
+		return 0, err
+	}
+	if code&0xf0 == 0x90 {
+		return int(code & 0x0f), nil
+	}
+	if l, ok, err := m.readLen(code, [3]byte{0, 0xdc, 0xdd}); ok || err != nil {
+		return l, err
+	}
+	return 0, fmt.Errorf("%w: expected array, got 0x%x", errInvalidMsgpack, code)
+}
+
+func (m *msgpackReader) readMapLen() (int, error) {
+	code, err := m.r.ReadByte()
+	if err != nil {
+		return 0, err
+	}
+	if code&0xf0 == 0x80 {
+		return int(code & 0x0f), nil
+	}
+	if l, ok, err := m.readLen(code, [3]byte{0, 0xde, 0xdf}); ok || err != nil {
+		return l, err
+	}
+	return 0, fmt.Errorf("%w: expected map, got 0x%x", errInvalidMsgpack, code)
+}
+
+func (m *msgpackReader) readString() (string, error) {
+	code, err := m.r.ReadByte()
+	if err != nil {
+		return "", err
+	}
+	var l int
+	switch {
+	case code&0xe0 == 0xa0:
+		l = int(code & 0x1f)
+	case code == 0xc0:
+		return "", nil
+	default:
+		var ok bool
+		// str and bin families are both used for strings depending on the encoder.
+		if l, ok, err = m.readLen(code, [3]byte{0xd9, 0xda, 0xdb}); !ok && err == nil {
+			if l, ok, err = m.readLen(code, [3]byte{0xc4, 0xc5, 0xc6}); !ok && err == nil {
+				return "", fmt.Errorf("%w: expected string, got 0x%x", errInvalidMsgpack, code)
+			}
+		}
+		if err != nil {
+			return "", err
+		}
+	}
+	b, err := m.readN(l)
+	if err != nil {
+		return "", err
+	}
+	return string(b), nil
+}
+
+// readNumber reads any numeric value. It returns nil for msgpack nil.
+func (m *msgpackReader) readNumber() (*float64, error) {
+	code, err := m.r.ReadByte()
+	if err != nil {
+		return nil, err
+	}
+	var v float64
+	switch {
+	case code <= 0x7f:
+		v = float64(code)
+	case code >= 0xe0:
+		v = float64(int8(code))
+	case code == 0xc0:
+		return nil, nil
+	case code == 0xca:
+		u, err := m.readUint(4)
+		if err != nil {
+			return nil, err
+		}
+		v = float64(math.Float32frombits(uint32(u)))
+	case code == 0xcb:
+		u, err := m.readUint(8)
+		if err != nil {
+			return nil, err
+		}
+		v = math.Float64frombits(u)
+	case code >= 0xcc && code <= 0xcf:
+		u, err := m.readUint(1 << (code - 0xcc))
+		if err != nil {
+			return nil, err
+		}
+		v = float64(u)
+	case code >= 0xd0 && code <= 0xd3:
+		n := 1 << (code - 0xd0)
+		u, err := m.readUint(n)
+		if err != nil {
+			return nil, err
+		}
+		// sign extend the value
+		shift := 64 - 8*n
+		v = float64(int64(u<<shift) >> shift)
+	default:
+		return nil, fmt.Errorf("%w: expected number, got 0x%x", errInvalidMsgpack, code)
+	}
+	return &v, nil
+}
+
+// readValue reads a value of any type, e.g. tags that can be strings or numbers.
+func (m *msgpackReader) readValue() (any, error) {
+	b, err := m.r.Peek(1)
+	if err != nil {
+		return nil, err
+	}
+	code := b[0]
+	switch {
+	case code == 0xc2 || code == 0xc3:
+		_, err := m.r.ReadByte()
+		return code == 0xc3, err
+	case code&0xe0 == 0xa0, code >= 0xd9 && code <= 0xdb, code >= 0xc4 && code <= 0xc6:
+		return m.readString()
+	case code&0xf0 == 0x90, code == 0xdc, code == 0xdd:
+		l, err := m.readArrayLen()
+		if err != nil {
+			return nil, err
+		}
+		values := make([]any, 0, l)
+		for i := 0; i < l; i++ {
+			v, err := m.readValue()
+			if err != nil {
+				return nil, err
+			}
+			values = append(values, v)
+		}
+		return values, nil
+	case code&0xf0 == 0x80, code == 0xde, code == 0xdf:
+		l, err := m.readMapLen()
+		if err != nil {
+			return nil, err
+		}
+		values := make(map[string]any, l)
+		for i := 0; i < l; i++ {
+			k, err := m.readString()
+			if err != nil {
+				return nil, err
+			}
+			if values[k], err = m.readValue(); err != nil {
+				return nil, err
+			}
+		}
+		return values, nil
+	default:
+		v, err := m.readNumber()
+		if err != nil || v == nil {
+			return nil, err
+		}
+		return *v, nil
+	}
+}
+
+// msgpackSeries holds the metadata of a series decoded from a msgpack render response.
+type msgpackSeries struct {
+	name  string
+	start int64
+	step  int64
+	tags  map[string]string
+}
+
+// msgpackToDataFrames converts a Graphite msgpack render response to data frames. The response is a list of
+// series with `name`, `start`, `end` (`stop` for carbonapi), `step` and `values`, where the timestamp of
+// each value is derived from start and step.
+func msgpackToDataFrames(r io.Reader, origRefIds map[string]string) (data.Frames, error) {
+	m := newMsgpackReader(r)
+	count, err := m.readArrayLen()
+	if err != nil {
+		return nil, err
+	}
+
+	frames := make(data.Frames, 0, count)
+	for i := 0; i < count; i++ {
+		series, values, err := m.readSeries()
+		if err != nil {
+			return nil, err
+		}
+
+		ls := strings.LastIndex(series.name, " ")
+		if ls == -1 {
+			return nil, fmt.Errorf("received graphite response with invalid target format: %s", series.name)
+		}
+		target := series.name[:ls]
+		formattedRefId := series.name[ls+1:]
+		refId, ok := origRefIds[formattedRefId]
+		if !ok {
+			logger.Warn("Unable to find refId associated with provided formattedRefId", "formattedRefId", formattedRefId)
+			refId = formattedRefId
+		}
+
+		timeVector := make([]time.Time, len(values))
+		for j := range values {
+			timeVector[j] = time.Unix(series.start+int64(j)*series.step, 0).UTC()
+		}
+
+		frames = append(frames, data.NewFrame(refId,
+			data.NewField("time", nil, timeVector),
+			data.NewField("value", series.tags, values).SetConfig(&data.FieldConfig{DisplayNameFromDS: target})))
+	}
+	return frames, nil
+}
+
+func (m *msgpackReader) readSeries() (msgpackSeries, []*float64, error) {
+	series := msgpackSeries{tags: map[string]string{}}
+	var values []*float64
+
+	fields, err := m.readMapLen()
+	if err != nil {
+		return series, nil, err
+	}
+	for i := 0; i < fields; i++ {
+		key, err := m.readString()
+		if err != nil {
+			return series, nil, err
+		}
+		switch key {
+		case "name":
+			if series.name, err = m.readString(); err != nil {
+				return series, nil, err
+			}
+		case "start", "step":
+			v, err := m.readNumber()
+			if err != nil {
+				return series, nil, err
+			}
+			if v != nil && key == "start" {
+				series.start = int64(*v)
+			} else if v != nil {
+				series.step = int64(*v)
+			}
+		case "values":
+			if isNil, err := m.peekNil(); err != nil || isNil {
+				if err != nil {
+					return series, nil, err
+				}
+				continue
+			}
+			l, err := m.readArrayLen()
+			if err != nil {
+				return series, nil, err
+			}
+			values = make([]*float64, 0, l)
+			for j := 0; j < l; j++ {
+				v, err := m.readNumber()
+				if err != nil {
+					return series, nil, err
+				}
+				if v != nil && math.IsNaN(*v) {
+					v = nil
+				}
+				values = append(values, v)
+			}
+		case "tags":
+			v, err := m.readValue()
+			if err != nil {
+				return series, nil, err
+			}
+			if tags, ok := v.(map[string]any); ok {
+				for name, value := range tags {
+					switch value := value.(type) {
+					case string:
+						series.tags[name] = value
+					case float64:
+						series.tags[name] = strconv.FormatFloat(value, 'f', -1, 64)
+					}
+				}
+			}
+		default:
+			if _, err := m.readValue(); err != nil {
+				return series, nil, err
+			}
+		}
+	}
+	if series.step <= 0 && len(values) > 1 {
+		return series, nil, fmt.Errorf("%w: series %q has no step", errInvalidMsgpack, series.name)
+	}
+	return series, values, nil
+}
@@ -0,0 +1,159 @@
+package graphite
+
+import (
+	"bytes"
+	"encoding/binary"
+	"io"
+	"math"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// msgpackWriter encodes the values used in the tests, using the smallest representation like Graphite does.
+type msgpackWriter struct {
+	bytes.Buffer
+}
+
+func (w *msgpackWriter) mapHeader(n int) *msgpackWriter {
+	w.WriteByte(0x80 | byte(n))
+	return w
+}
+
+func (w *msgpackWriter) arrayHeader(n int) *msgpackWriter {
+	if n < 16 {
+		w.WriteByte(0x90 | byte(n))
+		return w
+	}
+	w.WriteByte(0xdc)
+	_ = binary.Write(w, binary.BigEndian, uint16(n))
+	return w
+}
+
+func (w *msgpackWriter) str(s string) *msgpackWriter {
+	if len(s) < 32 {
+		w.WriteByte(0xa0 | byte(len(s)))
+	} else {
+		w.WriteByte(0xd9)
+		w.WriteByte(byte(len(s)))
+	}
+	w.WriteString(s)
+	return w
+}
+
+func (w *msgpackWriter) uint32(v uint32) *msgpackWriter {
+	w.WriteByte(0xce)
+	_ = binary.Write(w, binary.BigEndian, v)
+	return w
+}
+
+func (w *msgpackWriter) int(v int8) *msgpackWriter {
+	w.WriteByte(byte(v))
+	return w
+}
+
+func (w *msgpackWriter) float(v float64) *msgpackWriter {
+	w.WriteByte(0xcb)
+	_ = binary.Write(w, binary.BigEndian, math.Float64bits(v))
+	return w
+}
+
+func (w *msgpackWriter) nil() *msgpackWriter {
+	w.WriteByte(0xc0)
+	return w
+}
+
+func TestMsgpackToDataFrames(t *testing.T) {
+	t.Run("converts series to data frames", func(t *testing.T) {
+		w := &msgpackWriter{}
+		w.arrayHeader(1).mapHeader(6)
+		w.str("name").str("apps.backend.count A")
+		w.str("start").uint32(1700000000)
+		w.str("end").uint32(1700000180)
+		w.str("step").int(60)
+		w.str("values").arrayHeader(3).float(1.5).nil().float(math.NaN())
+		w.str("tags").mapHeader(2).str("name").str("apps.backend.count").str("dc").int(1)
+
+		frames, err := msgpackToDataFrames(w, map[string]string{"A": "query-a"})
+		require.NoError(t, err)
+		require.Len(t, frames, 1)
+
+		frame := frames[0]
+		assert.Equal(t, "query-a", frame.Name)
+		require.Equal(t, 3, frame.Rows())
+		assert.Equal(t, time.Unix(1700000000, 0).UTC(), frame.Fields[0].At(0))
+		assert.Equal(t, time.Unix(1700000120, 0).UTC(), frame.Fields[0].At(2))
+
+		value := frame.Fields[1]
+		assert.Equal(t, "apps.backend.count", value.Config.DisplayNameFromDS)
+		assert.Equal(t, data.Labels{"name": "apps.backend.count", "dc": "1"}, value.Labels)
+		assert.Equal(t, 1.5, *value.At(0).(*float64))
+		assert.Nil(t, value.At(1))
+		assert.Nil(t, value.At(2))
+	})
+
+	t.Run("reads long arrays and strings", func(t *testing.T) {
+		name := strings.Repeat("a", 40) + " A"
+		w := &msgpackWriter{}
+		w.arrayHeader(1).mapHeader(4)
+		w.str("name").str(name)
+		w.str("start").uint32(0)
+		w.str("step").int(10)
+		w.str("values").arrayHeader(20)
+		for i := 0; i < 20; i++ {
+			w.int(int8(-i))
+		}
+
+		frames, err := msgpackToDataFrames(w, map[string]string{})
+		require.NoError(t, err)
+		require.Len(t, frames, 1)
+		require.Equal(t, 20, frames[0].Rows())
+		assert.Equal(t, float64(-19), *frames[0].Fields[1].At(19).(*float64))
+		assert.Equal(t, time.Unix(190, 0).UTC(), frames[0].Fields[0].At(19))
+	})
+
+	t.Run("fails on truncated response", func(t *testing.T) {
+		w := &msgpackWriter{}
+		w.arrayHeader(1).mapHeader(2).str("name")
+
+		_, err := msgpackToDataFrames(w, map[string]string{})
+		require.Error(t, err)
+	})
+
+	t.Run("fails on unexpected type", func(t *testing.T) {
+		w := &msgpackWriter{}
+		w.mapHeader(0)
+
+		_, err := msgpackToDataFrames(w, map[string]string{})
+		require.ErrorIs(t, err, errInvalidMsgpack)
+	})
+}
+
+func TestToDataFramesMsgpack(t *testing.T) {
+	w := &msgpackWriter{}
+	w.arrayHeader(1).mapHeader(4)
+	w.str("name").str("target A")
+	w.str("start").uint32(1)
+	w.str("step").int(1)
+	w.str("values").arrayHeader(2).int(50).int(100)
+
+	httpResponse := &http.Response{
+		StatusCode: 200,
+		Header:     http.Header{"Content-Type": []string{msgpackContentType}},
+		Body:       io.NopCloser(w),
+	}
+	frames, err := (&Service{}).toDataFrames(logger, httpResponse, map[string]string{"A": "A"})
+	require.NoError(t, err)
+	require.Len(t, frames, 1)
+	assert.Equal(t, 2, frames[0].Rows())
+}
+
+func TestResponseFormat(t *testing.T) {
+	assert.Equal(t, "json", responseFormat(&datasourceInfo{}))
+	assert.Equal(t, "msgpack", responseFormat(&datasourceInfo{MsgpackEnabled: true}))
+}
@@ -0,0 +1,232 @@
+package graphite
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"path"
+	"strings"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend/resource/httpadapter"
+)
+
+// resourceHandlerFunc handles a resource request for a data source instance and returns a value that is
+// serialized as JSON, the status code of the response and an error if the request failed.
+type resourceHandlerFunc func(ctx context.Context, dsInfo *datasourceInfo, req *http.Request) (any, int, error)
+
+func (s *Service) newResourceMux() *http.ServeMux {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/metrics/find", s.handleResourceReq(s.handleMetricsFind))
+	mux.HandleFunc("/tags/autoComplete/tags", s.handleResourceReq(s.handleTagsAutoComplete("tags", "tagPrefix")))
+	mux.HandleFunc("/tags/autoComplete/values", s.handleResourceReq(s.handleTagsAutoComplete("values", "tag", "valuePrefix")))
+	mux.HandleFunc("/events", s.handleResourceReq(s.handleEvents))
+	mux.HandleFunc("/functions", s.handleResourceReq(s.handleFunctions))
+	return mux
+}
+
+func (s *Service) handleResourceReq(handlerFn resourceHandlerFunc) func(rw http.ResponseWriter, req *http.Request) {
+	return func(rw http.ResponseWriter, req *http.Request) {
+		ctx := req.Context()
+		logger := logger.FromContext(ctx)
+		dsInfo, err := s.getDSInfo(ctx, httpadapter.PluginConfigFromContext(ctx))
+		if err != nil {
+			logger.Error("Failed to get data source info", "error", err)
+			writeResourceError(rw, http.StatusInternalServerError, err)
+			return
+		}
+
+		result, status, err := handlerFn(ctx, dsInfo, req)
+		if err != nil {
+			logger.Warn("Graphite resource request failed", "path", req.URL.Path, "status", status, "error", err)
+			writeResourceError(rw, status, err)
+			return
+		}
+
+		body, err := json.Marshal(result)
+		if err != nil {
+			writeResourceError(rw, http.StatusInternalServerError, err)
+			return
+		}
+		rw.Header().Set("Content-Type", "application/json")
+		rw.WriteHeader(http.StatusOK)
+		if _, err := rw.Write(body); err != nil {
+			logger.Warn("Failed to write resource response", "error", err)
+		}
+	}
+}
+
+func writeResourceError(rw http.ResponseWriter, status int, err error) {
+	body, _ := json.Marshal(map[string]string{"error": err.Error()})
+	rw.Header().Set("Content-Type", "application/json")
+	rw.WriteHeader(status)
+	_, _ = rw.Write(body)
+}
+
+// handleMetricsFind queries the Graphite `/metrics/find` endpoint. The query is read from the JSON request body
+// and the response contains the nodes at the last level of the query, e.g. `001` and `002` for `prod.servers.*`.
+func (s *Service) handleMetricsFind(ctx context.Context, dsInfo *datasourceInfo, req *http.Request) (any, int, error) {
+	if req.Method != http.MethodPost {
+		return nil, http.StatusMethodNotAllowed, fmt.Errorf("unsupported method %s", req.Method)
+	}
+	var findReq MetricsFindRequest
+	if err := json.NewDecoder(req.Body).Decode(&findReq); err != nil {
+		return nil, http.StatusBadRequest, fmt.Errorf("failed to decode request: %w", err)
+	}
+	if findReq.Query == "" {
+		return nil, http.StatusBadRequest, fmt.Errorf("query is required")
+	}
+
+	params := url.Values{}
+	setIfNotEmpty(params, "from", findReq.From)
+	setIfNotEmpty(params, "until", findReq.Until)
+	form := url.Values{"query": []string{findReq.Query}}
+
+	var items []graphiteMetricFindItem
+	status, err := s.doResourceRequest(ctx, dsInfo, http.MethodPost, "metrics/find", params, form, &items)
+	if err != nil {
+		return nil, status, err
+	}
+
+	result := make([]MetricsFindResponseItem, 0, len(items))
+	for _, item := range items {
+		result = append(result, MetricsFindResponseItem{
+			Text:       item.Text,
+			ID:         item.ID,
+			Expandable: isTruthy(item.Expandable),
+		})
+	}
+	return result, http.StatusOK, nil
+}
+
+// handleTagsAutoComplete returns a handler for the Graphite `/tags/autoComplete/<kind>` endpoints. Only the
+// parameters supported by Graphite are forwarded.
+func (s *Service) handleTagsAutoComplete(kind string, params ...string) resourceHandlerFunc {
+	allowed := append([]string{"expr", "limit", "from", "until"}, params...)
+	return func(ctx context.Context, dsInfo *datasourceInfo, req *http.Request) (any, int, error) {
+		query := req.URL.Query()
+		forwarded := url.Values{}
+		for _, name := range allowed {
+			for _, value := range query[name] {
+				forwarded.Add(name, value)
+			}
+		}
+
+		var result []string
+		status, err := s.doResourceRequest(ctx, dsInfo, http.MethodGet, "tags/autoComplete/"+kind, forwarded, nil, &result)
+		if err != nil {
+			return nil, status, err
+		}
+		if result == nil {
+			result = []string{}
+		}
+		return result, http.StatusOK, nil
+	}
+}
+
+// handleEvents queries the Graphite `/events/get_data` endpoint used for annotations.
+func (s *Service) handleEvents(ctx context.Context, dsInfo *datasourceInfo, req *http.Request) (any, int, error) {
+	query := req.URL.Query()
+	params := url.Values{}
+	setIfNotEmpty(params, "from", query.Get("from"))
+	setIfNotEmpty(params, "until", query.Get("until"))
+	setIfNotEmpty(params, "tags", query.Get("tags"))
+
+	var events []graphiteEvent
+	status, err := s.doResourceRequest(ctx, dsInfo, http.MethodGet, "events/get_data", params, nil, &events)
+	if err != nil {
+		return nil, status, err
+	}
+
+	result := make([]Event, 0, len(events))
+	for _, e := range events {
+		result = append(result, Event{
+			When: int64(e.When),
+			What: e.What,
+			Tags: parseEventTags(e.Tags),
+			Data: e.Data,
+		})
+	}
+	return result, http.StatusOK, nil
+}
+
+// doResourceRequest sends a request to Graphite and decodes the JSON response into result. It returns the
+// status code that should be returned to the client.
+func (s *Service) doResourceRequest(ctx context.Context, dsInfo *datasourceInfo, method string, endpoint string, params url.Values, form url.Values, result any) (int, error) {
+	u, err := url.Parse(dsInfo.URL)
+	if err != nil {
+		return http.StatusInternalServerError, err
+	}
+	u.Path = path.Join(u.Path, endpoint)
+	u.RawQuery = params.Encode()
+
+	var body io.Reader
+	if form != nil {
+		body = strings.NewReader(form.Encode())
+	}
+	graphiteReq, err := http.NewRequestWithContext(ctx, method, u.String(), body)
+	if err != nil {
+		return http.StatusInternalServerError, fmt.Errorf("failed to create request: %w", err)
+	}
+	if form != nil {
+		graphiteReq.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	}
+
+	res, err := dsInfo.HTTPClient.Do(graphiteReq)
+	if err != nil {
+		return http.StatusBadGateway, err
+	}
+	defer func() {
+		if err := res.Body.Close(); err != nil {
+			logger.Warn("Failed to close response body", "error", err)
+		}
+	}()
+
+	if res.StatusCode/100 != 2 {
+		return res.StatusCode, fmt.Errorf("request failed, status: %s", res.Status)
+	}
+	if err := json.NewDecoder(res.Body).Decode(result); err != nil {
+		return http.StatusBadGateway, fmt.Errorf("failed to decode Graphite response: %w", err)
+	}
+	return http.StatusOK, nil
+}
+
+func setIfNotEmpty(values url.Values, key string, value string) {
+	if value != "" {
+		values.Set(key, value)
+	}
+}
+
+// isTruthy handles both formats of boolean fields returned by Graphite: `treejson` uses 0 and 1,
+// while other formats use booleans.
+func isTruthy(value any) bool {
+	switch v := value.(type) {
+	case bool:
+		return v
+	case float64:
+		return v != 0
+	case string:
+		return v == "1" || v == "true"
+	default:
+		return false
+	}
+}
+
+// parseEventTags handles tags returned either as a list or as a single string with comma
+// or space separated values by older Graphite versions.
+func parseEventTags(tags any) []string {
+	result := []string{}
+	switch v := tags.(type) {
+	case []any:
+		for _, tag := range v {
+			if s, ok := tag.(string); ok && s != "" {
+				result = append(result, s)
+			}
+		}
+	case string:
+		result = append(result, strings.FieldsFunc(v, func(r rune) bool { return r == ',' || r == ' ' })...)
+	}
+	return result
+}
@@ -0,0 +1,142 @@
+package graphite
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/grafana/grafana-plugin-sdk-go/backend/instancemgmt"
+	"github.com/grafana/grafana-plugin-sdk-go/backend/resource/httpadapter"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/infra/localcache"
+)
+
+type testInstanceManager struct {
+	info datasourceInfo
+}
+
+func (m testInstanceManager) Get(_ context.Context, _ backend.PluginContext) (instancemgmt.Instance, error) {
+	return m.info, nil
+}
+
+func (m testInstanceManager) Do(_ context.Context, _ backend.PluginContext, _ instancemgmt.InstanceCallbackFunc) error {
+	return nil
+}
+
+func newTestService(t *testing.T, handler http.HandlerFunc) *Service {
+	t.Helper()
+	srv := httptest.NewServer(handler)
+	t.Cleanup(srv.Close)
+	s := &Service{
+		im:             testInstanceManager{info: datasourceInfo{HTTPClient: srv.Client(), URL: srv.URL}},
+		functionsCache: localcache.New(functionsCacheTTL, time.Minute),
+	}
+	s.resourceHandler = httpadapter.New(s.newResourceMux())
+	return s
+}
+
+func callResource(t *testing.T, s *Service, method string, url string, body string) *backend.CallResourceResponse {
+	t.Helper()
+	var resp *backend.CallResourceResponse
+	err := s.CallResource(context.Background(), &backend.CallResourceRequest{
+		Method: method,
+		URL:    url,
+		Body:   []byte(body),
+	}, backend.CallResourceResponseSenderFunc(func(r *backend.CallResourceResponse) error {
+		resp = r
+		return nil
+	}))
+	require.NoError(t, err)
+	require.NotNil(t, resp)
+	return resp
+}
+
+func TestResourceHandler_MetricsFind(t *testing.T) {
+	s := newTestService(t, func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/metrics/find", r.URL.Path)
+		assert.Equal(t, http.MethodPost, r.Method)
+		assert.Equal(t, "-1h", r.URL.Query().Get("from"))
+		assert.NoError(t, r.ParseForm())
+		assert.Equal(t, "prod.servers.*", r.PostForm.Get("query"))
+		_, _ = w.Write([]byte(`[{"text":"001","id":"prod.servers.001","expandable":1,"leaf":0},{"text":"002","id":"prod.servers.002","expandable":0,"leaf":1}]`))
+	})
+
+	resp := callResource(t, s, http.MethodPost, "metrics/find", `{"query":"prod.servers.*","from":"-1h"}`)
+	require.Equal(t, http.StatusOK, resp.Status)
+
+	var items []MetricsFindResponseItem
+	require.NoError(t, json.Unmarshal(resp.Body, &items))
+	require.Equal(t, []MetricsFindResponseItem{
+		{Text: "001", ID: "prod.servers.001", Expandable: true},
+		{Text: "002", ID: "prod.servers.002", Expandable: false},
+	}, items)
+
+	t.Run("requires query", func(t *testing.T) {
+		resp := callResource(t, s, http.MethodPost, "metrics/find", `{}`)
+		require.Equal(t, http.StatusBadRequest, resp.Status)
+	})
+}
+
+func TestResourceHandler_TagsAutoComplete(t *testing.T) {
+	s := newTestService(t, func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/tags/autoComplete/tags":
+			assert.Equal(t, []string{"name=cpu", "host=a"}, r.URL.Query()["expr"])
+			assert.Equal(t, "da", r.URL.Query().Get("tagPrefix"))
+			assert.Empty(t, r.URL.Query().Get("unknown"))
+			_, _ = w.Write([]byte(`["datacenter"]`))
+		case "/tags/autoComplete/values":
+			assert.Equal(t, "datacenter", r.URL.Query().Get("tag"))
+			_, _ = w.Write([]byte(`["eu-west","us-east"]`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	})
+
+	resp := callResource(t, s, http.MethodGet, "tags/autoComplete/tags?expr=name%3Dcpu&expr=host%3Da&tagPrefix=da&unknown=1", "")
+	require.Equal(t, http.StatusOK, resp.Status)
+	require.JSONEq(t, `["datacenter"]`, string(resp.Body))
+
+	resp = callResource(t, s, http.MethodGet, "tags/autoComplete/values?tag=datacenter", "")
+	require.Equal(t, http.StatusOK, resp.Status)
+	require.JSONEq(t, `["eu-west","us-east"]`, string(resp.Body))
+}
+
+func TestResourceHandler_Events(t *testing.T) {
+	s := newTestService(t, func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/events/get_data", r.URL.Path)
+		assert.Equal(t, "deploy", r.URL.Query().Get("tags"))
+		_, _ = w.Write([]byte(`[
+			{"when": 1624000000, "what": "deploy v1", "tags": ["deploy", "backend"], "data": "v1"},
+			{"when": 1624000100.5, "what": "deploy v2", "tags": "deploy,frontend", "data": ""}
+		]`))
+	})
+
+	resp := callResource(t, s, http.MethodGet, "events?from=-1d&until=now&tags=deploy", "")
+	require.Equal(t, http.StatusOK, resp.Status)
+
+	var events []Event
+	require.NoError(t, json.Unmarshal(resp.Body, &events))
+	require.Equal(t, []Event{
+		{When: 1624000000, What: "deploy v1", Tags: []string{"deploy", "backend"}, Data: "v1"},
+		{When: 1624000100, What: "deploy v2", Tags: []string{"deploy", "frontend"}, Data: ""},
+	}, events)
+}
+
+func TestResourceHandler_UpstreamError(t *testing.T) {
+	s := newTestService(t, func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusUnauthorized)
+		_, _ = io.WriteString(w, "unauthorized")
+	})
+
+	resp := callResource(t, s, http.MethodGet, "tags/autoComplete/tags", "")
+	require.Equal(t, http.StatusUnauthorized, resp.Status)
+	require.Contains(t, string(resp.Body), "request failed")
+}
@@ -13,3 +13,38 @@ type TargetResponseDTO struct {
 
 type DataTimePoint [2]null.Float
 type DataTimeSeriesPoints []DataTimePoint
+
+// MetricsFindRequest is the body of the `metrics/find` resource request.
+type MetricsFindRequest struct {
+	Query string `json:"query"`
+	From  string `json:"from,omitempty"`
+	Until string `json:"until,omitempty"`
+}
+
+type MetricsFindResponseItem struct {
+	Text       string `json:"text"`
+	ID         string `json:"id,omitempty"`
+	Expandable bool   `json:"expandable"`
+}
+
+// Event is a Graphite event returned by the `events` resource.
+type Event struct {
+	// When is the time of the event in seconds since epoch.
+	When int64    `json:"when"`
+	What string   `json:"what"`
+	Tags []string `json:"tags"`
+	Data string   `json:"data"`
+}
+
+type graphiteMetricFindItem struct {
+	Text       string `json:"text"`
+	ID         string `json:"id"`
+	Expandable any    `json:"expandable"`
+}
+
+type graphiteEvent struct {
+	When float64 `json:"when"`
+	What string  `json:"what"`
+	Tags any     `json:"tags"`
+	Data string  `json:"data"`
+}
@@ -0,0 +1,155 @@
+package flux
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/grafana/grafana/pkg/tsdb/influxdb/models"
+)
+
+type schema struct {
+	dsInfo *models.DatasourceInfo
+}
+
+// NewSchema returns the schema of a Flux data source, read with the functions of the influxdata/influxdb/schema
+// package. Retention policies map to buckets.
+func NewSchema(dsInfo *models.DatasourceInfo) models.Schema {
+	return &schema{dsInfo: dsInfo}
+}
+
+func (s *schema) RetentionPolicies(ctx context.Context) ([]string, error) {
+	return s.values(ctx, `buckets() |> rename(columns: {"name": "_value"}) |> keep(columns: ["_value"])`)
+}
+
+func (s *schema) Measurements(ctx context.Context, bucket string, filter string, limit int) ([]string, error) {
+	bucket, err := s.bucket(bucket)
+	if err != nil {
+		return nil, err
+	}
+
+	q := fmt.Sprintf("import \"influxdata/influxdb/schema\"\nschema.measurements(bucket: %s)", fluxString(bucket))
+	if filter != "" {
+		q += fmt.Sprintf(" |> filter(fn: (r) => r._value =~ /(?i)%s/)", fluxRegex(filter))
+	}
+	if limit > 0 {
+		q += fmt.Sprintf(" |> limit(n: %d)", limit)
+	}
+	return s.values(ctx, q)
+}
+
+func (s *schema) FieldKeys(ctx context.Context, bucket string, measurement string) ([]models.FieldKey, error) {
+	bucket, err := s.bucket(bucket)
+	if err != nil {
+		return nil, err
+	}
+	if measurement == "" {
+		return nil, errors.New("measurement is required")
+	}
+
+	keys, err := s.values(ctx, fmt.Sprintf("import \"influxdata/influxdb/schema\"\nschema.measurementFieldKeys(bucket: %s, measurement: %s)",
+		fluxString(bucket), fluxString(measurement)))
+	if err != nil {
+		return nil, err
+	}
+
+	// the field type is only known from the data, which isn't worth scanning here
+	fields := make([]models.FieldKey, 0, len(keys))
+	for _, key := range keys {
+		fields = append(fields, models.FieldKey{Name: key})
+	}
+	return fields, nil
+}
+
+func (s *schema) TagKeys(ctx context.Context, bucket string, measurement string) ([]string, error) {
+	bucket, err := s.bucket(bucket)
+	if err != nil {
+		return nil, err
+	}
+
+	q := fmt.Sprintf("import \"influxdata/influxdb/schema\"\nschema.tagKeys(bucket: %s)", fluxString(bucket))
+	if measurement != "" {
+		q = fmt.Sprintf("import \"influxdata/influxdb/schema\"\nschema.measurementTagKeys(bucket: %s, measurement: %s)",
+			fluxString(bucket), fluxString(measurement))
+	}
+	return s.values(ctx, q)
+}
+
+func (s *schema) TagValues(ctx context.Context, bucket string, measurement string, key string, limit int) ([]string, error) {
+	bucket, err := s.bucket(bucket)
+	if err != nil {
+		return nil, err
+	}
+	if key == "" {
+		return nil, errors.New("tag key is required")
+	}
+
+	q := fmt.Sprintf("import \"influxdata/influxdb/schema\"\nschema.tagValues(bucket: %s, tag: %s)", fluxString(bucket), fluxString(key))
+	if measurement != "" {
+		q = fmt.Sprintf("import \"influxdata/influxdb/schema\"\nschema.measurementTagValues(bucket: %s, measurement: %s, tag: %s)",
+			fluxString(bucket), fluxString(measurement), fluxString(key))
+	}
+	if limit > 0 {
+		q += fmt.Sprintf(" |> limit(n: %d)", limit)
+	}
+	return s.values(ctx, q)
+}
+
+func (s *schema) bucket(bucket string) (string, error) {
+	if bucket == "" || bucket == "default" {
+		bucket = s.dsInfo.DefaultBucket
+	}
+	if bucket == "" {
+		return "", errors.New("bucket is required, as the data source has no default bucket")
+	}
+	return bucket, nil
+}
+
+// values runs a query and returns the _value column of all records.
+func (s *schema) values(ctx context.Context, q string) ([]string, error) {
+	r, err := runnerFromDataSource(s.dsInfo)
+	if err != nil {
+		return nil, err
+	}
+	defer r.client.Close()
+
+	return readValues(ctx, r, q)
+}
+
+func readValues(ctx context.Context, r queryRunner, q string) ([]string, error) {
+	result, err := r.runQuery(ctx, q)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		_ = result.Close()
+	}()
+
+	seen := map[string]bool{}
+	values := []string{}
+	for result.Next() {
+		v := result.Record().Value()
+		if v == nil {
+			continue
+		}
+		value := fmt.Sprint(v)
+		if !seen[value] {
+			seen[value] = true
+			values = append(values, value)
+		}
+	}
+	if err := result.Err(); err != nil {
+		return nil, err
+	}
+	return values, nil
+}
+
+func fluxString(value string) string {
+	return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`, "${", `\${`).Replace(value) + `"`
+}
+
+func fluxRegex(value string) string {
+	return strings.ReplaceAll(regexp.QuoteMeta(value), "/", `\/`)
+}
@@ -0,0 +1,201 @@
+package fsql
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"google.golang.org/grpc/metadata"
+
+	"github.com/grafana/grafana/pkg/tsdb/influxdb/models"
+)
+
+const (
+	// tableSchema is the schema InfluxDB 3 stores measurements in.
+	tableSchema = "iox"
+	// tagValuesLookback bounds the time range tag values are read from, like SHOW TAG VALUES of InfluxDB 3, so
+	// that the distinct values are not computed over the whole measurement.
+	tagValuesLookback = "1 day"
+	// defaultTagValuesLimit is the maximum number of tag values returned when no limit is given.
+	defaultTagValuesLimit = 1000
+)
+
+type schema struct {
+	dsInfo *models.DatasourceInfo
+}
+
+// NewSchema returns the schema of a SQL data source, read from information_schema. Tags are the dictionary
+// encoded columns, every other column but time is a field. There are no retention policies.
+func NewSchema(dsInfo *models.DatasourceInfo) models.Schema {
+	return &schema{dsInfo: dsInfo}
+}
+
+func (s *schema) RetentionPolicies(_ context.Context) ([]string, error) {
+	return nil, models.ErrSchemaNotSupported
+}
+
+func (s *schema) Measurements(ctx context.Context, _ string, filter string, limit int) ([]string, error) {
+	q := fmt.Sprintf("SELECT table_name FROM information_schema.tables WHERE table_schema = %s", sqlString(tableSchema))
+	if filter != "" {
+		q += fmt.Sprintf(" AND strpos(lower(table_name), lower(%s)) > 0", sqlString(filter))
+	}
+	q += " ORDER BY table_name"
+	if limit > 0 {
+		q += fmt.Sprintf(" LIMIT %d", limit)
+	}
+
+	rows, err := s.query(ctx, q)
+	if err != nil {
+		return nil, err
+	}
+	return firstColumn(rows), nil
+}
+
+func (s *schema) FieldKeys(ctx context.Context, _ string, measurement string) ([]models.FieldKey, error) {
+	columns, err := s.columns(ctx, measurement)
+	if err != nil {
+		return nil, err
+	}
+
+	fields := []models.FieldKey{}
+	for _, c := range columns {
+		if c[0] != "time" && !isTagType(c[1]) {
+			fields = append(fields, models.FieldKey{Name: c[0], Type: c[1]})
+		}
+	}
+	return fields, nil
+}
+
+func (s *schema) TagKeys(ctx context.Context, _ string, measurement string) ([]string, error) {
+	columns, err := s.columns(ctx, measurement)
+	if err != nil {
+		return nil, err
+	}
+
+	keys := []string{}
+	for _, c := range columns {
+		if isTagType(c[1]) {
+			keys = append(keys, c[0])
+		}
+	}
+	return keys, nil
+}
+
+func (s *schema) TagValues(ctx context.Context, _ string, measurement string, key string, limit int) ([]string, error) {
+	if measurement == "" {
+		return nil, errors.New("measurement is required")
+	}
+	if key == "" {
+		return nil, errors.New("tag key is required")
+	}
+
+	rows, err := s.query(ctx, tagValuesQuery(measurement, key, limit))
+	if err != nil {
+		return nil, err
+	}
+	return firstColumn(rows), nil
+}
+
+// tagValuesQuery returns the statement reading the distinct values of a tag written during the lookback.
+func tagValuesQuery(measurement string, key string, limit int) string {
+	if limit <= 0 {
+		limit = defaultTagValuesLimit
+	}
+	return fmt.Sprintf("SELECT DISTINCT %[1]s FROM %[2]s WHERE time >= now() - INTERVAL %[3]s AND %[1]s IS NOT NULL ORDER BY %[1]s LIMIT %[4]d",
+		sqlIdentifier(key), sqlIdentifier(measurement), sqlString(tagValuesLookback), limit)
+}
+
+// columns returns the name and data type of the columns of a measurement.
+func (s *schema) columns(ctx context.Context, measurement string) ([][]string, error) {
+	if measurement == "" {
+		return nil, errors.New("measurement is required")
+	}
+
+	q := fmt.Sprintf("SELECT column_name, data_type FROM information_schema.columns WHERE table_schema = %s AND table_name = %s ORDER BY ordinal_position",
+		sqlString(tableSchema), sqlString(measurement))
+	rows, err := s.query(ctx, q)
+	if err != nil {
+		return nil, err
+	}
+
+	columns := make([][]string, 0, len(rows))
+	for _, row := range rows {
+		if len(row) >= 2 {
+			columns = append(columns, row[:2])
+		}
+	}
+	return columns, nil
+}
+
+// query runs a statement and returns all rows as strings.
+func (s *schema) query(ctx context.Context, q string) ([][]string, error) {
+	logger := glog.FromContext(ctx)
+	r, err := runnerFromDataSource(s.dsInfo)
+	if err != nil {
+		return nil, err
+	}
+	defer func(client *client) {
+		if err := client.Close(); err != nil {
+			logger.Warn("Failed to close fsql client", "err", err)
+		}
+	}(r.client)
+
+	if r.client.md.Len() != 0 {
+		ctx = metadata.NewOutgoingContext(ctx, r.client.md)
+	}
+
+	info, err := r.client.Execute(ctx, q)
+	if err != nil {
+		return nil, fmt.Errorf("flightsql: %w", err)
+	}
+	if len(info.Endpoint) != 1 {
+		return nil, fmt.Errorf("unsupported endpoint count in response: %d", len(info.Endpoint))
+	}
+
+	reader, err := r.client.DoGetWithHeaderExtraction(ctx, info.Endpoint[0].Ticket)
+	if err != nil {
+		return nil, fmt.Errorf("flightsql: %w", err)
+	}
+	defer reader.Release()
+
+	var rows [][]string
+	for reader.Next() {
+		record := reader.Record()
+		for i := 0; i < int(record.NumRows()); i++ {
+			row := make([]string, record.NumCols())
+			for j, col := range record.Columns() {
+				if !col.IsNull(i) {
+					row[j] = col.ValueStr(i)
+				}
+			}
+			rows = append(rows, row)
+		}
+	}
+	if err := reader.Err(); err != nil {
+		return nil, fmt.Errorf("flightsql: %w", err)
+	}
+	return rows, nil
+}
+
+func isTagType(dataType string) bool {
+	return strings.HasPrefix(dataType, "Dictionary(")
+}
+
+func firstColumn(rows [][]string) []string {
+	values := make([]string, 0, len(rows))
+	for _, row := range rows {
+		if len(row) > 0 && row[0] != "" {
+			values = append(values, row[0])
+		}
+	}
+	return values
+}
+
+func sqlString(value string) string {
+	return "'" + strings.ReplaceAll(value, "'", "''") + "'"
+}
+
+func sqlIdentifier(name string) string {
+	return `"` + strings.ReplaceAll(name, `"`, `""`) + `"`
+}
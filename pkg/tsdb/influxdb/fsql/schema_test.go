@@ -0,0 +1,27 @@
+package fsql
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestTagValuesQuery(t *testing.T) {
+	t.Run("reads the values of the lookback with a limit", func(t *testing.T) {
+		require.Equal(t,
+			`SELECT DISTINCT "host" FROM "cpu" WHERE time >= now() - INTERVAL '1 day' AND "host" IS NOT NULL ORDER BY "host" LIMIT 50`,
+			tagValuesQuery("cpu", "host", 50))
+	})
+
+	t.Run("uses the default limit", func(t *testing.T) {
+		require.Equal(t,
+			`SELECT DISTINCT "host" FROM "cpu" WHERE time >= now() - INTERVAL '1 day' AND "host" IS NOT NULL ORDER BY "host" LIMIT 1000`,
+			tagValuesQuery("cpu", "host", 0))
+	})
+
+	t.Run("quotes identifiers", func(t *testing.T) {
+		require.Equal(t,
+			`SELECT DISTINCT "my""tag" FROM "my measurement" WHERE time >= now() - INTERVAL '1 day' AND "my""tag" IS NOT NULL ORDER BY "my""tag" LIMIT 10`,
+			tagValuesQuery("my measurement", `my"tag`, 10))
+	})
+}
@@ -8,6 +8,7 @@ import (
 	"github.com/grafana/grafana-plugin-sdk-go/backend"
 	"github.com/grafana/grafana-plugin-sdk-go/backend/datasource"
 	"github.com/grafana/grafana-plugin-sdk-go/backend/instancemgmt"
+	"github.com/grafana/grafana-plugin-sdk-go/backend/resource/httpadapter"
 	"github.com/grafana/grafana-plugin-sdk-go/backend/tracing"
 
 	"github.com/grafana/grafana/pkg/services/featuremgmt"
@@ -15,6 +16,7 @@ import (
 	"github.com/grafana/grafana/pkg/tsdb/influxdb/fsql"
 
 	"github.com/grafana/grafana/pkg/infra/httpclient"
+	"github.com/grafana/grafana/pkg/infra/localcache"
 	"github.com/grafana/grafana/pkg/infra/log"
 	"github.com/grafana/grafana/pkg/tsdb/influxdb/influxql"
 	"github.com/grafana/grafana/pkg/tsdb/influxdb/models"
@@ -23,15 +25,18 @@ import (
 var logger log.Logger = log.New("tsdb.influxdb")
 
 type Service struct {
-	im       instancemgmt.InstanceManager
-	features featuremgmt.FeatureToggles
+	im              instancemgmt.InstanceManager
+	features        featuremgmt.FeatureToggles
+	resourceHandler backend.CallResourceHandler
 }
 
 func ProvideService(httpClient httpclient.Provider, features featuremgmt.FeatureToggles) *Service {
-	return &Service{
+	s := &Service{
 		im:       datasource.NewInstanceManager(newInstanceSettings(httpClient)),
 		features: features,
 	}
+	s.resourceHandler = httpadapter.New(s.newResourceMux())
+	return s
 }
 
 func newInstanceSettings(httpClientProvider httpclient.Provider) datasource.InstanceFactoryFunc {
@@ -85,6 +90,7 @@ func newInstanceSettings(httpClientProvider httpclient.Provider) datasource.Inst
 			InsecureGrpc:  jsonData.InsecureGrpc,
 			Token:         settings.DecryptedSecureJSONData["token"],
 			Timeout:       opts.Timeouts.Timeout,
+			SchemaCache:   localcache.New(schemaCacheTTL, 2*schemaCacheTTL),
 		}
 		return model, nil
 	}
@@ -51,6 +51,8 @@ func Query(ctx context.Context, tracer trace.Tracer, dsInfo *models.DatasourceIn
 				return err
 			}
 
+			warnInvalidQuery(logger, reqQuery.RefID, query)
+
 			rawQuery, err := query.Build(req)
 			if err != nil {
 				return err
@@ -90,6 +92,8 @@ func Query(ctx context.Context, tracer trace.Tracer, dsInfo *models.DatasourceIn
 				return &backend.QueryDataResponse{}, err
 			}
 
+			warnInvalidQuery(logger, reqQuery.RefID, query)
+
 			rawQuery, err := query.Build(req)
 			if err != nil {
 				return &backend.QueryDataResponse{}, err
@@ -120,6 +124,15 @@ func Query(ctx context.Context, tracer trace.Tracer, dsInfo *models.DatasourceIn
 	return response, err
 }
 
+// warnInvalidQuery logs the validation errors of a query built with the query editor. The query is still sent to
+// InfluxDB, so that queries saved before they were validated keep working. The query editor reports the errors
+// with the validate resource.
+func warnInvalidQuery(logger log.Logger, refID string, query *models.Query) {
+	if err := query.Validate(); err != nil {
+		logger.Warn("Invalid InfluxQL query", "refId", refID, "error", strings.ReplaceAll(err.Error(), "\n", "; "))
+	}
+}
+
 func createRequest(ctx context.Context, logger log.Logger, dsInfo *models.DatasourceInfo, queryStr string, retentionPolicy string) (*http.Request, error) {
 	u, err := url.Parse(dsInfo.URL)
 	if err != nil {
@@ -0,0 +1,205 @@
+package influxql
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"regexp"
+	"strings"
+
+	"github.com/grafana/grafana/pkg/tsdb/influxdb/models"
+)
+
+var measurementRegexPattern = regexp.MustCompile(`^/.*/$`)
+
+type schema struct {
+	dsInfo *models.DatasourceInfo
+}
+
+// NewSchema returns the schema of an InfluxQL data source, read with SHOW statements.
+func NewSchema(dsInfo *models.DatasourceInfo) models.Schema {
+	return &schema{dsInfo: dsInfo}
+}
+
+func (s *schema) RetentionPolicies(ctx context.Context) ([]string, error) {
+	rows, err := s.show(ctx, fmt.Sprintf("SHOW RETENTION POLICIES ON %s", quoteIdentifier(s.dsInfo.DbName)), "")
+	if err != nil {
+		return nil, err
+	}
+	return columnValues(rows, "name"), nil
+}
+
+func (s *schema) Measurements(ctx context.Context, _ string, filter string, limit int) ([]string, error) {
+	q := "SHOW MEASUREMENTS"
+	if filter != "" {
+		// same case-insensitive lookup as the query editor
+		q += fmt.Sprintf(" WITH MEASUREMENT =~ /(?i)%s/", escapeRegex(filter))
+	}
+	if limit > 0 {
+		q += fmt.Sprintf(" LIMIT %d", limit)
+	}
+
+	rows, err := s.show(ctx, q, "")
+	if err != nil {
+		return nil, err
+	}
+	return columnValues(rows, "name"), nil
+}
+
+func (s *schema) FieldKeys(ctx context.Context, policy string, measurement string) ([]models.FieldKey, error) {
+	rows, err := s.show(ctx, "SHOW FIELD KEYS"+fromClause(policy, measurement), policy)
+	if err != nil {
+		return nil, err
+	}
+
+	seen := map[string]bool{}
+	fields := []models.FieldKey{}
+	for _, row := range rows {
+		keyIdx, typeIdx := columnIndex(row, "fieldKey"), columnIndex(row, "fieldType")
+		if keyIdx < 0 {
+			continue
+		}
+		for _, v := range row.Values {
+			if keyIdx >= len(v) || v[keyIdx] == nil {
+				continue
+			}
+			field := models.FieldKey{Name: fmt.Sprint(v[keyIdx])}
+			if typeIdx >= 0 && typeIdx < len(v) && v[typeIdx] != nil {
+				field.Type = fmt.Sprint(v[typeIdx])
+			}
+			if !seen[field.Name] {
+				seen[field.Name] = true
+				fields = append(fields, field)
+			}
+		}
+	}
+	return fields, nil
+}
+
+func (s *schema) TagKeys(ctx context.Context, policy string, measurement string) ([]string, error) {
+	rows, err := s.show(ctx, "SHOW TAG KEYS"+fromClause(policy, measurement), policy)
+	if err != nil {
+		return nil, err
+	}
+	return columnValues(rows, "tagKey"), nil
+}
+
+func (s *schema) TagValues(ctx context.Context, policy string, measurement string, key string, limit int) ([]string, error) {
+	if key == "" {
+		return nil, errors.New("tag key is required")
+	}
+
+	q := "SHOW TAG VALUES" + fromClause(policy, measurement)
+	q += " WITH KEY = " + quoteIdentifier(strings.TrimSuffix(key, "::tag"))
+	if limit > 0 {
+		q += fmt.Sprintf(" LIMIT %d", limit)
+	}
+
+	rows, err := s.show(ctx, q, policy)
+	if err != nil {
+		return nil, err
+	}
+	return columnValues(rows, "value"), nil
+}
+
+func (s *schema) show(ctx context.Context, q string, policy string) ([]models.Row, error) {
+	logger := glog.FromContext(ctx)
+	req, err := createRequest(ctx, logger, s.dsInfo, q, policy)
+	if err != nil {
+		return nil, err
+	}
+
+	res, err := s.dsInfo.HTTPClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		if err := res.Body.Close(); err != nil {
+			logger.Warn("Failed to close response body", "err", err)
+		}
+	}()
+
+	body, err := io.ReadAll(res.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	var response models.Response
+	if err := json.Unmarshal(body, &response); err != nil {
+		if res.StatusCode/100 != 2 {
+			return nil, fmt.Errorf("InfluxDB returned status %d: %s", res.StatusCode, body)
+		}
+		return nil, fmt.Errorf("failed to decode InfluxDB response: %w", err)
+	}
+	if response.Error != "" {
+		return nil, errors.New(response.Error)
+	}
+	if res.StatusCode/100 != 2 {
+		return nil, fmt.Errorf("InfluxDB returned status %d", res.StatusCode)
+	}
+
+	var rows []models.Row
+	for _, result := range response.Results {
+		if result.Error != "" {
+			return nil, errors.New(result.Error)
+		}
+		rows = append(rows, result.Series...)
+	}
+	return rows, nil
+}
+
+// columnValues returns the distinct values of a column across all series, in the order they were returned.
+func columnValues(rows []models.Row, column string) []string {
+	seen := map[string]bool{}
+	values := []string{}
+	for _, row := range rows {
+		idx := columnIndex(row, column)
+		if idx < 0 {
+			continue
+		}
+
+		for _, v := range row.Values {
+			if idx >= len(v) || v[idx] == nil {
+				continue
+			}
+			value := fmt.Sprint(v[idx])
+			if !seen[value] {
+				seen[value] = true
+				values = append(values, value)
+			}
+		}
+	}
+	return values
+}
+
+func columnIndex(row models.Row, column string) int {
+	for i, c := range row.Columns {
+		if c == column {
+			return i
+		}
+	}
+	return -1
+}
+
+func fromClause(policy string, measurement string) string {
+	if measurement == "" {
+		return ""
+	}
+	if !measurementRegexPattern.MatchString(measurement) {
+		measurement = quoteIdentifier(measurement)
+		if policy != "" && policy != defaultRetentionPolicy {
+			measurement = quoteIdentifier(policy) + "." + measurement
+		}
+	}
+	return " FROM " + measurement
+}
+
+func quoteIdentifier(name string) string {
+	return `"` + strings.ReplaceAll(name, `"`, `\"`) + `"`
+}
+
+func escapeRegex(value string) string {
+	return strings.ReplaceAll(regexp.QuoteMeta(value), "/", `\/`)
+}
@@ -0,0 +1,80 @@
+package influxql
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/tsdb/influxdb/models"
+)
+
+func TestSchema(t *testing.T) {
+	var policies []string
+	responses := map[string]string{}
+	srv := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		q := req.URL.Query().Get("q")
+		policies = append(policies, req.URL.Query().Get("rp"))
+		_, _ = rw.Write([]byte(responses[q]))
+	}))
+	t.Cleanup(srv.Close)
+
+	schema := NewSchema(&models.DatasourceInfo{
+		HTTPClient: srv.Client(),
+		URL:        srv.URL,
+		DbName:     "telegraf",
+		HTTPMode:   "GET",
+	})
+	ctx := context.Background()
+
+	t.Run("retention policies", func(t *testing.T) {
+		responses[`SHOW RETENTION POLICIES ON "telegraf"`] = `{"results":[{"series":[{"columns":["name","duration","default"],"values":[["autogen","0s",true],["long","8760h0m0s",false]]}]}]}`
+		rps, err := schema.RetentionPolicies(ctx)
+		require.NoError(t, err)
+		require.Equal(t, []string{"autogen", "long"}, rps)
+	})
+
+	t.Run("measurements with filter and limit", func(t *testing.T) {
+		responses[`SHOW MEASUREMENTS WITH MEASUREMENT =~ /(?i)cpu\.total/ LIMIT 10`] = `{"results":[{"series":[{"name":"measurements","columns":["name"],"values":[["cpu.total"]]}]}]}`
+		measurements, err := schema.Measurements(ctx, "", "cpu.total", 10)
+		require.NoError(t, err)
+		require.Equal(t, []string{"cpu.total"}, measurements)
+	})
+
+	t.Run("field keys of a measurement in a retention policy", func(t *testing.T) {
+		policies = nil
+		responses[`SHOW FIELD KEYS FROM "long"."cpu"`] = `{"results":[{"series":[{"name":"cpu","columns":["fieldKey","fieldType"],"values":[["usage_idle","float"],["usage_user","float"],["cores","integer"]]}]}]}`
+		fields, err := schema.FieldKeys(ctx, "long", "cpu")
+		require.NoError(t, err)
+		require.Equal(t, []models.FieldKey{
+			{Name: "usage_idle", Type: "float"},
+			{Name: "usage_user", Type: "float"},
+			{Name: "cores", Type: "integer"},
+		}, fields)
+		require.Equal(t, []string{"long"}, policies)
+	})
+
+	t.Run("tag keys of the default retention policy", func(t *testing.T) {
+		policies = nil
+		responses[`SHOW TAG KEYS FROM "cpu"`] = `{"results":[{"series":[{"name":"cpu","columns":["tagKey"],"values":[["host"],["region"]]}]}]}`
+		keys, err := schema.TagKeys(ctx, "default", "cpu")
+		require.NoError(t, err)
+		require.Equal(t, []string{"host", "region"}, keys)
+		require.Equal(t, []string{""}, policies)
+	})
+
+	t.Run("distinct tag values across measurements", func(t *testing.T) {
+		responses[`SHOW TAG VALUES FROM /cpu.*/ WITH KEY = "host" LIMIT 100`] = `{"results":[{"series":[{"name":"cpu","columns":["key","value"],"values":[["host","a"],["host","b"]]},{"name":"cpu2","columns":["key","value"],"values":[["host","b"],["host","c"]]}]}]}`
+		values, err := schema.TagValues(ctx, "", "/cpu.*/", "host::tag", 100)
+		require.NoError(t, err)
+		require.Equal(t, []string{"a", "b", "c"}, values)
+	})
+
+	t.Run("returns errors of the result", func(t *testing.T) {
+		responses[`SHOW TAG KEYS FROM "missing"`] = `{"results":[{"error":"measurement not found"}]}`
+		_, err := schema.TagKeys(ctx, "", "missing")
+		require.EqualError(t, err, "measurement not found")
+	})
+}
@@ -3,6 +3,8 @@ package models
 import (
 	"net/http"
 	"time"
+
+	"github.com/grafana/grafana/pkg/infra/localcache"
 )
 
 type DatasourceInfo struct {
@@ -22,4 +24,7 @@ type DatasourceInfo struct {
 
 	// FlightSQL grpc connection
 	InsecureGrpc bool `json:"insecureGrpc"`
+
+	// SchemaCache holds the results of schema resource requests, it lives as long as the instance.
+	SchemaCache *localcache.CacheService `json:"-"`
 }
@@ -0,0 +1,31 @@
+package models
+
+import (
+	"context"
+	"errors"
+)
+
+// ErrSchemaNotSupported is returned for schema requests a query language has no equivalent for.
+var ErrSchemaNotSupported = errors.New("not supported by the query language of the data source")
+
+// FieldKey is a field of a measurement.
+type FieldKey struct {
+	Name string `json:"name"`
+	Type string `json:"type"`
+}
+
+// Schema discovers the measurements, fields and tags of a data source. The retention policy is the bucket of
+// Flux data sources, an empty retention policy refers to the default one.
+type Schema interface {
+	// RetentionPolicies returns the retention policies of the database, or the buckets for Flux.
+	RetentionPolicies(ctx context.Context) ([]string, error)
+	// Measurements returns up to limit measurements, filter is an optional case-insensitive substring.
+	Measurements(ctx context.Context, policy string, filter string, limit int) ([]string, error)
+	// FieldKeys returns the fields of a measurement.
+	FieldKeys(ctx context.Context, policy string, measurement string) ([]FieldKey, error)
+	// TagKeys returns the tag keys of a measurement.
+	TagKeys(ctx context.Context, policy string, measurement string) ([]string, error)
+	// TagValues returns up to limit values of a tag of a measurement. SQL data sources only return the values
+	// written during the last day.
+	TagValues(ctx context.Context, policy string, measurement string, key string, limit int) ([]string, error)
+}
@@ -0,0 +1,198 @@
+package models
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend/gtime"
+)
+
+var templateVariablePattern = regexp.MustCompile(`^\$(\w+|\{[^}]+\})$`)
+
+// requiredParams is the number of parameters a query part can't be rendered without.
+var requiredParams = map[string]int{
+	"field":                 1,
+	"tag":                   1,
+	"math":                  1,
+	"alias":                 1,
+	"time":                  1,
+	"fill":                  1,
+	"holt_winters":          2,
+	"holt_winters_with_fit": 2,
+	"moving_average":        1,
+	"percentile":            1,
+	"top":                   1,
+	"bottom":                1,
+}
+
+// selectorParts are the select parts that don't aggregate or transform a field.
+var selectorParts = map[string]bool{
+	"field": true,
+	"tag":   true,
+	"math":  true,
+	"alias": true,
+}
+
+var tagOperators = map[string]bool{
+	"":       true,
+	"=":      true,
+	"!=":     true,
+	"<>":     true,
+	"<":      true,
+	">":      true,
+	"<=":     true,
+	">=":     true,
+	"=~":     true,
+	"!~":     true,
+	"Is":     true,
+	"Is Not": true,
+}
+
+// Validate checks a query built with the query editor, so that incomplete queries are reported in the editor.
+// Queries that don't validate are still run, the errors are only logged. Raw queries are not validated.
+func (query *Query) Validate() error {
+	if query.UseRawQuery && query.RawQuery != "" {
+		return nil
+	}
+
+	var errs []error
+	if query.Measurement == "" {
+		errs = append(errs, errors.New("measurement is required"))
+	}
+	if len(query.Selects) == 0 {
+		errs = append(errs, errors.New("at least one field must be selected"))
+	}
+
+	aggregated := true
+	for i, sel := range query.Selects {
+		if sel == nil || len(*sel) == 0 {
+			errs = append(errs, fmt.Errorf("select %d is empty", i+1))
+			continue
+		}
+		if first := (*sel)[0].Type; first != "field" && first != "tag" {
+			errs = append(errs, fmt.Errorf("select %d must start with a field, got %q", i+1, first))
+		}
+
+		hasFunction := false
+		for _, part := range *sel {
+			errs = append(errs, validatePart(part)...)
+			if !selectorParts[part.Type] {
+				hasFunction = true
+			}
+		}
+		aggregated = aggregated && hasFunction
+	}
+
+	groupByTime := false
+	for _, part := range query.GroupBy {
+		if part == nil {
+			continue
+		}
+		switch part.Type {
+		case "time":
+			groupByTime = true
+		case "fill":
+			if len(part.Params) > 0 && !validFill(part.Params[0]) {
+				errs = append(errs, fmt.Errorf("invalid fill value %q", part.Params[0]))
+			}
+		}
+		errs = append(errs, validatePart(*part)...)
+	}
+	if groupByTime && len(query.Selects) > 0 && !aggregated {
+		errs = append(errs, errors.New("GROUP BY time requires an aggregate or selector function in every select"))
+	}
+
+	for _, tag := range query.Tags {
+		errs = append(errs, validateTag(tag)...)
+	}
+
+	if query.Limit != "" && !isInteger(query.Limit) {
+		errs = append(errs, fmt.Errorf("limit must be an integer, got %q", query.Limit))
+	}
+	if query.Slimit != "" && !isInteger(query.Slimit) {
+		errs = append(errs, fmt.Errorf("slimit must be an integer, got %q", query.Slimit))
+	}
+
+	return errors.Join(errs...)
+}
+
+func validatePart(part QueryPart) []error {
+	var errs []error
+	if required := requiredParams[part.Type]; len(part.Params) < required {
+		errs = append(errs, fmt.Errorf("%s requires %d parameter(s), got %d", part.Type, required, len(part.Params)))
+	}
+
+	for i, def := range part.Def.Params {
+		if i >= len(part.Params) {
+			break
+		}
+		param := part.Params[i]
+		if isTemplateVariable(param) {
+			continue
+		}
+
+		switch def.Type {
+		case "int":
+			if !isInteger(param) {
+				errs = append(errs, fmt.Errorf("%s %s must be an integer, got %q", part.Type, def.Name, param))
+			}
+		case "number":
+			if _, err := strconv.ParseFloat(param, 64); err != nil {
+				errs = append(errs, fmt.Errorf("%s %s must be a number, got %q", part.Type, def.Name, param))
+			}
+		case "interval", "time":
+			if param == "auto" || strings.Contains(param, "$") {
+				continue
+			}
+			if _, err := gtime.ParseDuration(param); err != nil {
+				errs = append(errs, fmt.Errorf("%s %s must be a duration, got %q", part.Type, def.Name, param))
+			}
+		}
+	}
+
+	return errs
+}
+
+func validateTag(tag *Tag) []error {
+	var errs []error
+	if tag.Key == "" {
+		errs = append(errs, errors.New("tag key is required"))
+	}
+	if !tagOperators[tag.Operator] {
+		errs = append(errs, fmt.Errorf("invalid operator %q for tag %q", tag.Operator, tag.Key))
+	}
+	if (tag.Operator == "=~" || tag.Operator == "!~") && !regexpOperatorPattern.MatchString(tag.Value) &&
+		!isTemplateVariable(tag.Value) {
+		errs = append(errs, fmt.Errorf("value of tag %q must be a regular expression enclosed in slashes", tag.Key))
+	}
+	switch strings.ToUpper(tag.Condition) {
+	case "", "AND", "OR":
+	default:
+		errs = append(errs, fmt.Errorf("invalid condition %q for tag %q", tag.Condition, tag.Key))
+	}
+	return errs
+}
+
+func validFill(value string) bool {
+	switch value {
+	case "null", "none", "previous", "linear":
+		return true
+	}
+	if isTemplateVariable(value) {
+		return true
+	}
+	_, err := strconv.ParseFloat(value, 64)
+	return err == nil
+}
+
+func isInteger(value string) bool {
+	_, err := strconv.ParseInt(value, 10, 64)
+	return err == nil
+}
+
+func isTemplateVariable(value string) bool {
+	return templateVariablePattern.MatchString(value)
+}
@@ -0,0 +1,127 @@
+package models
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestQueryValidate(t *testing.T) {
+	part := func(typ string, params ...string) QueryPart {
+		qp, err := NewQueryPart(typ, params)
+		require.NoError(t, err)
+		return *qp
+	}
+	groupBy := func(typ string, params ...string) *QueryPart {
+		qp := part(typ, params...)
+		return &qp
+	}
+
+	validQuery := func() *Query {
+		return &Query{
+			Measurement: "cpu",
+			Selects:     []*Select{{part("field", "value"), part("mean")}},
+			GroupBy:     []*QueryPart{groupBy("time", "$__interval"), groupBy("fill", "null")},
+			Tags:        []*Tag{{Key: "host", Operator: "=~", Value: "/^server.*$/"}},
+		}
+	}
+
+	t.Run("accepts a complete query", func(t *testing.T) {
+		require.NoError(t, validQuery().Validate())
+	})
+
+	t.Run("skips raw queries", func(t *testing.T) {
+		require.NoError(t, (&Query{UseRawQuery: true, RawQuery: "SELECT 1"}).Validate())
+	})
+
+	tests := []struct {
+		name   string
+		modify func(q *Query)
+		err    string
+	}{
+		{
+			name:   "missing measurement",
+			modify: func(q *Query) { q.Measurement = "" },
+			err:    "measurement is required",
+		},
+		{
+			name:   "no selects",
+			modify: func(q *Query) { q.Selects = nil },
+			err:    "at least one field must be selected",
+		},
+		{
+			name:   "select without field",
+			modify: func(q *Query) { q.Selects = []*Select{{part("mean")}} },
+			err:    `select 1 must start with a field, got "mean"`,
+		},
+		{
+			name:   "field without name",
+			modify: func(q *Query) { q.Selects = []*Select{{part("field"), part("mean")}} },
+			err:    "field requires 1 parameter(s), got 0",
+		},
+		{
+			name:   "non integer parameter",
+			modify: func(q *Query) { q.Selects = []*Select{{part("field", "value"), part("percentile", "ninety")}} },
+			err:    `percentile nth must be an integer, got "ninety"`,
+		},
+		{
+			name: "invalid duration",
+			modify: func(q *Query) {
+				q.Selects = []*Select{{part("field", "value"), part("mean"), part("derivative", "soon")}}
+			},
+			err: `derivative duration must be a duration, got "soon"`,
+		},
+		{
+			name:   "group by time without aggregation",
+			modify: func(q *Query) { q.Selects = []*Select{{part("field", "value")}} },
+			err:    "GROUP BY time requires an aggregate or selector function in every select",
+		},
+		{
+			name:   "invalid fill",
+			modify: func(q *Query) { q.GroupBy[1] = groupBy("fill", "nothing") },
+			err:    `invalid fill value "nothing"`,
+		},
+		{
+			name:   "regex operator without regex",
+			modify: func(q *Query) { q.Tags[0].Value = "server" },
+			err:    `value of tag "host" must be a regular expression enclosed in slashes`,
+		},
+		{
+			name:   "invalid operator",
+			modify: func(q *Query) { q.Tags[0].Operator = "~=" },
+			err:    `invalid operator "~=" for tag "host"`,
+		},
+		{
+			name:   "invalid limit",
+			modify: func(q *Query) { q.Limit = "ten" },
+			err:    `limit must be an integer, got "ten"`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			q := validQuery()
+			tt.modify(q)
+			err := q.Validate()
+			require.Error(t, err)
+			require.Contains(t, err.Error(), tt.err)
+		})
+	}
+
+	t.Run("accepts template variables in parameters", func(t *testing.T) {
+		q := validQuery()
+		q.Selects = []*Select{{part("field", "value"), part("percentile", "$percentile")}}
+		q.GroupBy = []*QueryPart{groupBy("time", "$interval"), groupBy("fill", "$fill")}
+		q.Tags = []*Tag{{Key: "host", Operator: "=~", Value: "$host"}}
+		require.NoError(t, q.Validate())
+	})
+
+	t.Run("reports all errors", func(t *testing.T) {
+		q := validQuery()
+		q.Measurement = ""
+		q.Limit = "ten"
+		err := q.Validate()
+		require.ErrorContains(t, err, "measurement is required")
+		require.ErrorContains(t, err, "limit must be an integer")
+	})
+}
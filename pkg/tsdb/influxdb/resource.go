@@ -0,0 +1,203 @@
+package influxdb
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/grafana/grafana-plugin-sdk-go/backend/resource/httpadapter"
+
+	"github.com/grafana/grafana/pkg/tsdb/influxdb/flux"
+	"github.com/grafana/grafana/pkg/tsdb/influxdb/fsql"
+	"github.com/grafana/grafana/pkg/tsdb/influxdb/influxql"
+	"github.com/grafana/grafana/pkg/tsdb/influxdb/models"
+)
+
+const (
+	schemaCacheTTL           = 5 * time.Minute
+	defaultMeasurementsLimit = 100
+	defaultTagValuesLimit    = 1000
+	maxSchemaLimit           = 10000
+)
+
+// errBadRequest marks errors caused by invalid parameters.
+var errBadRequest = errors.New("bad request")
+
+// CallResource serves the schema of the data source to the query editor, and validates queries built with it:
+//
+//	GET  /retention-policies
+//	GET  /measurements?policy=&filter=&limit=
+//	GET  /field-keys?policy=&measurement=
+//	GET  /tag-keys?policy=&measurement=
+//	GET  /tag-values?policy=&measurement=&key=&limit=
+//	POST /validate
+//
+// For Flux the policy is the bucket, it defaults to the default bucket of the data source. Schema results are
+// cached per data source, the `refresh` parameter bypasses the cache.
+func (s *Service) CallResource(ctx context.Context, req *backend.CallResourceRequest, sender backend.CallResourceResponseSender) error {
+	return s.resourceHandler.CallResource(ctx, req, sender)
+}
+
+func (s *Service) newResourceMux() *http.ServeMux {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/retention-policies", s.handleSchemaResource(func(ctx context.Context, schema models.Schema, _ *http.Request) (any, error) {
+		return schema.RetentionPolicies(ctx)
+	}))
+	mux.HandleFunc("/measurements", s.handleSchemaResource(func(ctx context.Context, schema models.Schema, req *http.Request) (any, error) {
+		query := req.URL.Query()
+		limit, err := limitParam(req, defaultMeasurementsLimit)
+		if err != nil {
+			return nil, err
+		}
+		return schema.Measurements(ctx, query.Get("policy"), query.Get("filter"), limit)
+	}))
+	mux.HandleFunc("/field-keys", s.handleSchemaResource(func(ctx context.Context, schema models.Schema, req *http.Request) (any, error) {
+		query := req.URL.Query()
+		return schema.FieldKeys(ctx, query.Get("policy"), query.Get("measurement"))
+	}))
+	mux.HandleFunc("/tag-keys", s.handleSchemaResource(func(ctx context.Context, schema models.Schema, req *http.Request) (any, error) {
+		query := req.URL.Query()
+		return schema.TagKeys(ctx, query.Get("policy"), query.Get("measurement"))
+	}))
+	mux.HandleFunc("/tag-values", s.handleSchemaResource(func(ctx context.Context, schema models.Schema, req *http.Request) (any, error) {
+		query := req.URL.Query()
+		if query.Get("key") == "" {
+			return nil, fmt.Errorf("%w: missing key parameter", errBadRequest)
+		}
+		limit, err := limitParam(req, defaultTagValuesLimit)
+		if err != nil {
+			return nil, err
+		}
+		return schema.TagValues(ctx, query.Get("policy"), query.Get("measurement"), query.Get("key"), limit)
+	}))
+	mux.HandleFunc("/validate", s.handleValidate)
+	return mux
+}
+
+func (s *Service) handleSchemaResource(fn func(ctx context.Context, schema models.Schema, req *http.Request) (any, error)) http.HandlerFunc {
+	return func(rw http.ResponseWriter, req *http.Request) {
+		if req.Method != http.MethodGet {
+			http.Error(rw, fmt.Sprintf("unsupported method %s", req.Method), http.StatusMethodNotAllowed)
+			return
+		}
+
+		ctx := req.Context()
+		logger := logger.FromContext(ctx)
+		dsInfo, err := s.getDSInfo(ctx, httpadapter.PluginConfigFromContext(ctx))
+		if err != nil {
+			http.Error(rw, err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		query := req.URL.Query()
+		refresh, _ := strconv.ParseBool(query.Get("refresh"))
+		query.Del("refresh")
+		cacheKey := req.URL.Path + "?" + query.Encode()
+
+		result, cached := any(nil), false
+		if dsInfo.SchemaCache != nil && !refresh {
+			result, cached = dsInfo.SchemaCache.Get(cacheKey)
+		}
+		if !cached {
+			schema, err := schemaFor(dsInfo)
+			if err == nil {
+				result, err = fn(ctx, schema, req)
+			}
+			if err != nil {
+				switch {
+				case errors.Is(err, errBadRequest):
+					http.Error(rw, err.Error(), http.StatusBadRequest)
+				case errors.Is(err, models.ErrSchemaNotSupported):
+					http.Error(rw, err.Error(), http.StatusNotImplemented)
+				default:
+					logger.Error("Failed to read schema", "path", req.URL.Path, "err", err)
+					http.Error(rw, err.Error(), http.StatusInternalServerError)
+				}
+				return
+			}
+			if dsInfo.SchemaCache != nil {
+				dsInfo.SchemaCache.Set(cacheKey, result, schemaCacheTTL)
+			}
+		}
+
+		writeJSON(ctx, rw, result)
+	}
+}
+
+type validateResponse struct {
+	Valid  bool     `json:"valid"`
+	Errors []string `json:"errors,omitempty"`
+}
+
+// handleValidate checks an InfluxQL query of the query editor, the request body is the query model.
+func (s *Service) handleValidate(rw http.ResponseWriter, req *http.Request) {
+	if req.Method != http.MethodPost {
+		http.Error(rw, fmt.Sprintf("unsupported method %s", req.Method), http.StatusMethodNotAllowed)
+		return
+	}
+
+	body, err := io.ReadAll(req.Body)
+	if err != nil {
+		http.Error(rw, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	query, err := models.QueryParse(backend.DataQuery{JSON: body})
+	if err != nil {
+		http.Error(rw, fmt.Sprintf("invalid query model: %s", err), http.StatusBadRequest)
+		return
+	}
+
+	res := validateResponse{Valid: true}
+	if err := query.Validate(); err != nil {
+		res.Valid = false
+		if joined, ok := err.(interface{ Unwrap() []error }); ok {
+			for _, e := range joined.Unwrap() {
+				res.Errors = append(res.Errors, e.Error())
+			}
+		} else {
+			res.Errors = []string{err.Error()}
+		}
+	}
+
+	writeJSON(req.Context(), rw, res)
+}
+
+func schemaFor(dsInfo *models.DatasourceInfo) (models.Schema, error) {
+	switch dsInfo.Version {
+	case influxVersionFlux:
+		return flux.NewSchema(dsInfo), nil
+	case influxVersionInfluxQL:
+		return influxql.NewSchema(dsInfo), nil
+	case influxVersionSQL:
+		return fsql.NewSchema(dsInfo), nil
+	default:
+		return nil, fmt.Errorf("unknown influxdb version")
+	}
+}
+
+func limitParam(req *http.Request, defaultLimit int) (int, error) {
+	value := req.URL.Query().Get("limit")
+	if value == "" {
+		return defaultLimit, nil
+	}
+
+	limit, err := strconv.Atoi(value)
+	if err != nil || limit < 1 {
+		return 0, fmt.Errorf("%w: limit must be a positive integer", errBadRequest)
+	}
+	return min(limit, maxSchemaLimit), nil
+}
+
+func writeJSON(ctx context.Context, rw http.ResponseWriter, v any) {
+	rw.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(rw).Encode(v); err != nil {
+		logger.FromContext(ctx).Warn("Failed to write resource response", "err", err)
+	}
+}
@@ -0,0 +1,95 @@
+package influxdb
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/stretchr/testify/require"
+)
+
+func callResource(t *testing.T, s *Service, method string, path string, body string) *backend.CallResourceResponse {
+	t.Helper()
+
+	var res *backend.CallResourceResponse
+	err := s.CallResource(context.Background(), &backend.CallResourceRequest{
+		Method: method,
+		Path:   path,
+		URL:    path,
+		Body:   []byte(body),
+	}, backend.CallResourceResponseSenderFunc(func(r *backend.CallResourceResponse) error {
+		res = r
+		return nil
+	}))
+	require.NoError(t, err)
+	require.NotNil(t, res)
+	return res
+}
+
+func TestCallResource(t *testing.T) {
+	t.Run("measurements", func(t *testing.T) {
+		s := GetMockService(influxVersionInfluxQL, RoundTripper{
+			Body: `{"results":[{"series":[{"name":"measurements","columns":["name"],"values":[["cpu"],["mem"]]}]}]}`,
+		})
+
+		res := callResource(t, s, http.MethodGet, "measurements?filter=c&limit=10", "")
+		require.Equal(t, http.StatusOK, res.Status)
+		require.JSONEq(t, `["cpu","mem"]`, string(res.Body))
+	})
+
+	t.Run("tag values require a key", func(t *testing.T) {
+		s := GetMockService(influxVersionInfluxQL, RoundTripper{})
+
+		res := callResource(t, s, http.MethodGet, "tag-values?measurement=cpu", "")
+		require.Equal(t, http.StatusBadRequest, res.Status)
+	})
+
+	t.Run("invalid limit", func(t *testing.T) {
+		s := GetMockService(influxVersionInfluxQL, RoundTripper{})
+
+		res := callResource(t, s, http.MethodGet, "measurements?limit=-1", "")
+		require.Equal(t, http.StatusBadRequest, res.Status)
+	})
+
+	t.Run("retention policies are not supported by SQL", func(t *testing.T) {
+		s := GetMockService(influxVersionSQL, RoundTripper{})
+
+		res := callResource(t, s, http.MethodGet, "retention-policies", "")
+		require.Equal(t, http.StatusNotImplemented, res.Status)
+	})
+
+	t.Run("validate", func(t *testing.T) {
+		s := GetMockService(influxVersionInfluxQL, RoundTripper{})
+
+		res := callResource(t, s, http.MethodPost, "validate", `{
+			"measurement": "cpu",
+			"select": [[{"type": "field", "params": ["value"]}, {"type": "mean", "params": []}]],
+			"groupBy": [{"type": "time", "params": ["$__interval"]}, {"type": "fill", "params": ["null"]}]
+		}`)
+		require.Equal(t, http.StatusOK, res.Status)
+		require.JSONEq(t, `{"valid":true}`, string(res.Body))
+
+		res = callResource(t, s, http.MethodPost, "validate", `{
+			"select": [[{"type": "field", "params": ["value"]}]],
+			"groupBy": [{"type": "time", "params": ["$__interval"]}]
+		}`)
+		require.Equal(t, http.StatusOK, res.Status)
+
+		var body validateResponse
+		require.NoError(t, json.Unmarshal(res.Body, &body))
+		require.False(t, body.Valid)
+		require.Equal(t, []string{
+			"measurement is required",
+			"GROUP BY time requires an aggregate or selector function in every select",
+		}, body.Errors)
+	})
+
+	t.Run("validate rejects unknown query parts", func(t *testing.T) {
+		s := GetMockService(influxVersionInfluxQL, RoundTripper{})
+
+		res := callResource(t, s, http.MethodPost, "validate", `{"select": [[{"type": "nope", "params": []}]]}`)
+		require.Equal(t, http.StatusBadRequest, res.Status)
+	})
+}
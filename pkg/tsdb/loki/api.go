@@ -328,6 +328,42 @@ func (api *LokiAPI) RawQuery(ctx context.Context, resourcePath string) (RawLokiR
 	return rawLokiResponse, nil
 }
 
+type labelValuesResponse struct {
+	Data []string `json:"data"`
+}
+
+// StreamShards returns the values of the stream shard label in the time range, Loki only sets it on the streams
+// it shards.
+func (api *LokiAPI) StreamShards(ctx context.Context, start time.Time, end time.Time) ([]string, error) {
+	qs := url.Values{}
+	qs.Set("start", strconv.FormatInt(start.UnixNano(), 10))
+	qs.Set("end", strconv.FormatInt(end.UnixNano(), 10))
+	req, err := makeRawRequest(ctx, api.url, "/loki/api/v1/label/"+streamShardLabel+"/values?"+qs.Encode())
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := api.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		if err := resp.Body.Close(); err != nil {
+			api.log.Warn("Failed to close response body", "error", err)
+		}
+	}()
+
+	if resp.StatusCode/100 != 2 {
+		return nil, readLokiError(resp.Body)
+	}
+
+	values := labelValuesResponse{}
+	if err := json.NewDecoder(resp.Body).Decode(&values); err != nil {
+		return nil, fmt.Errorf("error decoding label values: %w", err)
+	}
+	return values.Data, nil
+}
+
 func getSupportingQueryHeaderValue(supportingQueryType SupportingQueryType) string {
 	value := ""
 
@@ -4,8 +4,10 @@ import (
 	"encoding/json"
 	"fmt"
 	"hash/fnv"
+	"math"
 	"sort"
 	"strings"
+	"time"
 
 	"github.com/grafana/grafana-plugin-sdk-go/data"
 )
@@ -224,6 +226,166 @@ func adjustDataplaneLogsFrame(frame *data.Frame, query *lokiQuery) error {
 	return nil
 }
 
+// mergeFrames merges the frames of the subqueries of a split query. Series with the same labels are merged into
+// one frame, values at the same time are aggregated with combine, or replaced without it. Log lines are sorted
+// in the direction of the query and limited to its line limit. Other frames are kept as they are.
+func mergeFrames(query *lokiQuery, frames data.Frames, combine func(a, b float64) float64) data.Frames {
+	merged := data.Frames{}
+	series := map[string]int{}
+	var logs *data.Frame
+
+	for _, frame := range frames {
+		switch {
+		case isMetricFrame(frame):
+			key := frame.Fields[1].Labels.String()
+			if i, ok := series[key]; ok {
+				merged[i] = mergeMetricFrames(merged[i], frame, combine)
+				continue
+			}
+			series[key] = len(merged)
+			merged = append(merged, frame)
+		case isLogsFrame(frame) && (logs == nil || sameSchema(logs, frame)):
+			if logs == nil {
+				logs = frame
+				merged = append(merged, frame)
+				continue
+			}
+			logs.Fields = appendFields(logs.Fields, frame.Fields)
+			logs.Meta.Stats = mergeStats(logs.Meta.Stats, frame.Meta.Stats)
+		default:
+			merged = append(merged, frame)
+		}
+	}
+
+	if logs != nil {
+		sortLogsFrame(logs, query.Direction, query.MaxLines)
+	}
+	return merged
+}
+
+func isMetricFrame(frame *data.Frame) bool {
+	return len(frame.Fields) == 2 && frame.Fields[0].Type() == data.FieldTypeTime && frame.Fields[1].Type() == data.FieldTypeFloat64
+}
+
+func isLogsFrame(frame *data.Frame) bool {
+	return len(frame.Fields) > 2 && frame.Fields[1].Type() == data.FieldTypeTime && frame.Meta != nil
+}
+
+func sameSchema(a *data.Frame, b *data.Frame) bool {
+	if len(a.Fields) != len(b.Fields) {
+		return false
+	}
+	for i := range a.Fields {
+		if a.Fields[i].Name != b.Fields[i].Name || a.Fields[i].Type() != b.Fields[i].Type() {
+			return false
+		}
+	}
+	return true
+}
+
+func mergeMetricFrames(dest *data.Frame, src *data.Frame, combine func(a, b float64) float64) *data.Frame {
+	values := map[int64]float64{}
+	for _, frame := range []*data.Frame{dest, src} {
+		timeField, valueField := frame.Fields[0], frame.Fields[1]
+		for i := 0; i < timeField.Len(); i++ {
+			t := timeField.At(i).(time.Time).UnixNano()
+			v := valueField.At(i).(float64)
+			if prev, ok := values[t]; ok && combine != nil {
+				v = combine(prev, v)
+			}
+			values[t] = v
+		}
+	}
+
+	timestamps := make([]int64, 0, len(values))
+	for t := range values {
+		timestamps = append(timestamps, t)
+	}
+	sort.Slice(timestamps, func(i, j int) bool { return timestamps[i] < timestamps[j] })
+
+	times := make([]time.Time, len(timestamps))
+	floats := make([]float64, len(timestamps))
+	for i, t := range timestamps {
+		times[i] = time.Unix(0, t).UTC()
+		floats[i] = values[t]
+	}
+
+	timeField := data.NewField(dest.Fields[0].Name, dest.Fields[0].Labels, times)
+	timeField.Config = dest.Fields[0].Config
+	valueField := data.NewField(dest.Fields[1].Name, dest.Fields[1].Labels, floats)
+	valueField.Config = dest.Fields[1].Config
+
+	frame := data.NewFrame(dest.Name, timeField, valueField)
+	frame.RefID = dest.RefID
+	frame.Meta = dest.Meta
+	if frame.Meta != nil && src.Meta != nil {
+		frame.Meta.Stats = mergeStats(frame.Meta.Stats, src.Meta.Stats)
+	}
+	return frame
+}
+
+func appendFields(dest []*data.Field, src []*data.Field) []*data.Field {
+	for i, field := range src {
+		for j := 0; j < field.Len(); j++ {
+			dest[i].Append(field.CopyAt(j))
+		}
+	}
+	return dest
+}
+
+// sortLogsFrame sorts the lines of a logs frame by time in the direction of the query, and removes the lines
+// exceeding the limit.
+func sortLogsFrame(frame *data.Frame, direction Direction, limit int) {
+	timeField := frame.Fields[1]
+	rows := make([]int, timeField.Len())
+	for i := range rows {
+		rows[i] = i
+	}
+	sort.SliceStable(rows, func(i, j int) bool {
+		a, b := timeField.At(rows[i]).(time.Time), timeField.At(rows[j]).(time.Time)
+		if direction == DirectionForward {
+			return a.Before(b)
+		}
+		return a.After(b)
+	})
+	if limit > 0 && len(rows) > limit {
+		rows = rows[:limit]
+	}
+
+	for i, field := range frame.Fields {
+		sorted := data.NewFieldFromFieldType(field.Type(), len(rows))
+		sorted.Name = field.Name
+		sorted.Labels = field.Labels
+		sorted.Config = field.Config
+		for j, row := range rows {
+			sorted.Set(j, field.CopyAt(row))
+		}
+		frame.Fields[i] = sorted
+	}
+}
+
+// mergeStats adds up the stats of subqueries. Throughputs can not be added up, the highest one is kept.
+func mergeStats(dest []data.QueryStat, src []data.QueryStat) []data.QueryStat {
+	for _, stat := range src {
+		found := false
+		for i := range dest {
+			if dest[i].DisplayName != stat.DisplayName {
+				continue
+			}
+			found = true
+			if strings.HasSuffix(stat.DisplayName, "per second") {
+				dest[i].Value = math.Max(dest[i].Value, stat.Value)
+			} else {
+				dest[i].Value += stat.Value
+			}
+		}
+		if !found {
+			dest = append(dest, stat)
+		}
+	}
+	return dest
+}
+
 func calculateCheckSum(time string, line string, labels []byte) (string, error) {
 	input := []byte(line + "_")
 	input = append(input, labels...)
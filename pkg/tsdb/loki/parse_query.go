@@ -171,6 +171,14 @@ func parseQuery(queryContext *backend.QueryDataRequest) ([]*lokiQuery, error) {
 
 		supportingQueryType := parseSupportingQueryType(model.SupportingQueryType)
 
+		var splitInterval time.Duration
+		if model.SplitDuration != nil && *model.SplitDuration != "" {
+			splitInterval, err = gtime.ParseDuration(*model.SplitDuration)
+			if err != nil {
+				return nil, fmt.Errorf("invalid split duration %q: %w", *model.SplitDuration, err)
+			}
+		}
+
 		qs = append(qs, &lokiQuery{
 			Expr:                expr,
 			QueryType:           queryType,
@@ -182,6 +190,7 @@ func parseQuery(queryContext *backend.QueryDataRequest) ([]*lokiQuery, error) {
 			End:                 end,
 			RefID:               query.RefID,
 			SupportingQueryType: supportingQueryType,
+			SplitInterval:       splitInterval,
 		})
 	}
 
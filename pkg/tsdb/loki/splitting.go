@@ -0,0 +1,485 @@
+package loki
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"regexp"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/grafana/dskit/concurrency"
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/grafana/grafana-plugin-sdk-go/backend/gtime"
+	"github.com/grafana/grafana-plugin-sdk-go/backend/log"
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+)
+
+const (
+	// same as the default split duration of the frontend
+	defaultSplitInterval    = 24 * time.Hour
+	defaultSplitConcurrency = 5
+
+	// streamShardLabel is added by Loki to the streams it shards, see `shard_streams` in the Loki configuration
+	streamShardLabel = "__stream_shard__"
+)
+
+// splitOptions configures how range queries are split into subqueries
+type splitOptions struct {
+	// Interval is the longest time range of a subquery
+	Interval time.Duration
+	// Sharding splits subqueries further by the stream shards of Loki
+	Sharding bool
+	// Concurrency is the maximum number of subqueries of a query running at the same time
+	Concurrency int
+	// Stream allows the frontend to run split queries over a Live channel, to render the time ranges as soon as
+	// they are done. Streamed queries don't go through the query data middlewares, so they are not cached,
+	// counted by the query limits or checked against label policies.
+	Stream bool
+}
+
+type splitSettings struct {
+	QuerySplitInterval      string `json:"querySplitInterval"`
+	QuerySharding           bool   `json:"querySharding"`
+	MaxConcurrentSubqueries int    `json:"maxConcurrentSubqueries"`
+	StreamSplitQueries      bool   `json:"streamSplitQueries"`
+}
+
+func parseSplitOptions(jsonData json.RawMessage) (splitOptions, error) {
+	opts := splitOptions{
+		Interval:    defaultSplitInterval,
+		Concurrency: defaultSplitConcurrency,
+	}
+	if len(jsonData) == 0 {
+		return opts, nil
+	}
+
+	settings := splitSettings{}
+	if err := json.Unmarshal(jsonData, &settings); err != nil {
+		return opts, fmt.Errorf("error reading settings: %w", err)
+	}
+	if settings.QuerySplitInterval != "" {
+		interval, err := gtime.ParseDuration(settings.QuerySplitInterval)
+		if err != nil {
+			return opts, fmt.Errorf("invalid query split interval %q: %w", settings.QuerySplitInterval, err)
+		}
+		opts.Interval = interval
+	}
+	if settings.MaxConcurrentSubqueries > 0 {
+		opts.Concurrency = settings.MaxConcurrentSubqueries
+	}
+	opts.Sharding = settings.QuerySharding
+	opts.Stream = settings.StreamSplitQueries
+	return opts, nil
+}
+
+// splitProgress is reported after each time range of a split query
+type splitProgress struct {
+	Done  int `json:"done"`
+	Total int `json:"total"`
+}
+
+// splitCallback receives the frames of each time range of a split query, in the order they are queried. Calls
+// are never concurrent.
+type splitCallback func(frames data.Frames, progress splitProgress)
+
+type timeRange struct {
+	Start time.Time
+	End   time.Time
+}
+
+// splitQuery runs a range query as subqueries of shorter time ranges, and for log queries and metric queries
+// that can be aggregated over shards, of stream shards. Time ranges are queried from the most recent one, log
+// queries stop once the line limit is reached. The frames of the subqueries are merged into the response.
+func splitQuery(ctx context.Context, api *LokiAPI, query *lokiQuery, opts splitOptions, responseOpts ResponseOpts, plog log.Logger, callback splitCallback) (*backend.DataResponse, error) {
+	if opts.Concurrency < 1 {
+		opts.Concurrency = 1
+	}
+	interval := opts.Interval
+	if query.SplitInterval > 0 {
+		interval = query.SplitInterval
+	}
+
+	logsQuery := isLogsQuery(query.Expr)
+	var ranges []timeRange
+	if logsQuery {
+		ranges = splitLogsTimeRange(query.Start, query.End, interval)
+	} else {
+		ranges = splitMetricTimeRange(query.Start, query.End, query.Step, interval)
+	}
+
+	// a log query is equivalent to the union of its shards
+	combine, shardable := shardAggregation(query.Expr)
+	shardable = shardable || logsQuery
+
+	var shardMatchers []string
+	if opts.Sharding && shardable {
+		shards, err := api.StreamShards(ctx, query.Start, query.End)
+		if err != nil {
+			// splitting by time still works without shards
+			plog.Warn("Failed to get stream shards", "error", err)
+		}
+		shardMatchers = groupShards(shards, opts.Concurrency)
+	}
+
+	plog.Debug("Splitting query", "timeRanges", len(ranges), "shardGroups", len(shardMatchers), "interval", interval)
+
+	if len(ranges) == 1 && len(shardMatchers) == 0 {
+		res, err := runQuery(ctx, api, query, responseOpts, plog)
+		if err == nil && res.Error == nil && callback != nil {
+			callback(res.Frames, splitProgress{Done: 1, Total: 1})
+		}
+		return res, err
+	}
+
+	// the most recent time range goes first, so it renders first. With a line limit, forward log queries
+	// return the oldest lines, so they go from the oldest time range.
+	if !logsQuery || query.Direction != DirectionForward {
+		slices.Reverse(ranges)
+	}
+
+	subqueries := make([][]*lokiQuery, len(ranges))
+	for i, r := range ranges {
+		subquery := *query
+		subquery.Start = r.Start
+		subquery.End = r.End
+		if len(shardMatchers) == 0 {
+			subqueries[i] = []*lokiQuery{&subquery}
+			continue
+		}
+		for _, matcher := range shardMatchers {
+			shardQuery := subquery
+			shardQuery.Expr = addStreamSelectorMatcher(subquery.Expr, matcher)
+			subqueries[i] = append(subqueries[i], &shardQuery)
+		}
+	}
+
+	if logsQuery {
+		return runLogsSubqueries(ctx, api, query, subqueries, opts, responseOpts, plog, callback)
+	}
+	return runMetricSubqueries(ctx, api, query, subqueries, combine, opts, responseOpts, plog, callback)
+}
+
+// runLogsSubqueries runs the time ranges of a log query one after the other, as the line limit of each time range
+// depends on the lines returned by the previous ones. Shards of a time range run concurrently.
+func runLogsSubqueries(ctx context.Context, api *LokiAPI, query *lokiQuery, subqueries [][]*lokiQuery, opts splitOptions, responseOpts ResponseOpts, plog log.Logger, callback splitCallback) (*backend.DataResponse, error) {
+	result := &backend.DataResponse{}
+	lines := 0
+	for i, shards := range subqueries {
+		if query.MaxLines > 0 {
+			if lines >= query.MaxLines {
+				break
+			}
+			for _, subquery := range shards {
+				subquery.MaxLines = query.MaxLines - lines
+			}
+		}
+
+		res, err := runSubqueries(ctx, api, query, shards, opts.Concurrency, responseOpts, plog)
+		if err != nil || res.Error != nil {
+			return res, err
+		}
+		frames := mergeFrames(query, res.Frames, nil)
+		for _, frame := range frames {
+			lines += frame.Rows()
+		}
+		result.Frames = append(result.Frames, frames...)
+
+		if callback != nil {
+			callback(frames, splitProgress{Done: i + 1, Total: len(subqueries)})
+		}
+	}
+
+	result.Frames = mergeFrames(query, result.Frames, nil)
+	return result, nil
+}
+
+// runMetricSubqueries runs all subqueries of a metric query concurrently. The shards of a time range are
+// aggregated with combine, and time ranges are reported in order as soon as they and the ones before are done.
+func runMetricSubqueries(ctx context.Context, api *LokiAPI, query *lokiQuery, subqueries [][]*lokiQuery, combine func(a, b float64) float64, opts splitOptions, responseOpts ResponseOpts, plog log.Logger, callback splitCallback) (*backend.DataResponse, error) {
+	type job struct {
+		timeRange int
+		subquery  *lokiQuery
+	}
+	jobs := []job{}
+	for i, shards := range subqueries {
+		for _, subquery := range shards {
+			jobs = append(jobs, job{timeRange: i, subquery: subquery})
+		}
+	}
+
+	var (
+		mu       sync.Mutex
+		frames   = make([]data.Frames, len(subqueries))
+		pending  = make([]int, len(subqueries))
+		reported = 0
+		failed   *backend.DataResponse
+	)
+	for i, shards := range subqueries {
+		pending[i] = len(shards)
+	}
+
+	err := concurrency.ForEachJob(ctx, len(jobs), opts.Concurrency, func(ctx context.Context, idx int) error {
+		j := jobs[idx]
+		res, err := runSubquery(ctx, api, query, j.subquery, responseOpts, plog)
+		if err != nil {
+			return err
+		}
+
+		mu.Lock()
+		defer mu.Unlock()
+		if res.Error != nil {
+			if failed == nil {
+				failed = res
+			}
+			// stops the other subqueries
+			return res.Error
+		}
+
+		frames[j.timeRange] = append(frames[j.timeRange], res.Frames...)
+		pending[j.timeRange]--
+		for reported < len(subqueries) && pending[reported] == 0 {
+			frames[reported] = mergeFrames(query, frames[reported], combine)
+			if callback != nil {
+				callback(frames[reported], splitProgress{Done: reported + 1, Total: len(subqueries)})
+			}
+			reported++
+		}
+		return nil
+	})
+	if failed != nil {
+		return failed, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	all := data.Frames{}
+	for _, f := range frames {
+		all = append(all, f...)
+	}
+	return &backend.DataResponse{Frames: mergeFrames(query, all, nil)}, nil
+}
+
+// runSubqueries runs subqueries of query concurrently, the response contains the frames of all of them or the
+// first error.
+func runSubqueries(ctx context.Context, api *LokiAPI, query *lokiQuery, subqueries []*lokiQuery, limit int, responseOpts ResponseOpts, plog log.Logger) (*backend.DataResponse, error) {
+	var (
+		mu     sync.Mutex
+		result = &backend.DataResponse{}
+	)
+	err := concurrency.ForEachJob(ctx, len(subqueries), limit, func(ctx context.Context, idx int) error {
+		res, err := runSubquery(ctx, api, query, subqueries[idx], responseOpts, plog)
+		if err != nil {
+			return err
+		}
+
+		mu.Lock()
+		defer mu.Unlock()
+		if res.Error != nil {
+			result = res
+			return res.Error
+		}
+		result.Frames = append(result.Frames, res.Frames...)
+		return nil
+	})
+	if result.Error != nil {
+		return result, nil
+	}
+	return result, err
+}
+
+// splitMetricTimeRange splits the time range of a metric query into time ranges aligned to the step, the same way
+// as the Loki query frontend. The end of a time range is inclusive, so it ends a step before the next one starts.
+func splitMetricTimeRange(start, end time.Time, step, interval time.Duration) []timeRange {
+	if step <= 0 || interval < step {
+		// we cannot create time ranges shorter than the step
+		return []timeRange{{Start: start, End: end}}
+	}
+
+	aligned := interval / step * step
+	alignedStart := start.Add(-time.Duration(start.UnixNano() % int64(step)))
+
+	ranges := []timeRange{}
+	for rangeStart := alignedStart; !rangeStart.After(end); rangeStart = rangeStart.Add(aligned) {
+		rangeEnd := rangeStart.Add(aligned - step)
+		if rangeEnd.After(end) {
+			rangeEnd = end
+		}
+		ranges = append(ranges, timeRange{Start: rangeStart, End: rangeEnd})
+	}
+	return ranges
+}
+
+// splitLogsTimeRange splits the time range of a log query. Loki includes either the start or the end of a log
+// query, so time ranges can share their bounds without skipping or duplicating lines. The shorter time range is
+// the oldest one.
+func splitLogsTimeRange(start, end time.Time, interval time.Duration) []timeRange {
+	if interval <= 0 || end.Sub(start) <= interval {
+		return []timeRange{{Start: start, End: end}}
+	}
+
+	ranges := []timeRange{}
+	for rangeEnd := end; rangeEnd.After(start); rangeEnd = rangeEnd.Add(-interval) {
+		rangeStart := rangeEnd.Add(-interval)
+		if rangeStart.Before(start) {
+			rangeStart = start
+		}
+		ranges = append([]timeRange{{Start: rangeStart, End: rangeEnd}}, ranges...)
+	}
+	return ranges
+}
+
+// isLogsQuery returns true for LogQL log queries, which start with a stream selector.
+func isLogsQuery(expr string) bool {
+	return strings.HasPrefix(strings.TrimSpace(expr), "{")
+}
+
+var (
+	shardableAggregationRegex = regexp.MustCompile(`^(sum|count|max|min)\s*(?:(?:by|without)\s*\([^)]*\)\s*)?\(`)
+	groupingRegex             = regexp.MustCompile(`^(?:(?:by|without)\s*\([^)]*\))?$`)
+	// absent_over_time is left out, it returns a series for every shard without the streams
+	rangeAggregationRegex = regexp.MustCompile(`^(?:rate|rate_counter|bytes_rate|count_over_time|bytes_over_time|sum_over_time|avg_over_time|max_over_time|min_over_time|first_over_time|last_over_time|stdvar_over_time|stddev_over_time|quantile_over_time)\s*\(`)
+)
+
+// shardAggregation returns how to aggregate the results of a metric query over stream shards. Only queries
+// which are a single sum, count, max or min aggregation of a range aggregation can be aggregated, as every
+// stream belongs to exactly one shard. A nested vector aggregation, like `sum(avg by (x) (...))`, would
+// aggregate series of several shards in each shard.
+func shardAggregation(expr string) (func(a, b float64) float64, bool) {
+	expr = strings.TrimSpace(expr)
+	match := shardableAggregationRegex.FindStringSubmatchIndex(expr)
+	if match == nil {
+		return nil, false
+	}
+
+	open := match[1] - 1
+	closing := matchingParen(expr, open)
+	if closing < 0 || !groupingRegex.MatchString(strings.TrimSpace(expr[closing+1:])) {
+		return nil, false
+	}
+	if !isRangeAggregation(expr[open+1 : closing]) {
+		return nil, false
+	}
+
+	switch expr[match[2]:match[3]] {
+	case "max":
+		return math.Max, true
+	case "min":
+		return math.Min, true
+	default:
+		// the count of series is the sum of the counts of each shard
+		return func(a, b float64) float64 { return a + b }, true
+	}
+}
+
+// isRangeAggregation returns true if expr is a single range aggregation without grouping, such as
+// `rate({job="a"}[5m])`. Range aggregations only contain log pipelines, so they can't contain vector aggregations.
+func isRangeAggregation(expr string) bool {
+	expr = strings.TrimSpace(expr)
+	match := rangeAggregationRegex.FindStringIndex(expr)
+	if match == nil {
+		return false
+	}
+	return matchingParen(expr, match[1]-1) == len(expr)-1
+}
+
+// matchingParen returns the position of the parenthesis closing the one at open, ignoring string literals.
+func matchingParen(expr string, open int) int {
+	depth := 0
+	result := -1
+	scanLogQL(expr[open:], func(i int) bool {
+		switch expr[open+i] {
+		case '(':
+			depth++
+		case ')':
+			depth--
+			if depth == 0 {
+				result = open + i
+				return false
+			}
+		}
+		return true
+	})
+	return result
+}
+
+// addStreamSelectorMatcher adds a label matcher to every stream selector of a LogQL expression.
+func addStreamSelectorMatcher(expr string, matcher string) string {
+	var sb strings.Builder
+	last := 0
+	scanLogQL(expr, func(i int) bool {
+		if expr[i] != '{' {
+			return true
+		}
+		sb.WriteString(expr[last : i+1])
+		sb.WriteString(matcher)
+		if rest := strings.TrimSpace(expr[i+1:]); !strings.HasPrefix(rest, "}") {
+			sb.WriteString(", ")
+		}
+		last = i + 1
+		return true
+	})
+	sb.WriteString(expr[last:])
+	return sb.String()
+}
+
+// scanLogQL calls fn with the position of every character of expr outside string literals, until fn returns
+// false.
+func scanLogQL(expr string, fn func(i int) bool) {
+	var quote byte
+	for i := 0; i < len(expr); i++ {
+		c := expr[i]
+		if quote != 0 {
+			if c == '\\' && quote == '"' {
+				i++
+			} else if c == quote {
+				quote = 0
+			}
+			continue
+		}
+		if c == '"' || c == '`' {
+			quote = c
+			continue
+		}
+		if !fn(i) {
+			return
+		}
+	}
+}
+
+// groupShards groups the stream shards into at most n matchers, and a matcher of the streams Loki did not
+// shard. It returns no matchers without shards.
+func groupShards(shards []string, n int) []string {
+	if len(shards) == 0 {
+		return nil
+	}
+	sort.SliceStable(shards, func(i, j int) bool {
+		a, errA := strconv.Atoi(shards[i])
+		b, errB := strconv.Atoi(shards[j])
+		if errA != nil || errB != nil {
+			return shards[i] < shards[j]
+		}
+		return a < b
+	})
+
+	if n < 1 {
+		n = 1
+	}
+	size := (len(shards) + n - 1) / n
+	matchers := []string{}
+	for i := 0; i < len(shards); i += size {
+		group := shards[i:min(i+size, len(shards))]
+		quoted := make([]string, len(group))
+		for j, shard := range group {
+			quoted[j] = regexp.QuoteMeta(shard)
+		}
+		matchers = append(matchers, fmt.Sprintf("%s=~%q", streamShardLabel, strings.Join(quoted, "|")))
+	}
+	return append(matchers, fmt.Sprintf(`%s=""`, streamShardLabel))
+}
@@ -0,0 +1,293 @@
+package loki
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/infra/tracing"
+)
+
+func TestParseSplitOptions(t *testing.T) {
+	opts, err := parseSplitOptions(nil)
+	require.NoError(t, err)
+	require.Equal(t, splitOptions{Interval: defaultSplitInterval, Concurrency: defaultSplitConcurrency}, opts)
+
+	opts, err = parseSplitOptions([]byte(`{"querySplitInterval": "6h", "querySharding": true, "maxConcurrentSubqueries": 2, "streamSplitQueries": true}`))
+	require.NoError(t, err)
+	require.Equal(t, splitOptions{Interval: 6 * time.Hour, Sharding: true, Concurrency: 2, Stream: true}, opts)
+
+	_, err = parseSplitOptions([]byte(`{"querySplitInterval": "abc"}`))
+	require.Error(t, err)
+}
+
+func TestSplitTimeRange(t *testing.T) {
+	ms := func(v int64) time.Time { return time.UnixMilli(v) }
+
+	t.Run("metric queries are aligned to the step", func(t *testing.T) {
+		require.Equal(t, []timeRange{
+			{Start: ms(0), End: ms(20)},
+			{Start: ms(30), End: ms(50)},
+			{Start: ms(60), End: ms(70)},
+		}, splitMetricTimeRange(ms(5), ms(70), 10*time.Millisecond, 35*time.Millisecond))
+	})
+
+	t.Run("metric queries are not split below the step", func(t *testing.T) {
+		require.Equal(t, []timeRange{{Start: ms(5), End: ms(70)}}, splitMetricTimeRange(ms(5), ms(70), 10*time.Millisecond, 5*time.Millisecond))
+	})
+
+	t.Run("log queries share bounds with the shortest range first", func(t *testing.T) {
+		require.Equal(t, []timeRange{
+			{Start: ms(100), End: ms(110)},
+			{Start: ms(110), End: ms(130)},
+			{Start: ms(130), End: ms(150)},
+		}, splitLogsTimeRange(ms(100), ms(150), 20*time.Millisecond))
+		require.Equal(t, []timeRange{{Start: ms(100), End: ms(150)}}, splitLogsTimeRange(ms(100), ms(150), time.Second))
+	})
+}
+
+func TestShardAggregation(t *testing.T) {
+	tests := []struct {
+		expr      string
+		shardable bool
+		result    float64
+	}{
+		{`sum(rate({job="a"}[5m]))`, true, 3},
+		{`sum by (level) (count_over_time({job="a"} |= "(" [5m]))`, true, 3},
+		{`count(rate({job="a"}[5m])) by (level)`, true, 3},
+		{`max without (pod) (rate({job="a"}[5m]))`, true, 2},
+		{`min(rate({job="a"}[5m]))`, true, 1},
+		{`sum(rate({job="a"}[5m])) / sum(rate({job="b"}[5m]))`, false, 0},
+		{`avg(rate({job="a"}[5m]))`, false, 0},
+		{`rate({job="a"}[5m])`, false, 0},
+		{`topk(5, sum by (pod) (rate({job="a"}[5m])))`, false, 0},
+		{`sum(avg by (x) (rate({job="a"}[5m])))`, false, 0},
+		{`count(sum by (pod) (rate({job="a"}[5m])))`, false, 0},
+		{`max(topk(5, rate({job="a"}[5m])))`, false, 0},
+		{`count(count_over_time({job="a"}[5m]))`, true, 3},
+		{`max(quantile_over_time(0.99, {job="a"} | unwrap latency [5m]))`, true, 2},
+		{`sum(max_over_time({job="a"} | unwrap latency [5m]) by (pod))`, false, 0},
+		{`sum(rate({job="a"}[5m]) * 2)`, false, 0},
+		{`sum(absent_over_time({job="a"}[5m]))`, false, 0},
+	}
+	for _, test := range tests {
+		t.Run(test.expr, func(t *testing.T) {
+			combine, ok := shardAggregation(test.expr)
+			require.Equal(t, test.shardable, ok)
+			if ok {
+				require.Equal(t, test.result, combine(1, 2))
+			}
+		})
+	}
+}
+
+func TestAddStreamSelectorMatcher(t *testing.T) {
+	matcher := `__stream_shard__=~"0|1"`
+	require.Equal(t, `{__stream_shard__=~"0|1", job="a"} |= "{x}"`, addStreamSelectorMatcher(`{job="a"} |= "{x}"`, matcher))
+	require.Equal(t, "sum(rate({__stream_shard__=~\"0|1\", job=\"a\"} | line_format `{{.msg}}` [5m])) / sum(rate({__stream_shard__=~\"0|1\", job=\"b\\\"}\"}[5m]))",
+		addStreamSelectorMatcher("sum(rate({job=\"a\"} | line_format `{{.msg}}` [5m])) / sum(rate({job=\"b\\\"}\"}[5m]))", matcher))
+	require.Equal(t, `{__stream_shard__=~"0|1"}`, addStreamSelectorMatcher(`{}`, matcher))
+}
+
+func TestGroupShards(t *testing.T) {
+	require.Nil(t, groupShards(nil, 2))
+	require.Equal(t, []string{
+		`__stream_shard__=~"0|1|2"`,
+		`__stream_shard__=~"3|10"`,
+		`__stream_shard__=""`,
+	}, groupShards([]string{"10", "2", "0", "3", "1"}, 2))
+}
+
+var shardValueRegex = regexp.MustCompile(`__stream_shard__=~?"([^"]*)"`)
+
+// fakeLoki returns a sample at the start of every metric query with the shard of the query as value, and a log
+// line at the end of every log query.
+type fakeLoki struct {
+	mu      sync.Mutex
+	shards  []string
+	queries []string
+}
+
+func (f *fakeLoki) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.URL.Path == "/loki/api/v1/label/__stream_shard__/values" {
+		_, _ = fmt.Fprintf(w, `{"status": "success", "data": [%s]}`, quoteAll(f.shards))
+		return
+	}
+
+	query := r.URL.Query()
+	expr := query.Get("query")
+	f.mu.Lock()
+	f.queries = append(f.queries, expr+" "+query.Get("start")+"-"+query.Get("end")+" "+query.Get("limit"))
+	f.mu.Unlock()
+
+	if expr == "error" {
+		w.WriteHeader(http.StatusBadRequest)
+		_, _ = w.Write([]byte(`{"message": "parse error"}`))
+		return
+	}
+
+	start, _ := strconv.ParseInt(query.Get("start"), 10, 64)
+	end, _ := strconv.ParseInt(query.Get("end"), 10, 64)
+	if isLogsQuery(expr) {
+		_, _ = fmt.Fprintf(w, `{"status": "success", "data": {"resultType": "streams", "result": [
+			{"stream": {"job": "a"}, "values": [["%d", "line %d"]]}
+		]}}`, end, end/int64(time.Millisecond))
+		return
+	}
+
+	value := 1
+	if m := shardValueRegex.FindStringSubmatch(expr); m != nil {
+		value = len(m[1])
+	}
+	_, _ = fmt.Fprintf(w, `{"status": "success", "data": {"resultType": "matrix", "result": [
+		{"metric": {"level": "info"}, "values": [[%f, "%d"]]}
+	]}}`, float64(start)/float64(time.Second), value)
+}
+
+func quoteAll(values []string) string {
+	s := ""
+	for i, v := range values {
+		if i > 0 {
+			s += ","
+		}
+		s += strconv.Quote(v)
+	}
+	return s
+}
+
+func TestSplitQuery(t *testing.T) {
+	start := time.Unix(0, 0).UTC()
+	end := start.Add(3 * time.Hour)
+
+	newAPI := func(t *testing.T, loki *fakeLoki) *LokiAPI {
+		srv := httptest.NewServer(loki)
+		t.Cleanup(srv.Close)
+		return newLokiAPI(srv.Client(), srv.URL, backend.NewLoggerWith("logger", "test"), tracing.InitializeTracerForTest(), false)
+	}
+	logger := backend.NewLoggerWith("logger", "test")
+
+	t.Run("metric queries are merged into one series", func(t *testing.T) {
+		loki := &fakeLoki{}
+		query := &lokiQuery{Expr: `rate({job="a"}[1m])`, QueryType: QueryTypeRange, Step: time.Hour, Start: start, End: end, RefID: "A"}
+
+		var progress []splitProgress
+		res, err := splitQuery(context.Background(), newAPI(t, loki), query, splitOptions{Interval: time.Hour, Concurrency: 2}, ResponseOpts{}, logger, func(frames data.Frames, p splitProgress) {
+			progress = append(progress, p)
+		})
+		require.NoError(t, err)
+		require.NoError(t, res.Error)
+		require.Len(t, loki.queries, 4)
+		require.Equal(t, []splitProgress{{1, 4}, {2, 4}, {3, 4}, {4, 4}}, progress)
+
+		require.Len(t, res.Frames, 1)
+		frame := res.Frames[0]
+		require.Equal(t, 4, frame.Rows())
+		require.Equal(t, start, frame.Fields[0].At(0))
+		require.Equal(t, end, frame.Fields[0].At(3))
+		require.Equal(t, "Expr: "+query.Expr+"\nStep: 1h0m0s", frame.Meta.ExecutedQueryString)
+	})
+
+	t.Run("shards of metric queries are aggregated", func(t *testing.T) {
+		loki := &fakeLoki{shards: []string{"0", "1", "2"}}
+		query := &lokiQuery{Expr: `sum by (level) (rate({job="a"}[1m]))`, QueryType: QueryTypeRange, Step: time.Hour, Start: start, End: start.Add(time.Hour), RefID: "A"}
+
+		res, err := splitQuery(context.Background(), newAPI(t, loki), query, splitOptions{Interval: 24 * time.Hour, Sharding: true, Concurrency: 2}, ResponseOpts{}, logger, nil)
+		require.NoError(t, err)
+		require.NoError(t, res.Error)
+		// two groups of shards and the streams without shard
+		require.Len(t, loki.queries, 3)
+
+		require.Len(t, res.Frames, 1)
+		require.Equal(t, 1, res.Frames[0].Rows())
+		// the fake returns the length of the shard matcher as value
+		require.Equal(t, float64(len("0|1")+len("2")+len("")), res.Frames[0].Fields[1].At(0))
+	})
+
+	t.Run("queries which can not be aggregated are not sharded", func(t *testing.T) {
+		loki := &fakeLoki{shards: []string{"0", "1"}}
+		query := &lokiQuery{Expr: `avg(rate({job="a"}[1m]))`, QueryType: QueryTypeRange, Step: time.Hour, Start: start, End: start.Add(time.Hour), RefID: "A"}
+
+		_, err := splitQuery(context.Background(), newAPI(t, loki), query, splitOptions{Interval: 24 * time.Hour, Sharding: true, Concurrency: 2}, ResponseOpts{}, logger, nil)
+		require.NoError(t, err)
+		require.Len(t, loki.queries, 1)
+	})
+
+	t.Run("log queries stop at the line limit", func(t *testing.T) {
+		loki := &fakeLoki{}
+		query := &lokiQuery{Expr: `{job="a"}`, QueryType: QueryTypeRange, Direction: DirectionBackward, MaxLines: 2, Step: time.Minute, Start: start, End: end, RefID: "A"}
+
+		res, err := splitQuery(context.Background(), newAPI(t, loki), query, splitOptions{Interval: time.Hour, Concurrency: 2}, ResponseOpts{}, logger, nil)
+		require.NoError(t, err)
+		require.NoError(t, res.Error)
+		// the most recent time ranges are queried first, with the remaining limit
+		require.Equal(t, []string{
+			fmt.Sprintf(`{job="a"} %d-%d 2`, start.Add(2*time.Hour).UnixNano(), end.UnixNano()),
+			fmt.Sprintf(`{job="a"} %d-%d 1`, start.Add(time.Hour).UnixNano(), start.Add(2*time.Hour).UnixNano()),
+		}, loki.queries)
+
+		require.Len(t, res.Frames, 1)
+		frame := res.Frames[0]
+		require.Equal(t, 2, frame.Rows())
+		require.Equal(t, end, frame.Fields[1].At(0).(time.Time).UTC())
+		require.Equal(t, start.Add(2*time.Hour), frame.Fields[1].At(1).(time.Time).UTC())
+	})
+
+	t.Run("errors of subqueries are returned", func(t *testing.T) {
+		loki := &fakeLoki{}
+		query := &lokiQuery{Expr: "error", QueryType: QueryTypeRange, Step: time.Hour, Start: start, End: end, RefID: "A"}
+
+		res, err := splitQuery(context.Background(), newAPI(t, loki), query, splitOptions{Interval: time.Hour, Concurrency: 1}, ResponseOpts{}, logger, nil)
+		require.NoError(t, err)
+		require.ErrorContains(t, res.Error, "parse error")
+	})
+}
+
+func TestMergeFrames(t *testing.T) {
+	ts := func(s int64) time.Time { return time.Unix(s, 0).UTC() }
+	series := func(labels data.Labels, times []time.Time, values []float64, bytes float64) *data.Frame {
+		frame := data.NewFrame("", data.NewField("Time", nil, times), data.NewField("Value", labels, values))
+		frame.Meta = &data.FrameMeta{Stats: []data.QueryStat{{FieldConfig: data.FieldConfig{DisplayName: "Summary: total bytes processed"}, Value: bytes}}}
+		return frame
+	}
+
+	t.Run("merges series with the same labels", func(t *testing.T) {
+		frames := mergeFrames(&lokiQuery{}, data.Frames{
+			series(data.Labels{"level": "info"}, []time.Time{ts(3), ts(4)}, []float64{3, 4}, 10),
+			series(data.Labels{"level": "error"}, []time.Time{ts(1)}, []float64{1}, 1),
+			series(data.Labels{"level": "info"}, []time.Time{ts(1), ts(2)}, []float64{1, 2}, 20),
+		}, nil)
+		require.Len(t, frames, 2)
+		assert.Equal(t, []any{ts(1), ts(2), ts(3), ts(4)}, fieldValues(frames[0].Fields[0]))
+		assert.Equal(t, []any{1.0, 2.0, 3.0, 4.0}, fieldValues(frames[0].Fields[1]))
+		assert.Equal(t, data.Labels{"level": "info"}, frames[0].Fields[1].Labels)
+		assert.Equal(t, 30.0, frames[0].Meta.Stats[0].Value)
+	})
+
+	t.Run("combines values at the same time", func(t *testing.T) {
+		frames := mergeFrames(&lokiQuery{}, data.Frames{
+			series(nil, []time.Time{ts(1), ts(2)}, []float64{1, 2}, 0),
+			series(nil, []time.Time{ts(2), ts(3)}, []float64{5, 3}, 0),
+		}, func(a, b float64) float64 { return a + b })
+		require.Len(t, frames, 1)
+		assert.Equal(t, []any{1.0, 7.0, 3.0}, fieldValues(frames[0].Fields[1]))
+	})
+}
+
+func fieldValues(field *data.Field) []any {
+	values := make([]any, field.Len())
+	for i := range values {
+		values[i] = field.At(i)
+	}
+	return values
+}
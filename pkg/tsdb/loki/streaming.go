@@ -7,12 +7,15 @@ import (
 	"net/url"
 	"os"
 	"os/signal"
+	"strconv"
 	"strings"
 	"time"
 
 	"github.com/gorilla/websocket"
 	"github.com/grafana/grafana-plugin-sdk-go/backend"
 	"github.com/grafana/grafana-plugin-sdk-go/data"
+
+	"github.com/grafana/grafana/pkg/services/featuremgmt"
 )
 
 func (s *Service) SubscribeStream(ctx context.Context, req *backend.SubscribeStreamRequest) (*backend.SubscribeStreamResponse, error) {
@@ -23,6 +26,18 @@ func (s *Service) SubscribeStream(ctx context.Context, req *backend.SubscribeStr
 		}, err
 	}
 
+	// Expect split/${uuid}, unique for each subscription, so panels running the same query don't share results
+	if strings.HasPrefix(req.Path, "split/") {
+		if !s.features.IsEnabled(ctx, featuremgmt.FlagLokiBackendQuerySplitting) || !dsInfo.split.Stream {
+			return &backend.SubscribeStreamResponse{
+				Status: backend.SubscribeStreamStatusNotFound,
+			}, fmt.Errorf("streaming split queries is not enabled")
+		}
+		return &backend.SubscribeStreamResponse{
+			Status: backend.SubscribeStreamStatusOK,
+		}, nil
+	}
+
 	// Expect tail/${key}
 	if !strings.HasPrefix(req.Path, "tail/") {
 		return &backend.SubscribeStreamResponse{
@@ -65,6 +80,10 @@ func (s *Service) RunStream(ctx context.Context, req *backend.RunStreamRequest,
 		return err
 	}
 
+	if strings.HasPrefix(req.Path, "split/") {
+		return s.runSplitStream(ctx, req, sender, dsInfo)
+	}
+
 	query, err := parseQueryModel(req.Data)
 	if err != nil {
 		return err
@@ -167,6 +186,108 @@ func (s *Service) RunStream(ctx context.Context, req *backend.RunStreamRequest,
 	}
 }
 
+// splitStreamRequest is the time range of a query sent over a split channel, next to the query model
+type splitStreamRequest struct {
+	TimeRange struct {
+		From string `json:"from"`
+		To   string `json:"to"`
+	} `json:"timeRange"`
+	IntervalMs int64 `json:"intervalMs"`
+}
+
+// runSplitStream runs a split query and sends the frames of each time range as soon as it is done, so the first
+// results render before the whole time range is queried. It's an opt-in alternative to the split queries of
+// QueryData, see splitOptions.Stream. Each time range is followed by a frame without fields,
+// with the progress of the query in its custom metadata.
+func (s *Service) runSplitStream(ctx context.Context, req *backend.RunStreamRequest, sender *backend.StreamSender, dsInfo *datasourceInfo) error {
+	logger := s.logger.FromContext(ctx)
+
+	query, err := parseSplitStreamQuery(req.Data)
+	if err != nil {
+		return err
+	}
+
+	responseOpts := ResponseOpts{
+		metricDataplane: s.features.IsEnabled(ctx, featuremgmt.FlagLokiMetricDataplane),
+		logsDataplane:   s.features.IsEnabled(ctx, featuremgmt.FlagLokiLogsDataplane),
+	}
+	api := newLokiAPI(dsInfo.HTTPClient, dsInfo.URL, logger, s.tracer, s.features.IsEnabled(ctx, featuremgmt.FlagLokiStructuredMetadata))
+
+	ctx, span := s.tracer.Start(ctx, "datasource.loki.runSplitStream")
+	defer span.End()
+
+	var sendErr error
+	res, err := splitQuery(ctx, api, query, dsInfo.split, responseOpts, logger, func(frames data.Frames, progress splitProgress) {
+		if sendErr != nil {
+			return
+		}
+		for _, frame := range frames {
+			if sendErr = sender.SendFrame(frame, data.IncludeAll); sendErr != nil {
+				return
+			}
+		}
+		sendErr = sender.SendFrame(splitProgressFrame(query.RefID, progress, nil), data.IncludeAll)
+	})
+	if err == nil && res != nil {
+		err = res.Error
+	}
+	if err != nil {
+		logger.Error("Error running split query", "error", err)
+		return sender.SendFrame(splitProgressFrame(query.RefID, splitProgress{}, err), data.IncludeAll)
+	}
+	return sendErr
+}
+
+func parseSplitStreamQuery(raw json.RawMessage) (*lokiQuery, error) {
+	streamReq := splitStreamRequest{}
+	if err := json.Unmarshal(raw, &streamReq); err != nil {
+		return nil, err
+	}
+	from, err := strconv.ParseInt(streamReq.TimeRange.From, 10, 64)
+	if err != nil {
+		return nil, fmt.Errorf("invalid time range start %q", streamReq.TimeRange.From)
+	}
+	to, err := strconv.ParseInt(streamReq.TimeRange.To, 10, 64)
+	if err != nil {
+		return nil, fmt.Errorf("invalid time range end %q", streamReq.TimeRange.To)
+	}
+
+	model, err := parseQueryModel(raw)
+	if err != nil {
+		return nil, err
+	}
+	if model.Expr == nil || *model.Expr == "" {
+		return nil, fmt.Errorf("missing expr in channel")
+	}
+
+	queries, err := parseQuery(&backend.QueryDataRequest{
+		Queries: []backend.DataQuery{{
+			RefID:     depointerizer(model.RefId),
+			JSON:      raw,
+			Interval:  time.Duration(streamReq.IntervalMs) * time.Millisecond,
+			TimeRange: backend.TimeRange{From: time.UnixMilli(from), To: time.UnixMilli(to)},
+		}},
+	})
+	if err != nil {
+		return nil, err
+	}
+	if queries[0].QueryType != QueryTypeRange {
+		return nil, fmt.Errorf("only range queries can be split")
+	}
+	return queries[0], nil
+}
+
+func splitProgressFrame(refID string, progress splitProgress, err error) *data.Frame {
+	frame := data.NewFrame("")
+	frame.RefID = refID
+	custom := map[string]any{"progress": progress}
+	if err != nil {
+		custom["error"] = err.Error()
+	}
+	frame.Meta = &data.FrameMeta{Custom: custom}
+	return frame
+}
+
 func (s *Service) PublishStream(_ context.Context, _ *backend.PublishStreamRequest) (*backend.PublishStreamResponse, error) {
 	return &backend.PublishStreamResponse{
 		Status: backend.PublishStreamStatusPermissionDenied,
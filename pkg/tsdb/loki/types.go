@@ -39,4 +39,6 @@ type lokiQuery struct {
 	End                 time.Time
 	RefID               string
 	SupportingQueryType SupportingQueryType
+	// SplitInterval overrides the time range of subqueries when queries are split
+	SplitInterval time.Duration
 }
@@ -0,0 +1,33 @@
+package mssql
+
+// mssqlSchemaQueries lists the schema of a Microsoft SQL Server database. An empty schema refers to the
+// default schema of the user.
+type mssqlSchemaQueries struct{}
+
+func (mssqlSchemaQueries) Schemas() string {
+	return `SELECT name FROM sys.schemas
+		WHERE name NOT IN ('sys', 'INFORMATION_SCHEMA', 'guest') AND name NOT LIKE 'db[_]%'
+		ORDER BY name`
+}
+
+func (mssqlSchemaQueries) Tables(schema string) (string, []any) {
+	return `SELECT TABLE_NAME, TABLE_TYPE FROM INFORMATION_SCHEMA.TABLES
+		WHERE TABLE_SCHEMA = COALESCE(NULLIF(@p1, ''), SCHEMA_NAME())
+		ORDER BY TABLE_NAME`, []any{schema}
+}
+
+func (mssqlSchemaQueries) Columns(schema, table string) (string, []any) {
+	return `SELECT COLUMN_NAME, DATA_TYPE, IS_NULLABLE FROM INFORMATION_SCHEMA.COLUMNS
+		WHERE TABLE_SCHEMA = COALESCE(NULLIF(@p1, ''), SCHEMA_NAME()) AND TABLE_NAME = @p2
+		ORDER BY ORDINAL_POSITION`, []any{schema, table}
+}
+
+func (mssqlSchemaQueries) Indexes(schema, table string) (string, []any) {
+	return `SELECT i.name, c.name, i.is_unique, i.is_primary_key
+		FROM sys.indexes i
+		JOIN sys.index_columns ic ON ic.object_id = i.object_id AND ic.index_id = i.index_id
+		JOIN sys.columns c ON c.object_id = ic.object_id AND c.column_id = ic.column_id
+		WHERE i.object_id = OBJECT_ID(QUOTENAME(COALESCE(NULLIF(@p1, ''), SCHEMA_NAME())) + '.' + QUOTENAME(@p2))
+			AND ic.is_included_column = 0
+		ORDER BY i.name, ic.key_ordinal`, []any{schema, table}
+}
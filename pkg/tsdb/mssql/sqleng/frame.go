@@ -0,0 +1,110 @@
+package sqleng
+
+import (
+	"database/sql"
+	"fmt"
+	"reflect"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/grafana/grafana-plugin-sdk-go/data/sqlutil"
+)
+
+// frameFromRows builds a frame row by row while reading the result set. Like sqlutil.FrameFromRows it stops
+// at the row limit, and it also stops once the estimated size of the read values exceeds bytesLimit, if set,
+// so a single query can not exhaust the memory of the server.
+func frameFromRows(rows *sql.Rows, rowLimit int64, bytesLimit int64, converters ...sqlutil.Converter) (*data.Frame, error) {
+	types, err := rows.ColumnTypes()
+	if err != nil {
+		return nil, err
+	}
+	names, err := rows.Columns()
+	if err != nil {
+		return nil, err
+	}
+	scanRow, err := sqlutil.MakeScanRow(types, names, converters...)
+	if err != nil {
+		return nil, err
+	}
+
+	frame := sqlutil.NewFrame(names, scanRow.Converters...)
+
+	var count, size int64
+	for {
+		for rows.Next() {
+			if count == rowLimit {
+				frame.AppendNotices(data.Notice{
+					Severity: data.NoticeSeverityWarning,
+					Text:     fmt.Sprintf("Results have been limited to %v because the SQL row limit was reached", rowLimit),
+				})
+				return frame, nil
+			}
+
+			row := scanRow.NewScannableRow()
+			if err := rows.Scan(row...); err != nil {
+				return nil, err
+			}
+			if err := sqlutil.Append(frame, row, scanRow.Converters...); err != nil {
+				return nil, err
+			}
+			count++
+
+			size += rowSize(row)
+			if bytesLimit > 0 && size > bytesLimit {
+				frame.AppendNotices(data.Notice{
+					Severity: data.NoticeSeverityWarning,
+					Text:     fmt.Sprintf("Results have been limited to %v rows because the result size limit of %v bytes was reached", count, bytesLimit),
+				})
+				return frame, nil
+			}
+		}
+
+		if !rows.NextResultSet() {
+			break
+		}
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return frame, nil
+}
+
+// rowSize estimates the memory used by the scanned values of a row.
+func rowSize(row []any) int64 {
+	var size int64
+	for _, v := range row {
+		size += valueSize(reflect.ValueOf(v))
+	}
+	return size
+}
+
+var timeType = reflect.TypeOf(time.Time{})
+
+func valueSize(v reflect.Value) int64 {
+	if v.IsValid() && v.Type() == timeType {
+		return int64(timeType.Size())
+	}
+	switch v.Kind() {
+	case reflect.Invalid:
+		return 0
+	case reflect.Pointer, reflect.Interface:
+		if v.IsNil() {
+			return 0
+		}
+		return valueSize(v.Elem())
+	case reflect.String:
+		return int64(v.Len())
+	case reflect.Slice:
+		return int64(v.Len()) * int64(v.Type().Elem().Size())
+	case reflect.Struct:
+		// e.g. sql.NullString
+		var size int64
+		for i := 0; i < v.NumField(); i++ {
+			size += valueSize(v.Field(i))
+		}
+		return size
+	default:
+		return int64(v.Type().Size())
+	}
+}
@@ -0,0 +1,62 @@
+package sqleng
+
+import (
+	"database/sql"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFrameFromRows(t *testing.T) {
+	newRows := func(t *testing.T, values ...string) *sql.Rows {
+		t.Helper()
+		db, mock, err := sqlmock.New()
+		require.NoError(t, err)
+		t.Cleanup(func() { _ = db.Close() })
+
+		mockRows := sqlmock.NewRowsWithColumnDefinition(sqlmock.NewColumn("value").OfType("VARCHAR", ""))
+		for _, v := range values {
+			mockRows.AddRow(v)
+		}
+		mock.ExpectQuery("SELECT value").WillReturnRows(mockRows)
+
+		rows, err := db.Query("SELECT value")
+		require.NoError(t, err)
+		t.Cleanup(func() { _ = rows.Close() })
+		return rows
+	}
+
+	t.Run("reads all rows within the limits", func(t *testing.T) {
+		frame, err := frameFromRows(newRows(t, "a", "b", "c"), 100, 1000)
+		require.NoError(t, err)
+		require.Equal(t, 3, frame.Rows())
+		require.Nil(t, frame.Meta)
+	})
+
+	t.Run("stops at the row limit", func(t *testing.T) {
+		frame, err := frameFromRows(newRows(t, "a", "b", "c"), 2, 1000)
+		require.NoError(t, err)
+		require.Equal(t, 2, frame.Rows())
+		require.Len(t, frame.Meta.Notices, 1)
+		require.Contains(t, frame.Meta.Notices[0].Text, "SQL row limit was reached")
+	})
+
+	t.Run("stops at the result size limit", func(t *testing.T) {
+		frame, err := frameFromRows(newRows(t, "aaaaaaaaaa", "bbbbbbbbbb", "cccccccccc"), 100, 15)
+		require.NoError(t, err)
+		require.Equal(t, 2, frame.Rows())
+		require.Len(t, frame.Meta.Notices, 1)
+		require.Contains(t, frame.Meta.Notices[0].Text, "result size limit of 15 bytes was reached")
+	})
+}
+
+func TestRowSize(t *testing.T) {
+	s := "abcd"
+	ns := sql.NullString{String: "ab", Valid: true}
+	var i int64
+	var empty *string
+
+	require.Equal(t, int64(4+3+1+8), rowSize([]any{&s, &ns, new(bool), &i}))
+	require.Equal(t, int64(0), rowSize([]any{empty, nil}))
+}
@@ -0,0 +1,253 @@
+package sqleng
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// schemaCacheTTL is how long the schema of a database is cached.
+const schemaCacheTTL = 5 * time.Minute
+
+// schemaCacheMaxEntries bounds the number of cached schemas, tables, columns and indexes lists of a data source.
+const schemaCacheMaxEntries = 1000
+
+// SchemaQueries returns the queries listing the schema of a database. Arguments are passed as positional
+// parameters in the placeholder style of the driver. An empty schema refers to the default schema of the
+// connection.
+type SchemaQueries interface {
+	// Schemas returns the query listing the schemas, with a name column.
+	Schemas() string
+	// Tables returns the query listing the tables and views of a schema, with name and type columns.
+	Tables(schema string) (string, []any)
+	// Columns returns the query listing the columns of a table in order, with name, type and nullable columns.
+	Columns(schema, table string) (string, []any)
+	// Indexes returns the query listing the indexed columns of a table ordered by index and column position,
+	// with index name, column name, unique and primary columns.
+	Indexes(schema, table string) (string, []any)
+}
+
+// Table is a table or view of a schema.
+type Table struct {
+	Name string `json:"name"`
+	Type string `json:"type"`
+}
+
+// Column is a column of a table.
+type Column struct {
+	Name     string `json:"name"`
+	Type     string `json:"type"`
+	Nullable bool   `json:"nullable"`
+}
+
+// Index is an index of a table.
+type Index struct {
+	Name    string   `json:"name"`
+	Columns []string `json:"columns"`
+	Unique  bool     `json:"unique"`
+	Primary bool     `json:"primary"`
+}
+
+// ErrSchemaNotSupported is returned when the data source does not support schema introspection.
+var ErrSchemaNotSupported = errors.New("schema introspection is not supported")
+
+// Schemas returns the schemas of the database.
+func (e *DataSourceHandler) Schemas(ctx context.Context, refresh bool) ([]string, error) {
+	if e.schemaQueries == nil {
+		return nil, ErrSchemaNotSupported
+	}
+	return cachedSchema(e.schemaCache, "schemas", refresh, func() ([]string, error) {
+		schemas := []string{}
+		err := e.scanSchemaRows(ctx, e.schemaQueries.Schemas(), nil, func(values []any) {
+			schemas = append(schemas, toString(values[0]))
+		})
+		return schemas, err
+	})
+}
+
+// Tables returns the tables and views of a schema.
+func (e *DataSourceHandler) Tables(ctx context.Context, schema string, refresh bool) ([]Table, error) {
+	if e.schemaQueries == nil {
+		return nil, ErrSchemaNotSupported
+	}
+	return cachedSchema(e.schemaCache, "tables/"+schema, refresh, func() ([]Table, error) {
+		tables := []Table{}
+		query, args := e.schemaQueries.Tables(schema)
+		err := e.scanSchemaRows(ctx, query, args, func(values []any) {
+			tables = append(tables, Table{Name: toString(values[0]), Type: toString(values[1])})
+		})
+		return tables, err
+	})
+}
+
+// Columns returns the columns of a table.
+func (e *DataSourceHandler) Columns(ctx context.Context, schema, table string, refresh bool) ([]Column, error) {
+	if e.schemaQueries == nil {
+		return nil, ErrSchemaNotSupported
+	}
+	return cachedSchema(e.schemaCache, "columns/"+schema+"/"+table, refresh, func() ([]Column, error) {
+		columns := []Column{}
+		query, args := e.schemaQueries.Columns(schema, table)
+		err := e.scanSchemaRows(ctx, query, args, func(values []any) {
+			columns = append(columns, Column{Name: toString(values[0]), Type: toString(values[1]), Nullable: toBool(values[2])})
+		})
+		return columns, err
+	})
+}
+
+// Indexes returns the indexes of a table.
+func (e *DataSourceHandler) Indexes(ctx context.Context, schema, table string, refresh bool) ([]Index, error) {
+	if e.schemaQueries == nil {
+		return nil, ErrSchemaNotSupported
+	}
+	return cachedSchema(e.schemaCache, "indexes/"+schema+"/"+table, refresh, func() ([]Index, error) {
+		indexes := []Index{}
+		query, args := e.schemaQueries.Indexes(schema, table)
+		err := e.scanSchemaRows(ctx, query, args, func(values []any) {
+			name := toString(values[0])
+			if len(indexes) == 0 || indexes[len(indexes)-1].Name != name {
+				indexes = append(indexes, Index{Name: name, Columns: []string{}, Unique: toBool(values[2]), Primary: toBool(values[3])})
+			}
+			index := &indexes[len(indexes)-1]
+			index.Columns = append(index.Columns, toString(values[1]))
+		})
+		return indexes, err
+	})
+}
+
+func (e *DataSourceHandler) scanSchemaRows(ctx context.Context, query string, args []any, fn func(values []any)) error {
+	rows, err := e.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err := rows.Close(); err != nil {
+			e.log.Warn("Failed to close rows", "err", err)
+		}
+	}()
+
+	columns, err := rows.Columns()
+	if err != nil {
+		return err
+	}
+	for rows.Next() {
+		values := make([]any, len(columns))
+		pointers := make([]any, len(columns))
+		for i := range values {
+			pointers[i] = &values[i]
+		}
+		if err := rows.Scan(pointers...); err != nil {
+			return err
+		}
+		fn(values)
+	}
+	return rows.Err()
+}
+
+func toString(v any) string {
+	switch v := v.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	case []byte:
+		return string(v)
+	default:
+		return fmt.Sprint(v)
+	}
+}
+
+// toBool converts the boolean values of the information schema of each database, e.g. `YES` or `1`.
+func toBool(v any) bool {
+	switch v := v.(type) {
+	case bool:
+		return v
+	case int64:
+		return v != 0
+	case nil:
+		return false
+	}
+	s := strings.ToUpper(toString(v))
+	if s == "YES" || s == "T" {
+		return true
+	}
+	b, err := strconv.ParseBool(s)
+	return err == nil && b
+}
+
+// schemaCache caches the schema of a data source. Handlers are created per data source instance, so the
+// cache is discarded when the settings of the data source are updated. Once the cache holds maxEntries, the
+// expired entries are removed, then the entries closest to expiry.
+type schemaCache struct {
+	mu         sync.Mutex
+	ttl        time.Duration
+	maxEntries int
+	entries    map[string]schemaCacheEntry
+}
+
+type schemaCacheEntry struct {
+	value   any
+	expires time.Time
+}
+
+func newSchemaCache(ttl time.Duration, maxEntries int) *schemaCache {
+	return &schemaCache{ttl: ttl, maxEntries: maxEntries, entries: map[string]schemaCacheEntry{}}
+}
+
+func (c *schemaCache) get(key string) (any, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	entry, ok := c.entries[key]
+	if !ok || time.Now().After(entry.expires) {
+		delete(c.entries, key)
+		return nil, false
+	}
+	return entry.value, true
+}
+
+func (c *schemaCache) set(key string, value any) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	now := time.Now()
+	if _, ok := c.entries[key]; !ok && len(c.entries) >= c.maxEntries {
+		c.evict(now)
+	}
+	c.entries[key] = schemaCacheEntry{value: value, expires: now.Add(c.ttl)}
+}
+
+// evict makes room for a new entry. It must be called with the lock held.
+func (c *schemaCache) evict(now time.Time) {
+	for key, entry := range c.entries {
+		if now.After(entry.expires) {
+			delete(c.entries, key)
+		}
+	}
+	for len(c.entries) >= c.maxEntries {
+		oldest := ""
+		for key, entry := range c.entries {
+			if oldest == "" || entry.expires.Before(c.entries[oldest].expires) {
+				oldest = key
+			}
+		}
+		delete(c.entries, oldest)
+	}
+}
+
+// cachedSchema returns the cached value of key, or loads and caches it. Errors are not cached.
+func cachedSchema[T any](c *schemaCache, key string, refresh bool, load func() (T, error)) (T, error) {
+	if !refresh {
+		if v, ok := c.get(key); ok {
+			return v.(T), nil
+		}
+	}
+	v, err := load()
+	if err != nil {
+		return v, err
+	}
+	c.set(key, v)
+	return v, nil
+}
@@ -15,6 +15,7 @@ import (
 	"time"
 
 	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/grafana/grafana-plugin-sdk-go/backend/resource/httpadapter"
 	"github.com/grafana/grafana-plugin-sdk-go/data"
 	"github.com/grafana/grafana-plugin-sdk-go/data/sqlutil"
 
@@ -59,6 +60,10 @@ type JsonData struct {
 	SecureDSProxyUsername   string `json:"secureSocksProxyUsername"`
 	AllowCleartextPasswords bool   `json:"allowCleartextPasswords"`
 	AuthenticationType      string `json:"authenticationType"`
+	// StatementTimeout is the maximum duration of a query in seconds, 0 means no timeout.
+	StatementTimeout int `json:"statementTimeout"`
+	// ResultBytesLimit is the maximum estimated size of the result of a query in bytes, 0 means no limit.
+	ResultBytesLimit int64 `json:"resultBytesLimit"`
 }
 
 type DataSourceInfo struct {
@@ -77,6 +82,12 @@ type DataPluginConfiguration struct {
 	TimeColumnNames   []string
 	MetricColumnTypes []string
 	RowLimit          int64
+	// QueryCanceler cancels queries on the server when their context is done. If nil, cancellation is left
+	// to the driver.
+	QueryCanceler QueryCanceler
+	// SchemaQueries list the schema of the database for the schema resources. If nil, the resources
+	// are not supported.
+	SchemaQueries SchemaQueries
 }
 
 type DataSourceHandler struct {
@@ -88,6 +99,12 @@ type DataSourceHandler struct {
 	log                    log.Logger
 	dsInfo                 DataSourceInfo
 	rowLimit               int64
+	resultBytesLimit       int64
+	statementTimeout       time.Duration
+	queryCanceler          QueryCanceler
+	schemaQueries          SchemaQueries
+	schemaCache            *schemaCache
+	resourceHandler        backend.CallResourceHandler
 	userError              string
 }
 
@@ -123,6 +140,11 @@ func NewQueryDataHandler(userFacingDefaultError string, db *sql.DB, config DataP
 		log:                    log,
 		dsInfo:                 config.DSInfo,
 		rowLimit:               config.RowLimit,
+		resultBytesLimit:       config.DSInfo.JsonData.ResultBytesLimit,
+		statementTimeout:       time.Duration(config.DSInfo.JsonData.StatementTimeout) * time.Second,
+		queryCanceler:          config.QueryCanceler,
+		schemaQueries:          config.SchemaQueries,
+		schemaCache:            newSchemaCache(schemaCacheTTL, schemaCacheMaxEntries),
 		userError:              userFacingDefaultError,
 	}
 
@@ -135,6 +157,7 @@ func NewQueryDataHandler(userFacingDefaultError string, db *sql.DB, config DataP
 	}
 
 	queryDataHandler.db = db
+	queryDataHandler.resourceHandler = httpadapter.New(queryDataHandler.newResourceMux())
 	return &queryDataHandler, nil
 }
 
@@ -248,11 +271,19 @@ func (e *DataSourceHandler) executeQuery(query backend.DataQuery, wg *sync.WaitG
 		return
 	}
 
-	rows, err := e.db.QueryContext(queryContext, interpolatedQuery)
+	parentContext := queryContext
+	if e.statementTimeout > 0 {
+		var cancel context.CancelFunc
+		queryContext, cancel = context.WithTimeout(queryContext, e.statementTimeout)
+		defer cancel()
+	}
+
+	rows, release, err := e.query(queryContext, logger, interpolatedQuery)
 	if err != nil {
-		errAppendDebug("db query error", e.TransformQueryError(logger, err), interpolatedQuery)
+		errAppendDebug("db query error", e.timeoutError(parentContext, queryContext, e.TransformQueryError(logger, err)), interpolatedQuery)
 		return
 	}
+	defer release()
 	defer func() {
 		if err := rows.Close(); err != nil {
 			logger.Warn("Failed to close rows", "err", err)
@@ -267,9 +298,9 @@ func (e *DataSourceHandler) executeQuery(query backend.DataQuery, wg *sync.WaitG
 
 	// Convert row.Rows to dataframe
 	stringConverters := e.queryResultTransformer.GetConverterList()
-	frame, err := sqlutil.FrameFromRows(rows, e.rowLimit, sqlutil.ToConverters(stringConverters...)...)
+	frame, err := frameFromRows(rows, e.rowLimit, e.resultBytesLimit, sqlutil.ToConverters(stringConverters...)...)
 	if err != nil {
-		errAppendDebug("convert frame from rows error", err, interpolatedQuery)
+		errAppendDebug("convert frame from rows error", e.timeoutError(parentContext, queryContext, err), interpolatedQuery)
 		return
 	}
 
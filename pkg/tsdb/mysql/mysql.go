@@ -134,6 +134,8 @@ func NewInstanceSettings(logger log.Logger) datasource.InstanceFactoryFunc {
 			TimeColumnNames:   []string{"time", "time_sec"},
 			MetricColumnTypes: []string{"CHAR", "VARCHAR", "TINYTEXT", "TEXT", "MEDIUMTEXT", "LONGTEXT"},
 			RowLimit:          sqlCfg.RowLimit,
+			QueryCanceler:     mysqlQueryCanceler{},
+			SchemaQueries:     mysqlSchemaQueries{},
 		}
 
 		userFacingDefaultError, err := cfg.UserFacingDefaultError()
@@ -158,6 +160,21 @@ func NewInstanceSettings(logger log.Logger) datasource.InstanceFactoryFunc {
 	}
 }
 
+// mysqlQueryCanceler cancels queries with KILL QUERY, as the driver only closes the connection when the
+// context of a query is done and the query keeps running on the server.
+type mysqlQueryCanceler struct{}
+
+func (mysqlQueryCanceler) SessionID(ctx context.Context, conn *sql.Conn) (int64, error) {
+	var id int64
+	err := conn.QueryRowContext(ctx, "SELECT CONNECTION_ID()").Scan(&id)
+	return id, err
+}
+
+func (mysqlQueryCanceler) CancelQuery(ctx context.Context, db *sql.DB, sessionID int64) error {
+	_, err := db.ExecContext(ctx, fmt.Sprintf("KILL QUERY %d", sessionID))
+	return err
+}
+
 type mysqlQueryResultTransformer struct {
 	userError string
 }
@@ -43,6 +43,15 @@ func (s *Service) CheckHealth(ctx context.Context, req *backend.CheckHealthReque
 	return dsHandler.CheckHealth(ctx, req)
 }
 
+// NOTE: do not put any business logic into this method. it's whole job is to forward the call "inside"
+func (s *Service) CallResource(ctx context.Context, req *backend.CallResourceRequest, sender backend.CallResourceResponseSender) error {
+	dsHandler, err := s.getDataSourceHandler(ctx, req.PluginContext)
+	if err != nil {
+		return err
+	}
+	return dsHandler.CallResource(ctx, req, sender)
+}
+
 // NOTE: do not put any business logic into this method. it's whole job is to forward the call "inside"
 func (s *Service) QueryData(ctx context.Context, req *backend.QueryDataRequest) (*backend.QueryDataResponse, error) {
 	dsHandler, err := s.getDataSourceHandler(ctx, req.PluginContext)
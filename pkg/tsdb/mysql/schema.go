@@ -0,0 +1,29 @@
+package mysql
+
+// mysqlSchemaQueries lists the schema of a MySQL server, where schemas are databases. An empty schema refers
+// to the database of the data source.
+type mysqlSchemaQueries struct{}
+
+func (mysqlSchemaQueries) Schemas() string {
+	return `SELECT schema_name FROM information_schema.schemata
+		WHERE schema_name NOT IN ('information_schema', 'mysql', 'performance_schema', 'sys')
+		ORDER BY schema_name`
+}
+
+func (mysqlSchemaQueries) Tables(schema string) (string, []any) {
+	return `SELECT table_name, table_type FROM information_schema.tables
+		WHERE table_schema = COALESCE(NULLIF(?, ''), DATABASE())
+		ORDER BY table_name`, []any{schema}
+}
+
+func (mysqlSchemaQueries) Columns(schema, table string) (string, []any) {
+	return `SELECT column_name, data_type, is_nullable FROM information_schema.columns
+		WHERE table_schema = COALESCE(NULLIF(?, ''), DATABASE()) AND table_name = ?
+		ORDER BY ordinal_position`, []any{schema, table}
+}
+
+func (mysqlSchemaQueries) Indexes(schema, table string) (string, []any) {
+	return `SELECT index_name, column_name, non_unique = 0, index_name = 'PRIMARY' FROM information_schema.statistics
+		WHERE table_schema = COALESCE(NULLIF(?, ''), DATABASE()) AND table_name = ?
+		ORDER BY index_name, seq_in_index`, []any{schema, table}
+}
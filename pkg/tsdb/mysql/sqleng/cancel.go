@@ -0,0 +1,85 @@
+package sqleng
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend/log"
+)
+
+// cancelQueryTimeout is the maximum duration of the statement cancelling a query on the server.
+const cancelQueryTimeout = 5 * time.Second
+
+// QueryCanceler cancels queries running on the database server. Closing the connection of a cancelled
+// query does not stop the query on every database, so it is cancelled from another connection of the pool.
+type QueryCanceler interface {
+	// SessionID returns the id of the server session of a connection.
+	SessionID(ctx context.Context, conn *sql.Conn) (int64, error)
+	// CancelQuery cancels the query running in the session with the given id.
+	CancelQuery(ctx context.Context, db *sql.DB, sessionID int64) error
+}
+
+// query runs a query and returns its rows and a function releasing the resources of the query, which must be
+// called after the rows are closed. When the data source sets a statement timeout and has a query canceler, the
+// query runs on a dedicated connection so it can be cancelled on the server when ctx is done. Otherwise it runs
+// on the pool, which saves reserving a connection and looking up its session id for every query.
+func (e *DataSourceHandler) query(ctx context.Context, logger log.Logger, query string) (*sql.Rows, func(), error) {
+	if e.queryCanceler == nil || e.statementTimeout == 0 {
+		rows, err := e.db.QueryContext(ctx, query)
+		return rows, func() {}, err
+	}
+
+	conn, err := e.db.Conn(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+	closeConn := func() {
+		if err := conn.Close(); err != nil {
+			logger.Warn("Failed to close connection", "err", err)
+		}
+	}
+
+	sessionID, err := e.queryCanceler.SessionID(ctx, conn)
+	if err != nil {
+		closeConn()
+		return nil, nil, err
+	}
+
+	cancelled := make(chan struct{})
+	stop := context.AfterFunc(ctx, func() {
+		defer close(cancelled)
+		cancelCtx, cancel := context.WithTimeout(context.Background(), cancelQueryTimeout)
+		defer cancel()
+		if err := e.queryCanceler.CancelQuery(cancelCtx, e.db, sessionID); err != nil {
+			logger.Warn("Failed to cancel query", "sessionId", sessionID, "err", err)
+			return
+		}
+		logger.Debug("Cancelled query", "sessionId", sessionID)
+	})
+	release := func() {
+		// the connection must not be reused by another query before the cancellation is done
+		if !stop() {
+			<-cancelled
+		}
+		closeConn()
+	}
+
+	rows, err := conn.QueryContext(ctx, query)
+	if err != nil {
+		release()
+		return nil, nil, err
+	}
+	return rows, release, nil
+}
+
+// timeoutError returns a descriptive error if a query was stopped by the statement timeout of the data source
+// rather than by the cancellation of the request.
+func (e *DataSourceHandler) timeoutError(parent context.Context, ctx context.Context, err error) error {
+	if e.statementTimeout > 0 && parent.Err() == nil && errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		return fmt.Errorf("query exceeded the statement timeout of %s: %w", e.statementTimeout, err)
+	}
+	return err
+}
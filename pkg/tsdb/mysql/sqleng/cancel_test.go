@@ -0,0 +1,105 @@
+package sqleng
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend/log"
+)
+
+type fakeQueryCanceler struct {
+	sessionID int64
+	sessions  int
+	cancelled chan int64
+}
+
+func (c *fakeQueryCanceler) SessionID(_ context.Context, _ *sql.Conn) (int64, error) {
+	c.sessions++
+	return c.sessionID, nil
+}
+
+func (c *fakeQueryCanceler) CancelQuery(_ context.Context, _ *sql.DB, sessionID int64) error {
+	c.cancelled <- sessionID
+	return nil
+}
+
+func TestQueryCancellation(t *testing.T) {
+	t.Run("cancels the query on the server when the context is cancelled", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		require.NoError(t, err)
+		t.Cleanup(func() { _ = db.Close() })
+		mock.ExpectQuery("SELECT SLEEP").WillDelayFor(time.Minute).WillReturnRows(sqlmock.NewRows([]string{"a"}))
+
+		canceler := &fakeQueryCanceler{sessionID: 42, cancelled: make(chan int64, 1)}
+		handler := &DataSourceHandler{db: db, queryCanceler: canceler, statementTimeout: time.Minute, log: log.DefaultLogger}
+
+		ctx, cancel := context.WithCancel(context.Background())
+		go func() {
+			time.Sleep(10 * time.Millisecond)
+			cancel()
+		}()
+
+		_, _, err = handler.query(ctx, handler.log, "SELECT SLEEP(60)")
+		require.Error(t, err)
+		require.Equal(t, int64(42), <-canceler.cancelled)
+	})
+
+	t.Run("does not cancel completed queries", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		require.NoError(t, err)
+		t.Cleanup(func() { _ = db.Close() })
+		mock.ExpectQuery("SELECT 1").WillReturnRows(sqlmock.NewRows([]string{"a"}).AddRow(1))
+
+		canceler := &fakeQueryCanceler{sessionID: 42, cancelled: make(chan int64, 1)}
+		handler := &DataSourceHandler{db: db, queryCanceler: canceler, statementTimeout: time.Minute, log: log.DefaultLogger}
+
+		ctx, cancel := context.WithCancel(context.Background())
+		rows, release, err := handler.query(ctx, handler.log, "SELECT 1")
+		require.NoError(t, err)
+		require.NoError(t, rows.Close())
+		release()
+		cancel()
+
+		require.Empty(t, canceler.cancelled)
+	})
+
+	t.Run("runs queries on the pool without a statement timeout", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		require.NoError(t, err)
+		t.Cleanup(func() { _ = db.Close() })
+		mock.ExpectQuery("SELECT 1").WillReturnRows(sqlmock.NewRows([]string{"a"}).AddRow(1))
+
+		canceler := &fakeQueryCanceler{sessionID: 42, cancelled: make(chan int64, 1)}
+		handler := &DataSourceHandler{db: db, queryCanceler: canceler, log: log.DefaultLogger}
+
+		rows, release, err := handler.query(context.Background(), handler.log, "SELECT 1")
+		require.NoError(t, err)
+		require.NoError(t, rows.Close())
+		release()
+
+		require.Zero(t, canceler.sessions)
+	})
+
+	t.Run("reports statement timeouts", func(t *testing.T) {
+		handler := &DataSourceHandler{statementTimeout: time.Millisecond}
+
+		parent := context.Background()
+		ctx, cancel := context.WithTimeout(parent, time.Millisecond)
+		defer cancel()
+		<-ctx.Done()
+
+		err := handler.timeoutError(parent, ctx, errors.New("canceling query"))
+		require.ErrorContains(t, err, "query exceeded the statement timeout of 1ms")
+
+		cancelledParent, cancelParent := context.WithCancel(context.Background())
+		cancelParent()
+		err = handler.timeoutError(cancelledParent, ctx, errors.New("canceling query"))
+		require.EqualError(t, err, "canceling query")
+	})
+}
@@ -0,0 +1,93 @@
+package sqleng
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+)
+
+// CallResource serves the schema of the database, used by the query editors for the query builder and
+// autocompletion:
+//
+//	GET /schemas
+//	GET /tables?schema=
+//	GET /columns?schema=&table=
+//	GET /indexes?schema=&table=
+//
+// An empty schema refers to the default schema of the connection. Results are cached, the `refresh`
+// parameter bypasses the cache.
+func (e *DataSourceHandler) CallResource(ctx context.Context, req *backend.CallResourceRequest, sender backend.CallResourceResponseSender) error {
+	return e.resourceHandler.CallResource(ctx, req, sender)
+}
+
+func (e *DataSourceHandler) newResourceMux() *http.ServeMux {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/schemas", e.handleSchemaResource(func(req *http.Request, refresh bool) (any, error) {
+		return e.Schemas(req.Context(), refresh)
+	}))
+	mux.HandleFunc("/tables", e.handleSchemaResource(func(req *http.Request, refresh bool) (any, error) {
+		return e.Tables(req.Context(), req.URL.Query().Get("schema"), refresh)
+	}))
+	mux.HandleFunc("/columns", e.handleSchemaResource(func(req *http.Request, refresh bool) (any, error) {
+		schema, table, err := tableParams(req)
+		if err != nil {
+			return nil, err
+		}
+		return e.Columns(req.Context(), schema, table, refresh)
+	}))
+	mux.HandleFunc("/indexes", e.handleSchemaResource(func(req *http.Request, refresh bool) (any, error) {
+		schema, table, err := tableParams(req)
+		if err != nil {
+			return nil, err
+		}
+		return e.Indexes(req.Context(), schema, table, refresh)
+	}))
+	return mux
+}
+
+// errBadRequest marks errors caused by invalid parameters.
+var errBadRequest = errors.New("bad request")
+
+func tableParams(req *http.Request) (string, string, error) {
+	query := req.URL.Query()
+	table := query.Get("table")
+	if table == "" {
+		return "", "", fmt.Errorf("%w: missing table parameter", errBadRequest)
+	}
+	return query.Get("schema"), table, nil
+}
+
+func (e *DataSourceHandler) handleSchemaResource(fn func(req *http.Request, refresh bool) (any, error)) http.HandlerFunc {
+	return func(rw http.ResponseWriter, req *http.Request) {
+		if req.Method != http.MethodGet {
+			http.Error(rw, fmt.Sprintf("unsupported method %s", req.Method), http.StatusMethodNotAllowed)
+			return
+		}
+
+		refresh, _ := strconv.ParseBool(req.URL.Query().Get("refresh"))
+		result, err := fn(req, refresh)
+		if err != nil {
+			switch {
+			case errors.Is(err, errBadRequest):
+				http.Error(rw, err.Error(), http.StatusBadRequest)
+			case errors.Is(err, ErrSchemaNotSupported):
+				http.Error(rw, err.Error(), http.StatusNotImplemented)
+			default:
+				logger := e.log.FromContext(req.Context())
+				logger.Error("Failed to read schema", "path", req.URL.Path, "err", err)
+				http.Error(rw, e.TransformQueryError(logger, err).Error(), http.StatusInternalServerError)
+			}
+			return
+		}
+
+		rw.Header().Set("Content-Type", "application/json")
+		if err := json.NewEncoder(rw).Encode(result); err != nil {
+			e.log.Warn("Failed to write schema response", "err", err)
+		}
+	}
+}
@@ -0,0 +1,207 @@
+package sqleng
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend/log"
+)
+
+type fakeSchemaQueries struct{}
+
+func (fakeSchemaQueries) Schemas() string {
+	return "SELECT schemas"
+}
+
+func (fakeSchemaQueries) Tables(schema string) (string, []any) {
+	return "SELECT tables", []any{schema}
+}
+
+func (fakeSchemaQueries) Columns(schema, table string) (string, []any) {
+	return "SELECT columns", []any{schema, table}
+}
+
+func (fakeSchemaQueries) Indexes(schema, table string) (string, []any) {
+	return "SELECT indexes", []any{schema, table}
+}
+
+func newSchemaTestHandler(t *testing.T, schemaQueries SchemaQueries) (*DataSourceHandler, sqlmock.Sqlmock) {
+	t.Helper()
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		require.NoError(t, mock.ExpectationsWereMet())
+		_ = db.Close()
+	})
+
+	config := DataPluginConfiguration{SchemaQueries: schemaQueries}
+	handler, err := NewQueryDataHandler("", db, config, &testQueryResultTransformer{}, nil, log.DefaultLogger)
+	require.NoError(t, err)
+	return handler, mock
+}
+
+func TestSchema(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("lists and caches tables", func(t *testing.T) {
+		handler, mock := newSchemaTestHandler(t, fakeSchemaQueries{})
+		mock.ExpectQuery("SELECT tables").WithArgs("public").WillReturnRows(
+			sqlmock.NewRows([]string{"name", "type"}).AddRow("users", "BASE TABLE").AddRow("active_users", "VIEW"),
+		)
+
+		expected := []Table{{Name: "users", Type: "BASE TABLE"}, {Name: "active_users", Type: "VIEW"}}
+		tables, err := handler.Tables(ctx, "public", false)
+		require.NoError(t, err)
+		require.Equal(t, expected, tables)
+
+		tables, err = handler.Tables(ctx, "public", false)
+		require.NoError(t, err)
+		require.Equal(t, expected, tables)
+	})
+
+	t.Run("refreshes cached values", func(t *testing.T) {
+		handler, mock := newSchemaTestHandler(t, fakeSchemaQueries{})
+		mock.ExpectQuery("SELECT schemas").WillReturnRows(sqlmock.NewRows([]string{"name"}).AddRow("public"))
+		mock.ExpectQuery("SELECT schemas").WillReturnRows(sqlmock.NewRows([]string{"name"}).AddRow("public").AddRow("metrics"))
+
+		schemas, err := handler.Schemas(ctx, false)
+		require.NoError(t, err)
+		require.Equal(t, []string{"public"}, schemas)
+
+		schemas, err = handler.Schemas(ctx, true)
+		require.NoError(t, err)
+		require.Equal(t, []string{"public", "metrics"}, schemas)
+	})
+
+	t.Run("does not cache errors", func(t *testing.T) {
+		handler, mock := newSchemaTestHandler(t, fakeSchemaQueries{})
+		mock.ExpectQuery("SELECT schemas").WillReturnError(sql.ErrConnDone)
+		mock.ExpectQuery("SELECT schemas").WillReturnRows(sqlmock.NewRows([]string{"name"}).AddRow("public"))
+
+		_, err := handler.Schemas(ctx, false)
+		require.ErrorIs(t, err, sql.ErrConnDone)
+
+		schemas, err := handler.Schemas(ctx, false)
+		require.NoError(t, err)
+		require.Equal(t, []string{"public"}, schemas)
+	})
+
+	t.Run("lists columns with types", func(t *testing.T) {
+		handler, mock := newSchemaTestHandler(t, fakeSchemaQueries{})
+		mock.ExpectQuery("SELECT columns").WithArgs("", "users").WillReturnRows(
+			sqlmock.NewRows([]string{"name", "type", "nullable"}).AddRow("id", "integer", "NO").AddRow("email", "text", []byte("YES")),
+		)
+
+		columns, err := handler.Columns(ctx, "", "users", false)
+		require.NoError(t, err)
+		require.Equal(t, []Column{
+			{Name: "id", Type: "integer", Nullable: false},
+			{Name: "email", Type: "text", Nullable: true},
+		}, columns)
+	})
+
+	t.Run("groups index columns", func(t *testing.T) {
+		handler, mock := newSchemaTestHandler(t, fakeSchemaQueries{})
+		mock.ExpectQuery("SELECT indexes").WithArgs("public", "users").WillReturnRows(
+			sqlmock.NewRows([]string{"index", "column", "unique", "primary"}).
+				AddRow("users_pkey", "id", true, true).
+				AddRow("users_name_idx", "last_name", int64(0), int64(0)).
+				AddRow("users_name_idx", "first_name", int64(0), int64(0)),
+		)
+
+		indexes, err := handler.Indexes(ctx, "public", "users", false)
+		require.NoError(t, err)
+		require.Equal(t, []Index{
+			{Name: "users_pkey", Columns: []string{"id"}, Unique: true, Primary: true},
+			{Name: "users_name_idx", Columns: []string{"last_name", "first_name"}},
+		}, indexes)
+	})
+}
+
+func TestSchemaCache(t *testing.T) {
+	t.Run("evicts expired entries first", func(t *testing.T) {
+		c := newSchemaCache(time.Minute, 2)
+		c.set("a", 1)
+		c.set("b", 2)
+		c.entries["a"] = schemaCacheEntry{value: 1, expires: time.Now().Add(-time.Second)}
+
+		c.set("c", 3)
+		require.Len(t, c.entries, 2)
+		_, ok := c.get("b")
+		require.True(t, ok)
+		_, ok = c.get("c")
+		require.True(t, ok)
+	})
+
+	t.Run("evicts the entries closest to expiry when full", func(t *testing.T) {
+		c := newSchemaCache(time.Minute, 2)
+		c.set("a", 1)
+		c.set("b", 2)
+		c.entries["a"] = schemaCacheEntry{value: 1, expires: time.Now().Add(time.Second)}
+
+		c.set("c", 3)
+		require.Len(t, c.entries, 2)
+		_, ok := c.get("a")
+		require.False(t, ok)
+	})
+
+	t.Run("replaces entries without evicting", func(t *testing.T) {
+		c := newSchemaCache(time.Minute, 2)
+		c.set("a", 1)
+		c.set("b", 2)
+
+		c.set("a", 3)
+		require.Len(t, c.entries, 2)
+		v, ok := c.get("a")
+		require.True(t, ok)
+		require.Equal(t, 3, v)
+	})
+}
+
+func TestSchemaResources(t *testing.T) {
+	get := func(handler *DataSourceHandler, url string) *httptest.ResponseRecorder {
+		rw := httptest.NewRecorder()
+		handler.newResourceMux().ServeHTTP(rw, httptest.NewRequest(http.MethodGet, url, nil))
+		return rw
+	}
+
+	t.Run("returns columns as JSON", func(t *testing.T) {
+		handler, mock := newSchemaTestHandler(t, fakeSchemaQueries{})
+		mock.ExpectQuery("SELECT columns").WithArgs("public", "users").WillReturnRows(
+			sqlmock.NewRows([]string{"name", "type", "nullable"}).AddRow("id", "integer", "NO"),
+		)
+
+		rw := get(handler, "/columns?schema=public&table=users")
+		require.Equal(t, http.StatusOK, rw.Code)
+		var columns []Column
+		require.NoError(t, json.Unmarshal(rw.Body.Bytes(), &columns))
+		require.Equal(t, []Column{{Name: "id", Type: "integer"}}, columns)
+	})
+
+	t.Run("requires a table", func(t *testing.T) {
+		handler, _ := newSchemaTestHandler(t, fakeSchemaQueries{})
+		rw := get(handler, "/indexes?schema=public")
+		require.Equal(t, http.StatusBadRequest, rw.Code)
+	})
+
+	t.Run("returns an error without schema queries", func(t *testing.T) {
+		handler, _ := newSchemaTestHandler(t, nil)
+		rw := get(handler, "/schemas")
+		require.Equal(t, http.StatusNotImplemented, rw.Code)
+	})
+
+	t.Run("returns query errors", func(t *testing.T) {
+		handler, mock := newSchemaTestHandler(t, fakeSchemaQueries{})
+		mock.ExpectQuery("SELECT tables").WillReturnError(sql.ErrConnDone)
+		rw := get(handler, "/tables")
+		require.Equal(t, http.StatusInternalServerError, rw.Code)
+	})
+}
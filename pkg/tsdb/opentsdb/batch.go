@@ -0,0 +1,73 @@
+package opentsdb
+
+import (
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+
+	"github.com/grafana/grafana/pkg/components/simplejson"
+)
+
+// queryBatch is a group of queries sent to OpenTSDB in a single request.
+type queryBatch struct {
+	timeRange    backend.TimeRange
+	msResolution bool
+	queries      []batchQuery
+}
+
+// batchQuery is a query of a batch, converted to an OpenTSDB sub query.
+type batchQuery struct {
+	refID  string
+	metric map[string]any
+	// annotation is set for annotation queries, which return the annotations of the metric instead of
+	// its data points.
+	annotation       bool
+	globalAnnotation bool
+}
+
+// batchQueries groups the queries sharing the same time range, keeping the order of the queries. Queries are
+// only batched if OpenTSDB returns the sub query of each series, otherwise each query is sent on its own.
+func (s *Service) batchQueries(dsInfo *datasourceInfo, queries []backend.DataQuery) []*queryBatch {
+	batches := make([]*queryBatch, 0, len(queries))
+	byTimeRange := map[backend.TimeRange]*queryBatch{}
+	for _, query := range queries {
+		q := s.buildBatchQuery(query)
+
+		batch, ok := byTimeRange[query.TimeRange]
+		if !ok || !dsInfo.supportsShowQuery() {
+			batch = &queryBatch{
+				timeRange:    query.TimeRange,
+				msResolution: dsInfo.TSDBResolution == 2,
+			}
+			batches = append(batches, batch)
+			byTimeRange[query.TimeRange] = batch
+		}
+		batch.queries = append(batch.queries, q)
+	}
+	return batches
+}
+
+func (s *Service) buildBatchQuery(query backend.DataQuery) batchQuery {
+	q := batchQuery{refID: query.RefID}
+
+	model, err := simplejson.NewJson(query.JSON)
+	if err == nil && model.Get("fromAnnotations").MustBool() {
+		q.annotation = true
+		q.globalAnnotation = model.Get("isGlobal").MustBool()
+		q.metric = map[string]any{
+			"aggregator": "sum",
+			"metric":     model.Get("target").MustString(),
+		}
+		return q
+	}
+
+	q.metric = s.buildMetric(query)
+	return q
+}
+
+// queryIndex returns the index of the query a series belongs to. Series without sub query information
+// are assigned to the first query, which is the only query of the batch in that case.
+func (b *queryBatch) queryIndex(series OpenTsdbResponse) int {
+	if series.Query != nil && series.Query.Index >= 0 && series.Query.Index < len(b.queries) {
+		return series.Query.Index
+	}
+	return 0
+}
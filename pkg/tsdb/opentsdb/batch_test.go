@@ -0,0 +1,124 @@
+package opentsdb
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/grafana/grafana-plugin-sdk-go/backend/instancemgmt"
+	"github.com/grafana/grafana-plugin-sdk-go/backend/resource/httpadapter"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type testInstanceManager struct {
+	info *datasourceInfo
+}
+
+func (m testInstanceManager) Get(_ context.Context, _ backend.PluginContext) (instancemgmt.Instance, error) {
+	return m.info, nil
+}
+
+func (m testInstanceManager) Do(_ context.Context, _ backend.PluginContext, _ instancemgmt.InstanceCallbackFunc) error {
+	return nil
+}
+
+func newTestService(t *testing.T, info datasourceInfo, handler http.HandlerFunc) *Service {
+	t.Helper()
+	srv := httptest.NewServer(handler)
+	t.Cleanup(srv.Close)
+	info.HTTPClient = srv.Client()
+	info.URL = srv.URL
+	if info.LookupLimit == 0 {
+		info.LookupLimit = defaultLookupLimit
+	}
+	s := &Service{im: testInstanceManager{info: &info}}
+	s.resourceHandler = httpadapter.New(s.newResourceMux())
+	return s
+}
+
+func TestBatchQueries(t *testing.T) {
+	service := &Service{}
+	now := time.Now()
+	rangeA := backend.TimeRange{From: now.Add(-time.Hour), To: now}
+	rangeB := backend.TimeRange{From: now.Add(-2 * time.Hour), To: now}
+	queries := []backend.DataQuery{
+		{RefID: "A", TimeRange: rangeA, JSON: []byte(`{"metric": "cpu", "aggregator": "avg"}`)},
+		{RefID: "B", TimeRange: rangeB, JSON: []byte(`{"metric": "mem", "aggregator": "avg"}`)},
+		{RefID: "C", TimeRange: rangeA, JSON: []byte(`{"fromAnnotations": true, "isGlobal": true, "target": "deploys"}`)},
+	}
+
+	t.Run("groups queries by time range", func(t *testing.T) {
+		batches := service.batchQueries(&datasourceInfo{TSDBVersion: 3, TSDBResolution: 2}, queries)
+		require.Len(t, batches, 2)
+
+		require.Equal(t, rangeA, batches[0].timeRange)
+		require.True(t, batches[0].msResolution)
+		require.Len(t, batches[0].queries, 2)
+		require.Equal(t, "A", batches[0].queries[0].refID)
+		require.Equal(t, "C", batches[0].queries[1].refID)
+
+		require.Equal(t, rangeB, batches[1].timeRange)
+		require.Len(t, batches[1].queries, 1)
+	})
+
+	t.Run("does not batch queries without show query support", func(t *testing.T) {
+		batches := service.batchQueries(&datasourceInfo{TSDBVersion: 1}, queries)
+		require.Len(t, batches, 3)
+	})
+
+	t.Run("builds annotation queries", func(t *testing.T) {
+		batches := service.batchQueries(&datasourceInfo{TSDBVersion: 3}, queries)
+		q := batches[0].queries[1]
+		require.True(t, q.annotation)
+		require.True(t, q.globalAnnotation)
+		require.Equal(t, map[string]any{"aggregator": "sum", "metric": "deploys"}, q.metric)
+	})
+}
+
+func TestQueryData(t *testing.T) {
+	now := time.Now()
+	rangeA := backend.TimeRange{From: now.Add(-time.Hour), To: now}
+	rangeB := backend.TimeRange{From: now.Add(-2 * time.Hour), To: now}
+
+	var requests atomic.Int32
+	s := newTestService(t, datasourceInfo{TSDBVersion: 3}, func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		var query OpenTsdbQuery
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&query))
+		assert.True(t, query.ShowQuery)
+		assert.True(t, query.ShowTSUIDs)
+
+		switch len(query.Queries) {
+		case 2:
+			assert.Equal(t, rangeA.From.UnixMilli(), query.Start)
+			_, _ = w.Write([]byte(`[
+				{"metric": "cpu", "dps": {"1": 1}, "query": {"index": 0}},
+				{"metric": "disk", "dps": {"1": 3}, "query": {"index": 1}}
+			]`))
+		default:
+			w.WriteHeader(http.StatusInternalServerError)
+		}
+	})
+
+	resp, err := s.QueryData(context.Background(), &backend.QueryDataRequest{
+		Queries: []backend.DataQuery{
+			{RefID: "A", TimeRange: rangeA, JSON: []byte(`{"metric": "cpu", "aggregator": "avg"}`)},
+			{RefID: "B", TimeRange: rangeB, JSON: []byte(`{"metric": "mem", "aggregator": "avg"}`)},
+			{RefID: "C", TimeRange: rangeA, JSON: []byte(`{"metric": "disk", "aggregator": "avg"}`)},
+		},
+	})
+	require.NoError(t, err)
+	require.Equal(t, int32(2), requests.Load())
+
+	require.NoError(t, resp.Responses["A"].Error)
+	require.Equal(t, "cpu", resp.Responses["A"].Frames[0].Name)
+	require.NoError(t, resp.Responses["C"].Error)
+	require.Equal(t, "disk", resp.Responses["C"].Frames[0].Name)
+	require.Error(t, resp.Responses["B"].Error, "failed batch should only fail its own queries")
+}
@@ -8,6 +8,7 @@ import (
 	"net/http"
 	"net/url"
 	"path"
+	"sort"
 	"strconv"
 	"strings"
 	"time"
@@ -15,6 +16,7 @@ import (
 	"github.com/grafana/grafana-plugin-sdk-go/backend"
 	"github.com/grafana/grafana-plugin-sdk-go/backend/datasource"
 	"github.com/grafana/grafana-plugin-sdk-go/backend/instancemgmt"
+	"github.com/grafana/grafana-plugin-sdk-go/backend/resource/httpadapter"
 	"github.com/grafana/grafana-plugin-sdk-go/data"
 
 	"github.com/grafana/grafana/pkg/components/simplejson"
@@ -26,18 +28,45 @@ import (
 var logger = log.New("tsdb.opentsdb")
 
 type Service struct {
-	im instancemgmt.InstanceManager
+	im              instancemgmt.InstanceManager
+	resourceHandler backend.CallResourceHandler
 }
 
 func ProvideService(httpClientProvider httpclient.Provider) *Service {
-	return &Service{
+	s := &Service{
 		im: datasource.NewInstanceManager(newInstanceSettings(httpClientProvider)),
 	}
+	s.resourceHandler = httpadapter.New(s.newResourceMux())
+	return s
+}
+
+func (s *Service) CallResource(ctx context.Context, req *backend.CallResourceRequest, sender backend.CallResourceResponseSender) error {
+	return s.resourceHandler.CallResource(ctx, req, sender)
 }
 
 type datasourceInfo struct {
 	HTTPClient *http.Client
 	URL        string
+	// TSDBVersion is the version option of the configuration editor, 1 for <=2.1, 2 for 2.2, 3 for 2.3
+	// and 4 for 2.4.
+	TSDBVersion int
+	// TSDBResolution is 1 for second and 2 for millisecond resolution.
+	TSDBResolution int
+	LookupLimit    int
+}
+
+type jsonData struct {
+	TSDBVersion    int `json:"tsdbVersion"`
+	TSDBResolution int `json:"tsdbResolution"`
+	LookupLimit    int `json:"lookupLimit"`
+}
+
+const defaultLookupLimit = 1000
+
+// supportsShowQuery returns true if OpenTSDB returns the sub query of each series, which is required to
+// map the series of a batch to their queries. The option exists since OpenTSDB 2.2.
+func (ds *datasourceInfo) supportsShowQuery() bool {
+	return ds.TSDBVersion >= 2
 }
 
 type DsAccess string
@@ -54,9 +83,22 @@ func newInstanceSettings(httpClientProvider httpclient.Provider) datasource.Inst
 			return nil, err
 		}
 
+		var jd jsonData
+		if len(settings.JSONData) > 0 {
+			if err := json.Unmarshal(settings.JSONData, &jd); err != nil {
+				return nil, fmt.Errorf("failed to parse data source settings: %w", err)
+			}
+		}
+		if jd.LookupLimit <= 0 {
+			jd.LookupLimit = defaultLookupLimit
+		}
+
 		model := &datasourceInfo{
-			HTTPClient: client,
-			URL:        settings.URL,
+			HTTPClient:     client,
+			URL:            settings.URL,
+			TSDBVersion:    jd.TSDBVersion,
+			TSDBResolution: jd.TSDBResolution,
+			LookupLimit:    jd.LookupLimit,
 		}
 
 		return model, nil
@@ -64,20 +106,45 @@ func newInstanceSettings(httpClientProvider httpclient.Provider) datasource.Inst
 }
 
 func (s *Service) QueryData(ctx context.Context, req *backend.QueryDataRequest) (*backend.QueryDataResponse, error) {
-	var tsdbQuery OpenTsdbQuery
-
 	logger := logger.FromContext(ctx)
 
-	q := req.Queries[0]
+	dsInfo, err := s.getDSInfo(ctx, req.PluginContext)
+	if err != nil {
+		return nil, err
+	}
 
-	myRefID := q.RefID
+	result := backend.NewQueryDataResponse()
+	for _, batch := range s.batchQueries(dsInfo, req.Queries) {
+		resp, err := s.executeBatch(ctx, logger, dsInfo, batch)
+		if err != nil {
+			// A failed batch does not fail the queries of other time ranges.
+			for _, q := range batch.queries {
+				result.Responses[q.refID] = backend.DataResponse{Error: err}
+			}
+			continue
+		}
+		for refID, r := range resp.Responses {
+			result.Responses[refID] = r
+		}
+	}
 
-	tsdbQuery.Start = q.TimeRange.From.UnixNano() / int64(time.Millisecond)
-	tsdbQuery.End = q.TimeRange.To.UnixNano() / int64(time.Millisecond)
+	return result, nil
+}
 
-	for _, query := range req.Queries {
-		metric := s.buildMetric(query)
-		tsdbQuery.Queries = append(tsdbQuery.Queries, metric)
+// executeBatch sends the queries of a batch to OpenTSDB in a single request.
+func (s *Service) executeBatch(ctx context.Context, logger log.Logger, dsInfo *datasourceInfo, batch *queryBatch) (*backend.QueryDataResponse, error) {
+	tsdbQuery := OpenTsdbQuery{
+		Start:        batch.timeRange.From.UnixNano() / int64(time.Millisecond),
+		End:          batch.timeRange.To.UnixNano() / int64(time.Millisecond),
+		MsResolution: batch.msResolution,
+		ShowQuery:    dsInfo.supportsShowQuery(),
+		ShowTSUIDs:   true,
+	}
+	for _, q := range batch.queries {
+		tsdbQuery.Queries = append(tsdbQuery.Queries, q.metric)
+		if q.annotation {
+			tsdbQuery.GlobalAnnotations = true
+		}
 	}
 
 	// TODO: Don't use global variable
@@ -85,34 +152,18 @@ func (s *Service) QueryData(ctx context.Context, req *backend.QueryDataRequest)
 		logger.Debug("OpenTsdb request", "params", tsdbQuery)
 	}
 
-	dsInfo, err := s.getDSInfo(ctx, req.PluginContext)
-	if err != nil {
-		return nil, err
-	}
-
 	request, err := s.createRequest(ctx, logger, dsInfo, tsdbQuery)
 	if err != nil {
-		return &backend.QueryDataResponse{}, err
+		return nil, err
 	}
 
 	res, err := dsInfo.HTTPClient.Do(request)
 	if err != nil {
-		return &backend.QueryDataResponse{}, err
-	}
-
-	defer func() {
-		err := res.Body.Close()
-		if err != nil {
-			logger.Warn("failed to close response body", "error", err)
-		}
-	}()
-
-	result, err := s.parseResponse(logger, res, myRefID)
-	if err != nil {
-		return &backend.QueryDataResponse{}, err
+		return nil, err
 	}
 
-	return result, nil
+	// parseResponse closes the body
+	return s.parseResponse(logger, res, batch)
 }
 
 func (s *Service) createRequest(ctx context.Context, logger log.Logger, dsInfo *datasourceInfo, data OpenTsdbQuery) (*http.Request, error) {
@@ -138,7 +189,7 @@ func (s *Service) createRequest(ctx context.Context, logger log.Logger, dsInfo *
 	return req, nil
 }
 
-func (s *Service) parseResponse(logger log.Logger, res *http.Response, myRefID string) (*backend.QueryDataResponse, error) {
+func (s *Service) parseResponse(logger log.Logger, res *http.Response, batch *queryBatch) (*backend.QueryDataResponse, error) {
 	resp := backend.NewQueryDataResponse()
 
 	body, err := io.ReadAll(res.Body)
@@ -163,30 +214,117 @@ func (s *Service) parseResponse(logger log.Logger, res *http.Response, myRefID s
 		return nil, err
 	}
 
-	frames := data.Frames{}
+	if len(batch.queries) == 0 {
+		return resp, nil
+	}
+	series := make([][]OpenTsdbResponse, len(batch.queries))
 	for _, val := range responseData {
-		timeVector := make([]time.Time, 0, len(val.DataPoints))
-		values := make([]float64, 0, len(val.DataPoints))
-		name := val.Metric
-		tags := val.Tags
+		i := batch.queryIndex(val)
+		series[i] = append(series[i], val)
+	}
+
+	for i, q := range batch.queries {
+		var frames data.Frames
+		if q.annotation {
+			frames = data.Frames{annotationsToFrame(q, series[i])}
+		} else {
+			frames, err = seriesToFrames(logger, series[i], batch.msResolution)
+			if err != nil {
+				return nil, err
+			}
+		}
+		resp.Responses[q.refID] = backend.DataResponse{Frames: frames}
+	}
+	return resp, nil
+}
 
-		for timeString, value := range val.DataPoints {
+func seriesToFrames(logger log.Logger, series []OpenTsdbResponse, msResolution bool) (data.Frames, error) {
+	frames := data.Frames{}
+	for _, val := range series {
+		timestamps := make([]int64, 0, len(val.DataPoints))
+		for timeString := range val.DataPoints {
 			timestamp, err := strconv.ParseInt(timeString, 10, 64)
 			if err != nil {
 				logger.Info("Failed to unmarshal opentsdb timestamp", "timestamp", timeString)
 				return nil, err
 			}
-			timeVector = append(timeVector, time.Unix(timestamp, 0).UTC())
-			values = append(values, value)
+			timestamps = append(timestamps, timestamp)
 		}
-		frames = append(frames, data.NewFrame(name,
+		sort.Slice(timestamps, func(i, j int) bool { return timestamps[i] < timestamps[j] })
+
+		timeVector := make([]time.Time, 0, len(timestamps))
+		values := make([]float64, 0, len(timestamps))
+		for _, timestamp := range timestamps {
+			if msResolution {
+				timeVector = append(timeVector, time.UnixMilli(timestamp).UTC())
+			} else {
+				timeVector = append(timeVector, time.Unix(timestamp, 0).UTC())
+			}
+			values = append(values, val.DataPoints[strconv.FormatInt(timestamp, 10)])
+		}
+		valueField := data.NewField("value", val.Tags, values)
+		// The aggregated tags have no single value and the TSUIDs identify the time series of the response,
+		// several of them when tags are aggregated. Both are kept out of the labels, so the series names of
+		// existing panels and alerts don't change.
+		custom := map[string]any{}
+		if len(val.AggregateTags) > 0 {
+			custom["aggregateTags"] = val.AggregateTags
+		}
+		if len(val.TSUIDs) > 0 {
+			custom["tsuids"] = val.TSUIDs
+		}
+		if len(custom) > 0 {
+			valueField.Config = &data.FieldConfig{Custom: custom}
+		}
+		frames = append(frames, data.NewFrame(val.Metric,
 			data.NewField("time", nil, timeVector),
-			data.NewField("value", tags, values)))
+			valueField))
 	}
-	result := resp.Responses[myRefID]
-	result.Frames = frames
-	resp.Responses[myRefID] = result
-	return resp, nil
+	return frames, nil
+}
+
+// annotationsToFrame converts the annotations of an annotation query to a frame. Global annotations are the
+// same for all series of the response, so they are only read from the first series.
+func annotationsToFrame(q batchQuery, series []OpenTsdbResponse) *data.Frame {
+	var annotations []OpenTsdbAnnotation
+	if q.globalAnnotation {
+		if len(series) > 0 {
+			annotations = series[0].GlobalAnnotations
+		}
+	} else {
+		for _, val := range series {
+			annotations = append(annotations, val.Annotations...)
+		}
+	}
+
+	times := make([]time.Time, 0, len(annotations))
+	timeEnds := make([]*time.Time, 0, len(annotations))
+	texts := make([]string, 0, len(annotations))
+	tags := make([]json.RawMessage, 0, len(annotations))
+	for _, a := range annotations {
+		times = append(times, time.Unix(a.StartTime, 0).UTC())
+		var timeEnd *time.Time
+		if a.EndTime > 0 {
+			t := time.Unix(a.EndTime, 0).UTC()
+			timeEnd = &t
+		}
+		timeEnds = append(timeEnds, timeEnd)
+		texts = append(texts, a.Description)
+
+		annotationTags := make([]string, 0, len(a.Custom))
+		for k, v := range a.Custom {
+			annotationTags = append(annotationTags, k+"="+v)
+		}
+		sort.Strings(annotationTags)
+		tagsJSON, _ := json.Marshal(annotationTags)
+		tags = append(tags, tagsJSON)
+	}
+
+	return data.NewFrame("annotations",
+		data.NewField("time", nil, times),
+		data.NewField("timeEnd", nil, timeEnds),
+		data.NewField("text", nil, texts),
+		data.NewField("tags", nil, tags))
 }
 
 func (s *Service) buildMetric(query backend.DataQuery) map[string]any {
@@ -2,6 +2,7 @@ package opentsdb
 
 import (
 	"context"
+	"encoding/json"
 	"io"
 	"net/http"
 	"strings"
@@ -33,7 +34,7 @@ func TestOpenTsdbExecutor(t *testing.T) {
 	t.Run("Parse response should handle invalid JSON", func(t *testing.T) {
 		response := `{ invalid }`
 
-		result, err := service.parseResponse(logger, &http.Response{Body: io.NopCloser(strings.NewReader(response))}, "A")
+		result, err := service.parseResponse(logger, &http.Response{Body: io.NopCloser(strings.NewReader(response))}, testBatch("A"))
 		require.Nil(t, result)
 		require.Error(t, err)
 	})
@@ -63,7 +64,7 @@ func TestOpenTsdbExecutor(t *testing.T) {
 
 		resp := http.Response{Body: io.NopCloser(strings.NewReader(response))}
 		resp.StatusCode = 200
-		result, err := service.parseResponse(logger, &resp, "A")
+		result, err := service.parseResponse(logger, &resp, testBatch("A"))
 		require.NoError(t, err)
 
 		frame := result.Responses["A"]
@@ -100,7 +101,7 @@ func TestOpenTsdbExecutor(t *testing.T) {
 
 		resp := http.Response{Body: io.NopCloser(strings.NewReader(response))}
 		resp.StatusCode = 200
-		result, err := service.parseResponse(logger, &resp, myRefid)
+		result, err := service.parseResponse(logger, &resp, testBatch(myRefid))
 		require.NoError(t, err)
 
 		if diff := cmp.Diff(testFrame, result.Responses[myRefid].Frames[0], data.FrameTestCompareOptions()...); diff != "" {
@@ -277,3 +278,106 @@ func TestOpenTsdbExecutor(t *testing.T) {
 		require.Equal(t, float64(60), metricRateOptions["resetValue"])
 	})
 }
+
+func testBatch(refIDs ...string) *queryBatch {
+	batch := &queryBatch{}
+	for _, refID := range refIDs {
+		batch.queries = append(batch.queries, batchQuery{refID: refID})
+	}
+	return batch
+}
+
+func TestParseResponse(t *testing.T) {
+	service := &Service{}
+
+	parse := func(t *testing.T, batch *queryBatch, body string) *backend.QueryDataResponse {
+		t.Helper()
+		result, err := service.parseResponse(logger, &http.Response{StatusCode: 200, Body: io.NopCloser(strings.NewReader(body))}, batch)
+		require.NoError(t, err)
+		return result
+	}
+
+	t.Run("maps series to queries of the batch", func(t *testing.T) {
+		result := parse(t, testBatch("A", "B"), `[
+			{"metric": "cpu", "dps": {"1": 1}, "query": {"index": 1}},
+			{"metric": "mem", "dps": {"1": 2}, "query": {"index": 0}},
+			{"metric": "disk", "dps": {"1": 3}, "query": {"index": 1}}
+		]`)
+
+		require.Len(t, result.Responses["A"].Frames, 1)
+		require.Equal(t, "mem", result.Responses["A"].Frames[0].Name)
+		require.Len(t, result.Responses["B"].Frames, 2)
+		require.Equal(t, "cpu", result.Responses["B"].Frames[0].Name)
+		require.Equal(t, "disk", result.Responses["B"].Frames[1].Name)
+	})
+
+	t.Run("sorts data points and returns the aggregated tags in the field config", func(t *testing.T) {
+		result := parse(t, testBatch("A"), `[
+			{"metric": "cpu", "tags": {"env": "prod"}, "aggregateTags": ["host"], "dps": {"3": 30, "1": 10, "2": 20}}
+		]`)
+
+		frame := result.Responses["A"].Frames[0]
+		require.Equal(t, 3, frame.Rows())
+		require.Equal(t, time.Unix(1, 0).UTC(), frame.Fields[0].At(0))
+		require.Equal(t, time.Unix(3, 0).UTC(), frame.Fields[0].At(2))
+		require.Equal(t, 10.0, frame.Fields[1].At(0))
+		require.Equal(t, data.Labels{"env": "prod"}, frame.Fields[1].Labels)
+		require.Equal(t, map[string]any{"aggregateTags": []string{"host"}}, frame.Fields[1].Config.Custom)
+	})
+
+	t.Run("returns the tsuids of the series in the field config", func(t *testing.T) {
+		result := parse(t, testBatch("A"), `[
+			{"metric": "cpu", "aggregateTags": ["host"], "tsuids": ["000001000001000001", "000001000001000002"], "dps": {"1": 1}},
+			{"metric": "mem", "dps": {"1": 2}}
+		]`)
+
+		frames := result.Responses["A"].Frames
+		require.Equal(t, map[string]any{
+			"aggregateTags": []string{"host"},
+			"tsuids":        []string{"000001000001000001", "000001000001000002"},
+		}, frames[0].Fields[1].Config.Custom)
+		require.Nil(t, frames[1].Fields[1].Config)
+	})
+
+	t.Run("reads millisecond timestamps", func(t *testing.T) {
+		batch := testBatch("A")
+		batch.msResolution = true
+		result := parse(t, batch, `[{"metric": "cpu", "dps": {"1405544146500": 50}}]`)
+
+		require.Equal(t, time.UnixMilli(1405544146500).UTC(), result.Responses["A"].Frames[0].Fields[0].At(0))
+	})
+
+	t.Run("converts annotations to frames", func(t *testing.T) {
+		batch := &queryBatch{queries: []batchQuery{
+			{refID: "A", annotation: true},
+			{refID: "B", annotation: true, globalAnnotation: true},
+		}}
+		result := parse(t, batch, `[
+			{
+				"metric": "deploys",
+				"dps": {},
+				"query": {"index": 0},
+				"annotations": [{"description": "deploy v1", "startTime": 1405544146, "custom": {"owner": "team-a"}}],
+				"globalAnnotations": [{"description": "maintenance", "startTime": 1405544000, "endTime": 1405545000}]
+			},
+			{
+				"metric": "deploys",
+				"dps": {},
+				"query": {"index": 1},
+				"globalAnnotations": [{"description": "maintenance", "startTime": 1405544000, "endTime": 1405545000}]
+			}
+		]`)
+
+		series := result.Responses["A"].Frames[0]
+		require.Equal(t, 1, series.Rows())
+		require.Equal(t, time.Unix(1405544146, 0).UTC(), series.Fields[0].At(0))
+		require.Nil(t, series.Fields[1].At(0))
+		require.Equal(t, "deploy v1", series.Fields[2].At(0))
+		require.JSONEq(t, `["owner=team-a"]`, string(series.Fields[3].At(0).(json.RawMessage)))
+
+		global := result.Responses["B"].Frames[0]
+		require.Equal(t, 1, global.Rows())
+		require.Equal(t, "maintenance", global.Fields[2].At(0))
+		require.Equal(t, time.Unix(1405545000, 0).UTC(), *global.Fields[1].At(0).(*time.Time))
+	})
+}
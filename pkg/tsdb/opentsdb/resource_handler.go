@@ -0,0 +1,85 @@
+package opentsdb
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"path"
+	"strconv"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend/resource/httpadapter"
+)
+
+func (s *Service) newResourceMux() *http.ServeMux {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/api/suggest", s.handlePassthrough("api/suggest", "max", "type", "q"))
+	mux.HandleFunc("/api/search/lookup", s.handlePassthrough("api/search/lookup", "limit", "m", "useMeta"))
+	return mux
+}
+
+// handlePassthrough returns a handler forwarding GET requests to an OpenTSDB endpoint. Only the given
+// parameters are forwarded, and the limit parameter defaults to the lookup limit of the data source.
+func (s *Service) handlePassthrough(endpoint string, limitParam string, params ...string) http.HandlerFunc {
+	allowed := append([]string{limitParam}, params...)
+	return func(rw http.ResponseWriter, req *http.Request) {
+		ctx := req.Context()
+		logger := logger.FromContext(ctx)
+
+		if req.Method != http.MethodGet {
+			http.Error(rw, fmt.Sprintf("unsupported method %s", req.Method), http.StatusMethodNotAllowed)
+			return
+		}
+
+		dsInfo, err := s.getDSInfo(ctx, httpadapter.PluginConfigFromContext(ctx))
+		if err != nil {
+			logger.Error("Failed to get data source info", "error", err)
+			http.Error(rw, err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		u, err := url.Parse(dsInfo.URL)
+		if err != nil {
+			http.Error(rw, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		u.Path = path.Join(u.Path, endpoint)
+
+		query := req.URL.Query()
+		forwarded := url.Values{}
+		for _, name := range allowed {
+			for _, value := range query[name] {
+				forwarded.Add(name, value)
+			}
+		}
+		if forwarded.Get(limitParam) == "" {
+			forwarded.Set(limitParam, strconv.Itoa(dsInfo.LookupLimit))
+		}
+		u.RawQuery = forwarded.Encode()
+
+		tsdbReq, err := http.NewRequestWithContext(ctx, http.MethodGet, u.String(), nil)
+		if err != nil {
+			http.Error(rw, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		res, err := dsInfo.HTTPClient.Do(tsdbReq)
+		if err != nil {
+			logger.Warn("OpenTSDB resource request failed", "endpoint", endpoint, "error", err)
+			http.Error(rw, err.Error(), http.StatusBadGateway)
+			return
+		}
+		defer func() {
+			if err := res.Body.Close(); err != nil {
+				logger.Warn("Failed to close response body", "error", err)
+			}
+		}()
+
+		if contentType := res.Header.Get("Content-Type"); contentType != "" {
+			rw.Header().Set("Content-Type", contentType)
+		}
+		rw.WriteHeader(res.StatusCode)
+		if _, err := io.Copy(rw, res.Body); err != nil {
+			logger.Warn("Failed to write resource response", "error", err)
+		}
+	}
+}
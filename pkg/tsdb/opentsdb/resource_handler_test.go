@@ -0,0 +1,65 @@
+package opentsdb
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func callResource(t *testing.T, s *Service, method string, url string) *backend.CallResourceResponse {
+	t.Helper()
+	var resp *backend.CallResourceResponse
+	err := s.CallResource(context.Background(), &backend.CallResourceRequest{
+		Method: method,
+		URL:    url,
+	}, backend.CallResourceResponseSenderFunc(func(r *backend.CallResourceResponse) error {
+		resp = r
+		return nil
+	}))
+	require.NoError(t, err)
+	require.NotNil(t, resp)
+	return resp
+}
+
+func TestResourceHandler_Suggest(t *testing.T) {
+	s := newTestService(t, datasourceInfo{LookupLimit: 50}, func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/api/suggest", r.URL.Path)
+		assert.Equal(t, "metrics", r.URL.Query().Get("type"))
+		assert.Equal(t, "cpu", r.URL.Query().Get("q"))
+		assert.Equal(t, "50", r.URL.Query().Get("max"))
+		assert.Empty(t, r.URL.Query().Get("other"))
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`["cpu.user","cpu.system"]`))
+	})
+
+	resp := callResource(t, s, http.MethodGet, "/api/suggest?type=metrics&q=cpu&other=1")
+	require.Equal(t, http.StatusOK, resp.Status)
+	require.JSONEq(t, `["cpu.user","cpu.system"]`, string(resp.Body))
+}
+
+func TestResourceHandler_Lookup(t *testing.T) {
+	s := newTestService(t, datasourceInfo{}, func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/api/search/lookup", r.URL.Path)
+		assert.Equal(t, "cpu{host=*}", r.URL.Query().Get("m"))
+		assert.Equal(t, "10", r.URL.Query().Get("limit"))
+		w.WriteHeader(http.StatusBadRequest)
+		_, _ = w.Write([]byte(`{"error":{"message":"no such metric"}}`))
+	})
+
+	resp := callResource(t, s, http.MethodGet, "/api/search/lookup?m=cpu%7Bhost%3D*%7D&limit=10")
+	require.Equal(t, http.StatusBadRequest, resp.Status)
+	require.Contains(t, string(resp.Body), "no such metric")
+}
+
+func TestResourceHandler_MethodNotAllowed(t *testing.T) {
+	s := newTestService(t, datasourceInfo{}, func(w http.ResponseWriter, r *http.Request) {
+		t.Error("unexpected request to OpenTSDB")
+	})
+
+	resp := callResource(t, s, http.MethodPost, "/api/suggest")
+	require.Equal(t, http.StatusMethodNotAllowed, resp.Status)
+}
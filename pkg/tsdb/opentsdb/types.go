@@ -1,13 +1,38 @@
 package opentsdb
 
 type OpenTsdbQuery struct {
-	Start   int64            `json:"start"`
-	End     int64            `json:"end"`
-	Queries []map[string]any `json:"queries"`
+	Start             int64            `json:"start"`
+	End               int64            `json:"end"`
+	Queries           []map[string]any `json:"queries"`
+	MsResolution      bool             `json:"msResolution,omitempty"`
+	GlobalAnnotations bool             `json:"globalAnnotations,omitempty"`
+	ShowQuery         bool             `json:"showQuery,omitempty"`
+	ShowTSUIDs        bool             `json:"showTSUIDs,omitempty"`
 }
 
 type OpenTsdbResponse struct {
-	Metric     string             `json:"metric"`
-	Tags       map[string]string  `json:"tags"`
-	DataPoints map[string]float64 `json:"dps"`
+	Metric        string            `json:"metric"`
+	Tags          map[string]string `json:"tags"`
+	AggregateTags []string          `json:"aggregateTags"`
+	// TSUIDs is only returned when the request sets showTSUIDs.
+	TSUIDs            []string             `json:"tsuids"`
+	DataPoints        map[string]float64   `json:"dps"`
+	Annotations       []OpenTsdbAnnotation `json:"annotations"`
+	GlobalAnnotations []OpenTsdbAnnotation `json:"globalAnnotations"`
+	// Query is only returned when the request sets showQuery.
+	Query *OpenTsdbResponseQuery `json:"query"`
+}
+
+// OpenTsdbResponseQuery is the sub query a series was returned for.
+type OpenTsdbResponseQuery struct {
+	Index int `json:"index"`
+}
+
+type OpenTsdbAnnotation struct {
+	TSUID       string            `json:"tsuid"`
+	Description string            `json:"description"`
+	Notes       string            `json:"notes"`
+	Custom      map[string]string `json:"custom"`
+	StartTime   int64             `json:"startTime"`
+	EndTime     int64             `json:"endTime"`
 }
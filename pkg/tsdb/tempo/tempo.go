@@ -2,6 +2,7 @@ package tempo
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"net/http"
 	"runtime"
@@ -81,17 +82,21 @@ func newInstanceSettings(httpClientProvider *httpclient.Provider) datasource.Ins
 	}
 }
 
+// headerFromAlert is set by Grafana Alerting on the requests of alert rule queries.
+const headerFromAlert = "FromAlert"
+
 func (s *Service) QueryData(ctx context.Context, req *backend.QueryDataRequest) (*backend.QueryDataResponse, error) {
 	ctxLogger := s.logger.FromContext(ctx)
 	ctxLogger.Debug("Processing queries", "queryLength", len(req.Queries), "function", logEntrypoint())
 
 	// create response struct
 	response := backend.NewQueryDataResponse()
+	_, fromAlert := req.Headers[headerFromAlert]
 
 	// loop over queries and execute them individually.
 	for i, q := range req.Queries {
 		ctxLogger.Debug("Processing query", "counter", i, "function", logEntrypoint())
-		if res, err := s.query(ctx, req.PluginContext, q); err != nil {
+		if res, err := s.query(ctx, req.PluginContext, q, fromAlert); err != nil {
 			ctxLogger.Error("Error processing query", "error", err)
 			return response, err
 		} else {
@@ -108,7 +113,22 @@ func (s *Service) QueryData(ctx context.Context, req *backend.QueryDataRequest)
 	return response, nil
 }
 
-func (s *Service) query(ctx context.Context, pCtx backend.PluginContext, query backend.DataQuery) (*backend.DataResponse, error) {
+// query executes a query. Alert rules can only use TraceQL metrics queries, other queries of alert rules return
+// an error response instead of failing the whole request.
+func (s *Service) query(ctx context.Context, pCtx backend.PluginContext, query backend.DataQuery, fromAlert bool) (*backend.DataResponse, error) {
+	if query.QueryType == string(dataquery.TempoQueryTypeTraceqlMetrics) || query.QueryType == string(dataquery.TempoQueryTypeTraceql) {
+		model := &dataquery.TempoQuery{}
+		if err := json.Unmarshal(query.JSON, model); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal Tempo query model: %w", err)
+		}
+		if isTraceQLMetricsQuery(query, model) {
+			return s.getTraceQLMetrics(ctx, pCtx, query, model)
+		}
+	}
+	if fromAlert {
+		res := backend.ErrDataResponse(backend.StatusBadRequest, fmt.Sprintf("query type '%s' is not supported for alerting, only TraceQL metrics queries can be used in alert rules", query.QueryType))
+		return &res, nil
+	}
 	if query.QueryType == string(dataquery.TempoQueryTypeTraceId) {
 		return s.getTrace(ctx, pCtx, query)
 	}
@@ -0,0 +1,285 @@
+package tempo
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/grafana/grafana-plugin-sdk-go/backend/gtime"
+	"github.com/grafana/grafana-plugin-sdk-go/backend/tracing"
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/grafana/grafana/pkg/tsdb/tempo/kinds/dataquery"
+)
+
+const (
+	// defaultMetricsStep is the step of TraceQL metrics queries without interval, e.g. in alert rules
+	defaultMetricsStep = 15 * time.Second
+	// maxMetricsPoints is the maximum number of samples Tempo returns for a series
+	maxMetricsPoints = 11000
+)
+
+// traceQLMetricsRegex matches queries with a metrics function, same as the query editor
+var traceQLMetricsRegex = regexp.MustCompile(`\|\s*(rate|count_over_time|avg_over_time|max_over_time|min_over_time|quantile_over_time|histogram_over_time)\s*\(`)
+
+// isTraceQLMetricsQuery returns true for TraceQL queries aggregating spans to time series. TraceQL queries are
+// stored with the traceql query type by the query editor, metrics queries are told apart by their functions.
+func isTraceQLMetricsQuery(query backend.DataQuery, model *dataquery.TempoQuery) bool {
+	if query.QueryType == string(dataquery.TempoQueryTypeTraceqlMetrics) {
+		return true
+	}
+	return query.QueryType == string(dataquery.TempoQueryTypeTraceql) && model.Query != nil && traceQLMetricsRegex.MatchString(*model.Query)
+}
+
+// metricsQueryRangeResponse is the JSON encoding of the QueryRangeResponse of Tempo
+type metricsQueryRangeResponse struct {
+	Series []metricsSeries `json:"series"`
+}
+
+type metricsSeries struct {
+	Labels  []metricsLabel  `json:"labels"`
+	Samples []metricsSample `json:"samples"`
+}
+
+type metricsLabel struct {
+	Key   string       `json:"key"`
+	Value metricsValue `json:"value"`
+}
+
+// metricsValue is a protobuf AnyValue, 64-bit integers are encoded as strings
+type metricsValue struct {
+	StringValue *string      `json:"stringValue,omitempty"`
+	IntValue    *json.Number `json:"intValue,omitempty"`
+	DoubleValue *json.Number `json:"doubleValue,omitempty"`
+	BoolValue   *bool        `json:"boolValue,omitempty"`
+}
+
+type metricsSample struct {
+	TimestampMs json.Number `json:"timestampMs"`
+	Value       float64     `json:"value"`
+}
+
+func (s *Service) getTraceQLMetrics(ctx context.Context, pCtx backend.PluginContext, query backend.DataQuery, model *dataquery.TempoQuery) (*backend.DataResponse, error) {
+	ctxLogger := s.logger.FromContext(ctx)
+	ctxLogger.Debug("Getting TraceQL metrics", "function", logEntrypoint())
+
+	result := &backend.DataResponse{}
+
+	ctx, span := tracing.DefaultTracer().Start(ctx, "datasource.tempo.getTraceQLMetrics", trace.WithAttributes(
+		attribute.String("queryType", query.QueryType),
+	))
+	defer span.End()
+
+	if model.Query == nil || strings.TrimSpace(*model.Query) == "" {
+		result.Error = fmt.Errorf("query is required")
+		return result, nil
+	}
+
+	step, err := metricsStep(query, model)
+	if err != nil {
+		result.Error = err
+		return result, nil
+	}
+
+	dsInfo, err := s.getDSInfo(ctx, pCtx)
+	if err != nil {
+		ctxLogger.Error("Failed to get datasource information", "error", err, "function", logEntrypoint())
+		return nil, err
+	}
+
+	request, err := s.createMetricsQueryRangeRequest(ctx, dsInfo, *model.Query, query.TimeRange, step)
+	if err != nil {
+		ctxLogger.Error("Failed to create request", "error", err, "function", logEntrypoint())
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return result, err
+	}
+
+	resp, err := dsInfo.HTTPClient.Do(request)
+	if err != nil {
+		ctxLogger.Error("Failed to send request to Tempo", "error", err, "function", logEntrypoint())
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return result, fmt.Errorf("failed get to tempo: %w", err)
+	}
+
+	defer func() {
+		if err := resp.Body.Close(); err != nil {
+			ctxLogger.Error("Failed to close response body", "error", err, "function", logEntrypoint())
+		}
+	}()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		ctxLogger.Error("Failed to read response body", "error", err, "function", logEntrypoint())
+		return &backend.DataResponse{}, err
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		ctxLogger.Error("Failed to run TraceQL metrics query", "status", resp.Status, "function", logEntrypoint())
+		result.Error = fmt.Errorf("failed to run TraceQL metrics query: %s Status: %s Body: %s", *model.Query, resp.Status, strings.TrimSpace(string(body)))
+		span.RecordError(result.Error)
+		span.SetStatus(codes.Error, result.Error.Error())
+		return result, nil
+	}
+
+	var queryRangeResponse metricsQueryRangeResponse
+	if err := json.Unmarshal(body, &queryRangeResponse); err != nil {
+		ctxLogger.Error("Failed to decode TraceQL metrics response", "error", err, "function", logEntrypoint())
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return &backend.DataResponse{}, fmt.Errorf("failed to decode TraceQL metrics response: %w", err)
+	}
+
+	frames, err := metricsSeriesToFrames(*model.Query, queryRangeResponse.Series)
+	if err != nil {
+		result.Error = err
+		return result, nil
+	}
+	for _, frame := range frames {
+		frame.RefID = query.RefID
+	}
+	result.Frames = frames
+	ctxLogger.Debug("Successfully got TraceQL metrics", "function", logEntrypoint())
+	return result, nil
+}
+
+// metricsStep returns the step of the query. It defaults to the interval of the query and is increased if the
+// time range would otherwise contain more samples than Tempo returns.
+func metricsStep(query backend.DataQuery, model *dataquery.TempoQuery) (time.Duration, error) {
+	step := query.Interval
+	if model.Step != nil && *model.Step != "" {
+		var err error
+		step, err = gtime.ParseDuration(*model.Step)
+		if err != nil {
+			return 0, fmt.Errorf("invalid step %q: %w", *model.Step, err)
+		}
+	}
+	if step <= 0 {
+		step = defaultMetricsStep
+	}
+	if minStep := query.TimeRange.Duration() / maxMetricsPoints; step < minStep {
+		step = minStep
+	}
+	return step, nil
+}
+
+func (s *Service) createMetricsQueryRangeRequest(ctx context.Context, dsInfo *Datasource, traceQL string, timeRange backend.TimeRange, step time.Duration) (*http.Request, error) {
+	params := url.Values{}
+	params.Set("q", traceQL)
+	params.Set("start", strconv.FormatInt(timeRange.From.Unix(), 10))
+	params.Set("end", strconv.FormatInt(timeRange.To.Unix(), 10))
+	params.Set("step", formatStep(step))
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, fmt.Sprintf("%s/api/metrics/query_range?%s", dsInfo.URL, params.Encode()), nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", "application/json")
+	return req, nil
+}
+
+// formatStep formats a step in seconds or milliseconds, which Tempo parses as duration
+func formatStep(step time.Duration) string {
+	if step%time.Second == 0 {
+		return fmt.Sprintf("%ds", int64(step/time.Second))
+	}
+	return fmt.Sprintf("%dms", step.Milliseconds())
+}
+
+// metricsSeriesToFrames returns a frame per series, in the same shape as Prometheus time series so they can be
+// used by expressions, alerts and recording rules.
+func metricsSeriesToFrames(traceQL string, series []metricsSeries) (data.Frames, error) {
+	frames := make(data.Frames, 0, len(series))
+	for _, s := range series {
+		labels := data.Labels{}
+		for _, label := range s.Labels {
+			labels[label.Key] = label.Value.String()
+		}
+
+		type point struct {
+			ms    int64
+			value float64
+		}
+		points := make([]point, 0, len(s.Samples))
+		for _, sample := range s.Samples {
+			ms, err := sample.TimestampMs.Int64()
+			if err != nil {
+				return nil, fmt.Errorf("invalid sample timestamp %q: %w", sample.TimestampMs, err)
+			}
+			points = append(points, point{ms: ms, value: sample.Value})
+		}
+		sort.SliceStable(points, func(i, j int) bool { return points[i].ms < points[j].ms })
+
+		times := make([]time.Time, len(points))
+		values := make([]float64, len(points))
+		for i, p := range points {
+			times[i] = time.UnixMilli(p.ms).UTC()
+			values[i] = p.value
+		}
+
+		valueField := data.NewField(data.TimeSeriesValueFieldName, labels, values)
+		valueField.Config = &data.FieldConfig{DisplayNameFromDS: metricsSeriesName(traceQL, s.Labels, len(series))}
+
+		frame := data.NewFrame("", data.NewField(data.TimeSeriesTimeFieldName, nil, times), valueField)
+		frame.Meta = &data.FrameMeta{
+			Type:                   data.FrameTypeTimeSeriesMulti,
+			ExecutedQueryString:    traceQL,
+			PreferredVisualization: data.VisTypeGraph,
+		}
+		frames = append(frames, frame)
+	}
+	return frames, nil
+}
+
+// metricsSeriesName returns the display name of a series, the value of its label if it has one, or the query
+// for a single series without labels.
+func metricsSeriesName(traceQL string, labels []metricsLabel, seriesCount int) string {
+	switch len(labels) {
+	case 0:
+		if seriesCount == 1 {
+			return traceQL
+		}
+		return ""
+	case 1:
+		return labels[0].Value.quoted()
+	}
+	parts := make([]string, 0, len(labels))
+	for _, label := range labels {
+		parts = append(parts, fmt.Sprintf("%s=%s", label.Key, label.Value.quoted()))
+	}
+	return "{" + strings.Join(parts, ", ") + "}"
+}
+
+func (v metricsValue) String() string {
+	switch {
+	case v.StringValue != nil:
+		return *v.StringValue
+	case v.IntValue != nil:
+		return v.IntValue.String()
+	case v.DoubleValue != nil:
+		return v.DoubleValue.String()
+	case v.BoolValue != nil:
+		return strconv.FormatBool(*v.BoolValue)
+	}
+	return ""
+}
+
+func (v metricsValue) quoted() string {
+	if v.StringValue != nil {
+		return strconv.Quote(*v.StringValue)
+	}
+	return v.String()
+}
@@ -0,0 +1,180 @@
+package tempo
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/grafana/grafana-plugin-sdk-go/backend/instancemgmt"
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/tsdb/tempo/kinds/dataquery"
+)
+
+type fakeInstanceManager struct {
+	ds *Datasource
+}
+
+func (m *fakeInstanceManager) Get(_ context.Context, _ backend.PluginContext) (instancemgmt.Instance, error) {
+	return m.ds, nil
+}
+
+func (m *fakeInstanceManager) Do(_ context.Context, _ backend.PluginContext, _ instancemgmt.InstanceCallbackFunc) error {
+	return nil
+}
+
+const metricsResponse = `{
+	"series": [
+		{
+			"labels": [{"key": "span.http.method", "value": {"stringValue": "GET"}}],
+			"samples": [
+				{"timestampMs": "1700000060000", "value": 2},
+				{"timestampMs": "1700000000000", "value": 1.5}
+			]
+		},
+		{
+			"labels": [{"key": "span.http.method", "value": {"stringValue": "POST"}}],
+			"samples": [{"timestampMs": "1700000000000", "value": 0.5}]
+		}
+	]
+}`
+
+func TestTraceQLMetrics(t *testing.T) {
+	from := time.Unix(1700000000, 0)
+	to := from.Add(time.Hour)
+
+	t.Run("detects metrics queries", func(t *testing.T) {
+		traceQL := func(q string) *dataquery.TempoQuery { return &dataquery.TempoQuery{Query: &q} }
+
+		assert.True(t, isTraceQLMetricsQuery(backend.DataQuery{QueryType: "traceql"}, traceQL(`{ } | rate()`)))
+		assert.True(t, isTraceQLMetricsQuery(backend.DataQuery{QueryType: "traceql"}, traceQL(`{ } | quantile_over_time(duration, .9) by (span.http.method)`)))
+		assert.True(t, isTraceQLMetricsQuery(backend.DataQuery{QueryType: "traceqlMetrics"}, traceQL(`{ }`)))
+		assert.False(t, isTraceQLMetricsQuery(backend.DataQuery{QueryType: "traceql"}, traceQL(`{ span.http.method = "GET" }`)))
+		assert.False(t, isTraceQLMetricsQuery(backend.DataQuery{QueryType: "traceId"}, traceQL(`{ } | rate()`)))
+	})
+
+	t.Run("calculates the step", func(t *testing.T) {
+		timeRange := backend.TimeRange{From: from, To: to}
+		step, err := metricsStep(backend.DataQuery{Interval: time.Minute, TimeRange: timeRange}, &dataquery.TempoQuery{})
+		require.NoError(t, err)
+		assert.Equal(t, time.Minute, step)
+
+		s := "5m"
+		step, err = metricsStep(backend.DataQuery{Interval: time.Minute, TimeRange: timeRange}, &dataquery.TempoQuery{Step: &s})
+		require.NoError(t, err)
+		assert.Equal(t, 5*time.Minute, step)
+
+		step, err = metricsStep(backend.DataQuery{TimeRange: timeRange}, &dataquery.TempoQuery{})
+		require.NoError(t, err)
+		assert.Equal(t, defaultMetricsStep, step)
+
+		step, err = metricsStep(backend.DataQuery{Interval: time.Millisecond, TimeRange: backend.TimeRange{From: from, To: from.Add(24 * time.Hour)}}, &dataquery.TempoQuery{})
+		require.NoError(t, err)
+		assert.Equal(t, 24*time.Hour/maxMetricsPoints, step)
+
+		invalid := "abc"
+		_, err = metricsStep(backend.DataQuery{TimeRange: timeRange}, &dataquery.TempoQuery{Step: &invalid})
+		require.Error(t, err)
+	})
+
+	t.Run("formats the step", func(t *testing.T) {
+		assert.Equal(t, "60s", formatStep(time.Minute))
+		assert.Equal(t, "1500ms", formatStep(1500*time.Millisecond))
+	})
+
+	t.Run("queries the query range endpoint and returns time series", func(t *testing.T) {
+		var requestURL string
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			requestURL = r.URL.String()
+			_, _ = w.Write([]byte(metricsResponse))
+		}))
+		t.Cleanup(srv.Close)
+
+		service := &Service{
+			logger: backend.NewLoggerWith("logger", "tempo-test"),
+			im:     &fakeInstanceManager{ds: &Datasource{HTTPClient: srv.Client(), URL: srv.URL}},
+		}
+		res, err := service.QueryData(context.Background(), &backend.QueryDataRequest{
+			Queries: []backend.DataQuery{{
+				RefID:     "A",
+				QueryType: "traceql",
+				Interval:  time.Minute,
+				TimeRange: backend.TimeRange{From: from, To: to},
+				JSON:      json.RawMessage(`{"query": "{ } | rate() by (span.http.method)"}`),
+			}},
+		})
+		require.NoError(t, err)
+		assert.Equal(t, "/api/metrics/query_range?end=1700003600&q=%7B+%7D+%7C+rate%28%29+by+%28span.http.method%29&start=1700000000&step=60s", requestURL)
+
+		resp := res.Responses["A"]
+		require.NoError(t, resp.Error)
+		require.Len(t, resp.Frames, 2)
+
+		frame := resp.Frames[0]
+		assert.Equal(t, "A", frame.RefID)
+		assert.Equal(t, data.FrameTypeTimeSeriesMulti, frame.Meta.Type)
+		require.Equal(t, 2, frame.Rows())
+		assert.Equal(t, time.UnixMilli(1700000000000).UTC(), frame.Fields[0].At(0))
+		assert.Equal(t, 1.5, frame.Fields[1].At(0))
+		assert.Equal(t, 2.0, frame.Fields[1].At(1))
+		assert.Equal(t, data.Labels{"span.http.method": "GET"}, frame.Fields[1].Labels)
+		assert.Equal(t, `"GET"`, frame.Fields[1].Config.DisplayNameFromDS)
+	})
+
+	t.Run("returns query errors", func(t *testing.T) {
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			http.Error(w, "invalid TraceQL query", http.StatusBadRequest)
+		}))
+		t.Cleanup(srv.Close)
+
+		service := &Service{
+			logger: backend.NewLoggerWith("logger", "tempo-test"),
+			im:     &fakeInstanceManager{ds: &Datasource{HTTPClient: srv.Client(), URL: srv.URL}},
+		}
+		res, err := service.QueryData(context.Background(), &backend.QueryDataRequest{
+			Queries: []backend.DataQuery{{
+				RefID:     "A",
+				QueryType: "traceqlMetrics",
+				TimeRange: backend.TimeRange{From: from, To: to},
+				JSON:      json.RawMessage(`{"query": "{ } | rate("}`),
+			}},
+		})
+		require.NoError(t, err)
+		require.ErrorContains(t, res.Responses["A"].Error, "invalid TraceQL query")
+	})
+	t.Run("returns an error for queries of alert rules that are not metrics queries", func(t *testing.T) {
+		service := &Service{
+			logger: backend.NewLoggerWith("logger", "tempo-test"),
+			im:     &fakeInstanceManager{ds: &Datasource{}},
+		}
+		res, err := service.QueryData(context.Background(), &backend.QueryDataRequest{
+			Headers: map[string]string{headerFromAlert: "true"},
+			Queries: []backend.DataQuery{
+				{RefID: "A", QueryType: "traceql", JSON: json.RawMessage(`{"query": "{ span.http.method = \"GET\" }"}`)},
+				{RefID: "B", QueryType: "traceId", JSON: json.RawMessage(`{"query": "abc"}`)},
+			},
+		})
+		require.NoError(t, err)
+		require.ErrorContains(t, res.Responses["A"].Error, "query type 'traceql' is not supported for alerting")
+		require.ErrorContains(t, res.Responses["B"].Error, "query type 'traceId' is not supported for alerting")
+	})
+}
+
+func TestMetricsSeriesName(t *testing.T) {
+	str := func(s string) metricsValue { return metricsValue{StringValue: &s} }
+	num := json.Number("200")
+
+	assert.Equal(t, "{ } | rate()", metricsSeriesName("{ } | rate()", nil, 1))
+	assert.Equal(t, "", metricsSeriesName("{ } | rate()", nil, 2))
+	assert.Equal(t, `"GET"`, metricsSeriesName("", []metricsLabel{{Key: "span.http.method", Value: str("GET")}}, 2))
+	assert.Equal(t, `{span.http.method="GET", span.http.status_code=200}`, metricsSeriesName("", []metricsLabel{
+		{Key: "span.http.method", Value: str("GET")},
+		{Key: "span.http.status_code", Value: metricsValue{IntValue: &num}},
+	}, 2))
+}
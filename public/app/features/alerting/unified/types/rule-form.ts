@@ -43,6 +43,7 @@ export interface RuleFormValues {
   evaluateEvery: string;
   evaluateFor: string;
   isPaused?: boolean;
+  liveChannel?: string; // Grafana Live channel of streaming rules, kept as is when the rule is saved
   manualRouting: boolean; // if true contactPoints are used. This field will not be used for saving the rule
   contactPoints?: AlertManagerManualRouting;
 
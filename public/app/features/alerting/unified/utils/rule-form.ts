@@ -194,8 +194,18 @@ export function getNotificationSettingsForDTO(
 }
 
 export function formValuesToRulerGrafanaRuleDTO(values: RuleFormValues): PostableRuleGrafanaRuleDTO {
-  const { name, condition, noDataState, execErrState, evaluateFor, queries, isPaused, contactPoints, manualRouting } =
-    values;
+  const {
+    name,
+    condition,
+    noDataState,
+    execErrState,
+    evaluateFor,
+    queries,
+    isPaused,
+    liveChannel,
+    contactPoints,
+    manualRouting,
+  } = values;
   if (condition) {
     const notificationSettings: GrafanaNotificationSettings | undefined = getNotificationSettingsForDTO(
       manualRouting,
@@ -211,6 +221,7 @@ export function formValuesToRulerGrafanaRuleDTO(values: RuleFormValues): Postabl
         data: queries.map(fixBothInstantAndRangeQuery),
         is_paused: Boolean(isPaused),
         notification_settings: notificationSettings,
+        live_channel: liveChannel || undefined,
       },
       for: evaluateFor,
       annotations: arrayToRecord(values.annotations || []),
@@ -271,6 +282,7 @@ export function rulerRuleToFormValues(ruleWithLocation: RuleWithLocation): RuleF
         labels: listifyLabelsOrAnnotations(rule.labels, true),
         folder: { title: namespace, uid: ga.namespace_uid },
         isPaused: ga.is_paused,
+        liveChannel: ga.live_channel,
 
         contactPoints: routingSettings,
         manualRouting: Boolean(routingSettings),
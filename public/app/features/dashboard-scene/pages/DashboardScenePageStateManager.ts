@@ -265,6 +265,11 @@ export class DashboardScenePageStateManager extends StateManagerBase<DashboardSc
   public clearDashboardCache() {
     this.dashboardCache = undefined;
   }
+
+  /** Removes the scene of a dashboard from the cache, so it is built again from the saved dashboard */
+  public clearSceneCache(uid: string) {
+    delete this.cache[uid];
+  }
 }
 
 let stateManager: DashboardScenePageStateManager | null = null;
@@ -1,9 +1,10 @@
-import { screen, render } from '@testing-library/react';
+import { screen, render, waitFor } from '@testing-library/react';
 import userEvent from '@testing-library/user-event';
 import React from 'react';
 import { TestProvider } from 'test/helpers/TestProvider';
 
 import { selectors } from '@grafana/e2e-selectors';
+import { locationService } from '@grafana/runtime';
 import { sceneGraph, SceneRefreshPicker } from '@grafana/scenes';
 import { SaveDashboardResponseDTO } from 'app/types';
 
@@ -131,6 +132,23 @@ describe('SaveDashboardDrawer', () => {
       expect(dashboard.state.isDirty).toEqual(false);
     });
 
+    it('Reloads the dashboard when it was merged with changes saved by others', async () => {
+      const { dashboard, openAndRender } = setup();
+      const reload = jest.spyOn(locationService, 'reload').mockImplementation(() => {});
+
+      dashboard.setState({ title: 'New title' });
+
+      openAndRender();
+
+      mockSaveDashboard({ merged: true });
+
+      await userEvent.click(await screen.findByTestId(selectors.components.Drawer.DashboardSaveDrawer.saveButton));
+
+      expect(dashboard.state.version).toEqual(11);
+      await waitFor(() => expect(reload).toHaveBeenCalled());
+      reload.mockRestore();
+    });
+
     it('Can handle save errors and overwrite', async () => {
       const { dashboard, openAndRender } = setup();
 
@@ -172,6 +190,7 @@ describe('SaveDashboardDrawer', () => {
 
 interface MockBackendApiOptions {
   saveError: 'version-mismatch' | 'name-exists' | 'plugin-dashboard';
+  merged: boolean;
 }
 
 function mockSaveDashboard(options: Partial<MockBackendApiOptions> = {}) {
@@ -12,6 +12,7 @@ import { useDispatch } from 'app/types';
 import { DashboardSavedEvent } from 'app/types/events';
 
 import { updateDashboardUidLastUsedDatasource } from '../../dashboard/utils/dashboard';
+import { getDashboardScenePageStateManager } from '../pages/DashboardScenePageStateManager';
 import { DashboardScene } from '../scene/DashboardScene';
 
 export function useSaveDashboard(isCopy = false) {
@@ -39,7 +40,9 @@ export function useSaveDashboard(isCopy = false) {
 
         // important that these happen before location redirect below
         appEvents.publish(new DashboardSavedEvent());
-        notifyApp.success('Dashboard saved');
+        notifyApp.success(
+          resultData.merged ? 'Dashboard saved and merged with changes saved by others' : 'Dashboard saved'
+        );
 
         //Update local storage dashboard to handle things like last used datasource
         updateDashboardUidLastUsedDatasource(resultData.uid);
@@ -68,6 +71,16 @@ export function useSaveDashboard(isCopy = false) {
           });
         }
 
+        if (resultData.merged) {
+          // the scene doesn't have the changes saved by others, so a later save would revert them
+          setTimeout(() => {
+            const stateManager = getDashboardScenePageStateManager();
+            stateManager.clearSceneCache(resultData.uid);
+            stateManager.clearDashboardCache();
+            locationService.reload();
+          });
+        }
+
         if (scene.state.meta.isStarred) {
           dispatch(
             updateDashboardName({
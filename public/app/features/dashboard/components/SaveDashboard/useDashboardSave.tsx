@@ -50,7 +50,9 @@ export const useDashboardSave = (isCopy = false) => {
 
         // important that these happen before location redirect below
         appEvents.publish(new DashboardSavedEvent());
-        notifyApp.success('Dashboard saved');
+        notifyApp.success(
+          result.merged ? 'Dashboard saved and merged with changes saved by others' : 'Dashboard saved'
+        );
 
         //Update local storage dashboard to handle things like last used datasource
         updateDashboardUidLastUsedDatasource(result.uid);
@@ -73,6 +75,10 @@ export const useDashboardSave = (isCopy = false) => {
         if (newUrl !== currentPath) {
           setTimeout(() => locationService.replace(newUrl));
         }
+        if (result.merged) {
+          // the model doesn't have the changes saved by others, so a later save would revert them
+          setTimeout(() => locationService.reload());
+        }
         if (dashboard.meta.isStarred) {
           dispatch(
             updateDashboardName({
@@ -38,4 +38,40 @@ describe('Terms Settings Editor', () => {
     // All other metric aggregations can be used in order by
     expect(screen.getByText(describeMetric(avg))).toBeInTheDocument();
   });
+
+  it('Should show the series limit instead of order by and min doc count when all terms are fetched', () => {
+    const termsAgg: Terms = {
+      id: '1',
+      type: 'terms',
+      settings: { useComposite: true, seriesLimit: '500' },
+    };
+    const query: ElasticsearchQuery = {
+      refId: 'A',
+      query: '',
+      bucketAggs: [termsAgg, { id: '2', type: 'date_histogram' }],
+      metrics: [{ id: '3', type: 'count' }],
+    };
+
+    renderWithESProvider(<TermsSettingsEditor bucketAgg={termsAgg} />, { providerProps: { query } });
+
+    expect(screen.getByLabelText('All terms')).toBeChecked();
+    expect(screen.getByLabelText('Series limit')).toHaveValue('500');
+    expect(screen.getByLabelText('Order')).toBeInTheDocument();
+    expect(screen.queryByLabelText('Order By')).not.toBeInTheDocument();
+    expect(screen.queryByLabelText('Min Doc Count')).not.toBeInTheDocument();
+  });
+
+  it('Should only allow fetching all terms for the first bucket aggregation', () => {
+    const termsAgg: Terms = { id: '2', type: 'terms' };
+    const query: ElasticsearchQuery = {
+      refId: 'A',
+      query: '',
+      bucketAggs: [{ id: '1', type: 'terms' }, termsAgg],
+      metrics: [{ id: '3', type: 'count' }],
+    };
+
+    renderWithESProvider(<TermsSettingsEditor bucketAgg={termsAgg} />, { providerProps: { query } });
+
+    expect(screen.queryByLabelText('All terms')).not.toBeInTheDocument();
+  });
 });
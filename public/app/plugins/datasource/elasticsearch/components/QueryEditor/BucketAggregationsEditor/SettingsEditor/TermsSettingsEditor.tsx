@@ -2,7 +2,7 @@ import { uniqueId } from 'lodash';
 import React, { useRef } from 'react';
 
 import { SelectableValue } from '@grafana/data';
-import { InlineField, Select, Input } from '@grafana/ui';
+import { InlineField, InlineSwitch, Select, Input } from '@grafana/ui';
 
 import { useDispatch } from '../../../../hooks/useStatelessReducer';
 import { MetricAggregation, Percentiles, ExtendedStatMetaType, ExtendedStats, Terms } from '../../../../types';
@@ -20,8 +20,11 @@ interface Props {
 }
 
 export const TermsSettingsEditor = ({ bucketAgg }: Props) => {
-  const { metrics } = useQuery();
+  const { metrics, bucketAggs } = useQuery();
   const orderBy = createOrderByOptions(metrics);
+  // Only the first bucket aggregation can be sent as a composite aggregation.
+  const canUseComposite = bucketAggs?.[0]?.id === bucketAgg.id;
+  const useComposite = canUseComposite && !!bucketAgg.settings?.useComposite;
   const { current: baseId } = useRef(uniqueId('es-terms-'));
 
   const dispatch = useDispatch();
@@ -53,30 +56,71 @@ export const TermsSettingsEditor = ({ bucketAgg }: Props) => {
         />
       </InlineField>
 
-      <InlineField label="Min Doc Count" {...inlineFieldProps}>
-        <Input
-          id={`${baseId}-min_doc_count`}
-          onBlur={(e) =>
-            dispatch(
-              changeBucketAggregationSetting({ bucketAgg, settingName: 'min_doc_count', newValue: e.target.value })
-            )
-          }
-          defaultValue={
-            bucketAgg.settings?.min_doc_count || bucketAggregationConfig.terms.defaultSettings?.min_doc_count
-          }
-        />
-      </InlineField>
+      {canUseComposite && (
+        <InlineField
+          label="All terms"
+          tooltip="Fetch all terms page by page with a composite aggregation, using the size as page size. Terms are sorted by value, so order by and min doc count are not available."
+          {...inlineFieldProps}
+        >
+          <InlineSwitch
+            id={`${baseId}-use_composite`}
+            onChange={(e: React.ChangeEvent<HTMLInputElement>) =>
+              dispatch(
+                changeBucketAggregationSetting({ bucketAgg, settingName: 'useComposite', newValue: e.target.checked })
+              )
+            }
+            checked={useComposite}
+          />
+        </InlineField>
+      )}
 
-      <InlineField label="Order By" {...inlineFieldProps}>
-        <Select
-          inputId={`${baseId}-order_by`}
-          onChange={(e) =>
-            dispatch(changeBucketAggregationSetting({ bucketAgg, settingName: 'orderBy', newValue: e.value }))
-          }
-          options={orderBy}
-          value={bucketAgg.settings?.orderBy || bucketAggregationConfig.terms.defaultSettings?.orderBy}
-        />
-      </InlineField>
+      {useComposite && (
+        <InlineField
+          label="Series limit"
+          tooltip="Maximum number of terms fetched. Results are truncated with a warning when the limit is reached."
+          {...inlineFieldProps}
+        >
+          <Input
+            id={`${baseId}-series_limit`}
+            placeholder="10000"
+            onBlur={(e) =>
+              dispatch(
+                changeBucketAggregationSetting({ bucketAgg, settingName: 'seriesLimit', newValue: e.target.value })
+              )
+            }
+            defaultValue={bucketAgg.settings?.seriesLimit}
+          />
+        </InlineField>
+      )}
+
+      {!useComposite && (
+        <InlineField label="Min Doc Count" {...inlineFieldProps}>
+          <Input
+            id={`${baseId}-min_doc_count`}
+            onBlur={(e) =>
+              dispatch(
+                changeBucketAggregationSetting({ bucketAgg, settingName: 'min_doc_count', newValue: e.target.value })
+              )
+            }
+            defaultValue={
+              bucketAgg.settings?.min_doc_count || bucketAggregationConfig.terms.defaultSettings?.min_doc_count
+            }
+          />
+        </InlineField>
+      )}
+
+      {!useComposite && (
+        <InlineField label="Order By" {...inlineFieldProps}>
+          <Select
+            inputId={`${baseId}-order_by`}
+            onChange={(e) =>
+              dispatch(changeBucketAggregationSetting({ bucketAgg, settingName: 'orderBy', newValue: e.value }))
+            }
+            options={orderBy}
+            value={bucketAgg.settings?.orderBy || bucketAggregationConfig.terms.defaultSettings?.orderBy}
+          />
+        </InlineField>
+      )}
 
       <InlineField label="Missing" {...inlineFieldProps}>
         <Input
@@ -21,6 +21,11 @@ export const useDescription = (bucketAgg: BucketAggregation): string => {
       const orderBy = bucketAgg.settings?.orderBy || '_term';
       let description = '';
 
+      // composite aggregations return all terms sorted by term, up to the series limit
+      if (bucketAgg.settings?.useComposite) {
+        return `All terms (${order}), Series limit: ${bucketAgg.settings.seriesLimit || '10000'}`;
+      }
+
       if (size !== '0') {
         const orderLabel = orderOptions.find(hasValue(order))?.label!;
         description = `${orderLabel} ${size}, `;
@@ -0,0 +1,32 @@
+import React from 'react';
+
+import { SelectableValue } from '@grafana/data';
+import { RadioButtonGroup } from '@grafana/ui';
+
+import { useDispatch } from '../../hooks/useStatelessReducer';
+import { RawQueryType } from '../../types';
+import { isRawQuery } from '../../utils';
+
+import { useQuery } from './ElasticsearchQueryContext';
+import { changeQueryType } from './state';
+
+type QueryLanguage = 'lucene' | RawQueryType;
+
+const OPTIONS: Array<SelectableValue<QueryLanguage>> = [
+  { value: 'lucene', label: 'Lucene', description: 'Lucene query with metric and bucket aggregations' },
+  { value: 'esql', label: 'ES|QL', description: 'Elasticsearch query language' },
+  { value: 'ppl', label: 'PPL', description: 'Piped processing language of OpenSearch compatible clusters' },
+];
+
+export const QueryLanguageSelector = () => {
+  const query = useQuery();
+  const dispatch = useDispatch();
+
+  const language: QueryLanguage = isRawQuery(query) ? (query.queryType as RawQueryType) : 'lucene';
+
+  const onChange = (newLanguage: QueryLanguage) => {
+    dispatch(changeQueryType(newLanguage === 'lucene' ? undefined : newLanguage));
+  };
+
+  return <RadioButtonGroup<QueryLanguage> fullWidth={false} options={OPTIONS} value={language} onChange={onChange} />;
+};
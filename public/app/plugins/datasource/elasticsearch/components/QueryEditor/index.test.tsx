@@ -129,4 +129,44 @@ describe('QueryEditor', () => {
 
     expect(screen.getByText('Group By')).toBeInTheDocument();
   });
+
+  describe('ES|QL and PPL queries', () => {
+    const query: ElasticsearchQuery = {
+      refId: 'A',
+      queryType: 'esql',
+      query: 'FROM logs | STATS count = COUNT(*) BY bucket = BUCKET(@timestamp, 1 minute)',
+      metrics: [{ id: '1', type: 'count' }],
+      bucketAggs: [{ id: '2', type: 'date_histogram' }],
+    };
+
+    it('Should only show the query and its format', () => {
+      render(<QueryEditor query={query} datasource={datasourceMock} onChange={noop} onRunQuery={noop} />);
+
+      expect(screen.getByText('ES|QL Query')).toBeInTheDocument();
+      expect(screen.getByText('Format')).toBeInTheDocument();
+      expect(screen.queryByText('Query type')).not.toBeInTheDocument();
+      expect(screen.queryByText('Group By')).not.toBeInTheDocument();
+    });
+
+    it('Should change the format', () => {
+      const onChange = jest.fn<void, [ElasticsearchQuery]>();
+      render(<QueryEditor query={query} datasource={datasourceMock} onChange={onChange} onRunQuery={noop} />);
+
+      fireEvent.click(screen.getByLabelText('Table'));
+
+      expect(onChange).toHaveBeenCalledTimes(1);
+      expect(onChange.mock.calls[0][0].format).toBe('table');
+    });
+
+    it('Should clear the query when the query language changes', () => {
+      const onChange = jest.fn<void, [ElasticsearchQuery]>();
+      render(<QueryEditor query={query} datasource={datasourceMock} onChange={onChange} onRunQuery={noop} />);
+
+      fireEvent.click(screen.getByLabelText('Lucene'));
+
+      expect(onChange).toHaveBeenCalledTimes(1);
+      expect(onChange.mock.calls[0][0].queryType).toBeUndefined();
+      expect(onChange.mock.calls[0][0].query).toBe('');
+    });
+  });
 });
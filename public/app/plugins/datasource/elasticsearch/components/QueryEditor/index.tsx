@@ -2,21 +2,22 @@ import { css } from '@emotion/css';
 import React, { useEffect, useId, useState } from 'react';
 import { SemVer } from 'semver';
 
-import { getDefaultTimeRange, GrafanaTheme2, QueryEditorProps } from '@grafana/data';
-import { Alert, InlineField, InlineLabel, Input, QueryField, useStyles2 } from '@grafana/ui';
+import { getDefaultTimeRange, GrafanaTheme2, QueryEditorProps, SelectableValue } from '@grafana/data';
+import { Alert, InlineField, InlineLabel, Input, QueryField, RadioButtonGroup, useStyles2 } from '@grafana/ui';
 
 import { ElasticDatasource } from '../../datasource';
 import { useNextId } from '../../hooks/useNextId';
 import { useDispatch } from '../../hooks/useStatelessReducer';
 import { ElasticsearchOptions, ElasticsearchQuery } from '../../types';
-import { isSupportedVersion, isTimeSeriesQuery, unsupportedVersionMessage } from '../../utils';
+import { isRawQuery, isSupportedVersion, isTimeSeriesQuery, unsupportedVersionMessage } from '../../utils';
 
 import { BucketAggregationsEditor } from './BucketAggregationsEditor';
 import { ElasticsearchProvider } from './ElasticsearchQueryContext';
 import { MetricAggregationsEditor } from './MetricAggregationsEditor';
 import { metricAggregationConfig } from './MetricAggregationsEditor/utils';
+import { QueryLanguageSelector } from './QueryLanguageSelector';
 import { QueryTypeSelector } from './QueryTypeSelector';
-import { changeAliasPattern, changeQuery } from './state';
+import { changeAliasPattern, changeFormat, changeQuery } from './state';
 
 export type ElasticQueryEditorProps = QueryEditorProps<ElasticDatasource, ElasticsearchQuery, ElasticsearchOptions>;
 
@@ -77,12 +78,67 @@ interface Props {
   value: ElasticsearchQuery;
 }
 
-export const ElasticSearchQueryField = ({ value, onChange }: { value?: string; onChange: (v: string) => void }) => {
+export const ElasticSearchQueryField = ({
+  value,
+  onChange,
+  placeholder = 'Enter a lucene query',
+}: {
+  value?: string;
+  onChange: (v: string) => void;
+  placeholder?: string;
+}) => {
   const styles = useStyles2(getStyles);
 
   return (
     <div className={styles.queryItem}>
-      <QueryField query={value} onChange={onChange} placeholder="Enter a lucene query" portalOrigin="elasticsearch" />
+      <QueryField query={value} onChange={onChange} placeholder={placeholder} portalOrigin="elasticsearch" />
+    </div>
+  );
+};
+
+const FORMAT_OPTIONS: Array<SelectableValue<string>> = [
+  { value: 'time_series', label: 'Time series' },
+  { value: 'table', label: 'Table' },
+];
+
+const RawQueryEditorForm = ({ value }: Props) => {
+  const dispatch = useDispatch();
+  const styles = useStyles2(getStyles);
+
+  const language = value.queryType === 'ppl' ? 'PPL' : 'ES|QL';
+
+  return (
+    <div className={styles.root}>
+      <InlineLabel width={17}>{language} Query</InlineLabel>
+      <ElasticSearchQueryField
+        onChange={(query) => dispatch(changeQuery(query))}
+        value={value?.query}
+        placeholder={`Enter a ${language} query`}
+      />
+      <InlineField
+        label="Format"
+        labelWidth={15}
+        tooltip="Results with a date column and numeric columns are returned as time series, unless the table format is selected. The time range is applied to the configured time field."
+      >
+        <RadioButtonGroup
+          options={FORMAT_OPTIONS}
+          value={value.format || 'time_series'}
+          onChange={(format) => dispatch(changeFormat(format))}
+        />
+      </InlineField>
+    </div>
+  );
+};
+
+const QueryLanguageRow = () => {
+  const styles = useStyles2(getStyles);
+
+  return (
+    <div className={styles.root}>
+      <InlineLabel width={17}>Query language</InlineLabel>
+      <div className={styles.queryItem}>
+        <QueryLanguageSelector />
+      </div>
     </div>
   );
 };
@@ -99,8 +155,18 @@ const QueryEditorForm = ({ value }: Props) => {
     (metric) => metricAggregationConfig[metric.type].impliedQueryType === 'metrics'
   );
 
+  if (isRawQuery(value)) {
+    return (
+      <>
+        <QueryLanguageRow />
+        <RawQueryEditorForm value={value} />
+      </>
+    );
+  }
+
   return (
     <>
+      <QueryLanguageRow />
       <div className={styles.root}>
         <InlineLabel width={17}>Query type</InlineLabel>
         <div className={styles.queryItem}>
@@ -1,7 +1,17 @@
 import { ElasticsearchQuery } from '../../types';
 import { reducerTester } from '../reducerTester';
 
-import { aliasPatternReducer, changeAliasPattern, changeQuery, initQuery, queryReducer } from './state';
+import {
+  aliasPatternReducer,
+  changeAliasPattern,
+  changeFormat,
+  changeQuery,
+  changeQueryType,
+  formatReducer,
+  initQuery,
+  queryReducer,
+  queryTypeReducer,
+} from './state';
 
 describe('Query Reducer', () => {
   describe('On Init', () => {
@@ -34,6 +44,13 @@ describe('Query Reducer', () => {
       .thenStateShouldEqual(expectedQuery);
   });
 
+  it('Should clear `query` when the query type changes', () => {
+    reducerTester<ElasticsearchQuery['query']>()
+      .givenReducer(queryReducer, 'Some lucene query')
+      .whenActionIsDispatched(changeQueryType('esql'))
+      .thenStateShouldEqual('');
+  });
+
   it('Should not change state with other action types', () => {
     const initialState: ElasticsearchQuery['query'] = 'Some lucene query';
 
@@ -63,3 +80,28 @@ describe('Alias Pattern Reducer', () => {
       .thenStateShouldEqual(initialState);
   });
 });
+
+describe('Query Type Reducer', () => {
+  it('Should correctly set `queryType`', () => {
+    reducerTester<ElasticsearchQuery['queryType']>()
+      .givenReducer(queryTypeReducer, undefined)
+      .whenActionIsDispatched(changeQueryType('ppl'))
+      .thenStateShouldEqual('ppl');
+  });
+
+  it('Should not change state on init', () => {
+    reducerTester<ElasticsearchQuery['queryType']>()
+      .givenReducer(queryTypeReducer, 'esql')
+      .whenActionIsDispatched(initQuery())
+      .thenStateShouldEqual('esql');
+  });
+});
+
+describe('Format Reducer', () => {
+  it('Should correctly set `format`', () => {
+    reducerTester<ElasticsearchQuery['format']>()
+      .givenReducer(formatReducer, undefined)
+      .whenActionIsDispatched(changeFormat('table'))
+      .thenStateShouldEqual('table');
+  });
+});
@@ -12,11 +12,20 @@ export const changeQuery = createAction<ElasticsearchQuery['query']>('change_que
 
 export const changeAliasPattern = createAction<ElasticsearchQuery['alias']>('change_alias_pattern');
 
+export const changeQueryType = createAction<ElasticsearchQuery['queryType']>('change_query_type');
+
+export const changeFormat = createAction<ElasticsearchQuery['format']>('change_format');
+
 export const queryReducer = (prevQuery: ElasticsearchQuery['query'], action: Action) => {
   if (changeQuery.match(action)) {
     return action.payload;
   }
 
+  // Lucene, ES|QL and PPL queries are not compatible, the query is cleared when the query type changes.
+  if (changeQueryType.match(action)) {
+    return '';
+  }
+
   if (initQuery.match(action)) {
     return prevQuery || '';
   }
@@ -35,3 +44,19 @@ export const aliasPatternReducer = (prevAliasPattern: ElasticsearchQuery['alias'
 
   return prevAliasPattern;
 };
+
+export const queryTypeReducer = (prevQueryType: ElasticsearchQuery['queryType'], action: Action) => {
+  if (changeQueryType.match(action)) {
+    return action.payload;
+  }
+
+  return prevQueryType;
+};
+
+export const formatReducer = (prevFormat: ElasticsearchQuery['format'], action: Action) => {
+  if (changeFormat.match(action)) {
+    return action.payload;
+  }
+
+  return prevFormat;
+};
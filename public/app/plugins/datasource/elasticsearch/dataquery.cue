@@ -29,8 +29,10 @@ composableKinds: DataQuery: {
 
 				// Alias pattern
 				alias?: string
-				// Lucene query
+				// Lucene query, or the ES|QL or PPL query when the query type is `esql` or `ppl`
 				query?: string
+				// Format of the results of ES|QL and PPL queries, `table` or `time_series`
+				format?: string
 				// Name of time field
 				timeField?: string
 				// List of bucket aggregations
@@ -99,6 +101,9 @@ composableKinds: DataQuery: {
 					min_doc_count?: string
 					orderBy?:       string
 					missing?:       string
+					// Fetch all terms page by page with a composite aggregation, up to the series limit
+					useComposite?: bool
+					seriesLimit?:  string
 				} @cuetsy(kind="interface")
 
 				#Filters: {
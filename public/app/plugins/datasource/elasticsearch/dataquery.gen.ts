@@ -72,6 +72,11 @@ export interface Terms extends BucketAggregationWithField {
     min_doc_count?: string;
     orderBy?: string;
     missing?: string;
+    /**
+     * Fetch all terms page by page with a composite aggregation, up to the series limit
+     */
+    useComposite?: boolean;
+    seriesLimit?: string;
   };
   type: 'terms';
 }
@@ -81,7 +86,12 @@ export interface TermsSettings {
   missing?: string;
   order?: TermsOrder;
   orderBy?: string;
+  seriesLimit?: string;
   size?: string;
+  /**
+   * Fetch all terms page by page with a composite aggregation, up to the series limit
+   */
+  useComposite?: boolean;
 }
 
 export interface Filters extends BaseBucketAggregation {
@@ -385,12 +395,16 @@ export interface ElasticsearchDataQuery extends common.DataQuery {
    * List of bucket aggregations
    */
   bucketAggs?: Array<BucketAggregation>;
+  /**
+   * Format of the results of ES|QL and PPL queries, `table` or `time_series`
+   */
+  format?: string;
   /**
    * List of metric aggregations
    */
   metrics?: Array<MetricAggregation>;
   /**
-   * Lucene query
+   * Lucene query, or the ES|QL or PPL query when the query type is `esql` or `ppl`
    */
   query?: string;
   /**
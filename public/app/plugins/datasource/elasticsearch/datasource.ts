@@ -82,7 +82,7 @@ import {
   isElasticsearchResponseWithHits,
   ElasticsearchHits,
 } from './types';
-import { getScriptValue, isSupportedVersion, isTimeSeriesQuery, unsupportedVersionMessage } from './utils';
+import { getScriptValue, isRawQuery, isSupportedVersion, isTimeSeriesQuery, unsupportedVersionMessage } from './utils';
 
 export const REF_ID_STARTER_LOG_VOLUME = 'log-volume-';
 export const REF_ID_STARTER_LOG_SAMPLE = 'log-sample-';
@@ -899,6 +899,9 @@ export class ElasticDatasource
   }
 
   toggleQueryFilter(query: ElasticsearchQuery, filter: ToggleFilterAction): ElasticsearchQuery {
+    if (isRawQuery(query)) {
+      return query;
+    }
     let expression = query.query ?? '';
     switch (filter.type) {
       case 'FILTER_FOR': {
@@ -927,7 +930,8 @@ export class ElasticDatasource
   }
 
   modifyQuery(query: ElasticsearchQuery, action: QueryFixAction): ElasticsearchQuery {
-    if (!action.options) {
+    // Filters are added with the Lucene syntax, which is not valid in ES|QL and PPL queries.
+    if (!action.options || isRawQuery(query)) {
       return query;
     }
 
@@ -976,6 +980,11 @@ export class ElasticDatasource
     scopedVars: ScopedVars,
     filters?: AdHocVariableFilter[]
   ): ElasticsearchQuery {
+    // ES|QL and PPL queries are interpolated as they are, ad hoc filters use the Lucene syntax and are not applied.
+    if (isRawQuery(query)) {
+      return JSON.parse(this.templateSrv.replace(JSON.stringify({ ...query, datasource: this.getRef() }), scopedVars));
+    }
+
     // We need a separate interpolation format for lucene queries, therefore we first interpolate any
     // lucene query string and then everything else
     const interpolateBucketAgg = (bucketAgg: BucketAggregation): BucketAggregation => {
@@ -70,6 +70,12 @@ export interface ElasticsearchOptions extends DataSourceJsonData {
 
 export type QueryType = 'metrics' | 'logs' | 'raw_data' | 'raw_document';
 
+/**
+ * Query types of queries written in ES|QL or PPL. These queries are sent as they are, without metric
+ * and bucket aggregations, and are only supported by the backend.
+ */
+export type RawQueryType = 'esql' | 'ppl';
+
 interface MetricConfiguration<T extends MetricAggregationType> {
   label: string;
   requiresField: boolean;
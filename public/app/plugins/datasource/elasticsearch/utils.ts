@@ -103,6 +103,10 @@ export const unsupportedVersionMessage =
   'Support for Elasticsearch versions after their end-of-life (currently versions < 7.16) was removed. Using unsupported version of Elasticsearch may lead to unexpected and incorrect results.';
 
 // To be considered a time series query, the last bucked aggregation must be a Date Histogram
+export const isRawQuery = (query: ElasticsearchQuery): boolean => {
+  return query?.queryType === 'esql' || query?.queryType === 'ppl';
+};
+
 export const isTimeSeriesQuery = (query: ElasticsearchQuery): boolean => {
   return query?.bucketAggs?.slice(-1)[0]?.type === 'date_histogram';
 };
@@ -14,8 +14,10 @@ import { GrafanaLiveEditor } from './components/GrafanaLiveEditor';
 import { NodeGraphEditor } from './components/NodeGraphEditor';
 import { PredictablePulseEditor } from './components/PredictablePulseEditor';
 import { RawFrameEditor } from './components/RawFrameEditor';
+import { RecordedQueryEditor } from './components/RecordedQueryEditor';
 import { SimulationQueryEditor } from './components/SimulationQueryEditor';
 import { USAQueryEditor, usaQueryModes } from './components/USAQueryEditor';
+import { WorkloadEditor } from './components/WorkloadEditor';
 import { defaultCSVWaveQuery, defaultPulseQuery, defaultQuery } from './constants';
 import { CSVWave, NodesQuery, TestDataDataQuery, TestDataQueryType, USAQuery } from './dataquery';
 import { TestDataDataSource } from './datasource';
@@ -120,6 +122,9 @@ export const QueryEditor = ({ query, datasource, onChange, onRunQuery }: Props)
         update.usa = {
           mode: usaQueryModes[0].value,
         };
+        break;
+      case TestDataQueryType.Workload:
+        update.workload = { seed: 1, series: 10 };
     }
 
     onUpdate(update);
@@ -286,6 +291,12 @@ export const QueryEditor = ({ query, datasource, onChange, onRunQuery }: Props)
         <RawFrameEditor onChange={onUpdate} query={query} ds={datasource} />
       )}
       {scenarioId === TestDataQueryType.CSVFile && <CSVFileEditor onChange={onUpdate} query={query} ds={datasource} />}
+      {scenarioId === TestDataQueryType.Workload && (
+        <WorkloadEditor onChange={onUpdate} query={query} ds={datasource} />
+      )}
+      {scenarioId === TestDataQueryType.RecordedQuery && (
+        <RecordedQueryEditor onChange={onUpdate} query={query} ds={datasource} />
+      )}
       {scenarioId === TestDataQueryType.CSVContent && (
         <CSVContentEditor onChange={onUpdate} query={query} ds={datasource} />
       )}
@@ -0,0 +1,46 @@
+import React, { useState } from 'react';
+
+import { DataSourcePicker } from '@grafana/runtime';
+import { Alert, CodeEditor, InlineField, InlineFieldRow } from '@grafana/ui';
+
+import { EditorProps } from '../QueryEditor';
+
+export const RecordedQueryEditor = ({ onChange, query }: EditorProps) => {
+  const [error, setError] = useState<string>();
+  const recording = query.recording ?? {};
+
+  const onSaveQuery = (content: string) => {
+    try {
+      const model = JSON.parse(content || '{}');
+      setError(undefined);
+      onChange({ ...query, recording: { ...recording, query: model } });
+    } catch (e) {
+      setError('Unable to parse the query: ' + e);
+    }
+  };
+
+  return (
+    <>
+      <InlineFieldRow>
+        <InlineField label="Data source" labelWidth={14} tooltip="The data source the query was recorded from">
+          <DataSourcePicker
+            width={32}
+            current={recording.datasource}
+            noDefault
+            onChange={(ds) => onChange({ ...query, recording: { ...recording, datasource: ds.uid } })}
+          />
+        </InlineField>
+      </InlineFieldRow>
+      {error && <Alert title={error} severity="error" />}
+      <CodeEditor
+        height={200}
+        language="json"
+        value={JSON.stringify(recording.query ?? {}, null, 2)}
+        onBlur={onSaveQuery}
+        onSave={onSaveQuery}
+        showMiniMap={false}
+        showLineNumbers={true}
+      />
+    </>
+  );
+};
@@ -2,18 +2,37 @@ import React, { FormEvent, useMemo, useState } from 'react';
 import { useAsync } from 'react-use';
 
 import { DataFrameJSON, SelectableValue } from '@grafana/data';
-import { InlineField, InlineFieldRow, InlineSwitch, Input, Label, Select } from '@grafana/ui';
+import { CodeEditor, InlineField, InlineFieldRow, InlineSwitch, Input, Label, Select } from '@grafana/ui';
 
 import { EditorProps } from '../QueryEditor';
 import { SimulationQuery } from '../dataquery';
 
 import { SimulationSchemaForm } from './SimulationSchemaForm';
 
-// Type         string      `json:"type"`
-// Name         string      `json:"name"`
-// Description  string      `json:"description"`
-// OnlyForward  bool        `json:"forward"`
-// ConfigFields *data.Frame `json:"config"`
+// Type         string          `json:"type"`
+// Name         string          `json:"name"`
+// Description  string          `json:"description"`
+// OnlyForward  bool            `json:"forward"`
+// ConfigFields *data.Frame     `json:"config"`
+// Spec         *simulationSpec `json:"spec,omitempty"`
+
+const customSimulation = '__custom';
+
+const defaultSpec = `type: counter
+name: Counter
+parameters:
+  - name: rate
+    value: 1
+variables:
+  - name: value
+    update: $value + $rate * $dt
+    noise: 0.5
+events:
+  - name: reset
+    every: 1m
+    set:
+      value: "0"
+`;
 
 interface SimInfo {
   type: string;
@@ -21,11 +40,13 @@ interface SimInfo {
   description: string;
   forward: boolean;
   config: DataFrameJSON;
+  spec?: unknown;
 }
 
 export const SimulationQueryEditor = ({ onChange, query, ds }: EditorProps) => {
   const simQuery = query.sim ?? ({} as SimulationQuery);
   const simKey = simQuery.key ?? {};
+  const isCustom = simQuery.spec !== undefined;
   // keep track of updated config state to pass down to form
   const [cfgValue, setCfgValue] = useState<Record<string, any>>({});
 
@@ -34,12 +55,15 @@ export const SimulationQueryEditor = ({ onChange, query, ds }: EditorProps) => {
     const v = await ds.getResource<SimInfo[]>('sims');
     return {
       sims: v,
-      options: v.map((s) => ({ label: s.name, value: s.type, description: s.description })),
+      options: [
+        ...v.filter((s) => !s.spec).map((s) => ({ label: s.name, value: s.type, description: s.description })),
+        { label: 'Custom', value: customSimulation, description: 'Define the simulation with a spec' },
+      ],
     };
   }, [ds]);
 
   const current = useMemo(() => {
-    const type = simKey.type;
+    const type = isCustom ? customSimulation : simKey.type;
     if (!type || !info.value) {
       return {};
     }
@@ -47,9 +71,12 @@ export const SimulationQueryEditor = ({ onChange, query, ds }: EditorProps) => {
       details: info.value.sims.find((v) => v.type === type),
       option: info.value.options.find((v) => v.value === type),
     };
-  }, [info.value, simKey?.type]);
+  }, [info.value, simKey?.type, isCustom]);
 
   let config = useAsync(async () => {
+    if (isCustom) {
+      return undefined;
+    }
     let path = simKey.type + '/' + simKey.tick + 'hz';
     if (simKey.uid) {
       path += '/' + simKey.uid;
@@ -57,7 +84,7 @@ export const SimulationQueryEditor = ({ onChange, query, ds }: EditorProps) => {
     let config = (await ds.getResource('sim/' + path))?.config;
     setCfgValue(config.value);
     return config;
-  }, [simKey.type, simKey.tick, simKey.uid]);
+  }, [simKey.type, simKey.tick, simKey.uid, isCustom]);
 
   const onUpdateKey = (key: typeof simQuery.key) => {
     onChange({ ...query, sim: { ...simQuery, key } });
@@ -74,7 +101,15 @@ export const SimulationQueryEditor = ({ onChange, query, ds }: EditorProps) => {
   };
 
   const onTypeChange = (v: SelectableValue<string>) => {
-    onUpdateKey({ ...simKey, type: v.value! });
+    if (v.value === customSimulation) {
+      onChange({ ...query, sim: { ...simQuery, key: { ...simKey, type: '' }, spec: simQuery.spec ?? defaultSpec } });
+      return;
+    }
+    onChange({ ...query, sim: { ...simQuery, key: { ...simKey, type: v.value! }, spec: undefined } });
+  };
+
+  const onSpecChange = (spec: string) => {
+    onChange({ ...query, sim: { ...simQuery, spec } });
   };
 
   const onToggleStream = () => {
@@ -133,11 +168,23 @@ export const SimulationQueryEditor = ({ onChange, query, ds }: EditorProps) => {
           <Input type="text" placeholder="optional" value={simQuery.key.uid} onChange={onUIDChanged} />
         </InlineField>
       </InlineFieldRow>
-      <SimulationSchemaForm
-        onChange={onSchemaFormChange}
-        config={cfgValue ?? config.value}
-        schema={current.details?.config.schema ?? { fields: [] }}
-      />
+      {isCustom ? (
+        <CodeEditor
+          height={300}
+          language="yaml"
+          value={simQuery.spec ?? ''}
+          onBlur={onSpecChange}
+          onSave={onSpecChange}
+          showMiniMap={false}
+          showLineNumbers={true}
+        />
+      ) : (
+        <SimulationSchemaForm
+          onChange={onSchemaFormChange}
+          config={cfgValue ?? config.value}
+          schema={current.details?.config.schema ?? { fields: [] }}
+        />
+      )}
     </>
   );
 };
@@ -0,0 +1,121 @@
+import React, { ChangeEvent, useState } from 'react';
+
+import { SelectableValue } from '@grafana/data';
+import { InlineField, InlineFieldRow, Input, Select } from '@grafana/ui';
+
+import { EditorProps } from '../QueryEditor';
+import { WorkloadLabel, WorkloadQuery } from '../dataquery';
+
+type NumberField = Exclude<keyof WorkloadQuery, 'labels' | 'latencyDistribution'>;
+
+const fields: Array<{
+  label: string;
+  id: NumberField;
+  placeholder: string;
+  tooltip: string;
+}> = [
+  {
+    label: 'Seed',
+    id: 'seed',
+    placeholder: '0',
+    tooltip: 'Queries with the same seed and time range return the same data.',
+  },
+  {
+    label: 'Series',
+    id: 'series',
+    placeholder: '1',
+    tooltip: 'The number of series, limited to the number of combinations of the label values.',
+  },
+  { label: 'Points', id: 'points', placeholder: 'auto', tooltip: 'The number of points of each series.' },
+  { label: 'Latency (ms)', id: 'latencyMs', placeholder: '0', tooltip: 'The mean latency of the query.' },
+  {
+    label: 'Latency stddev',
+    id: 'latencyStddevMs',
+    placeholder: '0',
+    tooltip: 'The standard deviation of the latency, for the uniform and normal distributions.',
+  },
+  { label: 'Error %', id: 'errorPercent', placeholder: '0', tooltip: 'The chance the query fails, from 0 to 100.' },
+  {
+    label: 'Payload (bytes)',
+    id: 'payloadBytes',
+    placeholder: '0',
+    tooltip: 'The size of a string field added to each series, in bytes per point.',
+  },
+];
+
+const distributions: Array<SelectableValue<WorkloadQuery['latencyDistribution']>> = [
+  { label: 'Fixed', value: 'fixed' },
+  { label: 'Uniform', value: 'uniform' },
+  { label: 'Normal', value: 'normal' },
+  { label: 'Exponential', value: 'exponential' },
+];
+
+export const formatWorkloadLabels = (labels?: WorkloadLabel[]) =>
+  (labels ?? []).map((l) => `${l.name}=${l.cardinality}`).join(', ');
+
+export const parseWorkloadLabels = (text: string): WorkloadLabel[] =>
+  text
+    .split(',')
+    .map((v) => v.trim())
+    .filter((v) => v.length > 0)
+    .map((v) => {
+      const [name, cardinality] = v.split('=');
+      return { name: name.trim(), cardinality: Number(cardinality) || 1 };
+    });
+
+export const WorkloadEditor = ({ onChange, query }: EditorProps) => {
+  const workload = query.workload ?? {};
+  const [labels, setLabels] = useState(formatWorkloadLabels(workload.labels));
+
+  const onUpdate = (update: Partial<WorkloadQuery>) => {
+    onChange({ ...query, workload: { ...workload, ...update } });
+  };
+
+  const onInputChange = (e: ChangeEvent<HTMLInputElement>) => {
+    const { name, value } = e.target;
+    onUpdate({ [name]: value === '' ? undefined : Number(value) });
+  };
+
+  return (
+    <>
+      <InlineFieldRow>
+        {fields.map(({ label, id, placeholder, tooltip }) => (
+          <InlineField label={label} labelWidth={14} key={id} tooltip={tooltip}>
+            <Input
+              width={16}
+              type="number"
+              name={id}
+              id={`workload.${id}-${query.refId}`}
+              value={workload[id]}
+              placeholder={placeholder}
+              onChange={onInputChange}
+            />
+          </InlineField>
+        ))}
+        <InlineField label="Distribution" labelWidth={14} tooltip="The distribution of the latency.">
+          <Select
+            width={16}
+            options={distributions}
+            value={workload.latencyDistribution ?? 'fixed'}
+            onChange={(v) => onUpdate({ latencyDistribution: v.value })}
+          />
+        </InlineField>
+      </InlineFieldRow>
+      <InlineFieldRow>
+        <InlineField
+          label="Labels"
+          labelWidth={14}
+          grow
+          tooltip="Labels of the series with their number of values, for example: pod=100, region=5"
+        >
+          <Input
+            placeholder="pod=100, region=5"
+            value={labels}
+            onChange={(e: ChangeEvent<HTMLInputElement>) => setLabels(e.target.value)}
+            onBlur={() => onUpdate({ labels: parseWorkloadLabels(labels) })}
+          />
+        </InlineField>
+      </InlineFieldRow>
+    </>
+  );
+};
@@ -25,6 +25,7 @@ export enum TestDataQueryType {
   RandomWalkTable = 'random_walk_table',
   RandomWalkWithError = 'random_walk_with_error',
   RawFrame = 'raw_frame',
+  RecordedQuery = 'recorded_query',
   ServerError500 = 'server_error_500',
   Simulation = 'simulation',
   SlowQuery = 'slow_query',
@@ -33,6 +34,7 @@ export enum TestDataQueryType {
   Trace = 'trace',
   USA = 'usa',
   VariablesQuery = 'variables-query',
+  Workload = 'workload',
 }
 
 export interface StreamingQuery {
@@ -60,6 +62,10 @@ export interface SimulationQuery {
     uid?: string;
   };
   last?: boolean;
+  /**
+   * Declares the simulation, in JSON or YAML
+   */
+  spec?: string;
   stream?: boolean;
 }
 
@@ -81,6 +87,55 @@ export const defaultUSAQuery: Partial<USAQuery> = {
   states: [],
 };
 
+export interface RecordingQuery {
+  /**
+   * UID of the data source the query was recorded from
+   */
+  datasource?: string;
+  /**
+   * Model of the recorded query
+   */
+  query?: Record<string, unknown>;
+}
+
+export interface WorkloadLabel {
+  cardinality: number;
+  name: string;
+}
+
+export interface WorkloadQuery {
+  /**
+   * Error percentage (the chance the query fails 0-100)
+   */
+  errorPercent?: number;
+  /**
+   * Labels of the series, with the number of values of each label
+   */
+  labels?: WorkloadLabel[];
+  latencyDistribution?: 'fixed' | 'uniform' | 'normal' | 'exponential';
+  /**
+   * Latency of the response in milliseconds, following the distribution
+   */
+  latencyMs?: number;
+  latencyStddevMs?: number;
+  /**
+   * Size of a string field added to each series, in bytes per point
+   */
+  payloadBytes?: number;
+  /**
+   * Number of points of each series
+   */
+  points?: number;
+  /**
+   * Seed of the generated values, errors and latencies
+   */
+  seed?: number;
+  /**
+   * Number of series, limited to the number of combinations of the label values
+   */
+  series?: number;
+}
+
 export interface CSVWave {
   labels?: string;
   name?: string;
@@ -118,6 +173,7 @@ export interface TestDataDataQuery extends common.DataQuery {
   points?: Array<Array<string | number>>;
   pulseWave?: PulseWaveQuery;
   rawFrameContent?: string;
+  recording?: RecordingQuery;
   scenarioId?: TestDataQueryType;
   seriesCount?: number;
   sim?: SimulationQuery;
@@ -125,6 +181,7 @@ export interface TestDataDataQuery extends common.DataQuery {
   stream?: StreamingQuery;
   stringInput?: string;
   usa?: USAQuery;
+  workload?: WorkloadQuery;
 }
 
 export const defaultTestDataDataQuery: Partial<TestDataDataQuery> = {
@@ -103,6 +103,16 @@ export class ConfigEditor extends PureComponent<Props, State> {
               />
             </Field>
           )}
+          <Field
+            label="Msgpack responses"
+            description="Request query results in msgpack format, which is faster to process for many series. Requires carbonapi or a Graphite version that supports msgpack."
+          >
+            <Switch
+              id="msgpack-enabled"
+              value={!!options.jsonData.msgpackEnabled}
+              onChange={onUpdateDatasourceJsonDataOptionChecked(this.props, 'msgpackEnabled')}
+            />
+          </Field>
         </FieldSet>
         <MappingsConfiguration
           mappings={(options.jsonData.importConfiguration?.loki?.mappings || []).map(toString)}
@@ -23,6 +23,7 @@ export interface GraphiteOptions extends DataSourceJsonData {
   graphiteVersion: string;
   graphiteType: GraphiteType;
   rollupIndicatorEnabled?: boolean;
+  msgpackEnabled?: boolean;
   importConfiguration: GraphiteQueryImportConfiguration;
 }
 
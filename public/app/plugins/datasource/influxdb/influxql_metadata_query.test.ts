@@ -270,4 +270,42 @@ describe('influx_metadata_query', () => {
       });
     });
   });
+  describe('schema resources', () => {
+    const mockGetResource = jest.fn();
+
+    beforeEach(() => {
+      config.featureToggles.influxdbSchemaResources = true;
+      ds.getResource = mockGetResource;
+    });
+
+    afterEach(() => {
+      config.featureToggles.influxdbSchemaResources = false;
+    });
+
+    it('should get measurements from the backend', async () => {
+      mockGetResource.mockResolvedValue(['cpu', 'mem']);
+      const measurements = await getAllMeasurements(ds, [], 'c');
+      expect(mockGetResource).toHaveBeenCalledWith('measurements', expect.objectContaining({ filter: 'c' }));
+      expect(measurements).toEqual(['cpu', 'mem']);
+      expect(mockMetricFindQuery).not.toHaveBeenCalled();
+      expect(mockRunMetadataQuery).not.toHaveBeenCalled();
+    });
+
+    it('should get field keys of a retention policy from the backend', async () => {
+      mockGetResource.mockResolvedValue([{ name: 'usage_idle', type: 'float' }]);
+      const fields = await getFieldKeys(ds, 'cpu', 'rp');
+      expect(mockGetResource).toHaveBeenCalledWith(
+        'field-keys',
+        expect.objectContaining({ measurement: 'cpu', policy: 'rp' })
+      );
+      expect(fields).toEqual(['usage_idle']);
+    });
+
+    it('should fall back to a query when filtering by tags', () => {
+      config.featureToggles.influxdbBackendMigration = true;
+      getTagValues(ds, [{ key: 'tagKey', value: 'tag_val' }], 'test_key', 'test_measurement');
+      expect(mockGetResource).not.toHaveBeenCalled();
+      expect(mockRunMetadataQuery).toHaveBeenCalled();
+    });
+  });
 });
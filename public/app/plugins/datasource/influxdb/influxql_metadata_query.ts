@@ -16,7 +16,41 @@ type MetadataQueryOptions = {
   withMeasurementFilter?: string;
 };
 
+const schemaResources: Partial<Record<MetadataQueryType, string>> = {
+  RETENTION_POLICIES: 'retention-policies',
+  MEASUREMENTS: 'measurements',
+  FIELDS: 'field-keys',
+  TAG_KEYS: 'tag-keys',
+  TAG_VALUES: 'tag-values',
+};
+
+// The schema resources are cached by the backend, but they can't filter by tag conditions.
+const canUseSchemaResource = (options: MetadataQueryOptions): boolean =>
+  Boolean(
+    config.featureToggles.influxdbSchemaResources &&
+      options.datasource.access === 'proxy' &&
+      schemaResources[options.type] &&
+      !options.tags?.length
+  );
+
+const runSchemaResourceQuery = async (options: MetadataQueryOptions): Promise<Array<{ text: string }>> => {
+  const { type, datasource, scopedVars, measurement, retentionPolicy, withKey, withMeasurementFilter } = options;
+  const replace = (value?: string) => (value ? datasource.templateSrv.replace(value, scopedVars) : undefined);
+  const params = {
+    policy: replace(retentionPolicy),
+    measurement: replace(measurement),
+    key: replace(withKey),
+    filter: withMeasurementFilter,
+  };
+  const result: Array<string | { name: string }> = await datasource.getResource(schemaResources[type]!, params);
+  return result.map((item) => ({ text: typeof item === 'string' ? item : item.name }));
+};
+
 const runExploreQuery = async (options: MetadataQueryOptions): Promise<Array<{ text: string }>> => {
+  if (canUseSchemaResource(options)) {
+    return runSchemaResourceQuery(options);
+  }
+
   const { type, datasource, scopedVars, measurement, retentionPolicy, tags, withKey, withMeasurementFilter } = options;
   const query = buildMetadataQuery({
     type,
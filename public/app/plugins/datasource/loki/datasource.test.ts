@@ -1617,6 +1617,51 @@ describe('LokiDatasource', () => {
     });
   });
 
+  describe('Backend query splitting', () => {
+    const query: DataQueryRequest<LokiQuery> = {
+      ...baseRequestOptions,
+      targets: [{ expr: 'count_over_time({a="b"}[1m])', refId: 'A' }],
+      app: CoreApp.Dashboard,
+    };
+
+    beforeAll(() => {
+      config.featureToggles.lokiBackendQuerySplitting = true;
+      config.featureToggles.lokiQuerySplitting = true;
+    });
+    afterAll(() => {
+      config.featureToggles.lokiBackendQuerySplitting = false;
+      config.featureToggles.lokiQuerySplitting = false;
+    });
+    beforeEach(() => {
+      jest.mocked(runSplitQuery).mockClear();
+    });
+
+    it('runs the query through the query data path', async () => {
+      const ds = createLokiDatasource(templateSrvStub);
+      const runQuery = jest.spyOn(ds, 'runQuery').mockReturnValue(of({ data: [] }));
+      const runBackendSplitQuery = jest.spyOn(ds, 'runBackendSplitQuery');
+
+      await expect(ds.query(query)).toEmitValuesWith(() => {
+        expect(runQuery).toHaveBeenCalled();
+        expect(runBackendSplitQuery).not.toHaveBeenCalled();
+        expect(runSplitQuery).not.toHaveBeenCalled();
+      });
+    });
+
+    it('streams the query when the data source opts in', async () => {
+      const ds = createLokiDatasource(templateSrvStub, {
+        jsonData: { streamSplitQueries: true },
+      } as DataSourceInstanceSettings<LokiOptions>);
+      const runQuery = jest.spyOn(ds, 'runQuery');
+      const runBackendSplitQuery = jest.spyOn(ds, 'runBackendSplitQuery').mockReturnValue(of({ data: [] }));
+
+      await expect(ds.query(query)).toEmitValuesWith(() => {
+        expect(runBackendSplitQuery).toHaveBeenCalled();
+        expect(runQuery).not.toHaveBeenCalled();
+      });
+    });
+  });
+
   describe('getQueryStats', () => {
     let ds: LokiDatasource;
     let query: LokiQuery;
@@ -1,4 +1,4 @@
-import { cloneDeep, map as lodashMap } from 'lodash';
+import { cloneDeep, map as lodashMap, partition } from 'lodash';
 import { lastValueFrom, merge, Observable, of, throwError } from 'rxjs';
 import { catchError, map, switchMap, tap } from 'rxjs/operators';
 
@@ -84,7 +84,7 @@ import {
   requestSupportsSplitting,
 } from './queryUtils';
 import { replaceVariables, returnVariables } from './querybuilder/parsingUtils';
-import { convertToWebSocketUrl, doLokiChannelStream } from './streaming';
+import { convertToWebSocketUrl, doLokiChannelStream, doLokiSplitStream } from './streaming';
 import { trackQuery } from './tracking';
 import {
   LokiOptions,
@@ -329,7 +329,12 @@ export class LokiDatasource
       return this.runLiveQueryThroughBackend(fixedRequest);
     }
 
-    if (config.featureToggles.lokiQuerySplitting && requestSupportsSplitting(fixedRequest.targets)) {
+    if (config.featureToggles.lokiBackendQuerySplitting && requestSupportsSplitting(fixedRequest.targets)) {
+      // the backend splits the range queries of the request, streaming them only renders the first time ranges sooner
+      if (this.instanceSettings.jsonData.streamSplitQueries) {
+        return this.runBackendSplitQuery(fixedRequest);
+      }
+    } else if (config.featureToggles.lokiQuerySplitting && requestSupportsSplitting(fixedRequest.targets)) {
       return runSplitQuery(this, fixedRequest);
     }
 
@@ -355,6 +360,28 @@ export class LokiDatasource
       );
   }
 
+  /**
+   * Used within the `query` to run range queries split into shorter time ranges by the backend, when the
+   * data source opts in to `streamSplitQueries`. The results of each time range are streamed, so the most
+   * recent data renders before the whole time range is queried.
+   * @returns An Observable of DataQueryResponse, completed once all time ranges are queried.
+   */
+  runBackendSplitQuery(fixedRequest: DataQueryRequest<LokiQuery>): Observable<DataQueryResponse> {
+    const queries = fixedRequest.targets.filter((q) => !q.hide && q.expr);
+    const [rangeQueries, otherQueries] = partition(queries, (q) => q.queryType !== LokiQueryType.Instant);
+
+    const responses = rangeQueries.map((q) => {
+      const query = this.applyTemplateVariables(q, fixedRequest.scopedVars, fixedRequest.filters);
+      return doLokiSplitStream(query, this, fixedRequest).pipe(
+        map((response) => transformBackendResult(response, [q], this.instanceSettings.jsonData.derivedFields ?? []))
+      );
+    });
+    if (otherQueries.length > 0) {
+      responses.push(this.runQuery({ ...fixedRequest, targets: otherQueries }));
+    }
+    return merge(...responses);
+  }
+
   /**
    * Used within the `query` to execute live queries.
    * It is intended for logs-queries, not metric queries.
@@ -1,13 +1,17 @@
-import { map, Observable, defer, mergeMap } from 'rxjs';
+import { map, Observable, defer, mergeMap, takeWhile } from 'rxjs';
+import { v4 as uuidv4 } from 'uuid';
 
 import {
   DataFrameJSON,
   DataQueryRequest,
   DataQueryResponse,
+  dataFrameFromJSON,
+  isLiveChannelMessageEvent,
   LiveChannelScope,
   LoadingState,
   StreamingDataFrame,
 } from '@grafana/data';
+import { combineResponses } from '@grafana/o11y-ds-frontend';
 import { getGrafanaLiveSrv, config } from '@grafana/runtime';
 
 import { LokiDatasource } from './datasource';
@@ -19,8 +23,10 @@ import { LokiQuery } from './types';
  * possible collisions
  */
 export async function getLiveStreamKey(query: LokiQuery): Promise<string> {
-  const str = JSON.stringify({ expr: query.expr });
+  return hashKey(JSON.stringify({ expr: query.expr }));
+}
 
+async function hashKey(str: string): Promise<string> {
   const msgUint8 = new TextEncoder().encode(str); // encode as (utf-8) Uint8Array
   const hashBuffer = await crypto.subtle.digest('SHA-1', msgUint8); // hash the message
   const hashArray = Array.from(new Uint8Array(hashBuffer.slice(0, 8))); // first 8 bytes
@@ -87,6 +93,65 @@ export function doLokiChannelStream(
   );
 }
 
+interface SplitProgress {
+  done: number;
+  total: number;
+}
+
+/**
+ * Runs a range query split into shorter time ranges by the backend. The backend sends the frames of each time range,
+ * followed by a frame without fields with the progress of the query, which are merged into a single response.
+ */
+export function doLokiSplitStream(
+  query: LokiQuery,
+  ds: LokiDatasource,
+  options: DataQueryRequest<LokiQuery>
+): Observable<DataQueryResponse> {
+  const data = {
+    ...query,
+    timeRange: {
+      from: options.range.from.valueOf().toString(),
+      to: options.range.to.valueOf().toString(),
+    },
+    intervalMs: options.intervalMs,
+  };
+
+  let response: DataQueryResponse = { data: [], key: `split-${query.refId}`, state: LoadingState.Loading };
+
+  // the backend runs a query for each channel, so channels must not be shared by several panels
+  return defer(() =>
+    getGrafanaLiveSrv().getStream({
+      scope: LiveChannelScope.DataSource,
+      namespace: ds.uid,
+      path: `split/${uuidv4()}`,
+      data,
+    })
+  ).pipe(
+    map((evt) => {
+      if (!isLiveChannelMessageEvent(evt)) {
+        return response;
+      }
+      const frame = dataFrameFromJSON(evt.message as DataFrameJSON);
+      if (frame.fields.length > 0) {
+        response = combineResponses(response, { data: [frame] });
+        response.state = LoadingState.Streaming;
+        return { ...response };
+      }
+
+      const error: string | undefined = frame.meta?.custom?.error;
+      const progress: SplitProgress | undefined = frame.meta?.custom?.progress;
+      if (error) {
+        response.errors = [{ message: error, refId: query.refId }];
+        response.state = LoadingState.Error;
+      } else if (progress && progress.done === progress.total) {
+        response.state = LoadingState.Done;
+      }
+      return { ...response };
+    }),
+    takeWhile((response) => response.state !== LoadingState.Done && response.state !== LoadingState.Error, true)
+  );
+}
+
 export const convertToWebSocketUrl = (url: string) => {
   const protocol = window.location.protocol === 'https:' ? 'wss://' : 'ws://';
   let backend = `${protocol}${window.location.host}${config.appSubUrl}`;
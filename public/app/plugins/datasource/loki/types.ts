@@ -44,6 +44,11 @@ export interface LokiOptions extends DataSourceJsonData {
   alertmanager?: string;
   keepCookies?: string[];
   predefinedOperations?: string;
+  // used by the backend to split queries, with the lokiBackendQuerySplitting feature toggle
+  querySplitInterval?: string;
+  querySharding?: boolean;
+  maxConcurrentSubqueries?: number;
+  streamSplitQueries?: boolean;
 }
 
 export interface LokiStreamResult {
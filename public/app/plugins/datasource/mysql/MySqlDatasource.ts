@@ -3,9 +3,8 @@ import { CompletionItemKind, LanguageDefinition, TableIdentifier } from '@grafan
 import { SqlDatasource, DB, SQLQuery, formatSQL } from '@grafana/sql';
 
 import { mapFieldsToTypes } from './fields';
-import { buildColumnQuery, buildTableQuery, showDatabases } from './mySqlMetaQuery';
 import { getSqlCompletionProvider } from './sqlCompletionProvider';
-import { quoteIdentifierIfNecessary, quoteLiteral, toRawSql } from './sqlUtil';
+import { quoteIdentifierIfNecessary, quoteLiteral, toRawSql, unquoteIdentifier } from './sqlUtil';
 import { MySQLOptions } from './types';
 
 export class MySqlDatasource extends SqlDatasource {
@@ -38,27 +37,31 @@ export class MySqlDatasource extends SqlDatasource {
   }
 
   async fetchDatasets(): Promise<string[]> {
-    const datasets = await this.runSql<string[]>(showDatabases(), { refId: 'datasets' });
-    return datasets.map((t) => quoteIdentifierIfNecessary(t[0]));
+    const datasets = await this.getResource<string[]>('schemas');
+    return datasets.map((d) => quoteIdentifierIfNecessary(d));
   }
 
   async fetchTables(dataset?: string): Promise<string[]> {
-    const tables = await this.runSql<string[]>(buildTableQuery(dataset), { refId: 'tables' });
-    return tables.map((t) => quoteIdentifierIfNecessary(t[0]));
+    const tables = await this.getResource<Array<{ name: string }>>('tables', schemaParams(dataset));
+    return tables.map((t) => quoteIdentifierIfNecessary(t.name));
   }
 
   async fetchFields(query: Partial<SQLQuery>) {
     if (!query.dataset || !query.table) {
       return [];
     }
-    const queryString = buildColumnQuery(query.table, query.dataset);
-    const frame = await this.runSql<string[]>(queryString, { refId: 'fields' });
-    const fields = frame.map((f) => ({
-      name: f[0],
-      text: f[0],
-      value: quoteIdentifierIfNecessary(f[0]),
-      type: f[1],
-      label: f[0],
+    // the table can be qualified with its database
+    const [dataset, table] = query.table.includes('.') ? query.table.split('.') : [query.dataset, query.table];
+    const columns = await this.getResource<Array<{ name: string; type: string }>>('columns', {
+      ...schemaParams(dataset),
+      table: unquoteIdentifier(table),
+    });
+    const fields = columns.map((c) => ({
+      name: c.name,
+      text: c.name,
+      value: quoteIdentifierIfNecessary(c.name),
+      type: c.type,
+      label: c.name,
     }));
     return mapFieldsToTypes(fields);
   }
@@ -102,3 +105,9 @@ export class MySqlDatasource extends SqlDatasource {
     };
   }
 }
+
+// schemaParams returns the parameters of the schema resources for a database, the database of the data source
+// if it's not set.
+function schemaParams(dataset?: string) {
+  return dataset ? { schema: unquoteIdentifier(dataset) } : {};
+}
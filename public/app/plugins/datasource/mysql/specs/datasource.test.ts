@@ -1,12 +1,6 @@
 import { of } from 'rxjs';
 
-import {
-  dataFrameToJSON,
-  getDefaultTimeRange,
-  DataSourceInstanceSettings,
-  FieldType,
-  createDataFrame,
-} from '@grafana/data';
+import { dataFrameToJSON, getDefaultTimeRange, DataSourceInstanceSettings, createDataFrame } from '@grafana/data';
 import { FetchResponse } from '@grafana/runtime';
 import { SQLQuery, makeVariable } from '@grafana/sql';
 
@@ -61,95 +55,48 @@ describe('MySQLDatasource', () => {
       const results = await ds.metricFindQuery(query, { range: defaultRange });
       expect(results.length).toBe(0);
     });
-
-    it('should return an empty array when fetchDatasets is called', async () => {
-      const ds = setupTestContext(response).ds;
-      const results = await ds.fetchDatasets();
-      expect(results.length).toBe(0);
-    });
-
-    it('should return an empty array when fetchTables is called', async () => {
-      const ds = setupTestContext(response).ds;
-      const results = await ds.fetchTables();
-      expect(results.length).toBe(0);
-    });
-
-    it('should return an empty array when fetchFields is called', async () => {
-      const ds = setupTestContext(response).ds;
-      const query: SQLQuery = {
-        refId: 'refId',
-        table: 'schema.table',
-        dataset: 'dataset',
-      };
-      const results = await ds.fetchFields(query);
-      expect(results.length).toBe(0);
-    });
   });
 
-  describe('When runSql returns a populated dataframe', () => {
+  describe('When fetching the schema', () => {
     it('should return a list of datasets when fetchDatasets is called', async () => {
-      const fetchDatasetsResponse = {
-        results: {
-          datasets: {
-            refId: 'datasets',
-            frames: [
-              dataFrameToJSON(
-                createDataFrame({
-                  fields: [{ name: 'name', type: FieldType.string, values: ['test1', 'test2', 'test3'] }],
-                })
-              ),
-            ],
-          },
-        },
-      };
-      const { ds } = setupTestContext(fetchDatasetsResponse);
+      const { ds } = setupTestContext({});
+      const getResource = jest.spyOn(ds, 'getResource').mockResolvedValue(['test1', 'test-2']);
 
       const results = await ds.fetchDatasets();
-      expect(results.length).toBe(3);
-      expect(results).toEqual(['test1', 'test2', 'test3']);
+      expect(getResource).toHaveBeenCalledWith('schemas');
+      expect(results).toEqual(['test1', '`test-2`']);
     });
 
     it('should return a list of tables when fetchTables is called', async () => {
-      const fetchTableResponse = {
-        results: {
-          tables: {
-            refId: 'tables',
-            frames: [
-              dataFrameToJSON(
-                createDataFrame({
-                  fields: [{ name: 'table_name', type: FieldType.string, values: ['test1', 'test2', 'test3'] }],
-                })
-              ),
-            ],
-          },
-        },
-      };
-      const { ds } = setupTestContext(fetchTableResponse);
+      const { ds } = setupTestContext({});
+      const getResource = jest.spyOn(ds, 'getResource').mockResolvedValue([
+        { name: 'test1', type: 'BASE TABLE' },
+        { name: 'test2', type: 'VIEW' },
+      ]);
 
-      const results = await ds.fetchTables();
-      expect(results.length).toBe(3);
-      expect(results).toEqual(['test1', 'test2', 'test3']);
+      expect(await ds.fetchTables('`grafana`')).toEqual(['test1', 'test2']);
+      expect(getResource).toHaveBeenCalledWith('tables', { schema: 'grafana' });
+
+      await ds.fetchTables();
+      expect(getResource).toHaveBeenLastCalledWith('tables', {});
+    });
+
+    it('should return an empty array when fetchFields is called without a table', async () => {
+      const { ds } = setupTestContext({});
+      const getResource = jest.spyOn(ds, 'getResource');
+
+      const results = await ds.fetchFields({ refId: 'fields', dataset: 'dataset' });
+      expect(results).toEqual([]);
+      expect(getResource).not.toHaveBeenCalled();
     });
 
     it('should return a list of fields when fetchFields is called', async () => {
-      const fetchFieldsResponse = {
-        results: {
-          fields: {
-            refId: 'fields',
-            frames: [
-              dataFrameToJSON(
-                createDataFrame({
-                  fields: [
-                    { name: 'column_name', type: FieldType.string, values: ['test1', 'test2', 'test3'] },
-                    { name: 'data_type', type: FieldType.string, values: ['int', 'char', 'bool'] },
-                  ],
-                })
-              ),
-            ],
-          },
-        },
-      };
-      const { ds } = setupTestContext(fetchFieldsResponse);
+      const { ds } = setupTestContext({});
+      const getResource = jest.spyOn(ds, 'getResource').mockResolvedValue([
+        { name: 'test1', type: 'int', nullable: false },
+        { name: 'test2', type: 'char', nullable: true },
+        { name: 'test3', type: 'bool', nullable: true },
+      ]);
 
       const sqlQuery: SQLQuery = {
         refId: 'fields',
@@ -157,6 +104,7 @@ describe('MySQLDatasource', () => {
         dataset: 'dataset',
       };
       const results = await ds.fetchFields(sqlQuery);
+      expect(getResource).toHaveBeenCalledWith('columns', { schema: 'dataset', table: 'table' });
       expect(results.length).toBe(3);
       expect(results[0].label).toBe('test1');
       expect(results[0].value).toBe('test1');
@@ -168,6 +116,14 @@ describe('MySQLDatasource', () => {
       expect(results[2].value).toBe('test3');
       expect(results[2].type).toBe('bool');
     });
+
+    it('should read the database of qualified tables when fetchFields is called', async () => {
+      const { ds } = setupTestContext({});
+      const getResource = jest.spyOn(ds, 'getResource').mockResolvedValue([]);
+
+      await ds.fetchFields({ refId: 'fields', table: 'other.`my-table`', dataset: 'dataset' });
+      expect(getResource).toHaveBeenCalledWith('columns', { schema: 'other', table: 'my-table' });
+    });
   });
 
   describe('When performing metricFindQuery that returns multiple string fields', () => {
@@ -1,9 +1,10 @@
 import { css } from '@emotion/css';
 import React from 'react';
 
-import { QueryEditorProps, SelectableValue } from '@grafana/data';
+import { CoreApp, QueryEditorProps, SelectableValue } from '@grafana/data';
 import { config, reportInteraction } from '@grafana/runtime';
 import {
+  Alert,
   Button,
   FileDropzone,
   HorizontalGroup,
@@ -47,8 +48,11 @@ class TempoQueryFieldComponent extends React.PureComponent<Props, State> {
   // Also do this if queryType is 'clear' (which is the case when the user changes the query type)
   // otherwise if the user changes the query type and refreshes the page, no query type will be selected
   // which is inconsistent with how the UI was originally when they selected the Tempo data source.
+  // Alert rules only support TraceQL metrics queries, other query types are replaced by the TraceQL query type.
   async componentDidMount() {
-    if (!this.props.query.queryType || this.props.query.queryType === 'clear') {
+    const unsupportedInAlerting =
+      this.props.app === CoreApp.UnifiedAlerting && this.props.query.queryType !== DEFAULT_QUERY_TYPE;
+    if (!this.props.query.queryType || this.props.query.queryType === 'clear' || unsupportedInAlerting) {
       this.props.onChange({
         ...this.props.query,
         queryType: DEFAULT_QUERY_TYPE,
@@ -71,11 +75,15 @@ class TempoQueryFieldComponent extends React.PureComponent<Props, State> {
 
     const graphDatasourceUid = datasource.serviceMap?.datasourceUid;
 
-    let queryTypeOptions: Array<SelectableValue<TempoQueryType>> = [
-      { value: 'traceqlSearch', label: 'Search' },
-      { value: 'traceql', label: 'TraceQL' },
-      { value: 'serviceMap', label: 'Service Graph' },
-    ];
+    // Only TraceQL metrics queries are executed by the backend, so they are the only queries alert rules can use.
+    const isAlerting = app === CoreApp.UnifiedAlerting;
+    let queryTypeOptions: Array<SelectableValue<TempoQueryType>> = isAlerting
+      ? [{ value: 'traceql', label: 'TraceQL' }]
+      : [
+          { value: 'traceqlSearch', label: 'Search' },
+          { value: 'traceql', label: 'TraceQL' },
+          { value: 'serviceMap', label: 'Service Graph' },
+        ];
 
     // Migrate user to new query type if they are using the old search query type
     if (
@@ -137,18 +145,25 @@ class TempoQueryFieldComponent extends React.PureComponent<Props, State> {
                 }}
                 size="md"
               />
-              <Button
-                variant="secondary"
-                size="sm"
-                onClick={() => {
-                  this.setState({ uploadModalOpen: true });
-                }}
-              >
-                Import trace
-              </Button>
+              {!isAlerting && (
+                <Button
+                  variant="secondary"
+                  size="sm"
+                  onClick={() => {
+                    this.setState({ uploadModalOpen: true });
+                  }}
+                >
+                  Import trace
+                </Button>
+              )}
             </HorizontalGroup>
           </InlineField>
         </InlineFieldRow>
+        {isAlerting && (
+          <Alert title="Only TraceQL metrics queries are supported in alert rules" severity="info">
+            Use a metrics function such as {'{ } | rate()'} to get time series from spans.
+          </Alert>
+        )}
         {query.queryType === 'traceqlSearch' && (
           <TraceQLSearch
             datasource={this.props.datasource}
@@ -52,7 +52,6 @@ import {
 import TempoLanguageProvider from './language_provider';
 import { createTableFrameFromMetricsSummaryQuery, emptyResponse, MetricsSummary } from './metricsSummary';
 import {
-  formatTraceQLMetrics,
   formatTraceQLResponse,
   transformFromOTLP as transformFromOTEL,
   transformTrace,
@@ -306,7 +305,7 @@ export class TempoDatasource extends DataSourceWithBackend<TempoQuery, TempoJson
               grafana_version: config.buildInfo.version,
               query: queryValue ?? '',
             });
-            subQueries.push(this.handleTraceQlMetricsQuery(options, queryValue));
+            subQueries.push(this.handleTraceQlMetricsQuery(options, targets.traceql, queryValue));
           } else {
             reportInteraction('grafana_traces_traceql_queried', {
               datasourceType: 'tempo',
@@ -641,25 +640,20 @@ export class TempoDatasource extends DataSourceWithBackend<TempoQuery, TempoJson
     }
   };
 
-  handleTraceQlMetricsQuery = (
+  /**
+   * TraceQL metrics queries are executed by the backend, which returns them as time series so they can also be
+   * used in alert and recording rules.
+   */
+  handleTraceQlMetricsQuery(
     options: DataQueryRequest<TempoQuery>,
+    targets: TempoQuery[],
     queryValue: string
-  ): Observable<DataQueryResponse> => {
-    return this._request('/api/metrics/query_range', {
-      query: queryValue,
-      start: options.range.from.unix(),
-      end: options.range.to.unix(),
-    }).pipe(
-      map((response) => {
-        return {
-          data: formatTraceQLMetrics(queryValue, response.data),
-        };
-      }),
-      catchError((err) => {
-        return of({ error: { message: getErrorMessage(err.data.message) }, data: [] });
-      })
-    );
-  };
+  ): Observable<DataQueryResponse> {
+    return super.query({
+      ...options,
+      targets: [{ ...targets[0], query: queryValue, queryType: 'traceqlMetrics' }],
+    });
+  }
 
   traceIdQueryRequest(options: DataQueryRequest<TempoQuery>, targets: TempoQuery[]): DataQueryRequest<TempoQuery> {
     const request = {
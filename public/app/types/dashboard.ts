@@ -32,6 +32,8 @@ export interface SaveDashboardResponseDTO {
   uid: string;
   url: string;
   version: number;
+  /** Set when the dashboard was merged with changes saved since it was loaded, so it must be loaded again */
+  merged?: boolean;
 }
 
 export interface DashboardMeta {
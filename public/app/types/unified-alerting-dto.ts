@@ -216,6 +216,7 @@ export interface PostableGrafanaRuleDefinition {
   data: AlertQuery[];
   is_paused?: boolean;
   notification_settings?: GrafanaNotificationSettings;
+  live_channel?: string;
 }
 export interface GrafanaRuleDefinition extends PostableGrafanaRuleDefinition {
   id?: string;